@@ -0,0 +1,50 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptValue holds one element of a header, such as Accept or
+// Accept-Encoding, that holds a list of values with optional
+// quality factors.
+type acceptValue struct {
+	value string
+	q     float64
+}
+
+// parseAccept parses the given header values, returning all the
+// elements they contain in the order they appear. Elements without a
+// quality factor are given a quality of 1; any other parameters are
+// discarded.
+func parseAccept(header []string) []acceptValue {
+	var vals []acceptValue
+	for _, h := range header {
+		for _, elem := range strings.Split(h, ",") {
+			params := strings.Split(elem, ";")
+			v := acceptValue{
+				value: strings.ToLower(strings.TrimSpace(params[0])),
+				q:     1,
+			}
+			if v.value == "" {
+				continue
+			}
+			for _, param := range params[1:] {
+				name, val, _ := strings.Cut(param, "=")
+				if strings.TrimSpace(name) != "q" {
+					continue
+				}
+				q, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+				if err != nil {
+					q = 0
+				}
+				v.q = q
+			}
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader is the header from which the request identifier
+// recorded in AccessLogEntry is taken. If the request does not have
+// the header, it is taken from the response instead, so that a
+// handler can set it.
+const RequestIDHeader = "X-Request-Id"
+
+// AccessLogger is the interface implemented by Server.AccessLog.
+type AccessLogger interface {
+	// LogAccess records a request that has been served.
+	LogAccess(ctx context.Context, e AccessLogEntry)
+}
+
+// AccessLogEntry holds the details of a served request
+// passed to an AccessLogger.
+type AccessLogEntry struct {
+	// RequestInfo holds the details also passed to
+	// Server.Observe, including the route's path pattern.
+	RequestInfo
+
+	// Time holds the time at which the request was received.
+	Time time.Time
+
+	// RemoteAddr holds the network address of the client.
+	RemoteAddr string
+
+	// User holds the user name from the request's basic
+	// authentication credentials, if any.
+	User string
+
+	// RequestURI holds the request target as sent by the client.
+	RequestURI string
+
+	// Proto holds the protocol of the request, such as "HTTP/1.1".
+	Proto string
+
+	// Referer holds the request's Referer header.
+	Referer string
+
+	// UserAgent holds the request's User-Agent header.
+	UserAgent string
+
+	// RequestID holds the request identifier (see RequestIDHeader).
+	RequestID string
+}
+
+// AccessLogFormat specifies the format of the lines
+// written by a logger returned by NewAccessLogger.
+type AccessLogFormat int
+
+const (
+	// AccessLogCombined specifies the Apache combined log format,
+	// followed by the quoted route path pattern, the duration
+	// in milliseconds and the quoted request identifier. For example:
+	//
+	//	10.0.0.1 - bob [10/Oct/2026:13:55:36 +0000] "GET /items/42 HTTP/1.1" 200 2326 "-" "curl/8.0" "/items/:id" 0.512 "abc123"
+	AccessLogCombined AccessLogFormat = iota
+
+	// AccessLogJSON specifies a JSON object on each line.
+	AccessLogJSON
+)
+
+// NewAccessLogger returns an AccessLogger that writes a line in the
+// given format to w for each request. It is safe to use the logger
+// from several goroutines at once.
+func NewAccessLogger(w io.Writer, format AccessLogFormat) AccessLogger {
+	return &writerAccessLogger{
+		w:      w,
+		format: format,
+	}
+}
+
+type writerAccessLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format AccessLogFormat
+}
+
+// LogAccess implements AccessLogger.LogAccess.
+func (l *writerAccessLogger) LogAccess(ctx context.Context, e AccessLogEntry) {
+	var line []byte
+	if l.format == AccessLogJSON {
+		line = jsonAccessLine(e)
+	} else {
+		line = combinedAccessLine(e)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(line)
+}
+
+// combinedAccessLine returns e formatted in the
+// AccessLogCombined format.
+func combinedAccessLine(e AccessLogEntry) []byte {
+	host, _, err := net.SplitHostPort(e.RemoteAddr)
+	if err != nil {
+		host = e.RemoteAddr
+	}
+	size := "-"
+	if e.BytesWritten > 0 {
+		size = strconv.FormatInt(e.BytesWritten, 10)
+	}
+	return []byte(fmt.Sprintf("%s - %s [%s] %s %d %s %s %s %s %s %s\n",
+		orDash(host),
+		orDash(e.User),
+		e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(e.Method+" "+e.RequestURI+" "+e.Proto),
+		e.Status,
+		size,
+		strconv.Quote(orDash(e.Referer)),
+		strconv.Quote(orDash(e.UserAgent)),
+		strconv.Quote(orDash(e.PathPattern)),
+		strconv.FormatFloat(milliseconds(e.Duration), 'f', 3, 64),
+		strconv.Quote(orDash(e.RequestID)),
+	))
+}
+
+// jsonAccessEntry holds the form of the lines
+// written in the AccessLogJSON format.
+type jsonAccessEntry struct {
+	Time         time.Time `json:"time"`
+	RemoteAddr   string    `json:"remote_addr"`
+	User         string    `json:"user,omitempty"`
+	Method       string    `json:"method"`
+	RequestURI   string    `json:"uri"`
+	Route        string    `json:"route"`
+	Proto        string    `json:"proto"`
+	Status       int       `json:"status"`
+	BytesRead    int64     `json:"bytes_read"`
+	BytesWritten int64     `json:"bytes_written"`
+	DurationMS   float64   `json:"duration_ms"`
+	Referer      string    `json:"referer,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	RequestID    string    `json:"request_id,omitempty"`
+}
+
+// jsonAccessLine returns e formatted in the
+// AccessLogJSON format.
+func jsonAccessLine(e AccessLogEntry) []byte {
+	data, _ := json.Marshal(jsonAccessEntry{
+		Time:         e.Time,
+		RemoteAddr:   e.RemoteAddr,
+		User:         e.User,
+		Method:       e.Method,
+		RequestURI:   e.RequestURI,
+		Route:        e.PathPattern,
+		Proto:        e.Proto,
+		Status:       e.Status,
+		BytesRead:    e.BytesRead,
+		BytesWritten: e.BytesWritten,
+		DurationMS:   milliseconds(e.Duration),
+		Referer:      e.Referer,
+		UserAgent:    e.UserAgent,
+		RequestID:    e.RequestID,
+	})
+	return append(data, '\n')
+}
+
+// NewSlogAccessLogger returns an AccessLogger that logs
+// each request to logger at the info level.
+func NewSlogAccessLogger(logger *slog.Logger) AccessLogger {
+	return slogAccessLogger{logger}
+}
+
+type slogAccessLogger struct {
+	logger *slog.Logger
+}
+
+// LogAccess implements AccessLogger.LogAccess.
+func (l slogAccessLogger) LogAccess(ctx context.Context, e AccessLogEntry) {
+	attrs := []slog.Attr{
+		slog.String("remote_addr", e.RemoteAddr),
+		slog.String("method", e.Method),
+		slog.String("uri", e.RequestURI),
+		slog.String("route", e.PathPattern),
+		slog.String("proto", e.Proto),
+		slog.Int("status", e.Status),
+		slog.Int64("bytes_read", e.BytesRead),
+		slog.Int64("bytes_written", e.BytesWritten),
+		slog.Duration("duration", e.Duration),
+	}
+	for _, a := range []struct{ key, value string }{
+		{"user", e.User},
+		{"referer", e.Referer},
+		{"user_agent", e.UserAgent},
+		{"request_id", e.RequestID},
+	} {
+		if a.value != "" {
+			attrs = append(attrs, slog.String(a.key, a.value))
+		}
+	}
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "httprequest: request", attrs...)
+}
+
+// newAccessLogEntry returns the access log entry for req, which was
+// received at the given time and resulted in a response with the
+// given headers.
+func newAccessLogEntry(req *http.Request, start time.Time, respHeader http.Header, info RequestInfo) AccessLogEntry {
+	user, _, _ := req.BasicAuth()
+	requestID := req.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = respHeader.Get(RequestIDHeader)
+	}
+	return AccessLogEntry{
+		RequestInfo: info,
+		Time:        start,
+		RemoteAddr:  req.RemoteAddr,
+		User:        user,
+		RequestURI:  req.RequestURI,
+		Proto:       req.Proto,
+		Referer:     req.Referer(),
+		UserAgent:   req.UserAgent(),
+		RequestID:   requestID,
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type accessLogHandlers struct{}
+
+func (accessLogHandlers) Get(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /items/:id"`
+	ID                string `httprequest:"id,path"`
+}) (string, error) {
+	if arg.ID == "missing" {
+		return "", httprequest.Errorf(httprequest.CodeNotFound, "no item")
+	}
+	p.Response.Header().Set(httprequest.RequestIDHeader, "generated-id")
+	return "item " + arg.ID, nil
+}
+
+func serveAccessLogRequest(c *qt.C, logger httprequest.AccessLogger, path string, header http.Header) {
+	srv := httprequest.Server{
+		AccessLog: logger,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (accessLogHandlers, context.Context, error) {
+		return accessLogHandlers{}, p.Context, nil
+	}))
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = "10.0.0.1:4567"
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestAccessLogCombined(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	logger := httprequest.NewAccessLogger(&buf, httprequest.AccessLogCombined)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("bob", "secret")
+	serveAccessLogRequest(c, logger, "/items/42?x=1", http.Header{
+		"Authorization":             req.Header["Authorization"],
+		"Referer":                   {"http://example.com/"},
+		"User-Agent":                {"test-agent/1.0"},
+		httprequest.RequestIDHeader: {"abc123"},
+	})
+	serveAccessLogRequest(c, logger, "/items/missing", nil)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	c.Assert(lines, qt.HasLen, 2)
+	c.Assert(lines[0], qt.Matches, `10\.0\.0\.1 - bob \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [-+]\d{4}\] "GET /items/42\?x=1 HTTP/1\.1" 200 9 "http://example\.com/" "test-agent/1\.0" "/items/:id" \d+\.\d{3} "abc123"`)
+	c.Assert(lines[1], qt.Matches, `10\.0\.0\.1 - - \[.*\] "GET /items/missing HTTP/1\.1" 404 \d+ "-" "-" "/items/:id" \d+\.\d{3} "-"`)
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	serveAccessLogRequest(c, httprequest.NewAccessLogger(&buf, httprequest.AccessLogJSON), "/items/42", nil)
+	var entry map[string]interface{}
+	c.Assert(json.Unmarshal(buf.Bytes(), &entry), qt.IsNil)
+	c.Assert(strings.Count(buf.String(), "\n"), qt.Equals, 1)
+	c.Assert(entry["time"], qt.Not(qt.Equals), "")
+	c.Assert(entry["duration_ms"], qt.Not(qt.IsNil))
+	delete(entry, "time")
+	delete(entry, "duration_ms")
+	c.Assert(entry, qt.DeepEquals, map[string]interface{}{
+		"remote_addr":   "10.0.0.1:4567",
+		"method":        "GET",
+		"uri":           "/items/42",
+		"route":         "/items/:id",
+		"proto":         "HTTP/1.1",
+		"status":        float64(http.StatusOK),
+		"bytes_read":    float64(0),
+		"bytes_written": float64(9),
+		"request_id":    "generated-id",
+	})
+}
+
+func TestAccessLogSlog(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	serveAccessLogRequest(c, httprequest.NewSlogAccessLogger(logger), "/items/42", http.Header{
+		"User-Agent": {"test-agent/1.0"},
+	})
+	var entry map[string]interface{}
+	c.Assert(json.Unmarshal(buf.Bytes(), &entry), qt.IsNil)
+	c.Assert(entry, qt.DeepEquals, map[string]interface{}{
+		"level":         "INFO",
+		"msg":           "httprequest: request",
+		"remote_addr":   "10.0.0.1:4567",
+		"method":        "GET",
+		"uri":           "/items/42",
+		"route":         "/items/:id",
+		"proto":         "HTTP/1.1",
+		"status":        float64(http.StatusOK),
+		"bytes_read":    float64(0),
+		"bytes_written": float64(9),
+		"user_agent":    "test-agent/1.0",
+		"request_id":    "generated-id",
+	})
+}
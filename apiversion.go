@@ -0,0 +1,150 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIVersionHeader holds the name of the response header that
+// is taken to hold the API version of the server when
+// Client.APIVersionHeader is empty.
+const DefaultAPIVersionHeader = "API-Version"
+
+// APIVersionInfo holds the API version and deprecation details reported
+// by the headers of a response, passed to Client.OnAPIVersion.
+type APIVersionInfo struct {
+	// Method holds the HTTP method of the request.
+	Method string
+
+	// URL holds the URL of the request.
+	URL string
+
+	// Version holds the API version of the server, from the
+	// header named by Client.APIVersionHeader, or
+	// DefaultAPIVersionHeader if that is empty.
+	Version string
+
+	// Deprecated holds whether the response has a Deprecation
+	// header (RFC 9745) signifying that the resource is, or will
+	// be, deprecated.
+	Deprecated bool
+
+	// DeprecationDate holds the time at which the resource was or
+	// will be deprecated, if the Deprecation header specifies one.
+	DeprecationDate time.Time
+
+	// Sunset holds the time at which the resource is expected to
+	// become unavailable, from the Sunset header (RFC 8594), if any.
+	Sunset time.Time
+
+	// Link holds the URL of the documentation of the deprecation
+	// or sunset, from the Link header with the relation type
+	// "deprecation" or "sunset", if any.
+	Link string
+}
+
+// setAPIVersion adds the API version specified by c.APIVersion to req,
+// either in the header named by c.APIVersionHeader or, if that is
+// empty, as the version parameter of each media range in the Accept
+// header, as understood by Server.VersionedHandlers. Versions that the
+// request already specifies are left unchanged.
+func (c *Client) setAPIVersion(req *http.Request) {
+	if c.APIVersion == "" {
+		return
+	}
+	if c.APIVersionHeader != "" {
+		if req.Header.Get(c.APIVersionHeader) == "" {
+			req.Header.Set(c.APIVersionHeader, c.APIVersion)
+		}
+		return
+	}
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		accept = JSONCodec.ContentType
+	}
+	mediaRanges := strings.Split(accept, ",")
+	for i, mediaRange := range mediaRanges {
+		mediaRange = strings.TrimSpace(mediaRange)
+		if _, params, err := mime.ParseMediaType(mediaRange); err == nil && params["version"] != "" {
+			mediaRanges[i] = mediaRange
+			continue
+		}
+		mediaRanges[i] = mediaRange + "; version=" + c.APIVersion
+	}
+	req.Header.Set("Accept", strings.Join(mediaRanges, ", "))
+}
+
+// apiVersionInfo returns the API version details of resp, the
+// response to req, and reports whether it has any.
+func (c *Client) apiVersionInfo(req *http.Request, resp *http.Response) (APIVersionInfo, bool) {
+	versionHeader := c.APIVersionHeader
+	if versionHeader == "" {
+		versionHeader = DefaultAPIVersionHeader
+	}
+	info := APIVersionInfo{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Version: resp.Header.Get(versionHeader),
+	}
+	if d := strings.TrimSpace(resp.Header.Get("Deprecation")); d != "" && d != "false" && d != "?0" {
+		info.Deprecated = true
+		info.DeprecationDate = parseDeprecationDate(d)
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Sunset")); err == nil {
+		info.Sunset = t
+	}
+	if info.Version == "" && !info.Deprecated && info.Sunset.IsZero() {
+		return APIVersionInfo{}, false
+	}
+	info.Link = deprecationLink(resp.Header)
+	return info, true
+}
+
+// parseDeprecationDate returns the date held in the value of a
+// Deprecation header, which is either a structured field date such as
+// "@1688169599", as specified by RFC 9745, or an HTTP date, as used by
+// earlier drafts. It returns the zero time if there is no date.
+func parseDeprecationDate(s string) time.Time {
+	if secs, ok := strings.CutPrefix(s, "@"); ok {
+		if n, err := strconv.ParseInt(secs, 10, 64); err == nil {
+			return time.Unix(n, 0).UTC()
+		}
+		return time.Time{}
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// deprecationLink returns the URL of the first link in the Link
+// header of h with the relation type "deprecation" or "sunset".
+func deprecationLink(h http.Header) string {
+	for _, v := range h["Link"] {
+		for _, link := range strings.Split(v, ",") {
+			parts := strings.Split(link, ";")
+			url := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(url, "<") || !strings.HasSuffix(url, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "deprecation") || strings.EqualFold(rel, "sunset") {
+						return url[1 : len(url)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestClientAPIVersionWithVersionedHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	hs := srv.VersionedHandlers(
+		func(p httprequest.Params) (userV1Handlers, context.Context, error) {
+			return userV1Handlers{}, p.Context, nil
+		},
+		func(p httprequest.Params) (userV2Handlers, context.Context, error) {
+			return userV2Handlers{}, p.Context, nil
+		},
+	)
+	hsrv := httptest.NewServer(srv.NewRouter(hs))
+	defer hsrv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:    hsrv.URL,
+		APIVersion: "2",
+	}
+	var resp map[string]string
+	err := client.Get(context.Background(), "/users/bob", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, map[string]string{"fullName": "bob", "pattern": "/v2/users/:id"})
+}
+
+var clientAPIVersionRequestTests = []struct {
+	about            string
+	versionHeader    string
+	codecs           []httprequest.Codec
+	header           http.Header
+	expectAccept     string
+	expectAPIVersion string
+}{{
+	about:        "default accept",
+	expectAccept: "application/json; version=3",
+}, {
+	about:        "codecs",
+	codecs:       []httprequest.Codec{httprequest.XMLCodec},
+	expectAccept: "application/xml; version=3, application/json; version=3",
+}, {
+	about:        "existing accept",
+	header:       http.Header{"Accept": {"text/plain, application/json;version=1"}},
+	expectAccept: "text/plain; version=3, application/json;version=1",
+}, {
+	about:            "header",
+	versionHeader:    "X-Api-Version",
+	expectAPIVersion: "3",
+}, {
+	about:            "existing header",
+	versionHeader:    "X-Api-Version",
+	header:           http.Header{"X-Api-Version": {"1"}},
+	expectAPIVersion: "1",
+}}
+
+func TestClientAPIVersionRequest(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range clientAPIVersionRequestTests {
+		c.Run(test.about, func(c *qt.C) {
+			var got *http.Request
+			client := &httprequest.Client{
+				APIVersion:       "3",
+				APIVersionHeader: test.versionHeader,
+				Codecs:           test.codecs,
+				Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+					got = req
+					return &http.Response{
+						StatusCode: http.StatusNoContent,
+						Header:     http.Header{},
+						Body:       http.NoBody,
+					}, nil
+				}),
+			}
+			req := mustNewRequest("http://example.com/x", "GET", nil)
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			err := client.Do(context.Background(), req, nil)
+			c.Assert(err, qt.IsNil)
+			c.Assert(got.Header.Get("Accept"), qt.Equals, test.expectAccept)
+			if test.versionHeader != "" {
+				c.Assert(got.Header.Get(test.versionHeader), qt.Equals, test.expectAPIVersion)
+			}
+		})
+	}
+}
+
+var clientOnAPIVersionTests = []struct {
+	about         string
+	versionHeader string
+	header        http.Header
+	expectInfo    *httprequest.APIVersionInfo
+}{{
+	about:  "no version headers",
+	header: http.Header{"Content-Type": {"application/json"}},
+}, {
+	about:  "version only",
+	header: http.Header{"Api-Version": {"4"}},
+	expectInfo: &httprequest.APIVersionInfo{
+		Version: "4",
+	},
+}, {
+	about:         "custom version header",
+	versionHeader: "X-Api-Version",
+	header:        http.Header{"X-Api-Version": {"5"}, "Api-Version": {"4"}},
+	expectInfo: &httprequest.APIVersionInfo{
+		Version: "5",
+	},
+}, {
+	about: "deprecation and sunset",
+	header: http.Header{
+		"Deprecation": {"@1688169599"},
+		"Sunset":      {"Sat, 31 Dec 2033 23:59:59 GMT"},
+		"Link": {
+			`<https://example.com/next>; rel="next"`,
+			`<https://example.com/deprecation>; rel="deprecation"; type="text/html"`,
+		},
+	},
+	expectInfo: &httprequest.APIVersionInfo{
+		Deprecated:      true,
+		DeprecationDate: time.Unix(1688169599, 0).UTC(),
+		Sunset:          time.Date(2033, 12, 31, 23, 59, 59, 0, time.UTC),
+		Link:            "https://example.com/deprecation",
+	},
+}, {
+	about:  "deprecation without date",
+	header: http.Header{"Deprecation": {"true"}},
+	expectInfo: &httprequest.APIVersionInfo{
+		Deprecated: true,
+	},
+}, {
+	about:  "deprecation with HTTP date",
+	header: http.Header{"Deprecation": {"Sun, 11 Nov 2018 23:59:59 GMT"}},
+	expectInfo: &httprequest.APIVersionInfo{
+		Deprecated:      true,
+		DeprecationDate: time.Date(2018, 11, 11, 23, 59, 59, 0, time.UTC),
+	},
+}, {
+	about:  "sunset only",
+	header: http.Header{"Sunset": {"Sat, 31 Dec 2033 23:59:59 GMT"}, "Link": {`<https://example.com/sunset>; rel=sunset`}},
+	expectInfo: &httprequest.APIVersionInfo{
+		Sunset: time.Date(2033, 12, 31, 23, 59, 59, 0, time.UTC),
+		Link:   "https://example.com/sunset",
+	},
+}}
+
+func TestClientOnAPIVersion(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range clientOnAPIVersionTests {
+		c.Run(test.about, func(c *qt.C) {
+			var infos []httprequest.APIVersionInfo
+			client := &httprequest.Client{
+				APIVersionHeader: test.versionHeader,
+				OnAPIVersion: func(ctx context.Context, info httprequest.APIVersionInfo) {
+					infos = append(infos, info)
+				},
+				Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: http.StatusNoContent,
+						Header:     test.header,
+						Body:       http.NoBody,
+					}, nil
+				}),
+			}
+			err := client.Get(context.Background(), "http://example.com/x", nil)
+			c.Assert(err, qt.IsNil)
+			if test.expectInfo == nil {
+				c.Assert(infos, qt.HasLen, 0)
+				return
+			}
+			expect := *test.expectInfo
+			expect.Method = "GET"
+			expect.URL = "http://example.com/x"
+			c.Assert(infos, qt.DeepEquals, []httprequest.APIVersionInfo{expect})
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// Attachment is a type that can be returned from a handler to send a
+// file for download. Its content is streamed to the client as with
+// Stream, with a Content-Disposition header telling the client to
+// save it under the given name.
+type Attachment struct {
+	// Name holds the file name suggested to the client. If it is
+	// empty, no file name is suggested.
+	Name string
+
+	// ContentType holds the content type of the file. If it is
+	// empty, it is guessed from the extension of Name, falling
+	// back to "application/octet-stream".
+	ContentType string
+
+	// Content holds the contents of the file. If it implements
+	// io.Closer, it is closed after the response has been written.
+	Content io.Reader
+
+	// Size holds the size of the file. If it is greater than zero,
+	// it is sent as the Content-Length header.
+	Size int64
+}
+
+// writeAttachment writes a as the response to req.
+func (srv *Server) writeAttachment(w http.ResponseWriter, req *http.Request, a Attachment) {
+	params := make(map[string]string)
+	if a.Name != "" {
+		params["filename"] = a.Name
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", params))
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(a.Name))
+	}
+	var body io.ReadCloser
+	switch content := a.Content.(type) {
+	case nil:
+	case io.ReadCloser:
+		body = content
+	default:
+		body = io.NopCloser(content)
+	}
+	srv.writeStream(w, req, Stream{
+		ContentType: contentType,
+		Length:      a.Size,
+		Body:        body,
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var attachmentTests = []struct {
+	about             string
+	attachment        httprequest.Attachment
+	expectBody        string
+	expectType        string
+	expectDisposition string
+	expectLength      string
+}{{
+	about: "all fields set",
+	attachment: httprequest.Attachment{
+		Name:        "report.bin",
+		ContentType: "application/x-report",
+		Content:     strings.NewReader("report"),
+		Size:        6,
+	},
+	expectBody:        "report",
+	expectType:        "application/x-report",
+	expectDisposition: `attachment; filename=report.bin`,
+	expectLength:      "6",
+}, {
+	about: "content type from name",
+	attachment: httprequest.Attachment{
+		Name:    "data/export.json",
+		Content: strings.NewReader("{}"),
+	},
+	expectBody:        "{}",
+	expectType:        "application/json",
+	expectDisposition: `attachment; filename="data/export.json"`,
+}, {
+	about: "name needing encoding",
+	attachment: httprequest.Attachment{
+		Name:    "résumé.txt",
+		Content: strings.NewReader("cv"),
+	},
+	expectBody:        "cv",
+	expectType:        "text/plain; charset=utf-8",
+	expectDisposition: `attachment; filename*=utf-8''r%C3%A9sum%C3%A9.txt`,
+}, {
+	about: "no name or content",
+	attachment: httprequest.Attachment{
+		ContentType: "text/plain",
+	},
+	expectType:        "text/plain",
+	expectDisposition: "attachment",
+}, {
+	about: "unknown extension",
+	attachment: httprequest.Attachment{
+		Name:    "file.unknown-ext",
+		Content: strings.NewReader("x"),
+	},
+	expectBody:        "x",
+	expectType:        "application/octet-stream",
+	expectDisposition: `attachment; filename=file.unknown-ext`,
+}}
+
+func TestAttachment(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range attachmentTests {
+		c.Run(test.about, func(c *qt.C) {
+			h := testServer.Handle(func(*struct{}) (httprequest.Attachment, error) {
+				return test.attachment, nil
+			})
+			rec := httptest.NewRecorder()
+			h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+			c.Assert(rec.Code, qt.Equals, http.StatusOK)
+			c.Assert(rec.Body.String(), qt.Equals, test.expectBody)
+			c.Assert(rec.Header().Get("Content-Type"), qt.Equals, test.expectType)
+			c.Assert(rec.Header().Get("Content-Disposition"), qt.Equals, test.expectDisposition)
+			c.Assert(rec.Header().Get("Content-Length"), qt.Equals, test.expectLength)
+		})
+	}
+}
+
+func TestAttachmentClosesContent(t *testing.T) {
+	c := qt.New(t)
+
+	content := &closeRecorder{
+		Reader: strings.NewReader("data"),
+	}
+	h := testServer.Handle(func(*struct{}) (httprequest.Attachment, error) {
+		return httprequest.Attachment{
+			Name:    "data.bin",
+			Content: content,
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	c.Assert(rec.Body.String(), qt.Equals, "data")
+	c.Assert(content.closed, qt.IsTrue)
+}
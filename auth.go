@@ -0,0 +1,22 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	errgo "gopkg.in/errgo.v1"
+)
+
+// authorize checks that the request described by p meets the
+// authorization requirements of its route, if any.
+func (srv *Server) authorize(p Params) error {
+	if len(p.Metadata.Auth) == 0 {
+		return nil
+	}
+	if srv.Authorize == nil {
+		// Fail closed rather than serving a route whose
+		// requirements cannot be checked.
+		return errgo.Newf("route %s requires authorization but Server.Authorize is not set", p.PathPattern)
+	}
+	return srv.Authorize(p.Context, p, p.Metadata.Auth)
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type authHandlers struct{}
+
+func (authHandlers) Public(*struct {
+	httprequest.Route `httprequest:"GET /public"`
+}) (string, error) {
+	return "public", nil
+}
+
+func (authHandlers) Write(arg *struct {
+	httprequest.Route `httprequest:"PUT /items" auth:"items:read, items:write"`
+	Body              string `httprequest:",body"`
+}) (string, error) {
+	return arg.Body, nil
+}
+
+// scopeAuthorizer returns an authorizer that allows requests
+// whose X-Scopes header holds all the required scopes.
+func scopeAuthorizer(gotRequirements *[]string) func(context.Context, httprequest.Params, []string) error {
+	return func(ctx context.Context, p httprequest.Params, requirements []string) error {
+		*gotRequirements = requirements
+		scopes := strings.Split(p.Request.Header.Get("X-Scopes"), " ")
+	outer:
+		for _, req := range requirements {
+			for _, scope := range scopes {
+				if scope == req {
+					continue outer
+				}
+			}
+			return httprequest.Errorf(httprequest.CodeForbidden, "missing scope %q", req)
+		}
+		return nil
+	}
+}
+
+var authTests = []struct {
+	about              string
+	method             string
+	path               string
+	scopes             string
+	body               string
+	expectStatus       int
+	expectBody         interface{}
+	expectRequirements []string
+}{{
+	about:        "no requirements",
+	method:       "GET",
+	path:         "/public",
+	expectStatus: http.StatusOK,
+	expectBody:   "public",
+}, {
+	about:              "requirements met",
+	method:             "PUT",
+	path:               "/items",
+	scopes:             "items:read items:write",
+	body:               `"x"`,
+	expectStatus:       http.StatusOK,
+	expectBody:         "x",
+	expectRequirements: []string{"items:read", "items:write"},
+}, {
+	about:        "requirements not met checked before unmarshaling",
+	method:       "PUT",
+	path:         "/items",
+	scopes:       "items:read",
+	body:         "not JSON",
+	expectStatus: http.StatusForbidden,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeForbidden,
+		Message: `missing scope "items:write"`,
+	},
+	expectRequirements: []string{"items:read", "items:write"},
+}}
+
+func TestAuthorize(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range authTests {
+		c.Run(test.about, func(c *qt.C) {
+			var gotRequirements []string
+			srv := httprequest.Server{
+				Authorize: scopeAuthorizer(&gotRequirements),
+			}
+			router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (authHandlers, context.Context, error) {
+				return authHandlers{}, p.Context, nil
+			}))
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Scopes", test.scopes)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+			c.Assert(gotRequirements, qt.DeepEquals, test.expectRequirements)
+		})
+	}
+}
+
+func TestAuthorizeNotSet(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	h := srv.Handle(func(arg *struct {
+		httprequest.Route `httprequest:"GET /secret" auth:"admin"`
+	}) (string, error) {
+		return "secret", nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/secret", nil), nil)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusInternalServerError, &httprequest.RemoteError{
+		Message: "route /secret requires authorization but Server.Authorize is not set",
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// basicAuthServer returns a server that responds with the basic
+// authentication credentials of each request, recording them in
+// users. Requests to /redirect are redirected to /, and the first
+// request to /retry fails with http.StatusServiceUnavailable.
+func basicAuthServer(users *[]string) *httptest.Server {
+	failed := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, password, _ := req.BasicAuth()
+		*users = append(*users, user+":"+password)
+		switch req.URL.Path {
+		case "/redirect":
+			http.Redirect(w, req, "/", http.StatusFound)
+			return
+		case "/retry":
+			if !failed {
+				failed = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		httprequest.WriteJSON(w, http.StatusOK, user+":"+password)
+	}))
+}
+
+var basicAuthTests = []struct {
+	about       string
+	path        string
+	clientAuth  *httprequest.BasicAuth
+	header      string
+	opts        []httprequest.CallOption
+	expectUsers []string
+}{{
+	about:       "no credentials",
+	path:        "/",
+	expectUsers: []string{":"},
+}, {
+	about:       "client credentials",
+	path:        "/",
+	clientAuth:  &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+	expectUsers: []string{"bob:secret"},
+}, {
+	about:       "call option overrides client credentials",
+	path:        "/",
+	clientAuth:  &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+	opts:        []httprequest.CallOption{httprequest.WithBasicAuth("alice", "pw")},
+	expectUsers: []string{"alice:pw"},
+}, {
+	about:       "existing authorization header",
+	path:        "/",
+	clientAuth:  &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+	header:      "Basic YWxpY2U6cHc=",
+	expectUsers: []string{"alice:pw"},
+}, {
+	about:       "redirect within host",
+	path:        "/redirect",
+	clientAuth:  &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+	expectUsers: []string{"bob:secret", "bob:secret"},
+}, {
+	about:       "retry",
+	path:        "/retry",
+	opts:        []httprequest.CallOption{httprequest.WithBasicAuth("alice", "pw")},
+	expectUsers: []string{"alice:pw", "alice:pw"},
+}}
+
+func TestClientBasicAuth(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range basicAuthTests {
+		c.Run(test.about, func(c *qt.C) {
+			var users []string
+			srv := basicAuthServer(&users)
+			defer srv.Close()
+			client := &httprequest.Client{
+				BaseURL:   srv.URL,
+				BasicAuth: test.clientAuth,
+				Retry: &httprequest.RetryPolicy{
+					MaxAttempts: 2,
+					Backoff: func(int) time.Duration {
+						return 0
+					},
+				},
+			}
+			req, err := http.NewRequest("GET", test.path, nil)
+			c.Assert(err, qt.IsNil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			var resp string
+			err = client.Do(context.Background(), req, &resp, test.opts...)
+			c.Assert(err, qt.IsNil)
+			c.Assert(users, qt.DeepEquals, test.expectUsers)
+			c.Assert(resp, qt.Equals, test.expectUsers[len(test.expectUsers)-1])
+		})
+	}
+}
+
+func TestClientBasicAuthNotSentToOtherHosts(t *testing.T) {
+	c := qt.New(t)
+
+	var otherUsers []string
+	other := basicAuthServer(&otherUsers)
+	defer other.Close()
+	var users []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, _, _ := req.BasicAuth()
+		users = append(users, user)
+		http.Redirect(w, req, other.URL+"/", http.StatusFound)
+	}))
+	defer srv.Close()
+	// Use a different host name for the first server so
+	// that the redirect is to another host.
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	c.Assert(err, qt.IsNil)
+	client := &httprequest.Client{
+		BaseURL:   "http://localhost:" + port,
+		BasicAuth: &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+	}
+	var resp string
+	err = client.Get(context.Background(), "/", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(users, qt.DeepEquals, []string{"bob"})
+	c.Assert(otherUsers, qt.DeepEquals, []string{":"})
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// BatchCall holds one of the calls made by Client.CallAll.
+type BatchCall struct {
+	// Params holds the parameters of the call,
+	// as passed to Client.Call.
+	Params interface{}
+
+	// Resp holds the value the response is unmarshaled into,
+	// as passed to Client.Call.
+	Resp interface{}
+
+	// Err is set to the error returned by the call, if any.
+	Err error
+}
+
+// CallAll makes all the given calls concurrently, as if by calling
+// Client.Call with each call's Params and Resp, and sets the Err field
+// of each call to the error it returned. No more than limit calls are
+// made at a time; if limit is zero or less, all the calls are made at
+// once. The options are applied to every call.
+//
+// CallAll returns when all the calls have completed. Calls that have
+// not started by the time ctx is done are not made and have their Err
+// field set to the context's error. The error of the first call in
+// calls that failed is returned, or nil if all the calls succeeded.
+//
+// For example, to get several users by ID:
+//
+//	calls := make([]httprequest.BatchCall, len(ids))
+//	users := make([]User, len(ids))
+//	for i, id := range ids {
+//		calls[i] = httprequest.BatchCall{
+//			Params: &params.GetUser{ID: id},
+//			Resp:   &users[i],
+//		}
+//	}
+//	err := client.CallAll(ctx, calls, 10)
+func (c *Client) CallAll(ctx context.Context, calls []BatchCall, limit int, opts ...CallOption) error {
+	if limit <= 0 || limit > len(calls) {
+		limit = len(calls)
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range calls {
+		call := &calls[i]
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			call.Err = errgo.Mask(ctx.Err(), errgo.Any)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			call.Err = errgo.Mask(err, errgo.Any)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				<-sem
+			}()
+			call.Err = c.Call(ctx, call.Params, call.Resp, opts...)
+		}()
+	}
+	wg.Wait()
+	for _, call := range calls {
+		if call.Err != nil {
+			return call.Err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type batchItemRequest struct {
+	httprequest.Route `httprequest:"GET /item/:id"`
+	ID                int `httprequest:"id,path"`
+}
+
+// batchServer returns a server that responds to /item/:id with ten
+// times the ID, or a not found error if the ID is negative. The
+// highest number of requests it has handled at once is stored in
+// maxActive.
+func batchServer(maxActive *int32) *httptest.Server {
+	var active int32
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *batchItemRequest) (int, error) {
+			n := atomic.AddInt32(&active, 1)
+			defer atomic.AddInt32(&active, -1)
+			for {
+				max := atomic.LoadInt32(maxActive)
+				if n <= max || atomic.CompareAndSwapInt32(maxActive, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if req.ID < 0 {
+				return 0, httprequest.Errorf(httprequest.CodeNotFound, "item %d not found", req.ID)
+			}
+			return req.ID * 10, nil
+		}),
+	}))
+}
+
+func TestCallAll(t *testing.T) {
+	c := qt.New(t)
+
+	var maxActive int32
+	srv := batchServer(&maxActive)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	ids := []int{1, -2, 3, 4, -5, 6, 7, 8}
+	results := make([]int, len(ids))
+	calls := make([]httprequest.BatchCall, len(ids))
+	for i, id := range ids {
+		calls[i] = httprequest.BatchCall{
+			Params: &batchItemRequest{ID: id},
+			Resp:   &results[i],
+		}
+	}
+	err := client.CallAll(context.Background(), calls, 3)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/item/-2: item -2 not found`)
+	c.Assert(results, qt.DeepEquals, []int{10, 0, 30, 40, 0, 60, 70, 80})
+	for i, call := range calls {
+		switch ids[i] {
+		case -2, -5:
+			c.Assert(call.Err, qt.ErrorMatches, `Get http://.*/item/-[25]: item -[25] not found`)
+		default:
+			c.Assert(call.Err, qt.IsNil)
+		}
+	}
+	c.Assert(atomic.LoadInt32(&maxActive) <= 3, qt.Equals, true)
+}
+
+func TestCallAllNoLimit(t *testing.T) {
+	c := qt.New(t)
+
+	var maxActive int32
+	srv := batchServer(&maxActive)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	results := make([]int, 5)
+	calls := make([]httprequest.BatchCall, len(results))
+	for i := range calls {
+		calls[i] = httprequest.BatchCall{
+			Params: &batchItemRequest{ID: i},
+			Resp:   &results[i],
+		}
+	}
+	err := client.CallAll(context.Background(), calls, 0)
+	c.Assert(err, qt.IsNil)
+	c.Assert(results, qt.DeepEquals, []int{0, 10, 20, 30, 40})
+}
+
+func TestCallAllContextDone(t *testing.T) {
+	c := qt.New(t)
+
+	var maxActive int32
+	srv := batchServer(&maxActive)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := []httprequest.BatchCall{{
+		Params: &batchItemRequest{ID: 1},
+	}, {
+		Params: &batchItemRequest{ID: 2},
+	}}
+	err := client.CallAll(ctx, calls, 1)
+	c.Assert(err, qt.ErrorMatches, `context canceled`)
+	for _, call := range calls {
+		c.Assert(call.Err, qt.ErrorMatches, `context canceled`)
+	}
+	c.Assert(atomic.LoadInt32(&maxActive), qt.Equals, int32(0))
+}
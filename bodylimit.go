@@ -0,0 +1,84 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// withBodyLimit returns a handler that limits the size of request
+// bodies according to srv.MaxBodySize, srv.MaxBodySizeByType and the
+// given route metadata before calling h. If a request declares a body
+// length larger than the limit, an error is written without calling h.
+func (srv *Server) withBodyLimit(h httprouter.Handle, m RouteMetadata) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		limit := srv.maxBodySize(req)
+		if m.MaxBodySize != 0 {
+			limit = m.MaxBodySize
+		}
+		if limit <= 0 || req.Body == nil || req.Body == http.NoBody {
+			h(w, req, p)
+			return
+		}
+		if req.ContentLength > limit {
+			srv.WriteError(req.Context(), w, Errorf(CodeRequestTooLarge, "request body too large"))
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, limit)
+		h(w, req, p)
+	}
+}
+
+// maxBodySize returns the body size limit for req from
+// srv.MaxBodySizeByType, falling back to srv.MaxBodySize.
+func (srv *Server) maxBodySize(req *http.Request) int64 {
+	if len(srv.MaxBodySizeByType) == 0 {
+		return srv.MaxBodySize
+	}
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return srv.MaxBodySize
+	}
+	if limit, ok := srv.MaxBodySizeByType[mediaType]; ok {
+		return limit
+	}
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		if limit, ok := srv.MaxBodySizeByType[mediaType[:i]+"/*"]; ok {
+			return limit
+		}
+	}
+	return srv.MaxBodySize
+}
+
+// isBodyTooLarge reports whether err, or any error underlying it,
+// resulted from reading more than the allowed size of request body.
+func isBodyTooLarge(err error) bool {
+	for err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return true
+		}
+		if ferr, ok := err.(*FieldsError); ok {
+			for _, err := range ferr.errs {
+				if isBodyTooLarge(err) {
+					return true
+				}
+			}
+			return false
+		}
+		u, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return false
+		}
+		err = u.Underlying()
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type bodyLimitHandlers struct{}
+
+func (bodyLimitHandlers) Default(arg *struct {
+	httprequest.Route `httprequest:"POST /default"`
+	Body              string `httprequest:",body"`
+}) (int, error) {
+	return len(arg.Body), nil
+}
+
+func (bodyLimitHandlers) Larger(arg *struct {
+	httprequest.Route `httprequest:"POST /larger" maxbodysize:"100"`
+	Body              string `httprequest:",body"`
+}) (int, error) {
+	return len(arg.Body), nil
+}
+
+func (bodyLimitHandlers) Unlimited(arg *struct {
+	httprequest.Route `httprequest:"POST /unlimited" maxbodysize:"-1"`
+	Body              string `httprequest:",body"`
+}) (int, error) {
+	return len(arg.Body), nil
+}
+
+func (bodyLimitHandlers) Form(arg *struct {
+	httprequest.Route `httprequest:"POST /form"`
+	A                 string `httprequest:"a,form"`
+}) (int, error) {
+	return len(arg.A), nil
+}
+
+var bodyLimitTests = []struct {
+	about        string
+	path         string
+	contentType  string
+	body         string
+	expectStatus int
+	expectBody   interface{}
+}{{
+	about:      "body within server limit",
+	path:       "/default",
+	body:       `"12345"`,
+	expectBody: 5,
+}, {
+	about:        "body exceeds server limit",
+	path:         "/default",
+	body:         `"` + strings.Repeat("x", 20) + `"`,
+	expectStatus: http.StatusRequestEntityTooLarge,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeRequestTooLarge,
+		Message: "request body too large",
+	},
+}, {
+	about:      "route limit overrides server limit",
+	path:       "/larger",
+	body:       `"` + strings.Repeat("x", 20) + `"`,
+	expectBody: 20,
+}, {
+	about:      "route without limit",
+	path:       "/unlimited",
+	body:       `"` + strings.Repeat("x", 200) + `"`,
+	expectBody: 200,
+}, {
+	about:        "form body exceeds server limit",
+	path:         "/form",
+	contentType:  "application/x-www-form-urlencoded",
+	body:         "a=" + strings.Repeat("x", 20),
+	expectStatus: http.StatusRequestEntityTooLarge,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeRequestTooLarge,
+		Message: "request body too large",
+	},
+}}
+
+func TestMaxBodySize(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		MaxBodySize: 10,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (bodyLimitHandlers, context.Context, error) {
+		return bodyLimitHandlers{}, p.Context, nil
+	}))
+	for _, test := range bodyLimitTests {
+		c.Run(test.about, func(c *qt.C) {
+			contentType := test.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if test.expectStatus == 0 {
+				test.expectStatus = http.StatusOK
+			}
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
+
+func TestMaxBodySizeWithUnknownLength(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		MaxBodySize: 10,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (bodyLimitHandlers, context.Context, error) {
+		return bodyLimitHandlers{}, p.Context, nil
+	}))
+	req := httptest.NewRequest("POST", "/default", strings.NewReader(`"`+strings.Repeat("x", 20)+`"`))
+	req.Header.Set("Content-Type", "application/json")
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusRequestEntityTooLarge, &httprequest.RemoteError{
+		Code:    httprequest.CodeRequestTooLarge,
+		Message: "request body too large",
+	})
+}
+
+var bodyLimitByTypeTests = []struct {
+	about        string
+	path         string
+	contentType  string
+	body         string
+	expectStatus int
+}{{
+	about:        "exact media type limit",
+	path:         "/default",
+	contentType:  "application/json",
+	body:         `"` + strings.Repeat("x", 20) + `"`,
+	expectStatus: http.StatusRequestEntityTooLarge,
+}, {
+	about:       "exact media type limit with parameters",
+	path:        "/default",
+	contentType: "application/json; charset=utf-8",
+	body:        `"12"`,
+}, {
+	about:       "media range limit",
+	path:        "/form",
+	contentType: "application/x-www-form-urlencoded",
+	body:        "a=" + strings.Repeat("x", 50),
+}, {
+	about:        "media range limit exceeded",
+	path:         "/form",
+	contentType:  "application/x-www-form-urlencoded",
+	body:         "a=" + strings.Repeat("x", 100),
+	expectStatus: http.StatusRequestEntityTooLarge,
+}, {
+	about:        "server limit for other types",
+	path:         "/default",
+	contentType:  "text/plain",
+	body:         strings.Repeat("x", 2000),
+	expectStatus: http.StatusRequestEntityTooLarge,
+}, {
+	about:       "route limit takes precedence",
+	path:        "/unlimited",
+	contentType: "application/json",
+	body:        `"` + strings.Repeat("x", 200) + `"`,
+}}
+
+func TestMaxBodySizeByType(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		MaxBodySize: 1000,
+		MaxBodySizeByType: map[string]int64{
+			"application/json": 10,
+			"application/*":    80,
+		},
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (bodyLimitHandlers, context.Context, error) {
+		return bodyLimitHandlers{}, p.Context, nil
+	}))
+	for _, test := range bodyLimitByTypeTests {
+		c.Run(test.about, func(c *qt.C) {
+			req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if test.expectStatus == 0 {
+				test.expectStatus = http.StatusOK
+			}
+			c.Assert(rec.Code, qt.Equals, test.expectStatus, qt.Commentf("body: %s", rec.Body))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type cacheHandlers struct{}
+
+func (cacheHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /cached" cache:"max-age=60,private"`
+}) (string, error) {
+	return "ok", nil
+}
+
+func (cacheHandlers) Uncached(*struct {
+	httprequest.Route `httprequest:"GET /uncached"`
+}) (string, error) {
+	return "ok", nil
+}
+
+func (cacheHandlers) Fail(*struct {
+	httprequest.Route `httprequest:"GET /fail" cache:"no-store"`
+}) (string, error) {
+	return "", httprequest.Errorf(httprequest.CodeNotFound, "not found")
+}
+
+var cacheControlTests = []struct {
+	about              string
+	path               string
+	expectStatus       int
+	expectCacheControl string
+}{{
+	about:              "cache tag",
+	path:               "/cached",
+	expectStatus:       http.StatusOK,
+	expectCacheControl: "max-age=60, private",
+}, {
+	about:        "no cache tag",
+	path:         "/uncached",
+	expectStatus: http.StatusOK,
+}, {
+	about:        "error response",
+	path:         "/fail",
+	expectStatus: http.StatusNotFound,
+}}
+
+func TestCacheControl(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (cacheHandlers, context.Context, error) {
+		return cacheHandlers{}, p.Context, nil
+	}))
+	for _, test := range cacheControlTests {
+		c.Run(test.about, func(c *qt.C) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", test.path, nil))
+			c.Assert(rec.Code, qt.Equals, test.expectStatus)
+			c.Assert(rec.Header().Get("Cache-Control"), qt.Equals, test.expectCacheControl)
+		})
+	}
+}
+
+func TestCacheControlMetadata(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(*struct {
+		httprequest.Route `httprequest:"GET /x" cache:" no-cache , max-age=0"`
+	}) {
+	})
+	c.Assert(h.Metadata.CacheControl, qt.Equals, "no-cache, max-age=0")
+}
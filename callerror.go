@@ -0,0 +1,57 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// callError is the error returned by Client.Do when a call fails. Its
+// cause is that of the underlying error, which Unwrap also returns,
+// so that the cause can be found with errors.Is and errors.As as well
+// as errgo.Cause. It holds the debug dump of the call, if any (see
+// Client.DebugDump).
+type callError struct {
+	err  error
+	dump []byte
+}
+
+// callError returns err, the error returned by a call, as a
+// *callError, with the dump captured by d attached if appropriate.
+func (c *Client) callError(ctx context.Context, d *debugDumper, err error) error {
+	e := &callError{
+		err: err,
+	}
+	if d != nil {
+		e.dump = c.debugDump(ctx, d, err)
+	}
+	return e
+}
+
+// Error implements error.Error.
+func (e *callError) Error() string {
+	return e.err.Error()
+}
+
+// Cause implements errgo.Causer.Cause.
+func (e *callError) Cause() error {
+	return errgo.Cause(e.err)
+}
+
+// Underlying implements errgo.Wrapper.Underlying.
+func (e *callError) Underlying() error {
+	return e.err
+}
+
+// Message implements errgo.Wrapper.Message.
+func (e *callError) Message() string {
+	return ""
+}
+
+// Unwrap returns the cause of the error.
+func (e *callError) Unwrap() error {
+	return e.Cause()
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// CallOption configures a single call made by a Client. Options are
+// accepted by all the Client methods that make requests, such as
+// Client.Call and Client.Do, and take precedence over the Client's
+// own configuration for the call.
+type CallOption func(*callOptions)
+
+// callOptions holds the configuration specified by CallOptions.
+type callOptions struct {
+	header          http.Header
+	timeout         time.Duration
+	retry           *RetryPolicy
+	retrySet        bool
+	expectedStatus  []int
+	maxResponseSize int64
+	basicAuth       *BasicAuth
+	idempotencyKey  string
+	urlVars         httprouter.Params
+
+	// conditional holds whether the request has been made
+	// conditional by WithIfMatch, WithIfNoneMatch or
+	// WithIfUnmodifiedSince.
+	conditional bool
+
+	uploadProgress   func(Progress)
+	downloadProgress func(Progress)
+
+	// multipartForm holds the form passed to
+	// Client.CallMultipart, if any.
+	multipartForm *MultipartForm
+
+	// responseInfo holds where to store the details of the
+	// response for Client.CallWithResponse, if requested.
+	responseInfo **ResponseInfo
+
+	// routeType holds the type of the parameters
+	// passed to Client.Call, if any.
+	routeType string
+}
+
+// newCallOptions returns the configuration specified by opts.
+func newCallOptions(opts []CallOption) *callOptions {
+	o := new(callOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHeader returns a CallOption that adds the given header value to
+// the request, in addition to any headers added by the call's
+// parameters, for example:
+//
+//	err := client.Call(ctx, req, &resp, httprequest.WithHeader("X-Tenant", tenant))
+func WithHeader(key, value string) CallOption {
+	return func(o *callOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithTimeout returns a CallOption that limits the time taken by the
+// call, including any retries and reading the response body, to d.
+func WithTimeout(d time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRetry returns a CallOption that uses the given policy in place
+// of Client.Retry. If policy is nil, the request is not retried.
+func WithRetry(policy *RetryPolicy) CallOption {
+	return func(o *callOptions) {
+		o.retry = policy
+		o.retrySet = true
+	}
+}
+
+// WithExpectedStatus returns a CallOption that specifies the statuses
+// of the responses that are treated as successful. A response with one
+// of the statuses is unmarshaled into the call's result, even if it
+// would otherwise signify an error, and a response with any other
+// status results in an error, even if it would otherwise signify
+// success.
+func WithExpectedStatus(statuses ...int) CallOption {
+	return func(o *callOptions) {
+		o.expectedStatus = append(o.expectedStatus, statuses...)
+	}
+}
+
+// WithMaxResponseSize returns a CallOption that limits the size of the
+// body of a successful response that is read to n bytes, in place of
+// Client.MaxResponseSize. Reading a larger body fails with an error
+// with a *ResponseTooLargeError cause. If n is negative, the size is
+// not limited.
+func WithMaxResponseSize(n int64) CallOption {
+	return func(o *callOptions) {
+		o.maxResponseSize = n
+	}
+}
+
+// WithBasicAuth returns a CallOption that sends the given credentials
+// using the Basic authentication scheme, in place of Client.BasicAuth
+// and any Authorization header set by the call's parameters.
+func WithBasicAuth(username, password string) CallOption {
+	return func(o *callOptions) {
+		o.basicAuth = &BasicAuth{
+			Username: username,
+			Password: password,
+		}
+	}
+}
+
+// WithIdempotencyKey returns a CallOption that sends the given key in
+// the request's Idempotency-Key header, in place of any set by the
+// call's parameters or generated by the Client (see Client.Retry).
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// apply applies the options to req before it is sent.
+func (o *callOptions) apply(req *http.Request) {
+	for k, vs := range o.header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if o.basicAuth != nil {
+		req.SetBasicAuth(o.basicAuth.Username, o.basicAuth.Password)
+	}
+	if o.idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, o.idempotencyKey)
+	}
+	if o.multipartForm != nil {
+		o.multipartForm.setBody(req)
+	}
+}
+
+// retryPolicy returns the retry policy to use for the call,
+// given the client's policy.
+func (o *callOptions) retryPolicy(p *RetryPolicy) *RetryPolicy {
+	if o.retrySet {
+		return o.retry
+	}
+	return p
+}
+
+// isSuccess reports whether a response with the given
+// status should be treated as successful.
+func (o *callOptions) isSuccess(status int) bool {
+	if o.expectedStatus == nil {
+		return 200 <= status && status < 300
+	}
+	for _, s := range o.expectedStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// context returns the context to use for the call and a function
+// that must be called when the call's response has been read.
+func (o *callOptions) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// ResponseTooLargeError is the cause of the error returned when the
+// body of a successful response is larger than permitted by
+// Client.MaxResponseSize or WithMaxResponseSize.
+type ResponseTooLargeError struct {
+	// Limit holds the maximum permitted size of the body.
+	Limit int64
+}
+
+// Error implements error.Error.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// limitBody limits the size of the body of resp, if it is successful,
+// as specified by the options, or by maxSize, the client's limit, if
+// they do not specify one. It returns the limited body, or nil if it
+// is not limited.
+func (o *callOptions) limitBody(resp *http.Response, maxSize int64) *limitedReadCloser {
+	if o.maxResponseSize != 0 {
+		maxSize = o.maxResponseSize
+	}
+	if maxSize <= 0 || !o.isSuccess(resp.StatusCode) {
+		return nil
+	}
+	body := &limitedReadCloser{
+		ReadCloser: resp.Body,
+		limit:      maxSize,
+		n:          maxSize,
+	}
+	resp.Body = body
+	return body
+}
+
+// limitedReadCloser returns a *ResponseTooLargeError when more than
+// limit bytes are read from the ReadCloser.
+type limitedReadCloser struct {
+	io.ReadCloser
+	limit int64
+	n     int64
+}
+
+// Read implements io.Reader.Read.
+func (r *limitedReadCloser) Read(buf []byte) (int, error) {
+	if r.n < 0 {
+		return 0, &ResponseTooLargeError{Limit: r.limit}
+	}
+	// Allow one more byte than the limit so that
+	// we know when the limit has been exceeded.
+	if int64(len(buf)) > r.n+1 {
+		buf = buf[:r.n+1]
+	}
+	n, err := r.ReadCloser.Read(buf)
+	r.n -= int64(n)
+	if r.n < 0 {
+		return n + int(r.n), &ResponseTooLargeError{Limit: r.limit}
+	}
+	return n, err
+}
+
+// exceeded reports whether more than the permitted
+// number of bytes have been read from r.
+func (r *limitedReadCloser) exceeded() bool {
+	return r != nil && r.n < 0
+}
+
+// cancelReadCloser calls cancel when it is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.Close.
+func (r cancelReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
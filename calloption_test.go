@@ -0,0 +1,202 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type headerEchoRequest struct {
+	httprequest.Route `httprequest:"GET /echo"`
+	Tenant            string `httprequest:"X-Tenant,header"`
+}
+
+// headerEchoServer returns a server that responds with
+// the values of the X-Tenant and X-Trace headers.
+func headerEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		httprequest.WriteJSON(w, http.StatusOK, map[string][]string{
+			"tenant": req.Header["X-Tenant"],
+			"trace":  req.Header["X-Trace"],
+		})
+	}))
+}
+
+func TestCallWithHeader(t *testing.T) {
+	c := qt.New(t)
+
+	srv := headerEchoServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	var resp map[string][]string
+	err := client.Call(context.Background(), &headerEchoRequest{Tenant: "acme"}, &resp,
+		httprequest.WithHeader("X-Trace", "t1"),
+		httprequest.WithHeader("X-Trace", "t2"),
+		httprequest.WithHeader("X-Tenant", "other"),
+	)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, map[string][]string{
+		"tenant": {"acme", "other"},
+		"trace":  {"t1", "t2"},
+	})
+
+	resp = nil
+	err = client.Get(context.Background(), "/echo", &resp, httprequest.WithHeader("X-Trace", "t3"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, map[string][]string{
+		"tenant": nil,
+		"trace":  {"t3"},
+	})
+
+	// The options apply only to the call they're passed to.
+	resp = nil
+	err = client.Get(context.Background(), "/echo", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, map[string][]string{
+		"tenant": nil,
+		"trace":  nil,
+	})
+}
+
+// statusServer returns a server that responds to requests to
+// /status/:code with the given status and a JSON body holding the
+// code, and to requests to /slow after waiting for the request's
+// context to be done.
+func statusServer() *httptest.Server {
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, arg *struct {
+			httprequest.Route `httprequest:"GET /status/:code"`
+			Code              int `httprequest:"code,path"`
+		}) {
+			httprequest.WriteJSON(p.Response, arg.Code, arg.Code)
+		}),
+		srv.Handle(func(p httprequest.Params, arg *struct {
+			httprequest.Route `httprequest:"GET /slow"`
+		}) {
+			<-p.Context.Done()
+		}),
+	}))
+}
+
+var callOptionTests = []struct {
+	about       string
+	path        string
+	opts        []httprequest.CallOption
+	expectResp  int
+	expectError string
+}{{
+	about:      "no options",
+	path:       "/status/200",
+	expectResp: 200,
+}, {
+	about:      "expected error status",
+	path:       "/status/404",
+	opts:       []httprequest.CallOption{httprequest.WithExpectedStatus(http.StatusOK, http.StatusNotFound)},
+	expectResp: 404,
+}, {
+	about:       "unexpected success status",
+	path:        "/status/201",
+	opts:        []httprequest.CallOption{httprequest.WithExpectedStatus(http.StatusOK)},
+	expectError: `Get http://.*/status/201: unexpected HTTP response status: 201 Created`,
+}, {
+	about:       "response too large",
+	path:        "/status/200",
+	opts:        []httprequest.CallOption{httprequest.WithMaxResponseSize(2)},
+	expectError: `Get http://.*/status/200: .*response body exceeds 2 bytes`,
+}, {
+	about:      "response within limit",
+	path:       "/status/200",
+	opts:       []httprequest.CallOption{httprequest.WithMaxResponseSize(4)},
+	expectResp: 200,
+}, {
+	about:       "timeout",
+	path:        "/slow",
+	opts:        []httprequest.CallOption{httprequest.WithTimeout(10 * time.Millisecond)},
+	expectError: `Get "?http://.*/slow"?: context deadline exceeded`,
+}}
+
+func TestCallOptions(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	for _, test := range callOptionTests {
+		c.Run(test.about, func(c *qt.C) {
+			var resp int
+			err := client.Get(context.Background(), test.path, &resp, test.opts...)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(resp, qt.Equals, test.expectResp)
+		})
+	}
+}
+
+func TestCallWithTimeoutAndRawResponse(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var resp *http.Response
+	err := client.Get(context.Background(), "/status/200", &resp, httprequest.WithTimeout(5*time.Second))
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	// The body can still be read after the call has returned.
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "200")
+}
+
+func TestCallWithRetry(t *testing.T) {
+	c := qt.New(t)
+
+	var bodies []string
+	srv := retryServer([]int{http.StatusServiceUnavailable}, "", &bodies)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	err := client.Get(context.Background(), "/", nil, httprequest.WithRetry(nil))
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/: attempt 1 failed`)
+	c.Assert(bodies, qt.HasLen, 1)
+
+	// Without the option, the client's policy is used.
+	bodies = nil
+	srv1 := retryServer([]int{http.StatusServiceUnavailable}, "", &bodies)
+	defer srv1.Close()
+	client.BaseURL = srv1.URL
+	var resp string
+	err = client.Get(context.Background(), "/", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+	c.Assert(bodies, qt.HasLen, 2)
+}
@@ -11,9 +11,11 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"reflect"
 	"strings"
+	"time"
 
 	"gopkg.in/errgo.v1"
 )
@@ -35,8 +37,45 @@ type DoerWithContext interface {
 type Client struct {
 	// BaseURL holds the base URL to use when making
 	// HTTP requests.
+	//
+	// A URL with the "unix" scheme, such as
+	// unix:///run/foo.sock/v1, sends requests to the Unix domain
+	// socket whose path is the part of the URL path up to and
+	// including the first element ending in ".sock" or ".socket",
+	// using the rest of the path as the base path of the requests.
+	// Such URLs cannot be used with a custom Doer.
+	//
+	// BaseURL is not used when Resolver is non-nil.
 	BaseURL string
 
+	// GenerateRequestID specifies that each request that does not
+	// already have a RequestIDHeader, whether set by the call or
+	// propagated from the context (see ContextWithPropagatedHeaders),
+	// is sent with one holding a newly generated UUID, so that
+	// failures can be correlated with the server's logs. The
+	// identifier is passed to OnRequest and OnResponse, and errors
+	// returned by calls are prefixed with "request " and the
+	// identifier.
+	GenerateRequestID bool
+
+	// Header holds headers that are added to every request made by
+	// the Client that does not already have them, whether set by the
+	// call's parameters or by a CallOption.
+	Header http.Header
+
+	// Resolver, if non-nil, is used to find the base URLs of the
+	// endpoints that serve requests with relative URLs, such as
+	// those made by Call. Each time such a request is sent, including
+	// when it is retried, the endpoint it is sent to is chosen from
+	// those returned by the Resolver using Balancer.
+	Resolver Resolver
+
+	// Balancer chooses the endpoint that each request is sent to
+	// when Resolver is non-nil. If it is nil, endpoints are chosen
+	// at random. See NewRoundRobinBalancer and
+	// NewLeastPendingBalancer.
+	Balancer Balancer
+
 	// Doer holds a value that will be used to actually
 	// make the HTTP request. If it is nil, http.DefaultClient
 	// will be used instead. If Doer implements DoerWithContext,
@@ -49,6 +88,191 @@ type Client struct {
 	// way to create an UnmarshalError function for a given type. If
 	// this is nil, DefaultErrorUnmarshaler will be used.
 	UnmarshalError func(resp *http.Response) error
+
+	// ErrorTypes, if non-nil, holds the Go error types used for
+	// error responses holding RemoteErrors with registered codes:
+	// the error returned by the call has the typed error as its
+	// cause in place of the *RemoteError. It does not apply when
+	// UnmarshalError returns errors of other types.
+	ErrorTypes *ErrorTypes
+
+	// Codecs holds the encodings, in addition to JSON, that may be
+	// used for the bodies of successful responses. A response body
+	// is decoded with the codec whose ContentType matches the media
+	// type of the response. If Codecs is non-empty, requests without
+	// an Accept header are sent with one listing the media types of
+	// the codecs and application/json. Codecs without an Unmarshal
+	// function are ignored.
+	Codecs []Codec
+
+	// MaxErrorBodySize holds the maximum number of bytes of a
+	// response body that are read in order to report it when the
+	// response cannot be unmarshaled (see DecodeResponseError) or
+	// holds an error. If it is zero, DefaultMaxErrorBodySize is used.
+	// It does not apply when UnmarshalError is set.
+	MaxErrorBodySize int
+
+	// MaxResponseSize, if positive, holds the maximum size of the
+	// body of a successful response that is read. Reading a larger
+	// body, including one returned to the caller, fails with an
+	// error with a *ResponseTooLargeError cause. It can be
+	// overridden for a call with WithMaxResponseSize.
+	MaxResponseSize int64
+
+	// StrictResponses specifies that the JSON bodies of successful
+	// responses are checked strictly against the types they are
+	// unmarshaled into: a response holding an object member that
+	// does not correspond to a field, or a null value for a field
+	// that is not tagged with omitempty and whose type cannot
+	// represent null, results in a *DecodeResponseError. This is
+	// intended to catch differences between the client and server
+	// types in test and integration environments.
+	StrictResponses bool
+
+	// APIVersion, if non-empty, holds the version of the API that
+	// the Client uses, which is sent with every request that does
+	// not already specify a version. It is sent in the header named
+	// by APIVersionHeader or, if that is empty, as the version
+	// parameter of the media ranges in the Accept header, for
+	// example "Accept: application/json; version=2", as understood
+	// by Server.VersionedHandlers.
+	APIVersion string
+
+	// APIVersionHeader holds the name of the header used to send
+	// APIVersion, and in which responses are taken to report the API
+	// version of the server. If it is empty, the version is sent in
+	// the Accept header and DefaultAPIVersionHeader is used for
+	// responses.
+	APIVersionHeader string
+
+	// OnAPIVersion, if non-nil, is called with the details of each
+	// response that reports the API version of the server, or that
+	// the resource is deprecated or will be removed, so that client
+	// libraries can warn when they talk to newer or sunsetting
+	// servers.
+	OnAPIVersion func(ctx context.Context, info APIVersionInfo)
+
+	// Retry, if non-nil, specifies how requests that fail with
+	// transient errors are retried. When a response to be retried
+	// has a Retry-After header, the client waits for the time it
+	// specifies before retrying. If the wait would extend beyond
+	// the deadline of the request's context, the request is not
+	// retried and the response is returned as if there were no
+	// retries.
+	//
+	// When requests may be retried, POST and PATCH requests without
+	// an Idempotency-Key header are sent with a randomly generated
+	// one, the same for every attempt, so that the server can avoid
+	// repeating their side effects (see Server.IdempotencyStore).
+	// This also makes them eligible for retry by DefaultShouldRetry.
+	// See also WithIdempotencyKey.
+	Retry *RetryPolicy
+
+	// BasicAuth, if non-nil, holds credentials that are sent using
+	// the Basic authentication scheme with each request that does
+	// not already have an Authorization header. See also
+	// WithBasicAuth.
+	//
+	// The header is sent with every attempt when a request is
+	// retried. When the Doer is an *http.Client, it is also sent
+	// when following redirects within the same host (or its
+	// subdomains), but not to other hosts.
+	BasicAuth *BasicAuth
+
+	// Auth, if non-nil, provides the credentials sent in the
+	// Authorization header of requests that do not already have
+	// one, including those set by BasicAuth. When a response has the
+	// status http.StatusUnauthorized, the credential is invalidated
+	// and the request is sent once more with a new one, provided its
+	// body can be sent again. See NewCachedAuth.
+	Auth AuthProvider
+
+	// Signer, if non-nil, is called to sign each request
+	// immediately before it is sent, with the request as it will be
+	// sent, including its final URL and all its headers, and the
+	// request body, which remains available to be sent. It is called
+	// again each time the request is retried, so it should set
+	// headers with http.Header.Set rather than Add. An error returned
+	// by Signer is returned by the call with its cause preserved.
+	//
+	// The request body is read into memory to be passed to Signer,
+	// so Signer should not be used for very large uploads. Bodies
+	// of unknown length that cannot be obtained again with
+	// req.GetBody, such as multipart forms with files that cannot
+	// be reread, are not read; Signer is passed a nil body for them.
+	//
+	// See CanonicalRequest for a way of implementing
+	// HMAC-style signature schemes.
+	Signer func(req *http.Request, body []byte) error
+
+	// RequestCompression, if non-nil, specifies that the bodies of
+	// requests are compressed when the server is known to accept
+	// compressed requests. Bodies are compressed as they are sent,
+	// and those of unknown length are not compressed. Signer is
+	// called with the compressed body, which is then held in memory
+	// as described above.
+	RequestCompression *RequestCompression
+
+	// DeadlineHeader, if non-empty, holds the name of a header, such
+	// as DefaultDeadlineHeader, in which the time remaining until the
+	// deadline of the call's context, including any set by
+	// WithTimeout, is sent with each request, for example "1500ms".
+	// A Server with the same DeadlineHeader applies the deadline to
+	// the context of its handlers, so that deadlines are propagated
+	// through calls to other services.
+	DeadlineHeader string
+
+	// OnRequest, if non-nil, is called before each call made by
+	// the Client with details of the request. When the request is
+	// retried, it is called only once.
+	OnRequest func(ctx context.Context, req ClientRequestInfo)
+
+	// OnResponse, if non-nil, is called after each call made by the
+	// Client with details of the response, or of the error if no
+	// response was received. It is called once the response has
+	// been unmarshaled, so that Err holds any error returned by the
+	// call.
+	OnResponse func(ctx context.Context, resp ClientResponseInfo)
+
+	// HookDump specifies whether the headers and bodies of
+	// requests and responses, and the timing of their network
+	// activity, are included in the details passed to OnRequest
+	// and OnResponse.
+	HookDump HookDump
+
+	// Cache, if non-nil, is used to store the JSON responses to GET
+	// requests that have an ETag or Last-Modified header. When a
+	// response to a request is stored, the request is made
+	// conditional with If-None-Match or If-Modified-Since headers,
+	// and a http.StatusNotModified response is replaced by the
+	// stored response, which is unmarshaled as if it had just been
+	// received. A stored response with a Vary header is only used
+	// for requests with the same values of the headers it names.
+	// Requests that already have conditional headers, and calls
+	// whose result is not unmarshaled from the body (for example
+	// a *http.Response or an io.Writer), are not affected. See
+	// NewMemoryCache.
+	Cache ResponseCache
+
+	// DebugDump specifies whether a dump of the request and response
+	// of a failed call is attached to the returned error. The dump
+	// can be retrieved with ErrorDebugDump. It holds the request line,
+	// the headers and up to MaxDebugDumpBodySize bytes of the
+	// bodies, with sensitive headers and the values of fields whose
+	// names suggest that they hold secrets, such as "password" or
+	// "api_key", redacted.
+	DebugDump bool
+
+	// OnDebugDump, if non-nil, is called with a dump of the request
+	// and response of each failed call made by the Client, as
+	// attached to the error when DebugDump is set.
+	OnDebugDump func(ctx context.Context, dump []byte)
+}
+
+// BasicAuth holds credentials for the Basic HTTP authentication scheme.
+type BasicAuth struct {
+	Username string
+	Password string
 }
 
 // Call invokes the endpoint implied by the given params,
@@ -61,15 +285,35 @@ type Client struct {
 // will be called to add additional headers to the HTTP request.
 //
 // If resp is nil, the response will be ignored if the
-// request was successful.
+// request was successful. If the response has status
+// http.StatusNoContent, resp will be left unchanged.
 //
 // If resp is of type **http.Response, instead of unmarshaling
 // into it, its element will be set to the returned HTTP
 // response directly and the caller is responsible for
 // closing its Body field.
 //
+// If resp is of type *Multipart, the response is unmarshaled
+// with UnmarshalMultipartResponse.
+//
+// If resp implements io.Writer, the response body is copied to it
+// instead of being unmarshaled. If resp is a pointer to a struct with
+// a field of type io.ReadCloser tagged with `httprequest:",body"`, the
+// field is set to the response body, which the caller is responsible
+// for closing. Both allow large responses to be streamed rather than
+// held in memory.
+//
+// If resp is a pointer to a struct, any of its fields tagged as header
+// fields, such as `httprequest:"X-Total-Count,header"`, are set from
+// the headers of a successful response after the body has been
+// unmarshaled, as Unmarshal does for requests.
+//
 // Any error that c.UnmarshalError or c.Doer returns will not
-// have its cause masked.
+// have its cause masked. The cause of an error returned after the
+// request is made can also be found with errors.Is and errors.As,
+// for example errors.Is(err, ErrNotFound).
+//
+// Any options are applied to the request; see CallOption.
 //
 // If the request returns a response with a status code signifying
 // success, but the response could not be unmarshaled, a
@@ -77,13 +321,20 @@ type Client struct {
 // the request returns an error status code, the Client.UnmarshalError
 // function is responsible for doing this if desired (the default error
 // unmarshal functions do).
-func (c *Client) Call(ctx context.Context, params, resp interface{}) error {
-	return c.CallURL(ctx, c.BaseURL, params, resp)
+func (c *Client) Call(ctx context.Context, params, resp interface{}, opts ...CallOption) error {
+	if c.Resolver != nil {
+		// Make a request with a relative URL so
+		// that Do uses the resolver.
+		return c.CallURL(ctx, "", params, resp, opts...)
+	}
+	return c.CallURL(ctx, c.BaseURL, params, resp, opts...)
 }
 
 // CallURL is like Call except that the given URL is used instead of
-// c.BaseURL.
-func (c *Client) CallURL(ctx context.Context, url string, params, resp interface{}) error {
+// c.BaseURL. The URL may be a template holding variables whose values
+// are given with WithURLVar. Any query parameters in the URL are
+// retained, with the form fields of the parameters appended to them.
+func (c *Client) CallURL(ctx context.Context, url string, params, resp interface{}, opts ...CallOption) error {
 	rt, err := getRequestType(reflect.TypeOf(params))
 	if err != nil {
 		return errgo.Mask(err)
@@ -91,15 +342,24 @@ func (c *Client) CallURL(ctx context.Context, url string, params, resp interface
 	if rt.method == "" {
 		return errgo.Newf("type %T has no httprequest.Route field", params)
 	}
+	urlVars := newCallOptions(opts).urlVars
+	url, err = expandURLTemplate(url, urlVars)
+	if err != nil {
+		return errgo.Mask(err)
+	}
 	reqURL, err := appendURL(url, rt.path)
 	if err != nil {
 		return errgo.Mask(err)
 	}
-	req, err := Marshal(reqURL.String(), rt.method, params)
+	req, err := marshalRequest(reqURL.String(), rt.method, params, urlVars)
 	if err != nil {
 		return errgo.Mask(err)
 	}
-	return c.Do(ctx, req, resp)
+	routeType := fmt.Sprintf("%T", params)
+	opts = append(opts[:len(opts):len(opts)], func(o *callOptions) {
+		o.routeType = routeType
+	})
+	return c.Do(ctx, req, resp, opts...)
 }
 
 // Do sends the given request and unmarshals its JSON
@@ -116,7 +376,14 @@ func (c *Client) CallURL(ctx context.Context, url string, params, resp interface
 // closing its Body field.
 //
 // Any error that c.UnmarshalError or c.Doer returns will not
-// have its cause masked.
+// have its cause masked. The cause of an error returned after the
+// request is made can also be found with errors.Is and errors.As,
+// for example errors.Is(err, ErrNotFound).
+//
+// Any options are applied to the request; see CallOption.
+//
+// Any headers stored in ctx by ContextWithPropagatedHeaders that the
+// request does not already have are added to it.
 //
 // If req.URL does not have a host part it will be treated as relative to
 // c.BaseURL. req.URL will be updated to the actual URL used.
@@ -124,65 +391,255 @@ func (c *Client) CallURL(ctx context.Context, url string, params, resp interface
 // If the response cannot by unmarshaled, a *DecodeResponseError
 // will be returned holding the response from the request.
 // the entire response body.
-func (c *Client) Do(ctx context.Context, req *http.Request, resp interface{}) error {
-	if req.URL.Host == "" {
+func (c *Client) Do(ctx context.Context, req *http.Request, resp interface{}, opts ...CallOption) error {
+	if req.URL.Host == "" && req.URL.Scheme != "unix" && c.Resolver == nil {
 		var err error
 		req.URL, err = appendURL(c.BaseURL, req.URL.String())
 		if err != nil {
 			return errgo.Mask(err)
 		}
 	}
+	o := newCallOptions(opts)
+	o.apply(req)
+	c.addDefaultHeaders(req)
+	if c.BasicAuth != nil && o.basicAuth == nil && req.Header.Get("Authorization") == "" {
+		req.SetBasicAuth(c.BasicAuth.Username, c.BasicAuth.Password)
+	}
+	addPropagatedHeaders(ctx, req)
+	requestID, err := c.setRequestID(req)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if len(c.Codecs) > 0 && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", c.accept())
+	}
+	c.setAPIVersion(req)
 	doer := c.Doer
+	if req.URL.Scheme == "unix" {
+		if doer != nil {
+			return errgo.Newf("cannot use Unix domain socket URL %q with a custom Doer", req.URL)
+		}
+		var err error
+		doer, err = unixSocketDoer(req)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+	}
 	if doer == nil {
 		doer = http.DefaultClient
 	}
-	var httpResp *http.Response
-	var err error
-	if ctxDoer, ok := doer.(DoerWithContext); ok {
-		httpResp, err = ctxDoer.DoWithContext(ctx, req)
+	if o.uploadProgress != nil {
+		doer = progressDoer{
+			report: o.uploadProgress,
+			doer:   doer,
+		}
+	}
+	if c.Signer != nil {
+		doer = signingDoer{
+			sign: c.Signer,
+			doer: doer,
+		}
+	}
+	if c.RequestCompression != nil {
+		doer = compressDoer{
+			compression: c.RequestCompression,
+			doer:        doer,
+		}
+	}
+	if c.Auth != nil && req.Header.Get("Authorization") == "" {
+		doer = authDoer{
+			auth: c.Auth,
+			doer: doer,
+		}
+	}
+	if c.DeadlineHeader != "" {
+		doer = deadlineDoer{
+			header: c.DeadlineHeader,
+			doer:   doer,
+		}
+	}
+	if c.Resolver != nil && req.URL.Host == "" && req.URL.Scheme == "" {
+		doer = resolvingDoer{
+			resolver: c.Resolver,
+			balancer: c.Balancer,
+			doer:     doer,
+		}
+	}
+	policy := o.retryPolicy(c.Retry)
+	if err := setIdempotencyKey(req, policy); err != nil {
+		return errgo.Mask(err)
+	}
+	cacheKey := c.cacheKey(req, resp)
+	cached := c.addCacheValidators(cacheKey, req)
+	ctx, cancel := o.context(ctx)
+	var tracer *connTracer
+	if (c.OnResponse != nil && c.HookDump&HookDumpTiming != 0) || o.responseInfo != nil {
+		tracer = new(connTracer)
+		ctx = httptrace.WithClientTrace(ctx, tracer.clientTrace())
+	}
+	var reqInfo ClientRequestInfo
+	if c.OnRequest != nil || c.OnResponse != nil {
+		reqInfo = c.requestInfo(req, o)
+	}
+	var dumper *debugDumper
+	if c.DebugDump || c.OnDebugDump != nil {
+		dumper = newDebugDumper(req)
+	}
+	if c.OnRequest != nil {
+		c.OnRequest(ctx, reqInfo)
+	}
+	start := time.Now()
+	httpResp, err := c.doWithRetry(ctx, doer, req, policy)
+	if err != nil {
+		cancel()
+		err = c.callError(ctx, dumper, c.requestIDError(errgo.Mask(urlError(err, req), errgo.Any), requestID))
+		if c.OnResponse != nil {
+			c.OnResponse(ctx, ClientResponseInfo{
+				Request:  reqInfo,
+				Duration: time.Since(start),
+				Err:      err,
+				Timing:   c.hookTiming(tracer),
+			})
+		}
+		return err
+	}
+	if dumper != nil {
+		dumper.captureResponse(httpResp)
+	}
+	if c.OnAPIVersion != nil {
+		if info, ok := c.apiVersionInfo(req, httpResp); ok {
+			c.OnAPIVersion(ctx, info)
+		}
+	}
+	o.reportDownload(httpResp)
+	limitedBody := o.limitBody(httpResp, c.MaxResponseSize)
+	rawResp := keepsResponseBody(resp)
+	if rawResp {
+		// The caller reads the body, so the context
+		// must remain valid until it is closed.
+		httpResp.Body = cancelReadCloser{httpResp.Body, cancel}
 	} else {
-		httpResp, err = doer.Do(req.WithContext(ctx))
+		defer cancel()
+	}
+	var respInfo ClientResponseInfo
+	if c.OnResponse != nil {
+		respInfo = c.responseInfo(httpResp, !rawResp || !o.isSuccess(httpResp.StatusCode))
+	}
+	finalResp, err := c.useCache(cacheKey, cached, req, httpResp)
+	if err == nil {
+		httpResp = finalResp
+		err = c.unmarshalResponse(httpResp, resp, o)
+	} else {
+		err = errgo.Mask(urlError(err, req), errgo.Any)
+	}
+	if err != nil && limitedBody.exceeded() {
+		// Whatever failed to read the body, the
+		// real problem is that it's too large.
+		err = errgo.Mask(urlError(&ResponseTooLargeError{Limit: limitedBody.limit}, req), errgo.Any)
 	}
 	if err != nil {
-		return errgo.Mask(urlError(err, req), errgo.Any)
+		err = c.callError(ctx, dumper, c.requestIDError(err, requestID))
 	}
-	return c.unmarshalResponse(httpResp, resp)
+	o.setResponseInfo(httpResp, tracer.result(), start)
+	if c.OnResponse != nil {
+		respInfo.Request = reqInfo
+		respInfo.Duration = time.Since(start)
+		respInfo.Err = err
+		respInfo.Timing = c.hookTiming(tracer)
+		c.OnResponse(ctx, respInfo)
+	}
+	return err
 }
 
 // Get is a convenience method that uses c.Do to issue a GET request to
 // the given URL. If the given URL does not have a host part then it will
 // be treated as relative to c.BaseURL.
-func (c *Client) Get(ctx context.Context, url string, resp interface{}) error {
+func (c *Client) Get(ctx context.Context, url string, resp interface{}, opts ...CallOption) error {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return errgo.Notef(err, "cannot make request")
 	}
-	return c.Do(ctx, req, resp)
+	return c.Do(ctx, req, resp, opts...)
 }
 
 // unmarshalResponse unmarshals an HTTP response into the given value.
-func (c *Client) unmarshalResponse(httpResp *http.Response, resp interface{}) error {
-	if 200 <= httpResp.StatusCode && httpResp.StatusCode < 300 {
+func (c *Client) unmarshalResponse(httpResp *http.Response, resp interface{}, o *callOptions) error {
+	if o.isSuccess(httpResp.StatusCode) {
 		if respPt, ok := resp.(**http.Response); ok {
 			*respPt = httpResp
 			return nil
 		}
-		defer httpResp.Body.Close()
-		if err := UnmarshalJSONResponse(httpResp, resp); err != nil {
-			return errgo.Mask(urlError(err, httpResp.Request), isDecodeResponseError)
+		if err := c.unmarshalResponseBody(httpResp, resp); err != nil {
+			return errgo.Mask(err, errgo.Any)
+		}
+		if err := unmarshalResponseHeader(httpResp.Header, resp); err != nil {
+			if f, ok := responseBodyField(resp); ok {
+				f.Interface().(io.Closer).Close()
+				f.Set(reflect.Zero(f.Type()))
+			}
+			return errgo.Mask(urlError(err, httpResp.Request), errgo.Any)
 		}
 		return nil
 	}
 	defer httpResp.Body.Close()
+	if 200 <= httpResp.StatusCode && httpResp.StatusCode < 300 {
+		// The status isn't one of those expected.
+		return errgo.Mask(urlError(errgo.Newf("unexpected HTTP response status: %s", httpResp.Status), httpResp.Request), errgo.Any)
+	}
+	if err, ok := c.conditionalError(httpResp, o); ok {
+		return errgo.Mask(urlError(err, httpResp.Request), errgo.Any)
+	}
+	return errgo.Mask(urlError(c.unmarshalError(httpResp), httpResp.Request), errgo.Any)
+}
+
+// unmarshalResponseBody unmarshals the body of a successful HTTP
+// response into the given value.
+func (c *Client) unmarshalResponseBody(httpResp *http.Response, resp interface{}) error {
+	if f, ok := responseBodyField(resp); ok {
+		f.Set(reflect.ValueOf(httpResp.Body))
+		return nil
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode == http.StatusNoContent {
+		// There's no body to unmarshal.
+		return nil
+	}
+	if w, ok := resp.(io.Writer); ok {
+		if _, err := io.Copy(w, httpResp.Body); err != nil {
+			return errgo.Mask(urlError(errgo.Notef(err, "cannot read response body"), httpResp.Request), errgo.Any)
+		}
+		return nil
+	}
+	if m, ok := resp.(*Multipart); ok {
+		if err := unmarshalMultipartResponse(httpResp, m, c.MaxErrorBodySize); err != nil {
+			return errgo.Mask(urlError(err, httpResp.Request), isDecodeResponseError)
+		}
+		return nil
+	}
+	if err := c.decodeResponse(httpResp, resp); err != nil {
+		return errgo.Mask(urlError(err, httpResp.Request), isDecodeResponseError)
+	}
+	return nil
+}
+
+// unmarshalError returns the error held in the given
+// response, which has a status signifying an error.
+func (c *Client) unmarshalError(httpResp *http.Response) error {
 	errUnmarshaler := c.UnmarshalError
 	if errUnmarshaler == nil {
 		errUnmarshaler = DefaultErrorUnmarshaler
+		if c.MaxErrorBodySize > 0 {
+			errUnmarshaler = errorUnmarshaler(remoteErrorType, c.MaxErrorBodySize)
+		}
 	}
 	err := errUnmarshaler(httpResp)
 	if err == nil {
-		err = errgo.Newf("unexpected HTTP response status: %s", httpResp.Status)
+		return errgo.Newf("unexpected HTTP response status: %s", httpResp.Status)
+	}
+	if c.ErrorTypes != nil {
+		err = c.ErrorTypes.typedError(err)
 	}
-	return errgo.Mask(urlError(err, httpResp.Request), errgo.Any)
+	return err
 }
 
 // ErrorUnmarshaler returns a function which will unmarshal error
@@ -190,6 +647,13 @@ func (c *Client) unmarshalResponse(httpResp *http.Response, resp interface{}) er
 // must be a pointer. A new instance of it is created every time the
 // returned function is called.
 //
+// If the error is a *RemoteError without a code, its Code is set to
+// the code registered for the response's status, if any (see
+// RegisterErrorCode). When template is a *RemoteError, responses
+// holding RFC 7807 problem details documents, with the content type
+// application/problem+json, are also unmarshaled into RemoteErrors
+// (see Problem).
+//
 // If the error cannot by unmarshaled, the function will return an
 // *HTTPResponseError holding the response from the request.
 func ErrorUnmarshaler(template error) func(*http.Response) error {
@@ -197,17 +661,31 @@ func ErrorUnmarshaler(template error) func(*http.Response) error {
 	if t.Kind() != reflect.Ptr {
 		panic(errgo.Newf("cannot unmarshal errors into value of type %T", template))
 	}
-	t = t.Elem()
+	return errorUnmarshaler(t.Elem(), 0)
+}
+
+var remoteErrorType = reflect.TypeOf(RemoteError{})
+
+// errorUnmarshaler is the internal version of ErrorUnmarshaler. It
+// unmarshals errors into new values of type *t, capturing up to limit
+// bytes of the body in any error (see readBodyForError).
+func errorUnmarshaler(t reflect.Type, limit int) func(*http.Response) error {
 	return func(resp *http.Response) error {
 		if 300 <= resp.StatusCode && resp.StatusCode < 400 {
 			// It's a redirection error.
 			loc, _ := resp.Location()
-			return newDecodeResponseError(resp, nil, fmt.Errorf("unexpected redirect (status %s) from %q to %q", resp.Status, resp.Request.URL, loc))
+			return newDecodeResponseError(resp, nil, fmt.Errorf("unexpected redirect (status %s) from %q to %q", resp.Status, resp.Request.URL, loc), limit)
+		}
+		if t == remoteErrorType && isProblemResponse(resp) {
+			return unmarshalProblemResponse(resp, limit)
 		}
 		errv := reflect.New(t)
-		if err := UnmarshalJSONResponse(resp, errv.Interface()); err != nil {
+		if err := unmarshalJSONResponse(resp, errv.Interface(), limit); err != nil {
 			return errgo.NoteMask(err, fmt.Sprintf("cannot unmarshal error response (status %s)", resp.Status), isDecodeResponseError)
 		}
+		if rerr, ok := errv.Interface().(*RemoteError); ok && rerr.Code == "" {
+			rerr.Code, _ = StatusErrorCode(resp.StatusCode)
+		}
 		return errv.Interface().(error)
 	}
 }
@@ -219,32 +697,40 @@ func ErrorUnmarshaler(template error) func(*http.Response) error {
 // If the response cannot be unmarshaled, an error of type
 // *DecodeResponseError will be returned.
 func UnmarshalJSONResponse(resp *http.Response, x interface{}) error {
+	return unmarshalJSONResponse(resp, x, 0)
+}
+
+// unmarshalJSONResponse is the internal version of
+// UnmarshalJSONResponse. Up to limit bytes of the body are
+// captured in any error (see readBodyForError).
+func unmarshalJSONResponse(resp *http.Response, x interface{}, limit int) error {
 	if x == nil {
 		return nil
 	}
+	limit = errorBodyLimit(limit)
 	if !isJSONMediaType(resp.Header) {
-		fancyErr := newFancyDecodeError(resp.Header, resp.Body)
-		return newDecodeResponseError(resp, fancyErr.body, fancyErr)
+		fancyErr := newFancyDecodeError(resp.Header, resp.Body, limit)
+		return newDecodeResponseError(resp, fancyErr.body, fancyErr, limit)
 	}
 	// Read enough data that we can produce a plausible-looking
 	// possibly-truncated response body in the error.
 	var buf bytes.Buffer
-	n, err := io.Copy(&buf, io.LimitReader(resp.Body, int64(maxErrorBodySize)))
+	n, err := io.Copy(&buf, io.LimitReader(resp.Body, int64(limit)))
 
 	bodyData := buf.Bytes()
 	if err != nil {
-		return newDecodeResponseError(resp, bodyData, errgo.Notef(err, "error reading response body"))
+		return newDecodeResponseError(resp, bodyData, errgo.Notef(err, "error reading response body"), limit)
 	}
-	if n < int64(maxErrorBodySize) {
+	if n < int64(limit) {
 		// We've read all the data; unmarshal it.
 		if err := json.Unmarshal(bodyData, x); err != nil {
-			return newDecodeResponseError(resp, bodyData, err)
+			return newDecodeResponseError(resp, bodyData, err, limit)
 		}
 		return nil
 	}
-	// The response is longer than maxErrorBodySize; stitch the read
+	// The response is longer than limit; stitch the read
 	// bytes together with the body so that we can still read
-	// bodies larger than maxErrorBodySize.
+	// bodies larger than limit.
 	dec := json.NewDecoder(io.MultiReader(&buf, resp.Body))
 
 	// Try to read all the body so that we can reuse the
@@ -254,7 +740,7 @@ func UnmarshalJSONResponse(resp *http.Response, x interface{}) error {
 	defer io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 8*1024))
 
 	if err := dec.Decode(x); err != nil {
-		return newDecodeResponseError(resp, bodyData, err)
+		return newDecodeResponseError(resp, bodyData, err, limit)
 	}
 	return nil
 }
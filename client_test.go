@@ -148,6 +148,10 @@ var callTests = []struct {
 		P: "hello",
 	},
 	expectResp: &chM1Resp{"hello"},
+}, {
+	about:      "no content response",
+	req:        &chNoContentReq{},
+	expectResp: &chM1Resp{},
 }}
 
 func TestCall(t *testing.T) {
@@ -552,9 +556,6 @@ func TestUnmarshalJSONResponseWithVariedJSONContentTypes(t *testing.T) {
 
 func TestUnmarshalJSONResponseWithErrorAndLargeBody(t *testing.T) {
 	c := qt.New(t)
-	defer c.Done()
-
-	c.Patch(httprequest.MaxErrorBodySize, 11)
 
 	resp := &http.Response{
 		Header: http.Header{
@@ -564,7 +565,7 @@ func TestUnmarshalJSONResponseWithErrorAndLargeBody(t *testing.T) {
 		Body:       ioutil.NopCloser(strings.NewReader(`123456789 123456789`)),
 	}
 	var val map[string]string
-	err := httprequest.UnmarshalJSONResponse(resp, &val)
+	err := httprequest.UnmarshalJSONResponseWithLimit(resp, &val, 11)
 	c.Assert(err, qt.ErrorMatches, `unexpected content type foo/bar; want application/json; content: "123456789 1"`)
 	c.Assert(val, qt.IsNil)
 	assertDecodeResponseError(c, err, http.StatusOK, `123456789 1`)
@@ -572,9 +573,6 @@ func TestUnmarshalJSONResponseWithErrorAndLargeBody(t *testing.T) {
 
 func TestUnmarshalJSONResponseWithLargeBody(t *testing.T) {
 	c := qt.New(t)
-	defer c.Done()
-
-	c.Patch(httprequest.MaxErrorBodySize, 11)
 
 	resp := &http.Response{
 		Header: http.Header{
@@ -584,7 +582,7 @@ func TestUnmarshalJSONResponseWithLargeBody(t *testing.T) {
 		Body:       ioutil.NopCloser(strings.NewReader(`"23456789 123456789"`)),
 	}
 	var val string
-	err := httprequest.UnmarshalJSONResponse(resp, &val)
+	err := httprequest.UnmarshalJSONResponseWithLimit(resp, &val, 11)
 	c.Assert(err, qt.Equals, nil)
 	c.Assert(val, qt.Equals, "23456789 123456789")
 }
@@ -608,9 +606,6 @@ func TestUnmarshalJSONWithDecodeError(t *testing.T) {
 
 func TestUnmarshalJSONWithDecodeErrorAndLargeBody(t *testing.T) {
 	c := qt.New(t)
-	defer c.Done()
-
-	c.Patch(httprequest.MaxErrorBodySize, 11)
 
 	resp := &http.Response{
 		Header: http.Header{
@@ -620,7 +615,7 @@ func TestUnmarshalJSONWithDecodeErrorAndLargeBody(t *testing.T) {
 		Body:       ioutil.NopCloser(strings.NewReader(`"23456789 123456789"`)),
 	}
 	var val chan string
-	err := httprequest.UnmarshalJSONResponse(resp, &val)
+	err := httprequest.UnmarshalJSONResponseWithLimit(resp, &val, 11)
 	c.Assert(err, qt.ErrorMatches, `json: cannot unmarshal string into Go value of type chan string`)
 	c.Assert(val, qt.IsNil)
 	assertDecodeResponseError(c, err, http.StatusOK, `"23456789 1`)
@@ -797,6 +792,14 @@ func (clientHandlers) ContentLength(rp httprequest.Params, p *chContentLengthReq
 	return rp.Request.ContentLength, nil
 }
 
+type chNoContentReq struct {
+	httprequest.Route `httprequest:"DELETE /no-content"`
+}
+
+func (clientHandlers) NoContent(p *chNoContentReq) (httprequest.NoContent, error) {
+	return httprequest.NoContent{}, nil
+}
+
 type doerFunc func(req *http.Request) (*http.Response, error)
 
 func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
@@ -0,0 +1,159 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// AuthProvider is the interface implemented by the providers of
+// credentials used by Client.Auth. Implementations must be safe to
+// call concurrently.
+type AuthProvider interface {
+	// Authorization returns the value of the Authorization header
+	// to send with a request, for example "Bearer xyz". It is called
+	// each time a request is sent, so it should return a cached
+	// credential when there is one.
+	Authorization(ctx context.Context) (string, error)
+
+	// Invalidate discards the given credential, previously returned
+	// by Authorization, which has been rejected by the server, so
+	// that Authorization returns a new one. If the credential has
+	// already been replaced, it should do nothing, so that
+	// concurrent calls that were rejected with the same credential
+	// cause it to be refreshed only once.
+	Invalidate(ctx context.Context, authorization string)
+}
+
+// NewCachedAuth returns an AuthProvider that obtains credentials by
+// calling fetch, which returns the value of the Authorization header
+// and the time at which it expires, or the zero time if it is not
+// known to expire. The credential is reused until it expires or is
+// rejected by the server. Concurrent requests for a credential share a
+// single call to fetch, which is made with a context that is not
+// cancelled when the requests are.
+func NewCachedAuth(fetch func(ctx context.Context) (authorization string, expires time.Time, err error)) AuthProvider {
+	return &cachedAuth{
+		fetch: fetch,
+	}
+}
+
+type cachedAuth struct {
+	fetch func(ctx context.Context) (string, time.Time, error)
+
+	mu            sync.Mutex
+	authorization string
+	expires       time.Time
+	fetching      *authFetch
+}
+
+// authFetch holds a call to cachedAuth.fetch in progress.
+type authFetch struct {
+	done          chan struct{}
+	authorization string
+	err           error
+}
+
+// Authorization implements AuthProvider.Authorization.
+func (a *cachedAuth) Authorization(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	if a.authorization != "" && (a.expires.IsZero() || time.Now().Before(a.expires)) {
+		defer a.mu.Unlock()
+		return a.authorization, nil
+	}
+	f := a.fetching
+	if f == nil {
+		f = &authFetch{
+			done: make(chan struct{}),
+		}
+		a.fetching = f
+		go a.doFetch(context.WithoutCancel(ctx), f)
+	}
+	a.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.authorization, f.err
+	case <-ctx.Done():
+		return "", errgo.Mask(ctx.Err(), errgo.Any)
+	}
+}
+
+// doFetch obtains a new credential for the fetch f.
+func (a *cachedAuth) doFetch(ctx context.Context, f *authFetch) {
+	authorization, expires, err := a.fetch(ctx)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.fetching = nil
+	if err != nil {
+		f.err = errgo.NoteMask(err, "cannot obtain credentials", errgo.Any)
+	} else {
+		f.authorization = authorization
+		a.authorization, a.expires = authorization, expires
+	}
+	close(f.done)
+}
+
+// Invalidate implements AuthProvider.Invalidate.
+func (a *cachedAuth) Invalidate(ctx context.Context, authorization string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.authorization == authorization {
+		a.authorization = ""
+	}
+}
+
+// authDoer is the Doer used by a Client with an AuthProvider. It
+// authorizes each request before sending it, and sends it once more
+// with a new credential if the credential is rejected.
+type authDoer struct {
+	auth AuthProvider
+	doer Doer
+}
+
+// Do implements Doer.Do.
+func (d authDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext.
+func (d authDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	authorization, err := d.auth.Authorization(ctx)
+	if err != nil {
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	req.Header.Set("Authorization", authorization)
+	resp, err := send(ctx, d.doer, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+		// The body can't be sent again.
+		return resp, nil
+	}
+	d.auth.Invalidate(ctx, authorization)
+	authorization, err = d.auth.Authorization(ctx)
+	if err != nil {
+		resp.Body.Close()
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			resp.Body.Close()
+			return nil, errgo.Notef(err, "cannot get request body for retry")
+		}
+		req.Body = body
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 8*1024))
+	resp.Body.Close()
+	req.Header.Set("Authorization", authorization)
+	return send(ctx, d.doer, req)
+}
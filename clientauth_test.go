@@ -0,0 +1,192 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// authServer returns a server that accepts requests authorized with
+// the given credential, responding with a JSON string holding the
+// request body, and rejects others with http.StatusUnauthorized.
+func authServer(accept *atomic.Value, authorizations *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		*authorizations = append(*authorizations, req.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if req.Header.Get("Authorization") != accept.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"Message":"unauthorized"}`))
+			return
+		}
+		data, _ := ioutil.ReadAll(req.Body)
+		w.Write([]byte(`"` + string(data) + `"`))
+	}))
+}
+
+// countingFetch returns a function that returns the credential
+// "Bearer tok-N", where N is the number of times it has been called.
+func countingFetch(n *int32) func(context.Context) (string, time.Time, error) {
+	return func(context.Context) (string, time.Time, error) {
+		return fmt.Sprintf("Bearer tok-%d", atomic.AddInt32(n, 1)), time.Time{}, nil
+	}
+}
+
+func TestClientAuthReusesCredential(t *testing.T) {
+	c := qt.New(t)
+	var accept atomic.Value
+	accept.Store("Bearer tok-1")
+	var authorizations []string
+	srv := authServer(&accept, &authorizations)
+	defer srv.Close()
+
+	var fetches int32
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Auth:    httprequest.NewCachedAuth(countingFetch(&fetches)),
+	}
+	for i := 0; i < 2; i++ {
+		var resp string
+		err := client.Get(context.Background(), "/x", &resp)
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(fetches, qt.Equals, int32(1))
+	c.Assert(authorizations, qt.DeepEquals, []string{"Bearer tok-1", "Bearer tok-1"})
+}
+
+func TestClientAuthRetriesOnUnauthorized(t *testing.T) {
+	c := qt.New(t)
+	var accept atomic.Value
+	accept.Store("Bearer tok-2")
+	var authorizations []string
+	srv := authServer(&accept, &authorizations)
+	defer srv.Close()
+
+	var fetches int32
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Auth:    httprequest.NewCachedAuth(countingFetch(&fetches)),
+	}
+	req, err := http.NewRequest("POST", "/x", strings.NewReader("hello"))
+	c.Assert(err, qt.IsNil)
+	var resp string
+	err = client.Do(context.Background(), req, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "hello")
+	c.Assert(fetches, qt.Equals, int32(2))
+	c.Assert(authorizations, qt.DeepEquals, []string{"Bearer tok-1", "Bearer tok-2"})
+}
+
+func TestClientAuthRetriesOnlyOnce(t *testing.T) {
+	c := qt.New(t)
+	var accept atomic.Value
+	accept.Store("Bearer other")
+	var authorizations []string
+	srv := authServer(&accept, &authorizations)
+	defer srv.Close()
+
+	var fetches int32
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Auth:    httprequest.NewCachedAuth(countingFetch(&fetches)),
+	}
+	err := client.Get(context.Background(), "/x", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/x: unauthorized`)
+	c.Assert(authorizations, qt.DeepEquals, []string{"Bearer tok-1", "Bearer tok-2"})
+}
+
+func TestClientAuthSingleFlightsRefresh(t *testing.T) {
+	c := qt.New(t)
+	var accept atomic.Value
+	accept.Store("Bearer tok-2")
+	var authorizations []string
+	srv := authServer(&accept, &authorizations)
+	defer srv.Close()
+
+	var fetches int32
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Auth:    httprequest.NewCachedAuth(countingFetch(&fetches)),
+	}
+	const n = 10
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- client.Get(context.Background(), "/x", nil)
+		}()
+	}
+	for i := 0; i < n; i++ {
+		c.Assert(<-errs, qt.IsNil)
+	}
+	c.Assert(fetches, qt.Equals, int32(2))
+}
+
+func TestClientAuthExpiry(t *testing.T) {
+	c := qt.New(t)
+	var fetches int32
+	auth := httprequest.NewCachedAuth(func(context.Context) (string, time.Time, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return fmt.Sprintf("Bearer tok-%d", n), time.Now().Add(-time.Second), nil
+	})
+	for i := 1; i <= 2; i++ {
+		authorization, err := auth.Authorization(context.Background())
+		c.Assert(err, qt.IsNil)
+		c.Assert(authorization, qt.Equals, fmt.Sprintf("Bearer tok-%d", i))
+	}
+}
+
+func TestClientAuthFetchError(t *testing.T) {
+	c := qt.New(t)
+	fetchErr := errgo.New("no token")
+	client := &httprequest.Client{
+		Auth: httprequest.NewCachedAuth(func(context.Context) (string, time.Time, error) {
+			return "", time.Time{}, fetchErr
+		}),
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			c.Errorf("unexpected request")
+			return nil, errgo.New("unexpected request")
+		}),
+	}
+	err := client.Get(context.Background(), "http://0.1.2.3/x", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://0.1.2.3/x: cannot obtain credentials: no token`)
+	c.Assert(errgo.Cause(err), qt.Equals, fetchErr)
+}
+
+func TestClientAuthExplicitAuthorization(t *testing.T) {
+	c := qt.New(t)
+	var got string
+	client := &httprequest.Client{
+		Auth: httprequest.NewCachedAuth(func(context.Context) (string, time.Time, error) {
+			c.Errorf("unexpected fetch")
+			return "", time.Time{}, nil
+		}),
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("Authorization")
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+			}, nil
+		}),
+	}
+	err := client.Get(context.Background(), "http://0.1.2.3/x", nil, httprequest.WithHeader("Authorization", "Bearer explicit"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(got, qt.Equals, "Bearer explicit")
+}
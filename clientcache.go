@@ -0,0 +1,254 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// ResponseCache is the interface implemented by the stores used by
+// Client.Cache. Implementations must be safe to call concurrently.
+type ResponseCache interface {
+	// Get returns the response stored with the given key,
+	// and reports whether there is one.
+	Get(key string) (*CachedResponse, bool)
+
+	// Set stores the given response with the given key.
+	Set(key string, r *CachedResponse)
+}
+
+// CachedResponse holds a response stored in a ResponseCache.
+type CachedResponse struct {
+	// ETag holds the response's ETag header.
+	ETag string
+
+	// LastModified holds the response's Last-Modified header.
+	LastModified string
+
+	// Header holds the response's headers.
+	Header http.Header
+
+	// Body holds the response body.
+	Body []byte
+
+	// Vary holds the values of the request headers named in the
+	// response's Vary header. The response is only used for
+	// requests with the same values.
+	Vary http.Header
+}
+
+// NewMemoryCache returns a ResponseCache that stores up to maxEntries
+// responses in memory, discarding the least recently used response
+// when it is full.
+func NewMemoryCache(maxEntries int) ResponseCache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+type memoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type memoryCacheEntry struct {
+	key string
+	r   *CachedResponse
+}
+
+// Get implements ResponseCache.Get.
+func (c *memoryCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*memoryCacheEntry).r, true
+}
+
+// Set implements ResponseCache.Set.
+func (c *memoryCache) Set(key string, r *CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*memoryCacheEntry).r = r
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{
+		key: key,
+		r:   r,
+	})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// cacheKey returns the key under which the response to req is
+// cached, or "" if it should not be cached. Only responses that are
+// decoded into resp are cached, so that streamed responses are not
+// read into memory.
+func (c *Client) cacheKey(req *http.Request, resp interface{}) string {
+	if c.Cache == nil || req.Method != "GET" {
+		return ""
+	}
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		// The caller is making its own conditional request.
+		return ""
+	}
+	if keepsResponseBody(resp) {
+		return ""
+	}
+	switch resp.(type) {
+	case io.Writer, *Multipart:
+		return ""
+	}
+	return req.URL.String()
+}
+
+// addCacheValidators adds conditional headers to req for the
+// response cached with the given key, if any, and returns it.
+// A cached response that varies on request headers is
+// only used if req has the same values for them.
+func (c *Client) addCacheValidators(key string, req *http.Request) *CachedResponse {
+	if key == "" {
+		return nil
+	}
+	cached, ok := c.Cache.Get(key)
+	if !ok {
+		return nil
+	}
+	for name, values := range cached.Vary {
+		if !equalStrings(req.Header.Values(name), values) {
+			return nil
+		}
+	}
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
+	return cached
+}
+
+// useCache returns the response to use in place of resp, which was
+// received for req, made with the given cache key and cached
+// response. A http.StatusNotModified response is replaced by the
+// cached response; a successful JSON response with an ETag or
+// Last-Modified header is stored in the cache unless it has a
+// "Vary: *" header.
+func (c *Client) useCache(key string, cached *CachedResponse, req *http.Request, resp *http.Response) (*http.Response, error) {
+	if key == "" {
+		return resp, nil
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		header := cached.Header.Clone()
+		for k, v := range resp.Header {
+			// RFC 9110 section 15.4.5: update the stored
+			// headers with those in the 304 response.
+			header[k] = v
+		}
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      resp.Proto,
+			ProtoMajor: resp.ProtoMajor,
+			ProtoMinor: resp.ProtoMinor,
+			Header:     header,
+			// Keep the original body's Closer, which may
+			// release resources associated with the call.
+			Body: multiReadCloser{
+				Reader: bytes.NewReader(cached.Body),
+				Closer: resp.Body,
+			},
+			ContentLength: int64(len(cached.Body)),
+			Request:       resp.Request,
+		}, nil
+	}
+	if resp.StatusCode != http.StatusOK || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return resp, nil
+	}
+	if !isJSONMediaType(resp.Header) {
+		return resp, nil
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+	vary, ok := varyHeader(req, resp.Header)
+	if !ok {
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, errgo.Notef(err, "cannot read response body")
+	}
+	c.Cache.Set(key, &CachedResponse{
+		ETag:         etag,
+		LastModified: lastModified,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		Vary:         vary,
+	})
+	resp.Body = multiReadCloser{
+		Reader: bytes.NewReader(body),
+		Closer: resp.Body,
+	}
+	return resp, nil
+}
+
+// varyHeader returns the values in req of the headers named in the Vary
+// header of a response with the given header, or false if the response
+// varies on more than request headers ("Vary: *") and cannot be reused.
+func varyHeader(req *http.Request, header http.Header) (http.Header, bool) {
+	var vary http.Header
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			if vary == nil {
+				vary = make(http.Header)
+			}
+			vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+		}
+	}
+	return vary, true
+}
+
+// equalStrings reports whether a and b hold the same strings.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
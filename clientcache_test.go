@@ -0,0 +1,206 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// cacheServer returns a server that serves the current value of
+// *version as the body of responses to /item, with an ETag derived
+// from it, and to /dated with a Last-Modified header. It records the
+// conditional headers of each request in conds.
+func cacheServer(version *int, conds *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		*conds = append(*conds, req.Header.Get("If-None-Match")+"|"+req.Header.Get("If-Modified-Since"))
+		switch req.URL.Path {
+		case "/item":
+			etag := fmt.Sprintf(`"v%d"`, *version)
+			w.Header().Set("ETag", etag)
+			if req.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/dated":
+			w.Header().Set("Last-Modified", "Fri, 16 Oct 2026 12:00:00 GMT")
+			if req.Header.Get("If-Modified-Since") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		httprequest.WriteJSON(w, http.StatusOK, *version)
+	}))
+}
+
+func TestClientCache(t *testing.T) {
+	c := qt.New(t)
+
+	version := 1
+	var conds []string
+	srv := cacheServer(&version, &conds)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Cache:   httprequest.NewMemoryCache(10),
+	}
+	get := func(path string) int {
+		var resp int
+		err := client.Get(context.Background(), path, &resp)
+		c.Assert(err, qt.IsNil)
+		return resp
+	}
+	c.Assert(get("/item"), qt.Equals, 1)
+	c.Assert(get("/item"), qt.Equals, 1)
+	version = 2
+	c.Assert(get("/item"), qt.Equals, 2)
+	c.Assert(get("/item"), qt.Equals, 2)
+	c.Assert(conds, qt.DeepEquals, []string{
+		"|",
+		`"v1"|`,
+		`"v1"|`,
+		`"v2"|`,
+	})
+
+	conds = nil
+	c.Assert(get("/dated"), qt.Equals, 2)
+	version = 3
+	// The server says it's not modified, so the cached value is used.
+	c.Assert(get("/dated"), qt.Equals, 2)
+	c.Assert(conds, qt.DeepEquals, []string{
+		"|",
+		"|Fri, 16 Oct 2026 12:00:00 GMT",
+	})
+
+	// Responses without validators aren't cached.
+	conds = nil
+	c.Assert(get("/other"), qt.Equals, 3)
+	c.Assert(get("/other"), qt.Equals, 3)
+	c.Assert(conds, qt.DeepEquals, []string{"|", "|"})
+}
+
+func TestClientCacheRawResponse(t *testing.T) {
+	c := qt.New(t)
+
+	version := 1
+	var conds []string
+	srv := cacheServer(&version, &conds)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Cache:   httprequest.NewMemoryCache(10),
+	}
+	for i := 0; i < 2; i++ {
+		var resp *http.Response
+		err := client.Get(context.Background(), "/item", &resp)
+		c.Assert(err, qt.IsNil)
+		c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+		var v int
+		err = httprequest.UnmarshalJSONResponse(resp, &v)
+		resp.Body.Close()
+		c.Assert(err, qt.IsNil)
+		c.Assert(v, qt.Equals, 1)
+	}
+	// Responses read by the caller are not cached.
+	c.Assert(conds, qt.DeepEquals, []string{"|", "|"})
+}
+
+func TestClientCacheNotJSON(t *testing.T) {
+	c := qt.New(t)
+
+	var conds []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conds = append(conds, req.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Cache:   httprequest.NewMemoryCache(10),
+	}
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		err := client.Get(context.Background(), "/text", &buf)
+		c.Assert(err, qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, "hello")
+	}
+	c.Assert(conds, qt.DeepEquals, []string{"", ""})
+}
+
+func TestClientCacheVary(t *testing.T) {
+	c := qt.New(t)
+
+	var conds []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conds = append(conds, req.Header.Get("If-None-Match"))
+		lang := req.Header.Get("Accept-Language")
+		etag := `"` + lang + `"`
+		w.Header().Set("ETag", etag)
+		if req.URL.Path == "/any" {
+			w.Header().Set("Vary", "*")
+		} else {
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		if req.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, lang)
+	}))
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Cache:   httprequest.NewMemoryCache(10),
+	}
+	get := func(path, lang string) string {
+		req, err := http.NewRequest("GET", path, nil)
+		c.Assert(err, qt.IsNil)
+		req.Header.Set("Accept-Language", lang)
+		var resp string
+		err = client.Do(context.Background(), req, &resp)
+		c.Assert(err, qt.IsNil)
+		return resp
+	}
+	c.Assert(get("/item", "en"), qt.Equals, "en")
+	c.Assert(get("/item", "en"), qt.Equals, "en")
+	// The cached response is not used for another language.
+	c.Assert(get("/item", "fr"), qt.Equals, "fr")
+	c.Assert(get("/item", "fr"), qt.Equals, "fr")
+	c.Assert(conds, qt.DeepEquals, []string{"", `"en"`, "", `"fr"`})
+
+	// Responses that vary on anything are not cached.
+	conds = nil
+	c.Assert(get("/any", "en"), qt.Equals, "en")
+	c.Assert(get("/any", "en"), qt.Equals, "en")
+	c.Assert(conds, qt.DeepEquals, []string{"", ""})
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := qt.New(t)
+
+	cache := httprequest.NewMemoryCache(2)
+	cache.Set("a", &httprequest.CachedResponse{ETag: "a"})
+	cache.Set("b", &httprequest.CachedResponse{ETag: "b"})
+	_, ok := cache.Get("a")
+	c.Assert(ok, qt.IsTrue)
+	cache.Set("c", &httprequest.CachedResponse{ETag: "c"})
+	_, ok = cache.Get("b")
+	c.Assert(ok, qt.IsFalse)
+	r, ok := cache.Get("a")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(r.ETag, qt.Equals, "a")
+	r, ok = cache.Get("c")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(r.ETag, qt.Equals, "c")
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ClientRequestInfo holds details of a request made by a Client,
+// passed to Client.OnRequest and Client.OnResponse.
+type ClientRequestInfo struct {
+	// Method holds the HTTP method of the request.
+	Method string
+
+	// URL holds the URL of the request.
+	URL string
+
+	// RouteType holds the name of the type of the parameters passed
+	// to Client.Call or Client.CallURL, such as
+	// "*params.GetUserRequest". It is empty for requests made by
+	// Client.Do or Client.Get.
+	RouteType string
+
+	// RequestID holds the request's identifier, from its
+	// RequestIDHeader, if any (see Client.GenerateRequestID).
+	RequestID string
+
+	// Header holds the request headers, with the values of
+	// sensitive headers such as Authorization redacted. It is only
+	// set when Client.HookDump includes HookDumpHeaders.
+	Header http.Header
+
+	// Body holds up to MaxHookDumpBodySize bytes of the request
+	// body. It is only set when Client.HookDump includes
+	// HookDumpBodies.
+	Body []byte
+}
+
+// ClientResponseInfo holds details of the outcome of a request made by
+// a Client, passed to Client.OnResponse.
+type ClientResponseInfo struct {
+	// Request holds details of the request.
+	Request ClientRequestInfo
+
+	// Status holds the HTTP status of the response, or zero if no
+	// response was received.
+	Status int
+
+	// Duration holds the time taken by the call,
+	// including any retries.
+	Duration time.Duration
+
+	// Err holds the error returned by the call, if any.
+	Err error
+
+	// Header holds the response headers, with the values of
+	// sensitive headers redacted. It is only set when
+	// Client.HookDump includes HookDumpHeaders.
+	Header http.Header
+
+	// Body holds up to MaxHookDumpBodySize bytes of the response
+	// body. It is only set when Client.HookDump includes
+	// HookDumpBodies and the response body is read by the Client,
+	// which is not the case when the response is returned to the
+	// caller as an *http.Response.
+	Body []byte
+
+	// Timing holds the timing of the network activity of the
+	// request. It is only set when Client.HookDump includes
+	// HookDumpTiming and a request was sent.
+	Timing *ConnectionTiming
+}
+
+// HookDump specifies what is included in the details passed to
+// Client.OnRequest and Client.OnResponse, in addition to those that are
+// always included.
+type HookDump int
+
+const (
+	// HookDumpHeaders specifies that request and response headers
+	// are included.
+	HookDumpHeaders HookDump = 1 << iota
+
+	// HookDumpBodies specifies that request and response bodies
+	// are included.
+	HookDumpBodies
+
+	// HookDumpTiming specifies that the timing of the network
+	// activity of requests is included in the details passed to
+	// Client.OnResponse. It is only available when the Client's
+	// Doer supports net/http/httptrace, as *http.Client does.
+	HookDumpTiming
+)
+
+// MaxHookDumpBodySize holds the maximum number of bytes of request and
+// response bodies passed to Client.OnRequest and Client.OnResponse.
+const MaxHookDumpBodySize = 64 * 1024
+
+// redactedHeaders holds the headers whose values
+// are redacted in dumped headers.
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// sanitizeHeader returns a copy of h with the values
+// of sensitive headers redacted.
+func sanitizeHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range redactedHeaders {
+		if vs := h[k]; vs != nil {
+			redacted := make([]string, len(vs))
+			for i := range redacted {
+				redacted[i] = "REDACTED"
+			}
+			h[k] = redacted
+		}
+	}
+	return h
+}
+
+// requestInfo returns the details of req to pass to c's hooks.
+func (c *Client) requestInfo(req *http.Request, o *callOptions) ClientRequestInfo {
+	info := ClientRequestInfo{
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		RouteType: o.routeType,
+		RequestID: req.Header.Get(RequestIDHeader),
+	}
+	if c.HookDump&HookDumpHeaders != 0 {
+		info.Header = sanitizeHeader(req.Header)
+	}
+	if c.HookDump&HookDumpBodies != 0 && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			info.Body, _ = ioutil.ReadAll(io.LimitReader(body, MaxHookDumpBodySize))
+			body.Close()
+		}
+	}
+	return info
+}
+
+// responseInfo returns the details of resp to pass to c.OnResponse.
+// If the body is dumped, resp.Body is replaced so that the body can
+// still be read in full.
+func (c *Client) responseInfo(resp *http.Response, dumpBody bool) ClientResponseInfo {
+	info := ClientResponseInfo{
+		Status: resp.StatusCode,
+	}
+	if c.HookDump&HookDumpHeaders != 0 {
+		info.Header = sanitizeHeader(resp.Header)
+	}
+	if dumpBody && c.HookDump&HookDumpBodies != 0 {
+		data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, MaxHookDumpBodySize))
+		info.Body = data
+		resp.Body = multiReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(data), resp.Body),
+			Closer: resp.Body,
+		}
+	}
+	return info
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type hookRequest struct {
+	httprequest.Route `httprequest:"POST /items/:id"`
+	ID                string `httprequest:"id,path"`
+	Body              struct {
+		Name string `json:"name"`
+	} `httprequest:",body"`
+}
+
+// hookServer returns a server that responds to requests to /items/ok
+// with a JSON object and to other requests with an error.
+func hookServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		if req.URL.Path != "/items/ok" {
+			httprequest.WriteJSON(w, http.StatusNotFound, &httprequest.RemoteError{
+				Code:    httprequest.CodeNotFound,
+				Message: "not found",
+			})
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, map[string]string{"id": "ok"})
+	}))
+}
+
+func TestClientHooks(t *testing.T) {
+	c := qt.New(t)
+
+	srv := hookServer()
+	defer srv.Close()
+	var reqs []httprequest.ClientRequestInfo
+	var resps []httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL:   srv.URL,
+		BasicAuth: &httprequest.BasicAuth{Username: "bob", Password: "secret"},
+		OnRequest: func(ctx context.Context, req httprequest.ClientRequestInfo) {
+			reqs = append(reqs, req)
+		},
+		OnResponse: func(ctx context.Context, resp httprequest.ClientResponseInfo) {
+			resps = append(resps, resp)
+		},
+		HookDump: httprequest.HookDumpHeaders | httprequest.HookDumpBodies,
+	}
+	params := &hookRequest{ID: "ok"}
+	params.Body.Name = "widget"
+	var resp map[string]string
+	err := client.Call(context.Background(), params, &resp)
+	c.Assert(err, qt.IsNil)
+	// The body is still unmarshaled after being dumped.
+	c.Assert(resp, qt.DeepEquals, map[string]string{"id": "ok"})
+
+	c.Assert(reqs, qt.HasLen, 1)
+	c.Assert(reqs[0].Method, qt.Equals, "POST")
+	c.Assert(reqs[0].URL, qt.Equals, srv.URL+"/items/ok")
+	c.Assert(reqs[0].RouteType, qt.Equals, "*httprequest_test.hookRequest")
+	c.Assert(reqs[0].Header.Get("Authorization"), qt.Equals, "REDACTED")
+	c.Assert(reqs[0].Header.Get("Content-Type"), qt.Equals, "application/json")
+	c.Assert(string(reqs[0].Body), qt.Equals, `{"name":"widget"}`)
+
+	c.Assert(resps, qt.HasLen, 1)
+	c.Assert(resps[0].Request, qt.DeepEquals, reqs[0])
+	c.Assert(resps[0].Status, qt.Equals, http.StatusOK)
+	c.Assert(resps[0].Err, qt.IsNil)
+	c.Assert(resps[0].Duration > 0, qt.IsTrue)
+	c.Assert(resps[0].Header.Get("Set-Cookie"), qt.Equals, "REDACTED")
+	c.Assert(string(resps[0].Body), qt.Equals, `{"id":"ok"}`)
+
+	// The error returned by the call is passed to OnResponse.
+	reqs, resps = nil, nil
+	err = client.Get(context.Background(), "/items/missing", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/items/missing: not found`)
+	c.Assert(reqs, qt.HasLen, 1)
+	c.Assert(reqs[0].RouteType, qt.Equals, "")
+	c.Assert(resps, qt.HasLen, 1)
+	c.Assert(resps[0].Status, qt.Equals, http.StatusNotFound)
+	c.Assert(resps[0].Err, qt.Equals, err)
+	c.Assert(string(resps[0].Body), qt.Equals, `{"Message":"not found","Code":"not found"}`)
+}
+
+func TestClientHooksWithoutDump(t *testing.T) {
+	c := qt.New(t)
+
+	srv := hookServer()
+	defer srv.Close()
+	var resps []httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		OnResponse: func(ctx context.Context, resp httprequest.ClientResponseInfo) {
+			resps = append(resps, resp)
+		},
+	}
+	err := client.Get(context.Background(), "/items/ok", nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resps, qt.HasLen, 1)
+	c.Assert(resps[0].Status, qt.Equals, http.StatusOK)
+	c.Assert(resps[0].Header, qt.IsNil)
+	c.Assert(resps[0].Body, qt.IsNil)
+	c.Assert(resps[0].Request.Header, qt.IsNil)
+	c.Assert(resps[0].Timing, qt.IsNil)
+}
+
+func TestClientHooksTransportError(t *testing.T) {
+	c := qt.New(t)
+
+	srv := hookServer()
+	srv.Close()
+	var resps []httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		OnResponse: func(ctx context.Context, resp httprequest.ClientResponseInfo) {
+			resps = append(resps, resp)
+		},
+	}
+	err := client.Get(context.Background(), "/items/ok", nil)
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(resps, qt.HasLen, 1)
+	c.Assert(resps[0].Status, qt.Equals, 0)
+	c.Assert(resps[0].Err, qt.Equals, err)
+}
+
+func TestClientHooksTiming(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		httprequest.WriteJSON(w, http.StatusOK, "ok")
+	}))
+	defer srv.Close()
+	var resps []httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL:  srv.URL,
+		Doer:     srv.Client(),
+		HookDump: httprequest.HookDumpTiming,
+		OnResponse: func(ctx context.Context, resp httprequest.ClientResponseInfo) {
+			resps = append(resps, resp)
+		},
+	}
+	for i := 0; i < 2; i++ {
+		err := client.Get(context.Background(), "/", nil)
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(resps, qt.HasLen, 2)
+
+	timing := resps[0].Timing
+	c.Assert(timing, qt.Not(qt.IsNil))
+	c.Assert(timing.Reused, qt.IsFalse)
+	c.Assert(timing.Connect > 0, qt.IsTrue)
+	c.Assert(timing.TLSHandshake > 0, qt.IsTrue)
+	c.Assert(timing.TimeToFirstByte >= timing.Connect+timing.TLSHandshake, qt.IsTrue)
+	c.Assert(timing.RemoteAddr, qt.Equals, srv.Listener.Addr().String())
+
+	timing = resps[1].Timing
+	c.Assert(timing, qt.Not(qt.IsNil))
+	c.Assert(timing.Reused, qt.IsTrue)
+	c.Assert(timing.Connect, qt.Equals, time.Duration(0))
+	c.Assert(timing.TLSHandshake, qt.Equals, time.Duration(0))
+	c.Assert(timing.TimeToFirstByte > 0, qt.IsTrue)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnectionTiming holds details of the network activity of a request
+// made by a Client, passed to Client.OnResponse when Client.HookDump
+// includes HookDumpTiming. When the request is retried, it describes
+// the final attempt.
+type ConnectionTiming struct {
+	// DNS holds the time taken to look up the server's address.
+	// It is zero if no lookup was made, for example because
+	// a connection was reused.
+	DNS time.Duration
+
+	// Connect holds the time taken to establish a connection
+	// to the server. It is zero if a connection was reused.
+	Connect time.Duration
+
+	// TLSHandshake holds the time taken by the TLS handshake.
+	// It is zero if a connection was reused or TLS is not used.
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte holds the time from when a connection was
+	// requested until the first byte of the response was received.
+	TimeToFirstByte time.Duration
+
+	// Reused holds whether a connection from a previous
+	// request was reused.
+	Reused bool
+
+	// RemoteAddr holds the address of the server.
+	RemoteAddr string
+}
+
+// connTracer records the timing of the network activity
+// of a request using httptrace.
+type connTracer struct {
+	mu           sync.Mutex
+	traced       bool
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	timing       ConnectionTiming
+}
+
+// clientTrace returns the trace to use to record the
+// activity of requests.
+func (t *connTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// This is a new attempt, so start again.
+			t.traced = true
+			t.start = time.Now()
+			t.timing = ConnectionTiming{}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNS = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.Before(t.start) {
+				// When several addresses are dialed
+				// concurrently, time from the first.
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil {
+				t.timing.Connect = time.Since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLSHandshake = time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.Reused = info.Reused
+			if addr := info.Conn.RemoteAddr(); addr != nil {
+				t.timing.RemoteAddr = addr.String()
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TimeToFirstByte = time.Since(t.start)
+		},
+	}
+}
+
+// result returns the recorded timing, or nil if t is nil or
+// nothing was recorded because the Doer does not support httptrace.
+func (t *connTracer) result() *ConnectionTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.traced {
+		return nil
+	}
+	timing := t.timing
+	return &timing
+}
+
+// hookTiming returns the timing recorded by tracer to pass to
+// c.OnResponse, which is nil unless c.HookDump includes
+// HookDumpTiming.
+func (c *Client) hookTiming(tracer *connTracer) *ConnectionTiming {
+	if c.HookDump&HookDumpTiming == 0 {
+		return nil
+	}
+	return tracer.result()
+}
@@ -9,11 +9,14 @@ import (
 	"go/ast"
 	"go/build"
 	"go/format"
+	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -26,12 +29,29 @@ import (
 // - deal with literal interface and struct types.
 // - copy doc comments from server methods.
 
+var (
+	generic    = flag.Bool("generic", false, "use the generic httprequest.Call function in generated methods")
+	paramsOnly = flag.Bool("params", false, "generate methods from the Route-tagged request types in a package rather than from a server type")
+)
+
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: httprequest-generate server-package server-type client-type\n")
+		fmt.Fprintf(os.Stderr, "usage: httprequest-generate-client [-generic] server-package server-type client-type\n")
+		fmt.Fprintf(os.Stderr, "       httprequest-generate-client -params [-generic] params-package client-type\n")
+		flag.PrintDefaults()
 		os.Exit(2)
 	}
 	flag.Parse()
+	if *paramsOnly {
+		if flag.NArg() != 2 {
+			flag.Usage()
+		}
+		if err := generate(flag.Arg(0), "", flag.Arg(1)); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if flag.NArg() != 3 {
 		flag.Usage()
 	}
@@ -49,11 +69,11 @@ type templateArg struct {
 	Imports    []string
 	Methods    []method
 	ClientType string
+	Generic    bool
 }
 
 var code = template.Must(template.New("").Parse(`
-// The code in this file was automatically generated by running httprequest-generate-client.
-// DO NOT EDIT
+// Code generated by httprequest-generate-client. DO NOT EDIT.
 
 package {{.PkgName}}
 import (
@@ -68,20 +88,27 @@ type {{.ClientType}} struct {
 {{range .Methods}}
 {{if .RespType}}
 	{{.Doc}}
-	func (c *{{$.ClientType}}) {{.Name}}(ctx context.Context, p *{{.ParamType}}) ({{.RespType}}, error) {
+	func (c *{{$.ClientType}}) {{.Name}}(ctx context.Context, p *{{.ParamType}}, opts ...httprequest.CallOption) ({{.RespType}}, error) {
+	{{- if $.Generic}}
+		return httprequest.Call[*{{.ParamType}}, {{.RespType}}](ctx, &c.Client, p, opts...)
+	{{- else}}
 		var r {{.RespType}}
-		err := c.Client.Call(ctx, p, &r)
+		err := c.Client.Call(ctx, p, &r, opts...)
 		return r, err
+	{{- end}}
 	}
 {{else}}
 	{{.Doc}}
-	func (c *{{$.ClientType}}) {{.Name}}(ctx context.Context, p *{{.ParamType}}) (error) {
-		return c.Client.Call(ctx, p, nil)
+	func (c *{{$.ClientType}}) {{.Name}}(ctx context.Context, p *{{.ParamType}}, opts ...httprequest.CallOption) error {
+		return c.Client.Call(ctx, p, nil, opts...)
 	}
 {{end}}
 {{end}}
 `))
 
+// generate writes a client for the server type in the given package to
+// a file in the current directory. If serverType is empty, the client
+// is generated from the request types in the package.
 func generate(serverPkgPath, serverType, clientType string) error {
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -96,7 +123,13 @@ func generate(serverPkgPath, serverType, clientType string) error {
 		return errgo.Notef(err, "cannot open %q", serverPkgPath)
 	}
 
-	methods, imports, err := serverMethods(serverPkg.ImportPath, serverType, localPkg.ImportPath)
+	var methods []method
+	var imports []string
+	if serverType == "" {
+		methods, imports, err = paramsMethods(serverPkg.ImportPath, localPkg.ImportPath)
+	} else {
+		methods, imports, err = serverMethods(serverPkg.ImportPath, serverType, localPkg.ImportPath)
+	}
 	if err != nil {
 		return errgo.Mask(err)
 	}
@@ -105,6 +138,7 @@ func generate(serverPkgPath, serverType, clientType string) error {
 		Methods:    methods,
 		PkgName:    localPkg.Name,
 		ClientType: clientType,
+		Generic:    *generic,
 	}
 	var buf bytes.Buffer
 	if err := code.Execute(&buf, arg); err != nil {
@@ -140,20 +174,10 @@ type method struct {
 //
 // The localPkg package will be the one that the code will be generated in.
 func serverMethods(serverPkg, serverType, localPkg string) ([]method, []string, error) {
-	cfg := packages.Config{
-		Mode: packages.LoadAllSyntax,
-		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
-			return parser.ParseFile(fset, filename, src, parser.ParseComments)
-		},
-	}
-	pkgs, err := packages.Load(&cfg, serverPkg)
+	pkgInfo, err := loadPackage(serverPkg)
 	if err != nil {
-		return nil, nil, errgo.Notef(err, "cannot load %q", serverPkg)
+		return nil, nil, errgo.Mask(err)
 	}
-	if len(pkgs) != 1 {
-		return nil, nil, errgo.Newf("packages.Load returned %d packages, not 1", len(pkgs))
-	}
-	pkgInfo := pkgs[0]
 	pkg := pkgInfo.Types
 
 	obj := pkg.Scope().Lookup(serverType)
@@ -167,11 +191,7 @@ func serverMethods(serverPkg, serverType, localPkg string) ([]method, []string,
 	// Use the pointer type to get as many methods as possible.
 	ptrObjType := types.NewPointer(objTypeName.Type())
 
-	imports := map[string]string{
-		"gopkg.in/httprequest.v1": "httprequest",
-		"context":                 "context",
-		localPkg:                  "",
-	}
+	imports := initialImports(localPkg)
 	var methods []method
 	mset := types.NewMethodSet(ptrObjType)
 	for i := 0; i < mset.Len(); i++ {
@@ -196,12 +216,141 @@ func serverMethods(serverPkg, serverType, localPkg string) ([]method, []string,
 			RespType:  typeStr(rtype, imports),
 		})
 	}
-	delete(imports, localPkg)
-	var allImports []string
+	return methods, importPaths(imports, localPkg), nil
+}
+
+// paramsMethods returns the list of methods for the request types
+// (types with an httprequest.Route field) in the given package, and
+// the required import packages. The method for a request type named
+// FooRequest (or Foo) is named Foo, and returns a *FooResponse if the
+// package defines a FooResponse type.
+//
+// The localPkg package will be the one that the code will be generated in.
+func paramsMethods(paramsPkg, localPkg string) ([]method, []string, error) {
+	pkgInfo, err := loadPackage(paramsPkg)
+	if err != nil {
+		return nil, nil, errgo.Mask(err)
+	}
+	scope := pkgInfo.Types.Scope()
+	imports := initialImports(localPkg)
+	var methods []method
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() {
+			continue
+		}
+		route, ok := routeTag(obj.Type())
+		if !ok {
+			continue
+		}
+		methodName := strings.TrimSuffix(name, "Request")
+		if methodName == "" {
+			methodName = name
+		}
+		var rtype types.Type
+		if robj, ok := scope.Lookup(methodName + "Response").(*types.TypeName); ok && robj != obj {
+			rtype = types.NewPointer(robj.Type())
+		}
+		methods = append(methods, method{
+			Name:      methodName,
+			Doc:       fmt.Sprintf("// %s makes a %s request.", methodName, route),
+			ParamType: typeStr(obj.Type(), imports),
+			RespType:  typeStr(rtype, imports),
+		})
+	}
+	if len(methods) == 0 {
+		return nil, nil, errgo.Newf("no request types found in %s", paramsPkg)
+	}
+	return methods, importPaths(imports, localPkg), nil
+}
+
+// routeTag returns the httprequest tag of the httprequest.Route field
+// of the struct type t, and reports whether it has one.
+func routeTag(t types.Type) (string, bool) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return "", false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		named, ok := f.Type().(*types.Named)
+		if !ok || !f.Embedded() {
+			continue
+		}
+		obj := named.Obj()
+		if obj.Pkg() == nil || obj.Pkg().Path() != "gopkg.in/httprequest.v1" || obj.Name() != "Route" {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i)).Get("httprequest")
+		if tag == "" {
+			return "", false
+		}
+		return tag, true
+	}
+	return "", false
+}
+
+// loadPackage loads the package with the given path,
+// including its syntax and comments.
+//
+// The package's files are found with packages.Load, but the package
+// is type-checked here, importing its dependencies from source, so
+// that the sizes of types used are those of the current compiler.
+func loadPackage(path string) (*packages.Package, error) {
+	cfg := packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles,
+	}
+	pkgs, err := packages.Load(&cfg, path)
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot load %q", path)
+	}
+	if len(pkgs) != 1 {
+		return nil, errgo.Newf("packages.Load returned %d packages, not 1", len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		return nil, errgo.Newf("cannot load %q: %v", path, pkg.Errors[0])
+	}
+	pkg.Fset = token.NewFileSet()
+	for _, filename := range pkg.GoFiles {
+		f, err := parser.ParseFile(pkg.Fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, errgo.Notef(err, "cannot parse %q", filename)
+		}
+		pkg.Syntax = append(pkg.Syntax, f)
+	}
+	tcfg := types.Config{
+		Importer: importer.ForCompiler(pkg.Fset, "source", nil),
+		Sizes:    types.SizesFor(build.Default.Compiler, build.Default.GOARCH),
+	}
+	pkg.Types, err = tcfg.Check(pkg.PkgPath, pkg.Fset, pkg.Syntax, nil)
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot type-check %q", path)
+	}
+	return pkg, nil
+}
+
+// initialImports returns the imports map (from package path to package
+// id) that all generated code starts with.
+func initialImports(localPkg string) map[string]string {
+	return map[string]string{
+		"gopkg.in/httprequest.v1": "httprequest",
+		"context":                 "context",
+		localPkg:                  "",
+	}
+}
+
+// importPaths returns the sorted paths in the given imports map,
+// excluding localPkg.
+func importPaths(imports map[string]string, localPkg string) []string {
+	var paths []string
 	for path := range imports {
-		allImports = append(allImports, path)
+		if path != localPkg {
+			paths = append(paths, path)
+		}
 	}
-	return methods, allImports, nil
+	sort.Strings(paths)
+	return paths
 }
 
 // docComment returns the doc comment for the method referred to
@@ -294,10 +443,10 @@ func parseMethodType(t *types.Signature) (ptype, rtype types.Type, err error) {
 		return nil, nil, errgo.Newf("parameter is %s, not a pointer to struct", ptype1.Elem())
 	}
 	rp := t.Results()
-	if rp.Len() > 2 {
+	if rp.Len() > 3 {
 		return nil, nil, errgo.New("wrong result count")
 	}
-	if rp.Len() == 2 {
+	if rp.Len() >= 2 {
 		rtype = rp.At(0).Type()
 	}
 	return ptype, rtype, nil
@@ -0,0 +1,170 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testPkg = "gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata"
+
+var generateTests = []struct {
+	about      string
+	generic    bool
+	serverType string
+	expect     string
+}{{
+	about:      "server type",
+	serverType: "Server",
+	expect: `// Code generated by httprequest-generate-client. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata/params"
+)
+
+type TestClient struct {
+	Client httprequest.Client
+}
+
+// DeleteUser deletes the user with the given id.
+func (c *TestClient) DeleteUser(ctx context.Context, p *params.DeleteUserRequest, opts ...httprequest.CallOption) error {
+	return c.Client.Call(ctx, p, nil, opts...)
+}
+
+// GetUser returns the user with the given id.
+func (c *TestClient) GetUser(ctx context.Context, p *params.GetUserRequest, opts ...httprequest.CallOption) (*params.GetUserResponse, error) {
+	var r *params.GetUserResponse
+	err := c.Client.Call(ctx, p, &r, opts...)
+	return r, err
+}
+`,
+}, {
+	about:      "server type with generics",
+	generic:    true,
+	serverType: "Server",
+	expect: `// Code generated by httprequest-generate-client. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata/params"
+)
+
+type TestClient struct {
+	Client httprequest.Client
+}
+
+// DeleteUser deletes the user with the given id.
+func (c *TestClient) DeleteUser(ctx context.Context, p *params.DeleteUserRequest, opts ...httprequest.CallOption) error {
+	return c.Client.Call(ctx, p, nil, opts...)
+}
+
+// GetUser returns the user with the given id.
+func (c *TestClient) GetUser(ctx context.Context, p *params.GetUserRequest, opts ...httprequest.CallOption) (*params.GetUserResponse, error) {
+	return httprequest.Call[*params.GetUserRequest, *params.GetUserResponse](ctx, &c.Client, p, opts...)
+}
+`,
+}, {
+	about: "request types",
+	expect: `// Code generated by httprequest-generate-client. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata/params"
+)
+
+type TestClient struct {
+	Client httprequest.Client
+}
+
+// DeleteUser makes a DELETE /users/:id request.
+func (c *TestClient) DeleteUser(ctx context.Context, p *params.DeleteUserRequest, opts ...httprequest.CallOption) error {
+	return c.Client.Call(ctx, p, nil, opts...)
+}
+
+// GetUser makes a GET /users/:id request.
+func (c *TestClient) GetUser(ctx context.Context, p *params.GetUserRequest, opts ...httprequest.CallOption) (*params.GetUserResponse, error) {
+	var r *params.GetUserResponse
+	err := c.Client.Call(ctx, p, &r, opts...)
+	return r, err
+}
+`,
+}, {
+	about:   "request types with generics",
+	generic: true,
+	expect: `// Code generated by httprequest-generate-client. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata/params"
+)
+
+type TestClient struct {
+	Client httprequest.Client
+}
+
+// DeleteUser makes a DELETE /users/:id request.
+func (c *TestClient) DeleteUser(ctx context.Context, p *params.DeleteUserRequest, opts ...httprequest.CallOption) error {
+	return c.Client.Call(ctx, p, nil, opts...)
+}
+
+// GetUser makes a GET /users/:id request.
+func (c *TestClient) GetUser(ctx context.Context, p *params.GetUserRequest, opts ...httprequest.CallOption) (*params.GetUserResponse, error) {
+	return httprequest.Call[*params.GetUserRequest, *params.GetUserResponse](ctx, &c.Client, p, opts...)
+}
+`,
+}}
+
+func TestGenerate(t *testing.T) {
+	c := qt.New(t)
+	wd, err := os.Getwd()
+	c.Assert(err, qt.IsNil)
+	clientDir := filepath.Join(wd, "testdata", "client")
+	for _, test := range generateTests {
+		c.Run(test.about, func(c *qt.C) {
+			c.Patch(generic, test.generic)
+			c.Assert(os.Chdir(clientDir), qt.IsNil)
+			defer os.Chdir(wd)
+
+			pkg := testPkg + "/params"
+			if test.serverType != "" {
+				pkg = testPkg + "/server"
+			}
+			err := generate(pkg, test.serverType, "TestClient")
+			c.Assert(err, qt.IsNil)
+			defer os.Remove("testclient_generated.go")
+			data, err := ioutil.ReadFile("testclient_generated.go")
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(data), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestGenerateNoRequestTypes(t *testing.T) {
+	c := qt.New(t)
+	wd, err := os.Getwd()
+	c.Assert(err, qt.IsNil)
+	c.Assert(os.Chdir(filepath.Join(wd, "testdata", "client")), qt.IsNil)
+	defer os.Chdir(wd)
+
+	err = generate(testPkg+"/client", "", "TestClient")
+	c.Assert(err, qt.ErrorMatches, `no request types found in .*/testdata/client`)
+}
@@ -0,0 +1,3 @@
+// Package client is the package that clients
+// are generated in by the generator tests.
+package client
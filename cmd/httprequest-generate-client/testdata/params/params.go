@@ -0,0 +1,24 @@
+// Package params holds the request and response
+// types used by the generator tests.
+package params
+
+import "gopkg.in/httprequest.v1"
+
+type GetUserRequest struct {
+	httprequest.Route `httprequest:"GET /users/:id"`
+	ID                string `httprequest:"id,path"`
+}
+
+type GetUserResponse struct {
+	Name string
+}
+
+type DeleteUserRequest struct {
+	httprequest.Route `httprequest:"DELETE /users/:id"`
+	ID                string `httprequest:"id,path"`
+}
+
+// NotARequest has no Route field.
+type NotARequest struct {
+	ID string
+}
@@ -0,0 +1,20 @@
+// Package server holds the server type used by the generator tests.
+package server
+
+import (
+	"gopkg.in/httprequest.v1"
+
+	"gopkg.in/httprequest.v1/cmd/httprequest-generate-client/testdata/params"
+)
+
+type Server struct{}
+
+// GetUser returns the user with the given id.
+func (*Server) GetUser(p httprequest.Params, r *params.GetUserRequest) (*params.GetUserResponse, error) {
+	return nil, nil
+}
+
+// DeleteUser deletes the user with the given id.
+func (*Server) DeleteUser(r *params.DeleteUserRequest) error {
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Codec represents an encoding that can be used for response
+// bodies. See Server.Codecs and Client.Codecs.
+//
+// Codecs for other encodings, such as MessagePack, can be defined
+// using third-party packages, for example:
+//
+//	var MsgpackCodec = httprequest.Codec{
+//		ContentType: "application/msgpack",
+//		Marshal:     msgpack.Marshal,
+//		Unmarshal:   msgpack.Unmarshal,
+//	}
+type Codec struct {
+	// ContentType holds the media type of the encoded
+	// values, for example "application/json".
+	ContentType string
+
+	// Marshal returns the encoded form of v.
+	Marshal func(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes data into v, which is a pointer to the
+	// value to decode into. It is used by Client to decode response
+	// bodies; if it is nil, the codec is only used by Server.
+	Unmarshal func(data []byte, v interface{}) error
+}
+
+// JSONCodec encodes response bodies as JSON. It is used when
+// Server.Codecs is empty or the client does not accept any of them.
+var JSONCodec = Codec{
+	ContentType: "application/json",
+	Marshal:     json.Marshal,
+	Unmarshal:   json.Unmarshal,
+}
+
+// XMLCodec encodes response bodies as XML.
+var XMLCodec = Codec{
+	ContentType: "application/xml",
+	Marshal:     xml.Marshal,
+	Unmarshal:   xml.Unmarshal,
+}
+
+// TextCodec encodes response bodies as plain text. It can encode
+// values of type string and []byte, and values that implement
+// encoding.TextMarshaler, and decode into pointers to values of those
+// types.
+var TextCodec = Codec{
+	ContentType: "text/plain",
+	Marshal:     marshalText,
+	Unmarshal:   unmarshalText,
+}
+
+// marshalText implements TextCodec.Marshal.
+func marshalText(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case encodingTextMarshaler:
+		return v.MarshalText()
+	}
+	return nil, errgo.Newf("cannot encode value of type %T as text", v)
+}
+
+// unmarshalText implements TextCodec.Unmarshal.
+func unmarshalText(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case *string:
+		*v = string(data)
+		return nil
+	case *[]byte:
+		*v = append([]byte(nil), data...)
+		return nil
+	case encodingTextUnmarshaler:
+		return v.UnmarshalText(data)
+	}
+	return errgo.Newf("cannot decode text into value of type %T", v)
+}
+
+type codecKey struct{}
+
+// contextWithCodec returns a context that records that the given
+// codec should be used for response bodies.
+func contextWithCodec(ctx context.Context, c *Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, c)
+}
+
+// codecFromContext returns the codec to use for response bodies
+// written in the given context.
+func codecFromContext(ctx context.Context) *Codec {
+	if c, ok := ctx.Value(codecKey{}).(*Codec); ok {
+		return c
+	}
+	return &JSONCodec
+}
+
+// codec returns the codec from srv.Codecs that best matches
+// the Accept header of req. It returns JSONCodec if the
+// request has no Accept header or accepts none of them.
+func (srv *Server) codec(req *http.Request) *Codec {
+	accepted := parseAccept(req.Header["Accept"])
+	var best *Codec
+	bestQ := 0.0
+	for i, c := range srv.Codecs {
+		for _, v := range accepted {
+			if v.q > bestQ && mediaTypeMatches(v.value, c.ContentType) {
+				best, bestQ = &srv.Codecs[i], v.q
+			}
+		}
+	}
+	if best == nil {
+		return &JSONCodec
+	}
+	return best
+}
+
+// accept returns the value of the Accept header sent by c
+// when c.Codecs is non-empty.
+func (c *Client) accept() string {
+	return strings.Join(c.decodedMediaTypes(), ", ")
+}
+
+// decodedMediaTypes returns the media types of the response bodies
+// that c can decode.
+func (c *Client) decodedMediaTypes() []string {
+	var types []string
+	hasJSON := false
+	for _, codec := range c.Codecs {
+		if codec.Unmarshal == nil {
+			continue
+		}
+		types = append(types, codec.ContentType)
+		hasJSON = hasJSON || codec.ContentType == JSONCodec.ContentType
+	}
+	if !hasJSON {
+		types = append(types, JSONCodec.ContentType)
+	}
+	return types
+}
+
+// decodeResponse decodes the body of resp into x, which should be a
+// pointer to the result to be decoded into, with the codec from
+// c.Codecs matching its content type, or as JSON. If the response
+// cannot be decoded, an error of type *DecodeResponseError is
+// returned.
+func (c *Client) decodeResponse(resp *http.Response, x interface{}) error {
+	if len(c.Codecs) == 0 || x == nil {
+		return c.unmarshalJSONBody(resp, x)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	for _, codec := range c.Codecs {
+		if codec.Unmarshal == nil || !strings.EqualFold(mediaType, codec.ContentType) {
+			continue
+		}
+		if codec.ContentType == JSONCodec.ContentType {
+			break
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		bodyData := data
+		if limit := errorBodyLimit(c.MaxErrorBodySize); len(bodyData) > limit {
+			bodyData = bodyData[:limit]
+		}
+		if err != nil {
+			return newDecodeResponseError(resp, bodyData, errgo.Notef(err, "error reading response body"), c.MaxErrorBodySize)
+		}
+		if err := codec.Unmarshal(data, x); err != nil {
+			return newDecodeResponseError(resp, bodyData, err, c.MaxErrorBodySize)
+		}
+		return nil
+	}
+	if !isJSONMediaType(resp.Header) {
+		fancyErr := newFancyDecodeError(resp.Header, resp.Body, c.MaxErrorBodySize)
+		fancyErr.want = strings.Join(c.decodedMediaTypes(), " or ")
+		return newDecodeResponseError(resp, fancyErr.body, fancyErr, c.MaxErrorBodySize)
+	}
+	return c.unmarshalJSONBody(resp, x)
+}
+
+// mediaTypeMatches reports whether the media range pattern,
+// as found in an Accept header, matches the given media type.
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return false
+}
+
+// writeBody writes val as the response body with the given status
+// code, encoded with the codec chosen for the request (see
+// Server.Codecs).
+func writeBody(ctx context.Context, w http.ResponseWriter, code int, val interface{}) error {
+	return writeEncoded(w, codecFromContext(ctx), code, val)
+}
+
+// writeEncoded writes val to w encoded with the given codec
+// and sets the HTTP status to the given code.
+func writeEncoded(w http.ResponseWriter, codec *Codec, code int, val interface{}) error {
+	return writeEncodedWithHeaders(w, codec, code, val)
+}
+
+// writeEncodedWithHeaders is like writeEncoded except that the SetHeader
+// methods of any of the given values that implement HeaderSetter are
+// called as well as that of val.
+func writeEncodedWithHeaders(w http.ResponseWriter, codec *Codec, code int, val interface{}, headerVals ...interface{}) error {
+	// TODO consider marshalling directly to w using json.NewEncoder.
+	// pro: this will not require a full buffer allocation.
+	// con: if there's an error after the first write, it will be lost.
+	data, err := codec.Marshal(val)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	w.Header().Set("content-type", codec.ContentType)
+	for _, v := range append(headerVals, val) {
+		if headerSetter, ok := v.(HeaderSetter); ok {
+			headerSetter.SetHeader(w.Header())
+		}
+	}
+	w.WriteHeader(code)
+	w.Write(data)
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type codecResult struct {
+	Name string
+}
+
+var codecTests = []struct {
+	about             string
+	codecs            []httprequest.Codec
+	accept            string
+	fail              bool
+	expectContentType string
+	expectStatus      int
+	expectBody        string
+}{{
+	about:             "no codecs",
+	accept:            "application/xml",
+	expectContentType: "application/json",
+	expectBody:        `{"Name":"x"}`,
+}, {
+	about:             "no Accept header",
+	codecs:            []httprequest.Codec{httprequest.XMLCodec, httprequest.JSONCodec},
+	expectContentType: "application/json",
+	expectBody:        `{"Name":"x"}`,
+}, {
+	about:             "XML accepted",
+	codecs:            []httprequest.Codec{httprequest.JSONCodec, httprequest.XMLCodec},
+	accept:            "application/xml",
+	expectContentType: "application/xml",
+	expectBody:        `<codecResult><Name>x</Name></codecResult>`,
+}, {
+	about:             "highest quality chosen",
+	codecs:            []httprequest.Codec{httprequest.JSONCodec, httprequest.XMLCodec},
+	accept:            "application/json;q=0.5, application/xml;q=0.8",
+	expectContentType: "application/xml",
+	expectBody:        `<codecResult><Name>x</Name></codecResult>`,
+}, {
+	about:             "wildcard chooses first codec",
+	codecs:            []httprequest.Codec{httprequest.XMLCodec, httprequest.JSONCodec},
+	accept:            "application/*",
+	expectContentType: "application/xml",
+	expectBody:        `<codecResult><Name>x</Name></codecResult>`,
+}, {
+	about:             "nothing acceptable",
+	codecs:            []httprequest.Codec{httprequest.XMLCodec},
+	accept:            "text/html",
+	expectContentType: "application/json",
+	expectBody:        `{"Name":"x"}`,
+}, {
+	about:             "error encoded with chosen codec",
+	codecs:            []httprequest.Codec{httprequest.JSONCodec, httprequest.XMLCodec},
+	accept:            "application/xml",
+	fail:              true,
+	expectContentType: "application/xml",
+	expectStatus:      http.StatusNotFound,
+	expectBody:        `<RemoteError><Message>not here</Message><Code>not found</Code></RemoteError>`,
+}}
+
+func TestCodecs(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range codecTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				Codecs: test.codecs,
+			}
+			h := srv.Handle(func(arg *struct{}) (*codecResult, error) {
+				if test.fail {
+					return nil, httprequest.Errorf(httprequest.CodeNotFound, "not here")
+				}
+				return &codecResult{Name: "x"}, nil
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.Handle(rec, req, nil)
+			if test.expectStatus == 0 {
+				test.expectStatus = http.StatusOK
+			}
+			c.Assert(rec.Code, qt.Equals, test.expectStatus)
+			c.Assert(rec.Header().Get("Content-Type"), qt.Equals, test.expectContentType)
+			c.Assert(rec.Body.String(), qt.Equals, test.expectBody)
+			if len(test.codecs) > 0 {
+				c.Assert(rec.Header().Get("Vary"), qt.Equals, "Accept")
+			}
+		})
+	}
+}
+
+func TestCustomStatusXML(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		Codecs: []httprequest.Codec{httprequest.XMLCodec},
+	}
+	h := srv.Handle(func(arg *struct{}) (httprequest.CustomStatus, error) {
+		return httprequest.CustomStatus{
+			Status: http.StatusAccepted,
+			Body:   codecResult{Name: "x"},
+		}, nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/xml")
+	rec := httptest.NewRecorder()
+	h.Handle(rec, req, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusAccepted)
+	c.Assert(rec.Body.String(), qt.Equals, `<codecResult><Name>x</Name></codecResult>`)
+}
+
+func TestClientCodecs(t *testing.T) {
+	c := qt.New(t)
+
+	var accept []string
+	srv := httprequest.Server{
+		Codecs: []httprequest.Codec{httprequest.JSONCodec, httprequest.XMLCodec},
+	}
+	router := srv.NewRouter([]httprequest.Handler{srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /result"`
+	}) (codecResult, error) {
+		accept = append(accept, p.Request.Header.Get("Accept"))
+		return codecResult{Name: "x"}, nil
+	})})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := httprequest.Client{
+		BaseURL: server.URL,
+		Codecs:  []httprequest.Codec{httprequest.XMLCodec},
+	}
+	var result codecResult
+	err := client.Get(context.Background(), "/result", &result)
+	c.Assert(err, qt.IsNil)
+	c.Assert(result, qt.Equals, codecResult{Name: "x"})
+
+	// JSON is still decoded.
+	result = codecResult{}
+	err = client.Get(context.Background(), "/result", &result, httprequest.WithHeader("Accept", "application/json"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(result, qt.Equals, codecResult{Name: "x"})
+	c.Assert(accept, qt.DeepEquals, []string{"application/xml, application/json", "application/json"})
+}
+
+func TestClientTextCodec(t *testing.T) {
+	c := qt.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("hello"))
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<p>hello</p>"))
+		}
+	}))
+	defer server.Close()
+
+	client := httprequest.Client{
+		BaseURL: server.URL,
+		Codecs:  []httprequest.Codec{httprequest.TextCodec},
+	}
+	var s string
+	err := client.Get(context.Background(), "/text", &s)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s, qt.Equals, "hello")
+
+	var b []byte
+	err = client.Get(context.Background(), "/text", &b)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "hello")
+
+	var result codecResult
+	err = client.Get(context.Background(), "/text", &result)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/text: cannot decode text into value of type \*httprequest_test.codecResult`)
+	_, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+	c.Assert(ok, qt.IsTrue)
+
+	err = client.Get(context.Background(), "/html", &s)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/html: unexpected content type text/html; want text/plain or application/json; content: hello`)
+}
+
+func TestTextCodecMarshal(t *testing.T) {
+	c := qt.New(t)
+
+	for _, v := range []interface{}{"hello", []byte("hello"), textValue("hello")} {
+		data, err := httprequest.TextCodec.Marshal(v)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(data), qt.Equals, "hello")
+	}
+	_, err := httprequest.TextCodec.Marshal(42)
+	c.Assert(err, qt.ErrorMatches, `cannot encode value of type int as text`)
+
+	var v textValue
+	err = httprequest.TextCodec.Unmarshal([]byte("hello"), &v)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, textValue("HELLO"))
+}
+
+// textValue implements encoding.TextMarshaler and
+// encoding.TextUnmarshaler, upper-casing text that
+// is unmarshaled.
+type textValue string
+
+func (v textValue) MarshalText() ([]byte, error) {
+	return []byte(v), nil
+}
+
+func (v *textValue) UnmarshalText(data []byte) error {
+	*v = textValue(strings.ToUpper(string(data)))
+	return nil
+}
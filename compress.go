@@ -0,0 +1,163 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// Compressor represents a content coding that can be used to compress
+// responses, or request bodies sent by a Client. See
+// Server.CompressionThreshold and Client.RequestCompression.
+type Compressor struct {
+	// Encoding holds the name of the content coding as used in the
+	// Accept-Encoding and Content-Encoding headers, for example
+	// "gzip".
+	Encoding string
+
+	// NewWriter returns a writer that writes the compressed form of
+	// all data written to it to w. Its Close method is called at the
+	// end of the response. If it implements a Flush method with no
+	// arguments that returns an error, that will be called when the
+	// response is flushed.
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// GzipCompressor compresses responses with gzip. It is used
+// when Server.Compressors is empty.
+var GzipCompressor = Compressor{
+	Encoding: "gzip",
+	NewWriter: func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	},
+}
+
+// compressor returns the compressor to use for the response to req,
+// or nil if the response should not be compressed.
+func (srv *Server) compressor(req *http.Request) *Compressor {
+	compressors := srv.Compressors
+	if len(compressors) == 0 {
+		compressors = []Compressor{GzipCompressor}
+	}
+	accepted := parseAccept(req.Header["Accept-Encoding"])
+	for i, c := range compressors {
+		for _, v := range accepted {
+			if (v.value == c.Encoding || v.value == "*") && v.q > 0 {
+				return &compressors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// compressWriter wraps an http.ResponseWriter and compresses the
+// response body if it reaches a size threshold before it is flushed.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *Compressor
+	threshold  int
+
+	// code holds the status code of the response.
+	code int
+
+	// buf holds the data written before the decision
+	// about compression has been made.
+	buf []byte
+
+	// started records whether the response header
+	// has been written.
+	started bool
+
+	// enc holds the compressing writer, or nil
+	// if the response is not being compressed.
+	enc io.WriteCloser
+}
+
+// Ensure statically that compressWriter does implement http.Flusher.
+var _ http.Flusher = (*compressWriter)(nil)
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.started || code < 200 {
+		// Informational responses can be sent immediately.
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.started {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.threshold {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush implements http.Flusher.Flush. If the response has not
+// been started, it is sent uncompressed.
+func (w *compressWriter) Flush() {
+	if !w.started {
+		w.start(false)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter so that
+// http.ResponseController can find any optional interfaces
+// it implements.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start writes the response header followed by any buffered data,
+// compressing the response if compress is true and the response
+// has a body that has not already been encoded.
+func (w *compressWriter) start(compress bool) error {
+	w.started = true
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	h := w.Header()
+	if compress && h.Get("Content-Encoding") == "" && w.code != http.StatusNoContent && w.code != http.StatusNotModified {
+		h.Set("Content-Encoding", w.compressor.Encoding)
+		h.Del("Content-Length")
+		w.enc = w.compressor.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// close finishes the response.
+func (w *compressWriter) close() error {
+	if !w.started {
+		return w.start(false)
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var deflateCompressor = httprequest.Compressor{
+	Encoding: "deflate",
+	NewWriter: func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	},
+}
+
+var longString = strings.Repeat("x", 100)
+
+var compressTests = []struct {
+	about          string
+	compressors    []httprequest.Compressor
+	acceptEncoding string
+	result         string
+	err            error
+	expectEncoding string
+	expectStatus   int
+	expectBody     string
+}{{
+	about:          "large response compressed",
+	acceptEncoding: "gzip",
+	result:         longString,
+	expectEncoding: "gzip",
+	expectBody:     `"` + longString + `"`,
+}, {
+	about:          "small response not compressed",
+	acceptEncoding: "gzip",
+	result:         "x",
+	expectBody:     `"x"`,
+}, {
+	about:      "no Accept-Encoding",
+	result:     longString,
+	expectBody: `"` + longString + `"`,
+}, {
+	about:          "gzip not acceptable",
+	acceptEncoding: "gzip;q=0, br",
+	result:         longString,
+	expectBody:     `"` + longString + `"`,
+}, {
+	about:          "error response compressed",
+	acceptEncoding: "gzip, deflate",
+	err:            errgo.New(longString),
+	expectEncoding: "gzip",
+	expectStatus:   http.StatusInternalServerError,
+	expectBody:     `{"Message":"` + longString + `"}`,
+}, {
+	about:          "preferred compressor chosen",
+	compressors:    []httprequest.Compressor{deflateCompressor, httprequest.GzipCompressor},
+	acceptEncoding: "gzip, deflate",
+	result:         longString,
+	expectEncoding: "deflate",
+	expectBody:     `"` + longString + `"`,
+}, {
+	about:          "wildcard encoding",
+	acceptEncoding: "*",
+	result:         longString,
+	expectEncoding: "gzip",
+	expectBody:     `"` + longString + `"`,
+}}
+
+func TestCompression(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range compressTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				ErrorMapper:          testErrorMapper,
+				CompressionThreshold: 50,
+				Compressors:          test.compressors,
+			}
+			h := srv.Handle(func(arg *struct{}) (string, error) {
+				return test.result, test.err
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			if test.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			h.Handle(rec, req, nil)
+			if test.expectStatus == 0 {
+				test.expectStatus = http.StatusOK
+			}
+			c.Assert(rec.Code, qt.Equals, test.expectStatus)
+			c.Assert(rec.Header().Get("Vary"), qt.Equals, "Accept-Encoding")
+			c.Assert(rec.Header().Get("Content-Encoding"), qt.Equals, test.expectEncoding)
+			c.Assert(decodeBody(c, test.expectEncoding, rec.Body), qt.Equals, test.expectBody)
+		})
+	}
+}
+
+func decodeBody(c *qt.C, encoding string, r io.Reader) string {
+	switch encoding {
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		c.Assert(err, qt.IsNil)
+		r = gr
+	case "deflate":
+		r = flate.NewReader(r)
+	}
+	data, err := io.ReadAll(r)
+	c.Assert(err, qt.IsNil)
+	return string(data)
+}
+
+func TestCompressionDisabledByDefault(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct{}) (string, error) {
+		return longString, nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.Handle(rec, req, nil)
+	c.Assert(rec.Header().Get("Content-Encoding"), qt.Equals, "")
+	c.Assert(rec.Header().Get("Vary"), qt.Equals, "")
+	c.Assert(rec.Body.String(), qt.Equals, `"`+longString+`"`)
+}
+
+func TestCompressionNotAppliedToFlushedStream(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		CompressionThreshold: 1,
+	}
+	h := srv.Handle(func(arg *struct{}) (<-chan httprequest.SSEEvent, error) {
+		events := make(chan httprequest.SSEEvent, 1)
+		events <- httprequest.SSEEvent{
+			Data: longString,
+		}
+		close(events)
+		return events, nil
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.Handle(rec, req, httprouter.Params{})
+	c.Assert(rec.Header().Get("Content-Encoding"), qt.Equals, "")
+	c.Assert(rec.Body.String(), qt.Equals, "data: "+longString+"\n\n")
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+)
+
+// concurrencyRetryAfter holds the time that clients are asked to wait
+// before retrying requests rejected because of a concurrency limit.
+const concurrencyRetryAfter = time.Second
+
+// ConcurrencyLimiter limits the number of requests served at once by
+// the routes that share it. Routes share a limiter by naming it in
+// their concurrencygroup tag (see RouteMetadata.ConcurrencyGroup and
+// Server.ConcurrencyGroups).
+type ConcurrencyLimiter struct {
+	sem chan struct{}
+}
+
+// NewConcurrencyLimiter returns a limiter that allows at most n
+// requests to be served at once. It panics if n is not positive.
+func NewConcurrencyLimiter(n int) *ConcurrencyLimiter {
+	if n <= 0 {
+		panic(errgo.Newf("concurrency limit %d is not positive", n))
+	}
+	return &ConcurrencyLimiter{
+		sem: make(chan struct{}, n),
+	}
+}
+
+// Active returns the number of requests currently being served
+// under the limit.
+func (l *ConcurrencyLimiter) Active() int {
+	return len(l.sem)
+}
+
+// acquire waits for up to the given time for the number of requests
+// being served to fall below the limit, reporting whether the request
+// may be served. If it returns true, release must be called when the
+// request has been served.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, wait time.Duration) bool {
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+	}
+	if wait <= 0 {
+		return false
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release records that a request allowed by acquire has been served.
+func (l *ConcurrencyLimiter) release() {
+	<-l.sem
+}
+
+// concurrencyLimiter returns the limiter that applies to the route of
+// hf, or nil if there is none. It panics if the route names a group
+// that is not in srv.ConcurrencyGroups.
+func (srv *Server) concurrencyLimiter(hf handlerFunc) *ConcurrencyLimiter {
+	if n := hf.metadata.MaxConcurrent; n > 0 {
+		return NewConcurrencyLimiter(n)
+	}
+	group := hf.metadata.ConcurrencyGroup
+	if group == "" {
+		return nil
+	}
+	l := srv.ConcurrencyGroups[group]
+	if l == nil {
+		panic(errgo.Newf("%s %s: unknown concurrency group %q", hf.method, hf.pathPattern, group))
+	}
+	return l
+}
+
+// withConcurrencyLimit returns a handler that calls h only while
+// fewer requests than allowed by l are being served. Requests that
+// cannot be served within srv.ConcurrencyWait are rejected with a
+// CodeServiceUnavailable error.
+func (srv *Server) withConcurrencyLimit(h httprouter.Handle, l *ConcurrencyLimiter, hf handlerFunc) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		if !l.acquire(req.Context(), srv.ConcurrencyWait) {
+			err := Errorf(CodeServiceUnavailable, "too many concurrent requests to %s %s", hf.method, hf.pathPattern)
+			srv.WriteError(req.Context(), w, WithRetryAfter(err, concurrencyRetryAfter))
+			return
+		}
+		defer l.release()
+		h(w, req, p)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type concurrencyHandlers struct {
+	started chan<- string
+	unblock <-chan struct{}
+}
+
+func (h concurrencyHandlers) Report(arg *struct {
+	httprequest.Route `httprequest:"GET /report" maxconcurrent:"1"`
+}) (string, error) {
+	return h.wait("report")
+}
+
+func (h concurrencyHandlers) Export(arg *struct {
+	httprequest.Route `httprequest:"GET /export" concurrencygroup:"heavy"`
+}) (string, error) {
+	return h.wait("export")
+}
+
+func (h concurrencyHandlers) Import(arg *struct {
+	httprequest.Route `httprequest:"GET /import" concurrencygroup:"heavy"`
+}) (string, error) {
+	return h.wait("import")
+}
+
+func (h concurrencyHandlers) Ping(arg *struct {
+	httprequest.Route `httprequest:"GET /ping"`
+}) (string, error) {
+	return "pong", nil
+}
+
+func (h concurrencyHandlers) wait(name string) (string, error) {
+	h.started <- name
+	<-h.unblock
+	return name, nil
+}
+
+// concurrencyRouter returns a router serving concurrencyHandlers
+// with srv, along with a channel that receives the name of each
+// handler as it starts and a function that unblocks the handlers.
+func concurrencyRouter(srv *httprequest.Server) (http.Handler, <-chan string, func()) {
+	started := make(chan string, 10)
+	unblock := make(chan struct{})
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (concurrencyHandlers, context.Context, error) {
+		return concurrencyHandlers{
+			started: started,
+			unblock: unblock,
+		}, p.Context, nil
+	}))
+	return router, started, func() { close(unblock) }
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	c := qt.New(t)
+
+	heavy := httprequest.NewConcurrencyLimiter(1)
+	srv := &httprequest.Server{
+		ConcurrencyGroups: map[string]*httprequest.ConcurrencyLimiter{
+			"heavy": heavy,
+		},
+	}
+	router, started, unblock := concurrencyRouter(srv)
+
+	var wg sync.WaitGroup
+	recs := make(map[string]*httptest.ResponseRecorder)
+	for _, path := range []string{"/report", "/export"} {
+		rec := httptest.NewRecorder()
+		recs[path] = rec
+		req := httptest.NewRequest("GET", path, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			router.ServeHTTP(rec, req)
+		}()
+		<-started
+	}
+	c.Assert(heavy.Active(), qt.Equals, 1)
+
+	// Both the route limit and the group limit are reached.
+	for _, path := range []string{"/report", "/export", "/import"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		qthttptest.AssertJSONResponse(c, rec, http.StatusServiceUnavailable, &httprequest.RemoteError{
+			Code:    httprequest.CodeServiceUnavailable,
+			Message: "too many concurrent requests to GET " + path,
+		})
+		c.Assert(rec.Header().Get("Retry-After"), qt.Equals, "1")
+	}
+
+	// Unlimited routes are unaffected.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "pong")
+
+	unblock()
+	wg.Wait()
+	qthttptest.AssertJSONResponse(c, recs["/report"], http.StatusOK, "report")
+	qthttptest.AssertJSONResponse(c, recs["/export"], http.StatusOK, "export")
+	c.Assert(heavy.Active(), qt.Equals, 0)
+}
+
+func TestConcurrencyLimitWait(t *testing.T) {
+	c := qt.New(t)
+
+	srv := &httprequest.Server{
+		ConcurrencyGroups: map[string]*httprequest.ConcurrencyLimiter{
+			"heavy": httprequest.NewConcurrencyLimiter(1),
+		},
+		ConcurrencyWait: 5 * time.Second,
+	}
+	router, started, unblock := concurrencyRouter(srv)
+
+	done := make(chan *httptest.ResponseRecorder)
+	for _, path := range []string{"/export", "/import"} {
+		req := httptest.NewRequest("GET", path, nil)
+		go func() {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			done <- rec
+		}()
+	}
+	// Only one of the requests starts until the other finishes.
+	first := <-started
+	select {
+	case name := <-started:
+		c.Fatalf("%s started while %s was being served", name, first)
+	case <-time.After(50 * time.Millisecond):
+	}
+	unblock()
+	second := <-started
+	c.Assert(first, qt.Not(qt.Equals), second)
+	for i := 0; i < 2; i++ {
+		c.Assert((<-done).Code, qt.Equals, http.StatusOK)
+	}
+}
+
+func TestNewConcurrencyLimiterPanicsWithBadLimit(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(func() {
+		httprequest.NewConcurrencyLimiter(0)
+	}, qt.PanicMatches, "concurrency limit 0 is not positive")
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+var (
+	// ErrNotModified is the cause of the error returned by a call
+	// made with WithIfNoneMatch when the server responds with
+	// http.StatusNotModified. The call's result is left unchanged,
+	// so the caller can continue to use the copy it already has.
+	ErrNotModified = errgo.New("not modified")
+
+	// ErrPreconditionFailed is the cause of the error returned by a
+	// call made with WithIfMatch or WithIfUnmodifiedSince when the
+	// server responds with http.StatusPreconditionFailed, typically
+	// because the resource has been changed by someone else.
+	ErrPreconditionFailed = errgo.New("precondition failed")
+)
+
+// WithIfMatch returns a CallOption that makes the request conditional
+// on the current entity tag of the resource being the given one, as
+// is done when updating a resource using optimistic concurrency. The
+// tag may be given with or without surrounding quotes.
+//
+// If the precondition does not hold, the call returns an error with
+// cause ErrPreconditionFailed.
+func WithIfMatch(etag string) CallOption {
+	return func(o *callOptions) {
+		o.setConditional("If-Match", quoteETag(etag))
+	}
+}
+
+// WithIfNoneMatch returns a CallOption that makes the request
+// conditional on the current entity tag of the resource not being the
+// given one, as is done when revalidating a cached copy of the
+// resource. The tag may be given with or without surrounding quotes.
+//
+// If the server responds with http.StatusNotModified, the call
+// returns an error with cause ErrNotModified.
+func WithIfNoneMatch(etag string) CallOption {
+	return func(o *callOptions) {
+		o.setConditional("If-None-Match", quoteETag(etag))
+	}
+}
+
+// WithIfUnmodifiedSince returns a CallOption that makes the request
+// conditional on the resource not having been modified since the given
+// time.
+//
+// If the precondition does not hold, the call returns an error with
+// cause ErrPreconditionFailed.
+func WithIfUnmodifiedSince(t time.Time) CallOption {
+	return func(o *callOptions) {
+		o.setConditional("If-Unmodified-Since", t.UTC().Format(http.TimeFormat))
+	}
+}
+
+// setConditional sets the given conditional request header.
+func (o *callOptions) setConditional(key, value string) {
+	if o.header == nil {
+		o.header = make(http.Header)
+	}
+	o.header.Set(key, value)
+	o.conditional = true
+}
+
+// quoteETag returns etag surrounded by quotes unless it is already
+// quoted, is a weak tag or is "*".
+func quoteETag(etag string) string {
+	if etag == "*" || len(etag) >= 2 && etag[0] == '"' || len(etag) >= 3 && etag[:3] == `W/"` {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// conditionalError returns the error for resp, the response to a call
+// with the given options, and true if resp is a http.StatusNotModified
+// or http.StatusPreconditionFailed response to a conditional request.
+func (c *Client) conditionalError(resp *http.Response, o *callOptions) (error, bool) {
+	if !o.conditional {
+		return nil, false
+	}
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return errgo.WithCausef(nil, ErrNotModified, ""), true
+	case http.StatusPreconditionFailed:
+		if !isJSONMediaType(resp.Header) {
+			return errgo.WithCausef(nil, ErrPreconditionFailed, ""), true
+		}
+		// Include the error from the server.
+		return errgo.WithCausef(c.unmarshalError(resp), ErrPreconditionFailed, "precondition failed"), true
+	}
+	return nil, false
+}
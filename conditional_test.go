@@ -0,0 +1,110 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var docModified = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// conditionalServer returns a server holding a document with the
+// entity tag "v1" last modified at docModified, which checks the
+// preconditions of requests to it. Precondition failures are
+// reported with a JSON error when the path is /doc and with no body
+// otherwise.
+func conditionalServer(c *qt.C) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		switch {
+		case req.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+			return
+		case req.Header.Get("If-Match") != "" && req.Header.Get("If-Match") != `"v1"`:
+		case req.Header.Get("If-Unmodified-Since") != "":
+			t, err := http.ParseTime(req.Header.Get("If-Unmodified-Since"))
+			c.Check(err, qt.IsNil)
+			if t.Before(docModified) {
+				break
+			}
+			fallthrough
+		default:
+			httprequest.WriteJSON(w, http.StatusOK, "doc")
+			return
+		}
+		if req.URL.Path != "/doc" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusPreconditionFailed, &httprequest.RemoteError{
+			Message: "document has changed",
+		})
+	}))
+}
+
+func TestConditionalRequests(t *testing.T) {
+	c := qt.New(t)
+
+	srv := conditionalServer(c)
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	ctx := context.Background()
+
+	doc := "cached"
+	err := client.Get(ctx, "/doc", &doc, httprequest.WithIfNoneMatch("v1"))
+	c.Assert(errgo.Cause(err), qt.Equals, httprequest.ErrNotModified)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/doc: not modified`)
+	c.Assert(doc, qt.Equals, "cached")
+
+	err = client.Get(ctx, "/doc", &doc, httprequest.WithIfNoneMatch(`W/"v0"`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(doc, qt.Equals, "doc")
+
+	err = client.Get(ctx, "/doc", &doc, httprequest.WithIfMatch(`"v1"`))
+	c.Assert(err, qt.IsNil)
+
+	err = client.Get(ctx, "/doc", &doc, httprequest.WithIfMatch("v0"))
+	c.Assert(errgo.Cause(err), qt.Equals, httprequest.ErrPreconditionFailed)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/doc: precondition failed: document has changed`)
+
+	err = client.Get(ctx, "/other", &doc, httprequest.WithIfMatch("v0"))
+	c.Assert(errgo.Cause(err), qt.Equals, httprequest.ErrPreconditionFailed)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/other: precondition failed`)
+
+	err = client.Get(ctx, "/doc", &doc, httprequest.WithIfUnmodifiedSince(docModified))
+	c.Assert(err, qt.IsNil)
+
+	err = client.Get(ctx, "/doc", &doc, httprequest.WithIfUnmodifiedSince(docModified.Add(-time.Hour)))
+	c.Assert(errgo.Cause(err), qt.Equals, httprequest.ErrPreconditionFailed)
+}
+
+func TestUnconditionalRequestStatuses(t *testing.T) {
+	c := qt.New(t)
+
+	srv := conditionalServer(c)
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	// Without a conditional option, the statuses
+	// are treated like any other error.
+	req, err := http.NewRequest("GET", "/other", nil)
+	c.Assert(err, qt.IsNil)
+	req.Header.Set("If-Match", `"v0"`)
+	err = client.Do(context.Background(), req, nil)
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(errgo.Cause(err), qt.Not(qt.Equals), httprequest.ErrPreconditionFailed)
+}
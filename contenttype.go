@@ -0,0 +1,48 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"mime"
+	"net/http"
+)
+
+// checkContentType checks that the content type of the body of req,
+// if it has one, is acceptable for unmarshaling into a value
+// of the given request type.
+func checkContentType(req *http.Request, rt *requestType) error {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength == 0 {
+		return nil
+	}
+	contentType := req.Header.Get("Content-Type")
+	switch {
+	case rt.bodyContentType != "":
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != rt.bodyContentType {
+			return unsupportedMediaType(contentType, rt.bodyContentType)
+		}
+	case rt.body:
+		if !isJSONMediaType(req.Header) {
+			return unsupportedMediaType(contentType, "application/json")
+		}
+	case rt.multipart:
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "multipart/form-data" {
+			return unsupportedMediaType(contentType, "multipart/form-data")
+		}
+	case rt.formBody:
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/x-www-form-urlencoded" && mediaType != "multipart/form-data" {
+			return unsupportedMediaType(contentType, "application/x-www-form-urlencoded or multipart/form-data")
+		}
+	}
+	return nil
+}
+
+func unsupportedMediaType(contentType, want string) error {
+	if contentType == "" {
+		return Errorf(CodeUnsupportedMediaType, "missing content type; want %s", want)
+	}
+	return Errorf(CodeUnsupportedMediaType, "unsupported content type %q; want %s", contentType, want)
+}
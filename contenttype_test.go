@@ -0,0 +1,142 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type contentTypeHandlers struct{}
+
+func (contentTypeHandlers) JSON(arg *struct {
+	httprequest.Route `httprequest:"POST /json"`
+	Body              string `httprequest:",body"`
+}) (string, error) {
+	return arg.Body, nil
+}
+
+func (contentTypeHandlers) Form(arg *struct {
+	httprequest.Route `httprequest:"POST /form"`
+	A                 string `httprequest:"a,form,inbody"`
+}) (string, error) {
+	return arg.A, nil
+}
+
+func (contentTypeHandlers) Query(arg *struct {
+	httprequest.Route `httprequest:"POST /query"`
+	A                 string `httprequest:"a,form"`
+}) (string, error) {
+	return arg.A, nil
+}
+
+var strictContentTypeTests = []struct {
+	about        string
+	path         string
+	contentType  string
+	body         string
+	expectStatus int
+	expectBody   interface{}
+}{{
+	about:        "json body",
+	path:         "/json",
+	contentType:  "application/json",
+	body:         `"hello"`,
+	expectStatus: http.StatusOK,
+	expectBody:   "hello",
+}, {
+	about:        "json suffix body",
+	path:         "/json",
+	contentType:  "application/vnd.example+json; charset=utf-8",
+	body:         `"hello"`,
+	expectStatus: http.StatusOK,
+	expectBody:   "hello",
+}, {
+	about:        "text body for json field",
+	path:         "/json",
+	contentType:  "text/plain",
+	body:         `hello`,
+	expectStatus: http.StatusUnsupportedMediaType,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeUnsupportedMediaType,
+		Message: `unsupported content type "text/plain"; want application/json`,
+	},
+}, {
+	about:        "missing content type for json field",
+	path:         "/json",
+	body:         `"hello"`,
+	expectStatus: http.StatusUnsupportedMediaType,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeUnsupportedMediaType,
+		Message: `missing content type; want application/json`,
+	},
+}, {
+	about:        "no body for json field",
+	path:         "/json",
+	contentType:  "text/plain",
+	expectStatus: http.StatusInternalServerError,
+}, {
+	about:        "form body",
+	path:         "/form",
+	contentType:  "application/x-www-form-urlencoded",
+	body:         "a=hello",
+	expectStatus: http.StatusOK,
+	expectBody:   "hello",
+}, {
+	about:        "json body for form fields",
+	path:         "/form",
+	contentType:  "application/json",
+	body:         `{"a": "hello"}`,
+	expectStatus: http.StatusUnsupportedMediaType,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeUnsupportedMediaType,
+		Message: `unsupported content type "application/json"; want application/x-www-form-urlencoded or multipart/form-data`,
+	},
+}, {
+	about:        "any body when no body is expected",
+	path:         "/query?a=hello",
+	contentType:  "text/plain",
+	body:         "ignored",
+	expectStatus: http.StatusOK,
+	expectBody:   "hello",
+}}
+
+func TestStrictContentType(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		StrictContentType: true,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (contentTypeHandlers, context.Context, error) {
+		return contentTypeHandlers{}, p.Context, nil
+	}))
+	for _, test := range strictContentTypeTests {
+		c.Run(test.about, func(c *qt.C) {
+			var body io.Reader
+			if test.body != "" {
+				body = strings.NewReader(test.body)
+			}
+			req := httptest.NewRequest("POST", test.path, body)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if test.expectBody == nil {
+				c.Assert(rec.Code, qt.Equals, test.expectStatus, qt.Commentf("body: %s", rec.Body))
+				return
+			}
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
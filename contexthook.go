@@ -0,0 +1,28 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+)
+
+// requestContext returns the context to use as Params.Context
+// when serving req, as returned by srv.ContextHook if set.
+func (srv *Server) requestContext(req *http.Request) (context.Context, error) {
+	ctx := req.Context()
+	if srv.ContextHook == nil {
+		return ctx, nil
+	}
+	ctx1, err := srv.ContextHook(ctx, req)
+	if err != nil {
+		return ctx, err
+	}
+	if ctx1 == nil {
+		// Fall back to the original context rather
+		// than passing a nil context to the handler.
+		return ctx, nil
+	}
+	return ctx1, nil
+}
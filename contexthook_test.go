@@ -0,0 +1,108 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type tenantKey struct{}
+
+type tenantHandlers struct {
+	tenant string
+}
+
+func (h tenantHandlers) Get(p httprequest.Params, _ *struct {
+	httprequest.Route `httprequest:"GET /tenant"`
+}) (string, error) {
+	if tenant, _ := p.Context.Value(tenantKey{}).(string); tenant != h.tenant {
+		return "", httprequest.Errorf("", "root and handler contexts differ")
+	}
+	return h.tenant, nil
+}
+
+var contextHookTests = []struct {
+	about        string
+	hook         func(ctx context.Context, req *http.Request) (context.Context, error)
+	expectStatus int
+	expectBody   interface{}
+}{{
+	about: "context from hook",
+	hook: func(ctx context.Context, req *http.Request) (context.Context, error) {
+		return context.WithValue(ctx, tenantKey{}, req.Header.Get("X-Tenant")), nil
+	},
+	expectStatus: http.StatusOK,
+	expectBody:   "acme",
+}, {
+	about: "error from hook",
+	hook: func(ctx context.Context, req *http.Request) (context.Context, error) {
+		return nil, httprequest.Errorf(httprequest.CodeForbidden, "unknown tenant")
+	},
+	expectStatus: http.StatusForbidden,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeForbidden,
+		Message: "unknown tenant",
+	},
+}, {
+	about: "nil context from hook",
+	hook: func(ctx context.Context, req *http.Request) (context.Context, error) {
+		return nil, nil
+	},
+	expectStatus: http.StatusOK,
+	expectBody:   "",
+}, {
+	about:        "no hook",
+	expectStatus: http.StatusOK,
+	expectBody:   "",
+}}
+
+func TestContextHookWithHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range contextHookTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				ContextHook: test.hook,
+			}
+			router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (tenantHandlers, context.Context, error) {
+				tenant, _ := p.Context.Value(tenantKey{}).(string)
+				return tenantHandlers{tenant: tenant}, p.Context, nil
+			}))
+			req := httptest.NewRequest("GET", "/tenant", nil)
+			req.Header.Set("X-Tenant", "acme")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
+
+func TestContextHookWithHandle(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range contextHookTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				ContextHook: test.hook,
+			}
+			h := srv.Handle(func(p httprequest.Params, _ *struct{}) (string, error) {
+				tenant, _ := p.Context.Value(tenantKey{}).(string)
+				return tenant, nil
+			})
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-Tenant", "acme")
+			rec := httptest.NewRecorder()
+			h.Handle(rec, req, nil)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultDeadlineHeader holds a conventional name for the header used
+// to propagate deadlines, for use as Client.DeadlineHeader and
+// Server.DeadlineHeader.
+const DefaultDeadlineHeader = "X-Request-Timeout"
+
+// formatDeadline returns the value of a deadline header for a request
+// that must complete within d, for example "1500ms".
+func formatDeadline(d time.Duration) string {
+	ms := (d + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(ms), 10) + "ms"
+}
+
+// parseDeadline parses the value of a deadline header, which holds a
+// duration as accepted by time.ParseDuration, reporting whether it is
+// a valid positive duration.
+func parseDeadline(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// contextWithDeadlineHeader returns ctx with its deadline tightened to
+// that specified by the given header of req, if any, and a function
+// that must be called to release its resources.
+func contextWithDeadlineHeader(ctx context.Context, req *http.Request, header string) (context.Context, context.CancelFunc) {
+	d, ok := parseDeadline(req.Header.Get(header))
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// deadlineDoer is the Doer used by a Client with a DeadlineHeader. It
+// sets the header of each request to the time remaining until the
+// deadline of the request's context, if it has one.
+type deadlineDoer struct {
+	header string
+	doer   Doer
+}
+
+// Do implements Doer.Do.
+func (d deadlineDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext.
+func (d deadlineDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining > 0 {
+			req.Header.Set(d.header, formatDeadline(remaining))
+		}
+	}
+	return send(ctx, d.doer, req)
+}
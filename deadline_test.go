@@ -0,0 +1,142 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestClientDeadlineHeader(t *testing.T) {
+	c := qt.New(t)
+	downstream := echoHeadersServer()
+	defer downstream.Close()
+
+	client := &httprequest.Client{
+		BaseURL:        downstream.URL,
+		DeadlineHeader: httprequest.DefaultDeadlineHeader,
+	}
+	var h http.Header
+	err := client.Get(context.Background(), "/", &h, httprequest.WithTimeout(5*time.Second))
+	c.Assert(err, qt.IsNil)
+	v := h.Get(httprequest.DefaultDeadlineHeader)
+	c.Assert(v, qt.Matches, `[0-9]+ms`)
+	d, err := time.ParseDuration(v)
+	c.Assert(err, qt.IsNil)
+	c.Assert(d > 4*time.Second && d <= 5*time.Second, qt.IsTrue, qt.Commentf("%v", d))
+
+	// Without a deadline, no header is sent.
+	h = nil
+	err = client.Get(context.Background(), "/", &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get(httprequest.DefaultDeadlineHeader), qt.Equals, "")
+}
+
+// deadlineServer returns a server that propagates deadlines and
+// serves GET /deadline by responding with the time remaining until
+// the deadline of its context, or -1 if there is none, and GET /relay
+// by calling GET /deadline on downstream with its context.
+func deadlineServer(downstream string) *httptest.Server {
+	srv := httprequest.Server{
+		DeadlineHeader: httprequest.DefaultDeadlineHeader,
+	}
+	router := httprouter.New()
+	for _, h := range []httprequest.Handler{srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /deadline"`
+	}) (time.Duration, error) {
+		deadline, ok := p.Context.Deadline()
+		if !ok {
+			return -1, nil
+		}
+		return time.Until(deadline), nil
+	}), srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /relay"`
+	}) (time.Duration, error) {
+		client := &httprequest.Client{
+			BaseURL:        downstream,
+			DeadlineHeader: httprequest.DefaultDeadlineHeader,
+		}
+		var d time.Duration
+		err := client.Get(p.Context, "/deadline", &d)
+		return d, err
+	})} {
+		router.Handle(h.Method, h.Path, h.Handle)
+	}
+	return httptest.NewServer(router)
+}
+
+var serverDeadlineHeaderTests = []struct {
+	about  string
+	header string
+	expect time.Duration
+}{{
+	about:  "no header",
+	expect: -1,
+}, {
+	about:  "milliseconds",
+	header: "2000ms",
+	expect: 2 * time.Second,
+}, {
+	about:  "seconds",
+	header: "1.5s",
+	expect: 1500 * time.Millisecond,
+}, {
+	about:  "invalid",
+	header: "soon",
+	expect: -1,
+}, {
+	about:  "negative",
+	header: "-1s",
+	expect: -1,
+}}
+
+func TestServerDeadlineHeader(t *testing.T) {
+	c := qt.New(t)
+	srv := deadlineServer("")
+	defer srv.Close()
+	for _, test := range serverDeadlineHeaderTests {
+		c.Run(test.about, func(c *qt.C) {
+			req, err := http.NewRequest("GET", srv.URL+"/deadline", nil)
+			c.Assert(err, qt.IsNil)
+			if test.header != "" {
+				req.Header.Set(httprequest.DefaultDeadlineHeader, test.header)
+			}
+			var d time.Duration
+			err = (&httprequest.Client{}).Do(context.Background(), req, &d)
+			c.Assert(err, qt.IsNil)
+			if test.expect < 0 {
+				c.Assert(d, qt.Equals, test.expect)
+				return
+			}
+			c.Assert(d > test.expect-time.Second && d <= test.expect, qt.IsTrue, qt.Commentf("%v", d))
+		})
+	}
+}
+
+func TestDeadlinePropagation(t *testing.T) {
+	c := qt.New(t)
+	downstream := deadlineServer("")
+	defer downstream.Close()
+	upstream := deadlineServer(downstream.URL)
+	defer upstream.Close()
+
+	client := &httprequest.Client{
+		BaseURL:        upstream.URL,
+		DeadlineHeader: httprequest.DefaultDeadlineHeader,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var d time.Duration
+	err := client.Get(ctx, "/relay", &d)
+	c.Assert(err, qt.IsNil)
+	c.Assert(d > 2*time.Second && d <= 3*time.Second, qt.IsTrue, qt.Commentf("%v", d))
+}
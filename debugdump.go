@@ -0,0 +1,204 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// MaxDebugDumpBodySize holds the maximum number of bytes of request and
+// response bodies included in debug dumps (see Client.DebugDump).
+const MaxDebugDumpBodySize = 8 * 1024
+
+// secretFieldPattern matches the names of JSON object members, form
+// fields and query parameters whose values are redacted in debug
+// dumps.
+var secretFieldPattern = regexp.MustCompile(`(?i)passw(or)?d|secret|token|api_?key|credential|private_?key`)
+
+// jsonMemberPattern matches a JSON object member with a string value.
+var jsonMemberPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// ErrorDebugDump returns the debug dump attached to an error returned
+// by a Client with DebugDump set, or nil if there is none.
+func ErrorDebugDump(err error) []byte {
+	for err != nil {
+		if err, ok := err.(*callError); ok {
+			return err.dump
+		}
+		w, ok := err.(errgo.Wrapper)
+		if !ok {
+			return nil
+		}
+		err = w.Underlying()
+	}
+	return nil
+}
+
+// debugDumper captures a debug dump of a request and its response.
+type debugDumper struct {
+	request  []byte
+	status   string
+	proto    string
+	header   http.Header
+	body     *captureReadCloser
+	hasReply bool
+}
+
+// newDebugDumper returns a debugDumper that has captured req, which
+// has not yet been sent.
+func newDebugDumper(req *http.Request) *debugDumper {
+	var buf bytes.Buffer
+	u := *req.URL
+	u.RawQuery = redactForm(u.RawQuery)
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, u.RequestURI())
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	sanitizeHeader(req.Header).Write(&buf)
+	buf.WriteString("\r\n")
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(io.LimitReader(body, MaxDebugDumpBodySize+1))
+			body.Close()
+			writeDumpBody(&buf, req.Header, data)
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		buf.WriteString("[body not available]\r\n")
+	}
+	return &debugDumper{
+		request: buf.Bytes(),
+	}
+}
+
+// captureResponse arranges for the response to be included in the
+// dump. The body is captured as it is read.
+func (d *debugDumper) captureResponse(resp *http.Response) {
+	d.hasReply = true
+	d.status = resp.Status
+	d.proto = resp.Proto
+	d.header = sanitizeHeader(resp.Header)
+	d.body = &captureReadCloser{
+		ReadCloser: resp.Body,
+	}
+	resp.Body = d.body
+}
+
+// dump returns the dump, including err, the error returned by the call.
+func (d *debugDumper) dump(err error) []byte {
+	var buf bytes.Buffer
+	buf.Write(d.request)
+	buf.WriteString("\r\n")
+	if !d.hasReply {
+		fmt.Fprintf(&buf, "[no response: %v]\r\n", err)
+		return buf.Bytes()
+	}
+	fmt.Fprintf(&buf, "%s %s\r\n", d.proto, d.status)
+	d.header.Write(&buf)
+	buf.WriteString("\r\n")
+	writeDumpBody(&buf, d.header, d.body.buf.Bytes())
+	return buf.Bytes()
+}
+
+// debugDump returns the dump captured by d, given err, the error
+// returned by the call, if c.DebugDump is set, and calls c.OnDebugDump
+// with it if that is set.
+func (c *Client) debugDump(ctx context.Context, d *debugDumper, err error) []byte {
+	dump := d.dump(err)
+	if c.OnDebugDump != nil {
+		c.OnDebugDump(ctx, dump)
+	}
+	if !c.DebugDump {
+		return nil
+	}
+	return dump
+}
+
+// writeDumpBody writes up to MaxDebugDumpBodySize bytes of the body
+// data, which was sent with the given header, to buf, redacting the
+// values of secret fields.
+func writeDumpBody(buf *bytes.Buffer, h http.Header, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	truncated := len(data) > MaxDebugDumpBodySize
+	if truncated {
+		data = data[:MaxDebugDumpBodySize]
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		buf.WriteString(redactForm(string(data)))
+	case isJSONMediaType(h):
+		buf.Write(redactJSON(data))
+	default:
+		buf.Write(data)
+	}
+	if truncated {
+		buf.WriteString("\r\n[truncated]")
+	}
+	buf.WriteString("\r\n")
+}
+
+// redactJSON returns data, which holds JSON, possibly truncated, with
+// the string values of secret object members redacted.
+func redactJSON(data []byte) []byte {
+	return jsonMemberPattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := jsonMemberPattern.FindSubmatch(m)
+		if !secretFieldPattern.Match(sub[1]) {
+			return m
+		}
+		return []byte(`"` + string(sub[1]) + `"` + string(sub[2]) + `"REDACTED"`)
+	})
+}
+
+// redactForm returns s, which holds URL-encoded form values, with the
+// values of secret fields redacted.
+func redactForm(s string) string {
+	if s == "" {
+		return s
+	}
+	fields := strings.Split(s, "&")
+	for i, f := range fields {
+		name := f
+		if j := strings.IndexByte(f, '='); j >= 0 {
+			name = f[:j]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil && secretFieldPattern.MatchString(unescaped) {
+			fields[i] = name + "=REDACTED"
+		}
+	}
+	return strings.Join(fields, "&")
+}
+
+// captureReadCloser records up to MaxDebugDumpBodySize+1 bytes read
+// from the ReadCloser.
+type captureReadCloser struct {
+	io.ReadCloser
+	buf bytes.Buffer
+}
+
+// Read implements io.Reader.Read.
+func (r *captureReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if room := MaxDebugDumpBodySize + 1 - r.buf.Len(); room > 0 {
+		if n < room {
+			room = n
+		}
+		r.buf.Write(p[:room])
+	}
+	return n, err
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestClientDebugDump(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=xyz")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Message":"bad request","token":"tok123"}`))
+	}))
+	defer srv.Close()
+
+	var dumps [][]byte
+	client := &httprequest.Client{
+		BaseURL:   srv.URL,
+		DebugDump: true,
+		OnDebugDump: func(ctx context.Context, dump []byte) {
+			dumps = append(dumps, dump)
+		},
+	}
+	req, err := http.NewRequest("POST", "/x?api_key=k1&q=v", strings.NewReader(`{"name":"bob","password":"hunter2"}`))
+	c.Assert(err, qt.IsNil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+	err = client.Do(context.Background(), req, nil)
+	c.Assert(err, qt.ErrorMatches, `Post .*: bad request`)
+	c.Assert(errgo.Cause(err), qt.Satisfies, func(err error) bool {
+		_, ok := err.(*httprequest.RemoteError)
+		return ok
+	})
+	dump := string(httprequest.ErrorDebugDump(err))
+	c.Assert(dumps, qt.HasLen, 1)
+	c.Assert(string(dumps[0]), qt.Equals, dump)
+
+	c.Assert(dump, qt.Contains, "POST /x?api_key=REDACTED&q=v HTTP/1.1\r\n")
+	c.Assert(dump, qt.Contains, "Authorization: REDACTED\r\n")
+	c.Assert(dump, qt.Contains, `{"name":"bob","password":"REDACTED"}`)
+	c.Assert(dump, qt.Contains, "HTTP/1.1 400 Bad Request\r\n")
+	c.Assert(dump, qt.Contains, "Set-Cookie: REDACTED\r\n")
+	c.Assert(dump, qt.Contains, `{"Message":"bad request","token":"REDACTED"}`)
+	for _, secret := range []string{"hunter2", "abc", "k1", "xyz", "tok123"} {
+		c.Assert(dump, qt.Not(qt.Contains), secret)
+	}
+}
+
+func TestClientDebugDumpTruncatesBody(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(strings.Repeat("x", httprequest.MaxDebugDumpBodySize*2)))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:   srv.URL,
+		DebugDump: true,
+	}
+	err := client.Get(context.Background(), "/x", nil)
+	c.Assert(err, qt.Not(qt.IsNil))
+	dump := string(httprequest.ErrorDebugDump(err))
+	c.Assert(dump, qt.Contains, "GET /x HTTP/1.1\r\n")
+	c.Assert(dump, qt.Contains, strings.Repeat("x", httprequest.MaxDebugDumpBodySize)+"\r\n[truncated]\r\n")
+	c.Assert(dump, qt.Not(qt.Contains), strings.Repeat("x", httprequest.MaxDebugDumpBodySize+1))
+}
+
+func TestClientDebugDumpTransportError(t *testing.T) {
+	c := qt.New(t)
+	client := &httprequest.Client{
+		DebugDump: true,
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errgo.New("connection refused")
+		}),
+	}
+	err := client.Get(context.Background(), "http://0.1.2.3/x", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://0.1.2.3/x: connection refused`)
+	dump := string(httprequest.ErrorDebugDump(err))
+	c.Assert(dump, qt.Contains, "GET /x HTTP/1.1\r\nHost: 0.1.2.3\r\n")
+	c.Assert(dump, qt.Contains, "[no response: Get http://0.1.2.3/x: connection refused]")
+}
+
+func TestClientDebugDumpNotOnSuccess(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+
+	called := false
+	client := &httprequest.Client{
+		BaseURL:   srv.URL,
+		DebugDump: true,
+		OnDebugDump: func(ctx context.Context, dump []byte) {
+			called = true
+		},
+	}
+	var resp string
+	err := client.Get(context.Background(), "/x", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+	c.Assert(called, qt.IsFalse)
+}
+
+func TestErrorDebugDumpWithoutDump(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequest.ErrorDebugDump(errgo.New("x")), qt.IsNil)
+	c.Assert(httprequest.ErrorDebugDump(nil), qt.IsNil)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option configures a Client derived from another with Client.With.
+type Option func(*Client)
+
+// With returns a new Client that is a shallow copy of c with the given
+// options applied. The new Client shares c's Doer, and so its
+// transport and connections, along with its other resources, such as
+// Cache, Auth and RequestCompression, so derived clients, for example
+// one per tenant, are cheap to create. Changes made to the new Client
+// do not affect c.
+func (c *Client) With(opts ...Option) *Client {
+	c1 := *c
+	c1.Header = c.Header.Clone()
+	c1.Codecs = append([]Codec(nil), c.Codecs...)
+	for _, opt := range opts {
+		opt(&c1)
+	}
+	return &c1
+}
+
+// WithBasePath returns an Option that appends the given path to the
+// derived Client's BaseURL, so that, for example, a Client with the
+// BaseURL "https://api.example.com" derived with
+// WithBasePath("/tenants/acme") makes requests relative to
+// "https://api.example.com/tenants/acme".
+func WithBasePath(path string) Option {
+	return func(c *Client) {
+		c.BaseURL = strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+}
+
+// WithDefaultHeader returns an Option that adds the given value to
+// the derived Client's Header.
+func WithDefaultHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.Header == nil {
+			c.Header = make(http.Header)
+		}
+		c.Header.Add(key, value)
+	}
+}
+
+// WithRetryPolicy returns an Option that sets the derived Client's
+// Retry to the given policy.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = policy
+	}
+}
+
+// addDefaultHeaders adds the headers in c.Header
+// that req does not already have to req.
+func (c *Client) addDefaultHeaders(req *http.Request) {
+	for k, vs := range c.Header {
+		k = http.CanonicalHeaderKey(k)
+		if len(req.Header[k]) > 0 {
+			continue
+		}
+		req.Header[k] = append([]string(nil), vs...)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestClientWith(t *testing.T) {
+	c := qt.New(t)
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+		httprequest.WriteJSON(w, http.StatusOK, req.Header)
+	}))
+	defer srv.Close()
+
+	var requests int32
+	base := &httprequest.Client{
+		BaseURL: srv.URL + "/api/",
+		Header:  http.Header{"X-Service": {"billing"}},
+		OnRequest: func(ctx context.Context, req httprequest.ClientRequestInfo) {
+			atomic.AddInt32(&requests, 1)
+		},
+	}
+	tenant := base.With(
+		httprequest.WithBasePath("/tenants/acme"),
+		httprequest.WithDefaultHeader("X-Tenant", "acme"),
+		httprequest.WithDefaultHeader("X-Service", "billing-acme"),
+	)
+	c.Assert(tenant.BaseURL, qt.Equals, srv.URL+"/api/tenants/acme")
+	c.Assert(base.BaseURL, qt.Equals, srv.URL+"/api/")
+	c.Assert(base.Header, qt.DeepEquals, http.Header{"X-Service": {"billing"}})
+
+	var h http.Header
+	err := tenant.Get(context.Background(), "/invoices", &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get("X-Tenant"), qt.Equals, "acme")
+	c.Assert(h.Values("X-Service"), qt.DeepEquals, []string{"billing", "billing-acme"})
+
+	h = nil
+	err = base.Get(context.Background(), "/invoices", &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get("X-Tenant"), qt.Equals, "")
+	c.Assert(h.Values("X-Service"), qt.DeepEquals, []string{"billing"})
+
+	c.Assert(paths, qt.DeepEquals, []string{"/api/tenants/acme/invoices", "/api/invoices"})
+	// The hooks are shared.
+	c.Assert(requests, qt.Equals, int32(2))
+}
+
+func TestClientDefaultHeaderDoesNotOverride(t *testing.T) {
+	c := qt.New(t)
+	srv := echoHeadersServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Header: http.Header{
+			"X-Tenant":  {"default"},
+			"x-service": {"default"},
+		},
+	}
+	var h http.Header
+	err := client.Get(context.Background(), "/", &h, httprequest.WithHeader("X-Tenant", "explicit"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Values("X-Tenant"), qt.DeepEquals, []string{"explicit"})
+	c.Assert(h.Values("X-Service"), qt.DeepEquals, []string{"default"})
+}
+
+func TestClientWithRetryPolicy(t *testing.T) {
+	c := qt.New(t)
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	base := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	retrying := base.With(httprequest.WithRetryPolicy(&httprequest.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff: func(int) time.Duration {
+			return 0
+		},
+	}))
+	c.Assert(base.Retry, qt.IsNil)
+	err := retrying.Get(context.Background(), "/", nil)
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(attempts, qt.Equals, int32(3))
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"encoding/json"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// detailsError is the error returned by WithDetails.
+type detailsError struct {
+	err     error
+	details json.RawMessage
+}
+
+// WithDetails returns an error with the same message and cause as err
+// that also holds v, a machine-readable description of the error, such
+// as the limit of a quota that has been exceeded. When the error is
+// written by a Server using DefaultErrorMapper, v is sent as the Info
+// of the RemoteError in the response, replacing any Info held by the
+// cause of err, and can be retrieved by the client with ErrorDetails.
+//
+// The value is marshaled as JSON immediately. If that fails, the
+// returned error, which still has the cause of err, describes the
+// failure instead. WithDetails returns nil if err is nil.
+func WithDetails(err error, v interface{}) error {
+	if err == nil {
+		return nil
+	}
+	data, merr := json.Marshal(v)
+	if merr != nil {
+		return errgo.WithCausef(err, errgo.Cause(err), "cannot marshal error details: %v", merr)
+	}
+	return &detailsError{
+		err:     err,
+		details: data,
+	}
+}
+
+// ErrorDetails unmarshals the details held by err into v, which should
+// be a pointer, and reports whether it did so. The details are those
+// attached with WithDetails, on the server side, or those held in the
+// Info of the RemoteError returned by a Client, on the client side,
+// for example:
+//
+//	var quota QuotaDetails
+//	if httprequest.ErrorDetails(err, &quota) {
+//		log.Printf("quota of %d exceeded", quota.Limit)
+//	}
+//
+// It returns false if err holds no details or they cannot be
+// unmarshaled into v.
+func ErrorDetails(err error, v interface{}) bool {
+	data := errorDetails(err)
+	if data == nil {
+		return false
+	}
+	return json.Unmarshal(*data, v) == nil
+}
+
+// errorDetails returns the details held by err, if any: those attached
+// by the outermost call to WithDetails or, if there are none, the Info
+// of the first *RemoteError found in the chain of errors wrapped by
+// err or as its cause.
+func errorDetails(err error) *json.RawMessage {
+	for e := err; e != nil; e = unwrapError(e) {
+		switch e := e.(type) {
+		case *detailsError:
+			return &e.details
+		case *RemoteError:
+			if e.Info != nil {
+				return e.Info
+			}
+		}
+	}
+	if rerr, ok := errgo.Cause(err).(*RemoteError); ok {
+		return rerr.Info
+	}
+	return nil
+}
+
+// attachedDetails returns the details attached to err by the
+// outermost call to WithDetails, if any.
+func attachedDetails(err error) *json.RawMessage {
+	for e := err; e != nil; e = unwrapError(e) {
+		if e, ok := e.(*detailsError); ok {
+			return &e.details
+		}
+	}
+	return nil
+}
+
+// Error implements error.Error.
+func (e *detailsError) Error() string {
+	return e.err.Error()
+}
+
+// Cause implements errgo.Causer.Cause.
+func (e *detailsError) Cause() error {
+	return errgo.Cause(e.err)
+}
+
+// Underlying implements errgo.Wrapper.Underlying.
+func (e *detailsError) Underlying() error {
+	return e.err
+}
+
+// Message implements errgo.Wrapper.Message.
+func (e *detailsError) Message() string {
+	return ""
+}
+
+// Unwrap returns the cause of the error.
+func (e *detailsError) Unwrap() error {
+	return e.Cause()
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type quotaDetails struct {
+	Limit int `json:"limit"`
+	Used  int `json:"used"`
+}
+
+func TestErrorDetailsRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	var srv httprequest.Server
+	hsrv := httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, arg *struct {
+			httprequest.Route `httprequest:"GET /items/:name"`
+			Name              string `httprequest:"name,path"`
+		}) error {
+			switch arg.Name {
+			case "quota":
+				err := errgo.WithCausef(nil, httprequest.ErrTooManyRequests, "quota exceeded")
+				return httprequest.WithDetails(err, quotaDetails{Limit: 10, Used: 12})
+			case "plain":
+				return httprequest.WithDetails(errgo.New("plain failure"), []string{"a", "b"})
+			}
+			return httprequest.ErrNotFound
+		}),
+	}))
+	defer hsrv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: hsrv.URL,
+	}
+	err := client.Get(context.Background(), "/items/quota", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/items/quota: quota exceeded`)
+	c.Assert(errors.Is(err, httprequest.ErrTooManyRequests), qt.IsTrue)
+	var quota quotaDetails
+	c.Assert(httprequest.ErrorDetails(err, &quota), qt.IsTrue)
+	c.Assert(quota, qt.DeepEquals, quotaDetails{Limit: 10, Used: 12})
+
+	err = client.Get(context.Background(), "/items/plain", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/items/plain: plain failure`)
+	var strs []string
+	c.Assert(httprequest.ErrorDetails(err, &strs), qt.IsTrue)
+	c.Assert(strs, qt.DeepEquals, []string{"a", "b"})
+
+	// Details of the wrong type are not found.
+	c.Assert(httprequest.ErrorDetails(err, &quota), qt.IsFalse)
+
+	err = client.Get(context.Background(), "/items/other", nil)
+	c.Assert(httprequest.ErrorDetails(err, &quota), qt.IsFalse)
+}
+
+func TestWithDetails(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequest.WithDetails(nil, 1), qt.IsNil)
+
+	cause := errgo.New("cause")
+	err := httprequest.WithDetails(errgo.WithCausef(nil, cause, "failure"), quotaDetails{Limit: 5})
+	c.Assert(err, qt.ErrorMatches, "failure")
+	c.Assert(errgo.Cause(err), qt.Equals, cause)
+	c.Assert(errors.Is(err, cause), qt.IsTrue)
+
+	// Details are available on the server side too, and the
+	// outermost details take precedence.
+	err = httprequest.WithDetails(errgo.Mask(err, errgo.Any), quotaDetails{Limit: 6})
+	var quota quotaDetails
+	c.Assert(httprequest.ErrorDetails(err, &quota), qt.IsTrue)
+	c.Assert(quota, qt.DeepEquals, quotaDetails{Limit: 6})
+
+	// Values that cannot be marshaled are reported.
+	err = httprequest.WithDetails(errgo.WithCausef(nil, cause, "failure"), func() {})
+	c.Assert(err, qt.ErrorMatches, "cannot marshal error details: json: unsupported type: func\\(\\): failure")
+	c.Assert(errgo.Cause(err), qt.Equals, cause)
+	c.Assert(httprequest.ErrorDetails(err, &quota), qt.IsFalse)
+}
+
+func TestWithDetailsReplacesInfo(t *testing.T) {
+	c := qt.New(t)
+	var srv httprequest.Server
+	rec := httptest.NewRecorder()
+	info := json.RawMessage(`{"limit":1}`)
+	rerr := &httprequest.RemoteError{
+		Code:    "details test",
+		Message: "failure",
+		Info:    &info,
+	}
+	srv.WriteError(context.Background(), rec, httprequest.WithDetails(rerr, quotaDetails{Limit: 2}))
+	c.Assert(rec.Body.String(), qt.Equals, `{"Message":"failure","Code":"details test","Info":{"limit":2,"used":0}}`)
+
+	var quota quotaDetails
+	c.Assert(httprequest.ErrorDetails(rerr, &quota), qt.IsTrue)
+	c.Assert(quota, qt.DeepEquals, quotaDetails{Limit: 1})
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+
+// responseBodyField returns the field that should be set to the
+// response body when resp is passed as the result of a call made by a
+// Client. If resp is a pointer to a struct with a field of type
+// io.ReadCloser tagged with `httprequest:",body"`, it returns the
+// field and true.
+func responseBodyField(resp interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(resp)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type != readCloserType {
+			continue
+		}
+		tag := f.Tag.Get("httprequest")
+		if j := strings.Index(tag, ","); j >= 0 && tag[j+1:] == "body" {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// keepsResponseBody reports whether the body of a successful response
+// is returned to the caller, who is then responsible for closing it,
+// when resp is passed as the result of a call made by a Client.
+func keepsResponseBody(resp interface{}) bool {
+	if _, ok := resp.(**http.Response); ok {
+		return true
+	}
+	_, ok := responseBodyField(resp)
+	return ok
+}
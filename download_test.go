@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// downloadServer returns a server that responds to /file with a
+// large plain text body and to other requests with a not found error.
+func downloadServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/file" {
+			httprequest.WriteJSON(w, http.StatusNotFound, &httprequest.RemoteError{
+				Code:    httprequest.CodeNotFound,
+				Message: "no such file",
+			})
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, strings.Repeat("x", 100000))
+	}))
+}
+
+func TestDownloadToWriter(t *testing.T) {
+	c := qt.New(t)
+
+	srv := downloadServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var buf bytes.Buffer
+	err := client.Get(context.Background(), "/file", &buf)
+	c.Assert(err, qt.IsNil)
+	c.Assert(buf.String(), qt.Equals, strings.Repeat("x", 100000))
+
+	buf.Reset()
+	err = client.Get(context.Background(), "/missing", &buf)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/missing: no such file`)
+	c.Assert(buf.Len(), qt.Equals, 0)
+}
+
+type downloadResponse struct {
+	Body io.ReadCloser `httprequest:",body"`
+}
+
+func TestDownloadToBodyField(t *testing.T) {
+	c := qt.New(t)
+
+	srv := downloadServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var resp downloadResponse
+	err := client.Get(context.Background(), "/file", &resp, httprequest.WithTimeout(time.Minute))
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	// The body can be read after the call has returned.
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, strings.Repeat("x", 100000))
+
+	var resp1 downloadResponse
+	err = client.Get(context.Background(), "/missing", &resp1)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/missing: no such file`)
+	c.Assert(resp1.Body, qt.IsNil)
+}
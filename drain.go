@@ -0,0 +1,78 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// maxDrainPollInterval holds the longest interval
+// between checks for active requests in Drain.
+const maxDrainPollInterval = 500 * time.Millisecond
+
+// Drain waits until there are no requests being served by handlers
+// created by Handle or Handlers, or until ctx is done, in which case it
+// returns the context's error. If srv.RejectWhileDraining is set, new
+// requests are rejected from the time Drain is called.
+//
+// Drain is intended to be called after http.Server.Shutdown to wait for
+// handlers that are not tracked by the HTTP server, such as those
+// serving hijacked or detached requests, or to stop accepting work
+// before shutting down the listener. As with http.Server, a Server
+// must not be copied after Drain or any of its handlers have been
+// called.
+func (srv *Server) Drain(ctx context.Context) error {
+	atomic.StoreInt32(&srv.draining, 1)
+	// Poll in the same way as http.Server.Shutdown,
+	// with an exponentially increasing interval.
+	interval := time.Millisecond
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		if srv.ActiveRequests() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errgo.Mask(ctx.Err(), errgo.Any)
+		case <-timer.C:
+		}
+		if interval *= 2; interval > maxDrainPollInterval {
+			interval = maxDrainPollInterval
+		}
+		timer.Reset(interval)
+	}
+}
+
+// ActiveRequests returns the number of requests currently being
+// served by handlers created by Handle or Handlers.
+func (srv *Server) ActiveRequests() int {
+	return int(atomic.LoadInt64(&srv.active))
+}
+
+// enter records the start of a request, reporting whether
+// the request should be served.
+func (srv *Server) enter() bool {
+	if srv.RejectWhileDraining && atomic.LoadInt32(&srv.draining) != 0 {
+		return false
+	}
+	atomic.AddInt64(&srv.active, 1)
+	return true
+}
+
+// hold records that a request started with enter continues to be
+// served after it has been recorded as ended. The caller must call
+// exit when it has finished serving it.
+func (srv *Server) hold() {
+	atomic.AddInt64(&srv.active, 1)
+}
+
+// exit records the end of a request started with enter.
+func (srv *Server) exit() {
+	atomic.AddInt64(&srv.active, -1)
+}
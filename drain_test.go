@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestDrain(t *testing.T) {
+	c := qt.New(t)
+
+	srv := &httprequest.Server{
+		RejectWhileDraining: true,
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := srv.Handle(func(*struct{}) (string, error) {
+		started <- struct{}{}
+		<-release
+		return "done", nil
+	})
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+		done <- rec
+	}()
+	<-started
+	c.Assert(srv.ActiveRequests(), qt.Equals, 1)
+
+	// Drain gives up when its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := srv.Drain(ctx)
+	c.Assert(errgo.Cause(err), qt.Equals, context.DeadlineExceeded)
+
+	// New requests are rejected while draining.
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusServiceUnavailable, &httprequest.RemoteError{
+		Code:    httprequest.CodeServiceUnavailable,
+		Message: "server is shutting down",
+	})
+
+	drained := make(chan error)
+	go func() {
+		drained <- srv.Drain(context.Background())
+	}()
+	select {
+	case err := <-drained:
+		c.Fatalf("Drain returned early with %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	qthttptest.AssertJSONResponse(c, <-done, http.StatusOK, "done")
+	c.Assert(<-drained, qt.IsNil)
+	c.Assert(srv.ActiveRequests(), qt.Equals, 0)
+}
+
+func TestDrainWithoutReject(t *testing.T) {
+	c := qt.New(t)
+
+	srv := &httprequest.Server{}
+	h := srv.Handle(func(*struct{}) (string, error) {
+		return "ok", nil
+	})
+	err := srv.Drain(context.Background())
+	c.Assert(err, qt.IsNil)
+
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "ok")
+}
+
+func TestDrainTimedOutRequest(t *testing.T) {
+	c := qt.New(t)
+
+	srv := &httprequest.Server{}
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	h := srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /slow" timeout:"10ms"`
+	}) {
+		defer close(returned)
+		<-release
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/slow", nil), nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusServiceUnavailable)
+
+	// The timed-out handler is still running, so
+	// the request has not finished.
+	c.Assert(srv.ActiveRequests(), qt.Equals, 1)
+	drained := make(chan error)
+	go func() {
+		drained <- srv.Drain(context.Background())
+	}()
+	select {
+	case err := <-drained:
+		c.Fatalf("Drain returned early with %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-returned
+	c.Assert(<-drained, qt.IsNil)
+	c.Assert(srv.ActiveRequests(), qt.Equals, 0)
+}
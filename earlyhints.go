@@ -0,0 +1,111 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// EarlyHints is a type that can be returned from a handler to declare
+// resources related to the response, such as stylesheets or other API
+// resources that the client is likely to need next. The links are sent
+// as described in WriteEarlyHints before Body is written as if it had
+// been returned by the handler itself.
+type EarlyHints struct {
+	// Links holds the related resources.
+	Links []Link
+
+	// Body holds the result of the handler.
+	Body interface{}
+}
+
+// Link describes a resource related to a response.
+type Link struct {
+	// URL holds the URL of the resource.
+	URL string
+
+	// Rel holds the relation type of the link.
+	// If it is empty, "preload" is used.
+	Rel string
+
+	// As holds the destination of a preloaded
+	// resource, such as "style" or "fetch".
+	As string
+
+	// Type holds the media type of the resource.
+	Type string
+}
+
+// String returns the link in the form used in a Link header, for
+// example "</style.css>; rel=preload; as=style".
+func (l Link) String() string {
+	var b strings.Builder
+	b.WriteString("<" + l.URL + ">; rel=" + l.rel())
+	if l.As != "" {
+		b.WriteString("; as=" + l.As)
+	}
+	if l.Type != "" {
+		b.WriteString(`; type="` + l.Type + `"`)
+	}
+	return b.String()
+}
+
+func (l Link) rel() string {
+	if l.Rel == "" {
+		return "preload"
+	}
+	return l.Rel
+}
+
+// WriteEarlyHints adds the given links to the Link header of the
+// response. If the connection supports HTTP/2 server push, preloaded
+// resources with absolute paths on the same host are pushed to the
+// client; otherwise the links are sent in a 103 (Early Hints)
+// informational response so that the client can start fetching them
+// before the final response is written.
+//
+// Handlers that take time to produce their result can call
+// WriteEarlyHints with Params.Response before doing so rather than
+// returning EarlyHints.
+func WriteEarlyHints(w http.ResponseWriter, links ...Link) {
+	if len(links) == 0 {
+		return
+	}
+	for _, l := range links {
+		w.Header().Add("Link", l.String())
+	}
+	if pusher := findPusher(w); pusher != nil {
+		pushed := false
+		for _, l := range links {
+			if l.rel() != "preload" || !strings.HasPrefix(l.URL, "/") || strings.HasPrefix(l.URL, "//") {
+				continue
+			}
+			if err := pusher.Push(l.URL, nil); err == nil {
+				pushed = true
+			}
+		}
+		if pushed {
+			return
+		}
+	}
+	w.WriteHeader(http.StatusEarlyHints)
+}
+
+// findPusher returns the http.Pusher implemented by w or any
+// ResponseWriter that it wraps, or nil if there is none.
+func findPusher(w http.ResponseWriter) http.Pusher {
+	for {
+		if pusher, ok := w.(http.Pusher); ok {
+			return pusher
+		}
+		u, ok := w.(interface {
+			Unwrap() http.ResponseWriter
+		})
+		if !ok {
+			return nil
+		}
+		w = u.Unwrap()
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var linkStringTests = []struct {
+	link   httprequest.Link
+	expect string
+}{{
+	link:   httprequest.Link{URL: "/style.css", As: "style"},
+	expect: "</style.css>; rel=preload; as=style",
+}, {
+	link:   httprequest.Link{URL: "/items/2", Rel: "next"},
+	expect: "</items/2>; rel=next",
+}, {
+	link:   httprequest.Link{URL: "/data.json", As: "fetch", Type: "application/json"},
+	expect: `</data.json>; rel=preload; as=fetch; type="application/json"`,
+}}
+
+func TestLinkString(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range linkStringTests {
+		c.Check(test.link.String(), qt.Equals, test.expect)
+	}
+}
+
+type earlyHintsRequest struct {
+	httprequest.Route `httprequest:"GET /page"`
+}
+
+var earlyHintsLinks = []httprequest.Link{{
+	URL: "/style.css",
+	As:  "style",
+}, {
+	URL: "/items/2",
+	Rel: "next",
+}}
+
+func earlyHintsHandler(srv *httprequest.Server) httprequest.Handler {
+	return srv.Handle(func(*earlyHintsRequest) (httprequest.EarlyHints, error) {
+		return httprequest.EarlyHints{
+			Links: earlyHintsLinks,
+			Body:  "content",
+		}, nil
+	})
+}
+
+func TestEarlyHints(t *testing.T) {
+	c := qt.New(t)
+
+	var observed []int
+	srv := httprequest.Server{
+		Observe: func(ctx context.Context, info httprequest.RequestInfo) {
+			observed = append(observed, info.Status)
+		},
+	}
+	server := httptest.NewServer(srv.NewRouter([]httprequest.Handler{earlyHintsHandler(&srv)}))
+	defer server.Close()
+
+	var hints []textproto.MIMEHeader
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			c.Check(code, qt.Equals, http.StatusEarlyHints)
+			hints = append(hints, header)
+			return nil
+		},
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", server.URL+"/page", nil)
+	c.Assert(err, qt.IsNil)
+	resp, err := http.DefaultClient.Do(req)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+
+	expectLinks := []string{
+		"</style.css>; rel=preload; as=style",
+		"</items/2>; rel=next",
+	}
+	c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+	c.Assert(string(body), qt.Equals, `"content"`)
+	c.Assert(resp.Header["Link"], qt.DeepEquals, expectLinks)
+	c.Assert(hints, qt.HasLen, 1)
+	c.Assert(hints[0]["Link"], qt.DeepEquals, expectLinks)
+	// The informational response is not observed as the status.
+	c.Assert(observed, qt.DeepEquals, []int{http.StatusOK})
+}
+
+// pushRecorder is a ResponseRecorder that supports server push.
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (r *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	r.pushed = append(r.pushed, target)
+	return nil
+}
+
+func TestEarlyHintsWithPush(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		// Check that the pusher is found through the
+		// wrapping ResponseWriters.
+		CompressionThreshold: 1000,
+		Observe:              func(context.Context, httprequest.RequestInfo) {},
+	}
+	rec := &pushRecorder{
+		ResponseRecorder: httptest.NewRecorder(),
+	}
+	srv.NewRouter([]httprequest.Handler{earlyHintsHandler(&srv)}).ServeHTTP(rec, httptest.NewRequest("GET", "/page", nil))
+	qthttptest.AssertJSONResponse(c, rec.ResponseRecorder, http.StatusOK, "content")
+	c.Assert(rec.pushed, qt.DeepEquals, []string{"/style.css"})
+	c.Assert(rec.Header()["Link"], qt.HasLen, 2)
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+)
+
+// ResponseEnvelope is the form of response body produced by
+// DefaultEnvelope. A custom Server.Envelope function can
+// use it to add metadata to every response.
+type ResponseEnvelope struct {
+	// Data holds the result of a successful handler.
+	Data interface{} `json:"data,omitempty"`
+
+	// Error holds the error body of an error response.
+	Error interface{} `json:"error,omitempty"`
+
+	// Meta holds any metadata about the response.
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// DefaultEnvelope is a function suitable for use as Server.Envelope.
+// It wraps the body in a ResponseEnvelope, as its Error field if
+// isError is true and its Data field otherwise.
+func DefaultEnvelope(ctx context.Context, body interface{}, isError bool) interface{} {
+	if isError {
+		return &ResponseEnvelope{Error: body}
+	}
+	return &ResponseEnvelope{Data: body}
+}
+
+// writeEnveloped is like writeBody except that the body is
+// first wrapped with srv.Envelope, if set.
+func (srv *Server) writeEnveloped(ctx context.Context, w http.ResponseWriter, status int, body interface{}, isError bool) error {
+	if srv.Envelope == nil {
+		return writeBody(ctx, w, status, body)
+	}
+	return writeEncodedWithHeaders(w, codecFromContext(ctx), status, srv.Envelope(ctx, body, isError), body)
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type envelopeHandlers struct{}
+
+func (envelopeHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /item"`
+}) (map[string]string, error) {
+	return map[string]string{"name": "widget"}, nil
+}
+
+func (envelopeHandlers) Create(*struct {
+	httprequest.Route `httprequest:"POST /item"`
+}) (httprequest.Created, error) {
+	return httprequest.Created{
+		Location: "/item",
+		Body:     map[string]string{"name": "widget"},
+	}, nil
+}
+
+func (envelopeHandlers) Fail(*struct {
+	httprequest.Route `httprequest:"GET /fail"`
+}) (string, error) {
+	return "", httprequest.Errorf(httprequest.CodeNotFound, "no such item")
+}
+
+func (envelopeHandlers) Delete(*struct {
+	httprequest.Route `httprequest:"DELETE /item"`
+}) (httprequest.NoContent, error) {
+	return httprequest.NoContent{}, nil
+}
+
+var envelopeTests = []struct {
+	about        string
+	envelope     func(ctx context.Context, body interface{}, isError bool) interface{}
+	method       string
+	path         string
+	expectStatus int
+	expectBody   interface{}
+	expectHeader http.Header
+}{{
+	about:        "result",
+	envelope:     httprequest.DefaultEnvelope,
+	method:       "GET",
+	path:         "/item",
+	expectStatus: http.StatusOK,
+	expectBody: map[string]interface{}{
+		"data": map[string]interface{}{"name": "widget"},
+	},
+}, {
+	about:        "result with headers and status",
+	envelope:     httprequest.DefaultEnvelope,
+	method:       "POST",
+	path:         "/item",
+	expectStatus: http.StatusCreated,
+	expectBody: map[string]interface{}{
+		"data": map[string]interface{}{"name": "widget"},
+	},
+	expectHeader: http.Header{
+		"Location": {"/item"},
+	},
+}, {
+	about:        "error",
+	envelope:     httprequest.DefaultEnvelope,
+	method:       "GET",
+	path:         "/fail",
+	expectStatus: http.StatusNotFound,
+	expectBody: map[string]interface{}{
+		"error": map[string]interface{}{
+			"Code":    "not found",
+			"Message": "no such item",
+		},
+	},
+}, {
+	about: "custom envelope with metadata",
+	envelope: func(ctx context.Context, body interface{}, isError bool) interface{} {
+		env := httprequest.DefaultEnvelope(ctx, body, isError).(*httprequest.ResponseEnvelope)
+		env.Meta = map[string]interface{}{
+			"version": "v1",
+		}
+		return env
+	},
+	method:       "GET",
+	path:         "/item",
+	expectStatus: http.StatusOK,
+	expectBody: map[string]interface{}{
+		"data": map[string]interface{}{"name": "widget"},
+		"meta": map[string]interface{}{"version": "v1"},
+	},
+}, {
+	about:        "no envelope",
+	method:       "GET",
+	path:         "/item",
+	expectStatus: http.StatusOK,
+	expectBody:   map[string]interface{}{"name": "widget"},
+}}
+
+func TestEnvelope(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range envelopeTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				Envelope: test.envelope,
+			}
+			router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (envelopeHandlers, context.Context, error) {
+				return envelopeHandlers{}, p.Context, nil
+			}))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+			for k, v := range test.expectHeader {
+				c.Check(rec.Header()[k], qt.DeepEquals, v, qt.Commentf("header %s", k))
+			}
+		})
+	}
+}
+
+func TestEnvelopeDoesNotWrapNoContent(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		Envelope: httprequest.DefaultEnvelope,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (envelopeHandlers, context.Context, error) {
+		return envelopeHandlers{}, p.Context, nil
+	}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("DELETE", "/item", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusNoContent)
+	c.Assert(rec.Body.String(), qt.Equals, "")
+}
+
+func TestEnvelopeDoesNotWrapProblems(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		Envelope:    httprequest.DefaultEnvelope,
+		ErrorFormat: httprequest.ErrorFormatProblem,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (envelopeHandlers, context.Context, error) {
+		return envelopeHandlers{}, p.Context, nil
+	}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/fail", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusNotFound)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, httprequest.ProblemContentType)
+}
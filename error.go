@@ -11,11 +11,42 @@ import (
 
 // These constants are recognized by DefaultErrorMapper
 // as mapping to the similarly named HTTP status codes.
+// Other codes can be added with RegisterErrorCode.
 const (
 	CodeBadRequest   = "bad request"
 	CodeUnauthorized = "unauthorized"
 	CodeForbidden    = "forbidden"
 	CodeNotFound     = "not found"
+
+	CodeMethodNotAllowed     = "method not allowed"
+	CodeRequestTooLarge      = "request too large"
+	CodeTimeout              = "timeout"
+	CodeTooManyRequests      = "too many requests"
+	CodeServiceUnavailable   = "service unavailable"
+	CodeBadGateway           = "bad gateway"
+	CodeUnsupportedMediaType = "unsupported media type"
+	CodeNotAcceptable        = "not acceptable"
+	CodeNotImplemented       = "not implemented"
+)
+
+// These errors can be used with errors.Is to check the codes of
+// errors (see RemoteError.Is). Each holds the similarly named code,
+// which is also used as its message, so they can also be returned
+// by handlers.
+var (
+	ErrBadRequest           = Errorf(CodeBadRequest, "")
+	ErrUnauthorized         = Errorf(CodeUnauthorized, "")
+	ErrForbidden            = Errorf(CodeForbidden, "")
+	ErrNotFound             = Errorf(CodeNotFound, "")
+	ErrMethodNotAllowed     = Errorf(CodeMethodNotAllowed, "")
+	ErrRequestTooLarge      = Errorf(CodeRequestTooLarge, "")
+	ErrTimeout              = Errorf(CodeTimeout, "")
+	ErrTooManyRequests      = Errorf(CodeTooManyRequests, "")
+	ErrServiceUnavailable   = Errorf(CodeServiceUnavailable, "")
+	ErrBadGateway           = Errorf(CodeBadGateway, "")
+	ErrUnsupportedMediaType = Errorf(CodeUnsupportedMediaType, "")
+	ErrNotAcceptable        = Errorf(CodeNotAcceptable, "")
+	ErrNotImplemented       = Errorf(CodeNotImplemented, "")
 )
 
 // DefaultErrorUnmarshaler is the default error unmarshaler
@@ -24,24 +55,18 @@ var DefaultErrorUnmarshaler = ErrorUnmarshaler(new(RemoteError))
 
 // DefaultErrorMapper is used by Server when ErrorMapper is nil. It maps
 // all errors to RemoteError instances; if an error implements the
-// ErrorCoder interface, the Code field will be set accordingly; some
-// codes will map to specific HTTP status codes (for example, if
+// ErrorCoder interface, the Code field will be set accordingly; codes
+// registered with RegisterErrorCode, including those defined by this
+// package, map to the registered HTTP status codes (for example, if
 // ErrorCode returns CodeBadRequest, the resulting HTTP status will be
-// http.StatusBadRequest).
+// http.StatusBadRequest). Other errors result in
+// http.StatusInternalServerError.
 var DefaultErrorMapper = defaultErrorMapper
 
 func defaultErrorMapper(ctx context.Context, err error) (status int, body interface{}) {
 	errorBody := errorResponseBody(err)
-	switch errorBody.Code {
-	case CodeBadRequest:
-		status = http.StatusBadRequest
-	case CodeUnauthorized:
-		status = http.StatusUnauthorized
-	case CodeForbidden:
-		status = http.StatusForbidden
-	case CodeNotFound:
-		status = http.StatusNotFound
-	default:
+	status, ok := ErrorCodeStatus(errorBody.Code)
+	if !ok {
 		status = http.StatusInternalServerError
 	}
 	return status, errorBody
@@ -57,6 +82,9 @@ func errorResponseBody(err error) *RemoteError {
 		// error message but copy everything else.
 		errResp = *cause
 		errResp.Message = err.Error()
+		if info := attachedDetails(err); info != nil {
+			errResp.Info = info
+		}
 		return &errResp
 	}
 
@@ -65,6 +93,10 @@ func errorResponseBody(err error) *RemoteError {
 	if coder, ok := cause.(ErrorCoder); ok {
 		errResp.Code = coder.ErrorCode()
 	}
+	if ferr := fieldsError(err); ferr != nil {
+		errResp.Fields = ferr.Fields
+	}
+	errResp.Info = attachedDetails(err)
 	return &errResp
 }
 
@@ -86,8 +118,20 @@ type RemoteError struct {
 	// Code may hold a code that classifies the error.
 	Code string `json:",omitempty"`
 
-	// Info holds any other information associated with the error.
+	// Info holds any other information associated with the error
+	// (see WithDetails and ErrorDetails).
 	Info *json.RawMessage `json:",omitempty"`
+
+	// Fields may hold the problems found with individual
+	// request fields (see FieldsError).
+	Fields []FieldError `json:",omitempty"`
+
+	// Wrapped may hold the error that caused this one, such as an
+	// error returned by another service, with its own code, as
+	// written by a Server with SerializeErrorCauses set. It is
+	// returned by Unwrap, so that the codes of the errors in the
+	// chain can be checked with errors.Is.
+	Wrapped *RemoteError `json:",omitempty"`
 }
 
 // Error implements the error interface.
@@ -103,6 +147,29 @@ func (e *RemoteError) ErrorCode() string {
 	return e.Code
 }
 
+// Unwrap returns e.Wrapped, if any.
+func (e *RemoteError) Unwrap() error {
+	if e.Wrapped == nil {
+		return nil
+	}
+	return e.Wrapped
+}
+
+// Is reports whether target is a *RemoteError with the same non-empty
+// code as e, so that errors can be checked against the sentinel
+// errors defined for each code, such as ErrNotFound, with errors.Is:
+//
+//	if errors.Is(err, httprequest.ErrNotFound) {
+//
+// Codes without a sentinel error can be checked with a RemoteError
+// holding the code, for example &RemoteError{Code: "quota exceeded"}.
+// Errors returned by Client support errors.Is; errors wrapped with
+// errgo must first be passed to errgo.Cause.
+func (e *RemoteError) Is(target error) bool {
+	t, ok := target.(*RemoteError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
 // Errorf returns a new RemoteError instance that uses the
 // given code and formats the message with fmt.Sprintf(f, a...).
 // If f is empty and there are no other arguments, code will also
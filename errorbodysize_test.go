@@ -0,0 +1,84 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestClientMaxErrorBodySize(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		if req.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte("0123456789abcdef"))
+	}))
+	defer srv.Close()
+
+	client := httprequest.Client{
+		BaseURL:          srv.URL,
+		MaxErrorBodySize: 10,
+	}
+	for _, path := range []string{"/ok", "/error"} {
+		c.Run(path, func(c *qt.C) {
+			var resp map[string]string
+			err := client.Get(context.Background(), path, &resp)
+			c.Assert(err, qt.ErrorMatches, `Get http://.*`+path+`: .*unexpected content type text/plain; want application/json; content: 0123456789`)
+			derr, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+			c.Assert(ok, qt.IsTrue, qt.Commentf("%T", errgo.Cause(err)))
+			data, err := ioutil.ReadAll(derr.Response.Body)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(data), qt.Equals, "0123456789")
+		})
+	}
+
+	// Other clients use the default limit.
+	var resp map[string]string
+	err := (&httprequest.Client{BaseURL: srv.URL}).Get(context.Background(), "/ok", &resp)
+	derr, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+	c.Assert(ok, qt.IsTrue, qt.Commentf("%T", errgo.Cause(err)))
+	data, err := ioutil.ReadAll(derr.Response.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "0123456789abcdef")
+}
+
+func TestServerMaxErrorBodySize(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		MaxErrorBodySize: 4,
+	}
+	router := httprouter.New()
+	h := srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"PUT /items"`
+		Body              map[string]string `httprequest:",body"`
+	}) error {
+		return nil
+	})
+	router.Handle(h.Method, h.Path, h.Handle)
+
+	req := httptest.NewRequest("PUT", "/items", strings.NewReader("abcdefgh"))
+	req.Header.Set("Content-Type", "text/plain")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var rerr httprequest.RemoteError
+	err := json.Unmarshal(rec.Body.Bytes(), &rerr)
+	c.Assert(err, qt.IsNil)
+	c.Assert(rerr.Message, qt.Matches, `.*unexpected content type text/plain; want application/json; content: abcd`)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"reflect"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// maxErrorCauses holds the maximum number of wrapped
+// errors serialized by withErrorCauses.
+const maxErrorCauses = 10
+
+// withErrorCauses returns the error response body resp, which was
+// produced for err, with the errors wrapped by err that have codes
+// added as its Wrapped chain, if it is a *RemoteError that does not
+// already have one.
+func withErrorCauses(resp interface{}, err error) interface{} {
+	rerr, ok := resp.(*RemoteError)
+	if !ok || rerr.Wrapped != nil {
+		return resp
+	}
+	wrapped := errorCauses(err)
+	if wrapped == nil {
+		return resp
+	}
+	rerr1 := *rerr
+	rerr1.Wrapped = wrapped
+	return &rerr1
+}
+
+// errorCauses returns the chain of the causes of the errors wrapped
+// by err, other than its own cause, that have codes, as found by
+// following errgo.Wrapper.Underlying or Unwrap. A *RemoteError in the
+// chain, such as one returned by a Client, is included along with any
+// errors it wraps itself, ending the chain.
+func errorCauses(err error) *RemoteError {
+	var head, tail *RemoteError
+	add := func(e *RemoteError) {
+		if head == nil {
+			head = e
+		} else {
+			tail.Wrapped = e
+		}
+		tail = e
+	}
+	last := errgo.Cause(err)
+	for e, n := unwrapError(err), 0; e != nil && n < maxErrorCauses; e = unwrapError(e) {
+		cause := e
+		if causer, ok := e.(errgo.Causer); ok {
+			cause = causer.Cause()
+		}
+		if cause == nil || sameError(cause, last) {
+			continue
+		}
+		last = cause
+		switch cause1 := cause.(type) {
+		case *RemoteError:
+			rerr := *cause1
+			add(&rerr)
+			return head
+		case ErrorCoder:
+			if code := cause1.ErrorCode(); code != "" {
+				add(&RemoteError{
+					Code:    code,
+					Message: e.Error(),
+				})
+				n++
+			}
+		}
+	}
+	return head
+}
+
+// sameError reports whether err1 and err2 are the same error.
+func sameError(err1, err2 error) bool {
+	return reflect.TypeOf(err1) == reflect.TypeOf(err2) && reflect.TypeOf(err1).Comparable() && err1 == err2
+}
+
+// unwrapError returns the error wrapped by err, if any.
+func unwrapError(err error) error {
+	switch err := err.(type) {
+	case errgo.Wrapper:
+		return err.Underlying()
+	case interface{ Unwrap() error }:
+		return err.Unwrap()
+	}
+	return nil
+}
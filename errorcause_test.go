@@ -0,0 +1,121 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type errorCauseUserRequest struct {
+	httprequest.Route `httprequest:"GET /users/:name"`
+	Name              string `httprequest:"name,path"`
+}
+
+// newErrorCauseServer returns a server that serves the user with the
+// given name by calling get.
+func newErrorCauseServer(srv *httprequest.Server, get func(ctx context.Context, name string) (string, error)) *httptest.Server {
+	router := httprouter.New()
+	h := srv.Handle(func(p httprequest.Params, arg *errorCauseUserRequest) (string, error) {
+		return get(p.Context, arg.Name)
+	})
+	router.Handle(h.Method, h.Path, h.Handle)
+	return httptest.NewServer(router)
+}
+
+func TestSerializeErrorCausesMultiHop(t *testing.T) {
+	c := qt.New(t)
+	// C fails with a not-found error.
+	srvC := newErrorCauseServer(&httprequest.Server{}, func(ctx context.Context, name string) (string, error) {
+		return "", errgo.WithCausef(nil, httprequest.ErrNotFound, "user %q not found", name)
+	})
+	defer srvC.Close()
+
+	// B calls C and reports a bad gateway error.
+	srvB := newErrorCauseServer(&httprequest.Server{
+		SerializeErrorCauses: true,
+	}, func(ctx context.Context, name string) (string, error) {
+		client := &httprequest.Client{BaseURL: srvC.URL}
+		var resp string
+		if err := client.Get(ctx, "/users/"+name, &resp); err != nil {
+			return "", errgo.WithCausef(err, httprequest.ErrBadGateway, "cannot get user from C")
+		}
+		return resp, nil
+	})
+	defer srvB.Close()
+
+	// A calls B and passes its error on unchanged.
+	srvA := newErrorCauseServer(&httprequest.Server{
+		SerializeErrorCauses: true,
+	}, func(ctx context.Context, name string) (string, error) {
+		client := &httprequest.Client{BaseURL: srvB.URL}
+		var resp string
+		if err := client.Get(ctx, "/users/"+name, &resp); err != nil {
+			return "", errgo.NoteMask(err, "cannot get user from B", errgo.Any)
+		}
+		return resp, nil
+	})
+	defer srvA.Close()
+
+	client := &httprequest.Client{BaseURL: srvA.URL}
+	err := client.Get(context.Background(), "/users/bob", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/users/bob: cannot get user from B: Get http://.*/users/bob: cannot get user from C: .*`)
+	c.Assert(errors.Is(err, httprequest.ErrBadGateway), qt.IsTrue)
+	c.Assert(errors.Is(err, httprequest.ErrNotFound), qt.IsTrue)
+
+	var rerr *httprequest.RemoteError
+	c.Assert(errors.As(err, &rerr), qt.IsTrue)
+	c.Assert(rerr.Code, qt.Equals, httprequest.CodeBadGateway)
+	c.Assert(rerr.Wrapped, qt.DeepEquals, &httprequest.RemoteError{
+		Code:    httprequest.CodeNotFound,
+		Message: `user "bob" not found`,
+	})
+}
+
+func TestSerializeErrorCausesErrorCoder(t *testing.T) {
+	c := qt.New(t)
+	srv := newErrorCauseServer(&httprequest.Server{
+		SerializeErrorCauses: true,
+	}, func(ctx context.Context, name string) (string, error) {
+		err := errgo.WithCausef(nil, httprequest.ErrForbidden, "access denied")
+		err = errgo.NoteMask(err, "cannot check access", errgo.Any)
+		return "", errgo.WithCausef(err, httprequest.ErrServiceUnavailable, "cannot get user")
+	})
+	defer srv.Close()
+
+	client := &httprequest.Client{BaseURL: srv.URL}
+	err := client.Get(context.Background(), "/users/bob", nil)
+	c.Assert(errors.Is(err, httprequest.ErrServiceUnavailable), qt.IsTrue)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsTrue)
+}
+
+func TestSerializeErrorCausesDisabled(t *testing.T) {
+	c := qt.New(t)
+	srvC := newErrorCauseServer(&httprequest.Server{}, func(ctx context.Context, name string) (string, error) {
+		return "", httprequest.ErrNotFound
+	})
+	defer srvC.Close()
+	srvB := newErrorCauseServer(&httprequest.Server{}, func(ctx context.Context, name string) (string, error) {
+		client := &httprequest.Client{BaseURL: srvC.URL}
+		err := client.Get(ctx, "/users/"+name, nil)
+		return "", errgo.WithCausef(err, httprequest.ErrBadGateway, "cannot get user")
+	})
+	defer srvB.Close()
+
+	client := &httprequest.Client{BaseURL: srvB.URL}
+	err := client.Get(context.Background(), "/users/bob", nil)
+	c.Assert(errors.Is(err, httprequest.ErrBadGateway), qt.IsTrue)
+	c.Assert(errors.Is(err, httprequest.ErrNotFound), qt.IsFalse)
+	var rerr *httprequest.RemoteError
+	c.Assert(errors.As(err, &rerr), qt.IsTrue)
+	c.Assert(rerr.Wrapped == nil, qt.IsTrue)
+}
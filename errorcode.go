@@ -0,0 +1,90 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"sync"
+)
+
+// errorCodes holds the registry of error codes
+// used by RegisterErrorCode.
+var errorCodes = struct {
+	mu       sync.RWMutex
+	statuses map[string]int
+	codes    map[int]string
+}{
+	statuses: make(map[string]int),
+	codes:    make(map[int]string),
+}
+
+func init() {
+	for _, c := range []struct {
+		code   string
+		status int
+	}{
+		{CodeBadRequest, http.StatusBadRequest},
+		{CodeUnauthorized, http.StatusUnauthorized},
+		{CodeForbidden, http.StatusForbidden},
+		{CodeNotFound, http.StatusNotFound},
+		{CodeMethodNotAllowed, http.StatusMethodNotAllowed},
+		{CodeRequestTooLarge, http.StatusRequestEntityTooLarge},
+		{CodeServiceUnavailable, http.StatusServiceUnavailable},
+		{CodeTimeout, http.StatusServiceUnavailable},
+		{CodeTooManyRequests, http.StatusTooManyRequests},
+		{CodeBadGateway, http.StatusBadGateway},
+		{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{CodeNotAcceptable, http.StatusNotAcceptable},
+		{CodeNotImplemented, http.StatusNotImplemented},
+	} {
+		RegisterErrorCode(c.code, c.status)
+	}
+}
+
+// RegisterErrorCode registers the HTTP status used for errors with the
+// given code (see ErrorCoder and RemoteError.Code), replacing any
+// status registered for the code before. DefaultErrorMapper writes
+// errors with the code with the status, so that applications can
+// define their own codes, such as "quota exceeded" mapping to
+// http.StatusTooManyRequests, without a custom Server.ErrorMapper.
+//
+// The code is also used for error responses with the status received
+// by a Client that hold a RemoteError without a code, unless another
+// code has already been registered for the status. The codes defined
+// by this package, such as CodeNotFound, are registered for their
+// statuses by default.
+//
+// RegisterErrorCode is typically called during initialization, but
+// it is safe to call concurrently with other functions. It panics if
+// code is empty.
+func RegisterErrorCode(code string, status int) {
+	if code == "" {
+		panic("httprequest: RegisterErrorCode called with empty code")
+	}
+	errorCodes.mu.Lock()
+	defer errorCodes.mu.Unlock()
+	errorCodes.statuses[code] = status
+	if _, ok := errorCodes.codes[status]; !ok {
+		errorCodes.codes[status] = code
+	}
+}
+
+// ErrorCodeStatus returns the HTTP status registered for the given
+// error code with RegisterErrorCode, and reports whether there is one.
+func ErrorCodeStatus(code string) (int, bool) {
+	errorCodes.mu.RLock()
+	defer errorCodes.mu.RUnlock()
+	status, ok := errorCodes.statuses[code]
+	return status, ok
+}
+
+// StatusErrorCode returns the error code used for error responses
+// with the given HTTP status that do not specify one (see
+// RegisterErrorCode), and reports whether there is one.
+func StatusErrorCode(status int) (string, bool) {
+	errorCodes.mu.RLock()
+	defer errorCodes.mu.RUnlock()
+	code, ok := errorCodes.codes[status]
+	return code, ok
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestRegisterErrorCode(t *testing.T) {
+	c := qt.New(t)
+	httprequest.RegisterErrorCode("test quota exceeded", http.StatusTooManyRequests)
+	httprequest.RegisterErrorCode("test teapot", http.StatusTeapot)
+
+	var srv httprequest.Server
+	router := httprouter.New()
+	h := srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /error/:code"`
+		Code              string `httprequest:"code,path"`
+	}) error {
+		return httprequest.Errorf(arg.Code, "failed")
+	})
+	router.Handle(h.Method, h.Path, h.Handle)
+	hsrv := httptest.NewServer(router)
+	defer hsrv.Close()
+
+	for _, test := range []struct {
+		code         string
+		expectStatus int
+	}{{
+		code:         "test quota exceeded",
+		expectStatus: http.StatusTooManyRequests,
+	}, {
+		code:         "test teapot",
+		expectStatus: http.StatusTeapot,
+	}, {
+		code:         httprequest.CodeNotFound,
+		expectStatus: http.StatusNotFound,
+	}, {
+		code:         "test unregistered",
+		expectStatus: http.StatusInternalServerError,
+	}} {
+		c.Run(test.code, func(c *qt.C) {
+			resp, err := http.Get(hsrv.URL + "/error/" + test.code)
+			c.Assert(err, qt.IsNil)
+			resp.Body.Close()
+			c.Assert(resp.StatusCode, qt.Equals, test.expectStatus)
+		})
+	}
+
+	status, ok := httprequest.ErrorCodeStatus("test quota exceeded")
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(status, qt.Equals, http.StatusTooManyRequests)
+	_, ok = httprequest.ErrorCodeStatus("test unregistered")
+	c.Assert(ok, qt.IsFalse)
+
+	// The status already had a code, so it is not replaced.
+	code, ok := httprequest.StatusErrorCode(http.StatusTooManyRequests)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(code, qt.Equals, httprequest.CodeTooManyRequests)
+	code, ok = httprequest.StatusErrorCode(http.StatusTeapot)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(code, qt.Equals, "test teapot")
+	code, ok = httprequest.StatusErrorCode(http.StatusServiceUnavailable)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(code, qt.Equals, httprequest.CodeServiceUnavailable)
+}
+
+func TestClientErrorCodeFromStatus(t *testing.T) {
+	c := qt.New(t)
+	httprequest.RegisterErrorCode("test gone", http.StatusGone)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		status := http.StatusGone
+		body := &httprequest.RemoteError{Message: "gone"}
+		switch req.URL.Path {
+		case "/coded":
+			body.Code = "other"
+		case "/unregistered":
+			status = http.StatusConflict
+		}
+		httprequest.WriteJSON(w, status, body)
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	for _, test := range []struct {
+		path       string
+		expectCode string
+	}{{
+		path:       "/uncoded",
+		expectCode: "test gone",
+	}, {
+		path:       "/coded",
+		expectCode: "other",
+	}, {
+		path:       "/unregistered",
+		expectCode: "",
+	}} {
+		c.Run(test.path, func(c *qt.C) {
+			err := client.Get(context.Background(), test.path, nil)
+			c.Assert(err, qt.ErrorMatches, `Get http://.*: gone`)
+			c.Assert(errgo.Cause(err).(*httprequest.RemoteError).Code, qt.Equals, test.expectCode)
+		})
+	}
+}
+
+func TestRegisterErrorCodeEmpty(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(func() {
+		httprequest.RegisterErrorCode("", http.StatusTeapot)
+	}, qt.PanicMatches, `httprequest: RegisterErrorCode called with empty code`)
+}
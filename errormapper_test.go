@@ -0,0 +1,94 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type versionedError struct {
+	Version string
+	Path    string
+	Message string
+}
+
+func versionedErrorMapper(ctx context.Context, req *http.Request, err error) (int, interface{}) {
+	if req == nil {
+		return http.StatusInternalServerError, &versionedError{
+			Message: err.Error(),
+		}
+	}
+	return http.StatusTeapot, &versionedError{
+		Version: req.Header.Get("API-Version"),
+		Path:    req.URL.Path,
+		Message: err.Error(),
+	}
+}
+
+var errorMapperWithRequestTests = []struct {
+	about      string
+	path       string
+	expectBody versionedError
+}{{
+	about: "handler error",
+	path:  "/items/1",
+	expectBody: versionedError{
+		Version: "2",
+		Path:    "/items/1",
+		Message: "no deletions",
+	},
+}, {
+	about: "router error",
+	path:  "/other",
+	expectBody: versionedError{
+		Version: "2",
+		Path:    "/other",
+		Message: "no handler for DELETE /other",
+	},
+}}
+
+func TestErrorMapperWithRequest(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		ErrorMapper: func(context.Context, error) (int, interface{}) {
+			panic("ErrorMapper called")
+		},
+		ErrorMapperWithRequest: versionedErrorMapper,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	for _, test := range errorMapperWithRequestTests {
+		c.Run(test.about, func(c *qt.C) {
+			req := httptest.NewRequest("DELETE", test.path, nil)
+			req.Header.Set("API-Version", "2")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			qthttptest.AssertJSONResponse(c, rec, http.StatusTeapot, test.expectBody)
+		})
+	}
+}
+
+func TestErrorMapperWithRequestWithoutRequest(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		ErrorMapperWithRequest: versionedErrorMapper,
+	}
+	rec := httptest.NewRecorder()
+	srv.WriteError(context.Background(), rec, errgo.New("oops"))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusInternalServerError, versionedError{
+		Message: "oops",
+	})
+}
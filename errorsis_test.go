@@ -0,0 +1,87 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestRemoteErrorIs(t *testing.T) {
+	c := qt.New(t)
+	err := httprequest.Errorf(httprequest.CodeNotFound, "user %q not found", "bob")
+	c.Assert(errors.Is(err, httprequest.ErrNotFound), qt.IsTrue)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsFalse)
+	c.Assert(errors.Is(err, &httprequest.RemoteError{Code: httprequest.CodeNotFound}), qt.IsTrue)
+	c.Assert(errors.Is(err, errgo.New("not found")), qt.IsFalse)
+
+	// Errors without codes don't match each other.
+	c.Assert(errors.Is(&httprequest.RemoteError{Message: "a"}, &httprequest.RemoteError{Message: "b"}), qt.IsFalse)
+
+	// Errors wrapped by errgo are found via their cause.
+	wrapped := errgo.NoteMask(err, "cannot get user", errgo.Any)
+	c.Assert(errors.Is(errgo.Cause(wrapped), httprequest.ErrNotFound), qt.IsTrue)
+}
+
+func TestClientErrorsIs(t *testing.T) {
+	c := qt.New(t)
+	var srv httprequest.Server
+	router := httprouter.New()
+	for _, h := range []httprequest.Handler{srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /users/:name"`
+		Name              string `httprequest:"name,path"`
+	}) (string, error) {
+		switch arg.Name {
+		case "quota":
+			return "", httprequest.Errorf("test quota", "quota exceeded")
+		case "sentinel":
+			return "", httprequest.ErrForbidden
+		}
+		return "", errgo.WithCausef(nil, httprequest.ErrNotFound, "user %q not found", arg.Name)
+	})} {
+		router.Handle(h.Method, h.Path, h.Handle)
+	}
+	hsrv := httptest.NewServer(router)
+	defer hsrv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: hsrv.URL,
+	}
+	err := client.Get(context.Background(), "/users/bob", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/users/bob: user "bob" not found`)
+	c.Assert(errors.Is(err, httprequest.ErrNotFound), qt.IsTrue)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsFalse)
+	var rerr *httprequest.RemoteError
+	c.Assert(errors.As(err, &rerr), qt.IsTrue)
+	c.Assert(rerr.Code, qt.Equals, httprequest.CodeNotFound)
+
+	err = client.Get(context.Background(), "/users/sentinel", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/users/sentinel: forbidden`)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsTrue)
+
+	err = client.Get(context.Background(), "/users/quota", nil)
+	c.Assert(errors.Is(err, &httprequest.RemoteError{Code: "test quota"}), qt.IsTrue)
+}
+
+func TestClientErrorsAsTransportError(t *testing.T) {
+	c := qt.New(t)
+	transportErr := errgo.New("connection refused")
+	client := &httprequest.Client{
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, transportErr
+		}),
+	}
+	err := client.Get(context.Background(), "http://0.1.2.3/x", nil)
+	c.Assert(errors.Is(err, transportErr), qt.IsTrue)
+	c.Assert(errgo.Cause(err), qt.Equals, transportErr)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// ErrorTypes maps error codes to the Go error types returned by a
+// Client for error responses with the codes (see Client.ErrorTypes),
+// so that callers can inspect typed errors, such as a
+// *QuotaExceededError with the details of the quota, rather than a
+// *RemoteError. For example:
+//
+//	types := new(httprequest.ErrorTypes)
+//	types.Register("quota exceeded", func(rerr *httprequest.RemoteError) (error, error) {
+//		qerr := &QuotaExceededError{Message: rerr.Message}
+//		if err := rerr.UnmarshalInfo(qerr); err != nil {
+//			return nil, err
+//		}
+//		return qerr, nil
+//	})
+//
+// The zero value is an empty registry ready to use. An ErrorTypes is
+// safe to use concurrently.
+type ErrorTypes struct {
+	mu        sync.RWMutex
+	factories map[string]func(*RemoteError) (error, error)
+}
+
+// Register registers newError as the function that creates the errors
+// for RemoteErrors with the given code, replacing any registered
+// before. The function is passed the RemoteError unmarshaled from the
+// response, and typically decodes its Info with UnmarshalInfo. If it
+// returns an error, the call fails with that error; if it returns
+// nil, nil, the RemoteError is returned unchanged. Register panics if
+// code is empty.
+func (t *ErrorTypes) Register(code string, newError func(rerr *RemoteError) (error, error)) {
+	if code == "" {
+		panic("httprequest: ErrorTypes.Register called with empty code")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.factories == nil {
+		t.factories = make(map[string]func(*RemoteError) (error, error))
+	}
+	t.factories[code] = newError
+}
+
+// typedError returns the error registered for the code of err, if it
+// is a *RemoteError with a registered code, or err otherwise.
+func (t *ErrorTypes) typedError(err error) error {
+	rerr, ok := err.(*RemoteError)
+	if !ok || rerr.Code == "" {
+		return err
+	}
+	t.mu.RLock()
+	newError := t.factories[rerr.Code]
+	t.mu.RUnlock()
+	if newError == nil {
+		return err
+	}
+	terr, err := newError(rerr)
+	if err != nil {
+		return errgo.Notef(err, "cannot create error for code %q", rerr.Code)
+	}
+	if terr == nil {
+		return rerr
+	}
+	return terr
+}
+
+// UnmarshalInfo unmarshals the JSON held in e.Info into v.
+// It does nothing if e.Info is nil.
+func (e *RemoteError) UnmarshalInfo(v interface{}) error {
+	if e.Info == nil {
+		return nil
+	}
+	if err := json.Unmarshal(*e.Info, v); err != nil {
+		return errgo.Notef(err, "cannot unmarshal info of %s", errorCodeDesc(e.Code))
+	}
+	return nil
+}
+
+// errorCodeDesc describes an error with the given code.
+func errorCodeDesc(code string) string {
+	if code == "" {
+		return "error"
+	}
+	return fmt.Sprintf("%q error", code)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type quotaExceededError struct {
+	Message string `json:"-"`
+	Limit   int
+	Used    int
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("%s (%d/%d)", e.Message, e.Used, e.Limit)
+}
+
+// newErrorTypes returns an ErrorTypes that creates a
+// *quotaExceededError for the "quota exceeded" code.
+func newErrorTypes() *httprequest.ErrorTypes {
+	types := new(httprequest.ErrorTypes)
+	types.Register("quota exceeded", func(rerr *httprequest.RemoteError) (error, error) {
+		qerr := &quotaExceededError{Message: rerr.Message}
+		if err := rerr.UnmarshalInfo(qerr); err != nil {
+			return nil, err
+		}
+		return qerr, nil
+	})
+	return types
+}
+
+// errorInfoServer returns a server that responds with a RemoteError
+// with the code and info given by the request's query parameters.
+func errorInfoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		info := json.RawMessage(req.FormValue("info"))
+		rerr := &httprequest.RemoteError{
+			Code:    req.FormValue("code"),
+			Message: "request failed",
+		}
+		if len(info) > 0 {
+			rerr.Info = &info
+		}
+		httprequest.WriteJSON(w, http.StatusTooManyRequests, rerr)
+	}))
+}
+
+func TestClientErrorTypes(t *testing.T) {
+	c := qt.New(t)
+	srv := errorInfoServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:    srv.URL,
+		ErrorTypes: newErrorTypes(),
+	}
+	err := client.Get(context.Background(), `/?code=quota+exceeded&info={"Limit":10,"Used":12}`, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: request failed \(12/10\)`)
+	qerr, ok := errgo.Cause(err).(*quotaExceededError)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(qerr, qt.DeepEquals, &quotaExceededError{
+		Message: "request failed",
+		Limit:   10,
+		Used:    12,
+	})
+	var qerr1 *quotaExceededError
+	c.Assert(errors.As(err, &qerr1), qt.IsTrue)
+	c.Assert(qerr1, qt.Equals, qerr)
+
+	// Errors with other codes are returned as RemoteErrors.
+	err = client.Get(context.Background(), "/?code=other", nil)
+	c.Assert(errgo.Cause(err), qt.DeepEquals, &httprequest.RemoteError{
+		Code:    "other",
+		Message: "request failed",
+	})
+
+	// Errors without info create typed errors with zero fields.
+	err = client.Get(context.Background(), "/?code=quota+exceeded", nil)
+	c.Assert(errgo.Cause(err), qt.DeepEquals, &quotaExceededError{
+		Message: "request failed",
+	})
+}
+
+func TestClientErrorTypesBadInfo(t *testing.T) {
+	c := qt.New(t)
+	srv := errorInfoServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:    srv.URL,
+		ErrorTypes: newErrorTypes(),
+	}
+	err := client.Get(context.Background(), `/?code=quota+exceeded&info={"Limit":"x"}`, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: cannot create error for code "quota exceeded": cannot unmarshal info of "quota exceeded" error: .*`)
+}
+
+func TestErrorTypesRegisterNil(t *testing.T) {
+	c := qt.New(t)
+	srv := errorInfoServer()
+	defer srv.Close()
+
+	types := new(httprequest.ErrorTypes)
+	types.Register("ignored", func(rerr *httprequest.RemoteError) (error, error) {
+		return nil, nil
+	})
+	client := &httprequest.Client{
+		BaseURL:    srv.URL,
+		ErrorTypes: types,
+	}
+	err := client.Get(context.Background(), "/?code=ignored", nil)
+	c.Assert(errors.Is(err, &httprequest.RemoteError{Code: "ignored"}), qt.IsTrue)
+
+	c.Assert(func() {
+		types.Register("", nil)
+	}, qt.PanicMatches, `httprequest: ErrorTypes.Register called with empty code`)
+}
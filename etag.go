@@ -0,0 +1,66 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ETagger may be implemented by a value returned from a handler to
+// provide an entity tag for the response. The server sets the ETag
+// header of a successful http.StatusOK response to the returned tag
+// and, for GET and HEAD requests, responds with
+// http.StatusNotModified and no body if the tag matches the request's
+// If-None-Match header. The result is not encoded in that case.
+//
+// The tag may be given with or without surrounding quotes; a weak
+// tag must be given in full, for example W/"v1". If ETag returns the
+// empty string, no ETag header is set.
+type ETagger interface {
+	ETag() string
+}
+
+// writeNotModified sets the ETag header from the entity tag provided
+// by val if it implements ETagger. It reports whether the request's
+// If-None-Match header matches the tag, in which case it has written a
+// http.StatusNotModified response.
+func writeNotModified(w http.ResponseWriter, req *http.Request, val interface{}) bool {
+	etagger, ok := val.(ETagger)
+	if !ok || isNil(val) {
+		return false
+	}
+	etag := etagger.ETag()
+	if etag == "" {
+		return false
+	}
+	if !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, `W/"`) {
+		etag = `"` + etag + `"`
+	}
+	w.Header().Set("ETag", etag)
+	if req.Method != "GET" && req.Method != "HEAD" {
+		return false
+	}
+	if !etagMatches(req.Header["If-None-Match"], etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether any of the entity tags in the given
+// If-None-Match header values matches etag using the weak comparison
+// function defined by RFC 9110.
+func etagMatches(header []string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, h := range header {
+		for _, tag := range strings.Split(h, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
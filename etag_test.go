@@ -0,0 +1,100 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type etagResult struct {
+	Tag string `json:"-"`
+	N   int
+}
+
+func (r etagResult) ETag() string {
+	return r.Tag
+}
+
+var etagTests = []struct {
+	about        string
+	method       string
+	tag          string
+	ifNoneMatch  string
+	expectETag   string
+	expectStatus int
+	expectBody   string
+}{{
+	about:        "no If-None-Match",
+	tag:          "v1",
+	expectETag:   `"v1"`,
+	expectStatus: http.StatusOK,
+	expectBody:   `{"N":1}`,
+}, {
+	about:        "matching tag",
+	tag:          "v1",
+	ifNoneMatch:  `"v0", "v1"`,
+	expectETag:   `"v1"`,
+	expectStatus: http.StatusNotModified,
+}, {
+	about:        "non-matching tag",
+	tag:          `"v2"`,
+	ifNoneMatch:  `"v1"`,
+	expectETag:   `"v2"`,
+	expectStatus: http.StatusOK,
+	expectBody:   `{"N":1}`,
+}, {
+	about:        "weak comparison",
+	tag:          `W/"v1"`,
+	ifNoneMatch:  `"v1"`,
+	expectETag:   `W/"v1"`,
+	expectStatus: http.StatusNotModified,
+}, {
+	about:        "wildcard",
+	tag:          "v1",
+	ifNoneMatch:  "*",
+	expectETag:   `"v1"`,
+	expectStatus: http.StatusNotModified,
+}, {
+	about:        "empty tag",
+	ifNoneMatch:  "*",
+	expectStatus: http.StatusOK,
+	expectBody:   `{"N":1}`,
+}, {
+	about:        "non-GET request",
+	method:       "POST",
+	tag:          "v1",
+	ifNoneMatch:  `"v1"`,
+	expectETag:   `"v1"`,
+	expectStatus: http.StatusOK,
+	expectBody:   `{"N":1}`,
+}}
+
+func TestETag(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range etagTests {
+		c.Run(test.about, func(c *qt.C) {
+			h := testServer.Handle(func(arg *struct{}) (etagResult, error) {
+				return etagResult{Tag: test.tag, N: 1}, nil
+			})
+			method := test.method
+			if method == "" {
+				method = "GET"
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			if test.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", test.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			h.Handle(rec, req, nil)
+			c.Assert(rec.Code, qt.Equals, test.expectStatus)
+			c.Assert(rec.Header().Get("ETag"), qt.Equals, test.expectETag)
+			c.Assert(rec.Body.String(), qt.Equals, test.expectBody)
+		})
+	}
+}
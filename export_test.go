@@ -1,4 +1,6 @@
 package httprequest
 
 var AppendURL = appendURL
-var MaxErrorBodySize = &maxErrorBodySize
+var PathPatternsConflict = pathPatternsConflict
+var ParseRetryAfter = parseRetryAfter
+var UnmarshalJSONResponseWithLimit = unmarshalJSONResponse
@@ -2,6 +2,7 @@ package httprequest
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -41,10 +42,11 @@ func (e *DecodeResponseError) Error() string {
 // uses the given error for its message. The Response field
 // holds a copy of req. If bodyData is non-nil, it
 // will be used as the data in the Response.Body field;
-// otherwise body data will be read from req.Body.
-func newDecodeResponseError(resp *http.Response, bodyData []byte, err error) *DecodeResponseError {
+// otherwise up to limit bytes of body data will be read from
+// req.Body (see readBodyForError).
+func newDecodeResponseError(resp *http.Response, bodyData []byte, err error, limit int) *DecodeResponseError {
 	if bodyData == nil {
-		bodyData = readBodyForError(resp.Body)
+		bodyData = readBodyForError(resp.Body, limit)
 	}
 	resp1 := *resp
 	resp1.Body = ioutil.NopCloser(bytes.NewReader(bodyData))
@@ -59,10 +61,11 @@ func newDecodeResponseError(resp *http.Response, bodyData []byte, err error) *De
 // uses the given error for its message. The Request field
 // holds a copy of req. If bodyData is non-nil, it
 // will be used as the data in the Request.Body field;
-// otherwise body data will be read from req.Body.
-func newDecodeRequestError(req *http.Request, bodyData []byte, err error) *DecodeRequestError {
+// otherwise up to limit bytes of body data will be read from
+// req.Body (see readBodyForError).
+func newDecodeRequestError(req *http.Request, bodyData []byte, err error, limit int) *DecodeRequestError {
 	if bodyData == nil {
-		bodyData = readBodyForError(req.Body)
+		bodyData = readBodyForError(req.Body, limit)
 	}
 	req1 := *req
 	req1.Body = ioutil.NopCloser(bytes.NewReader(bodyData))
@@ -97,33 +100,64 @@ type fancyDecodeError struct {
 	// contentType holds the contentType of the request or response.
 	contentType string
 
-	// body holds up to maxErrorBodySize saved bytes of the
+	// body holds up to the error body size limit saved bytes of the
 	// request or response body.
 	body []byte
+
+	// want holds the expected media type. If it is empty,
+	// application/json is expected.
+	want string
 }
 
-func newFancyDecodeError(h http.Header, body io.Reader) *fancyDecodeError {
+func newFancyDecodeError(h http.Header, body io.Reader, limit int) *fancyDecodeError {
 	return &fancyDecodeError{
 		contentType: h.Get("Content-Type"),
-		body:        readBodyForError(body),
+		body:        readBodyForError(body, limit),
 	}
 }
 
-func readBodyForError(r io.Reader) []byte {
-	data, _ := ioutil.ReadAll(io.LimitReader(noErrorReader{r}, int64(maxErrorBodySize)))
+// readBodyForError reads up to limit bytes from r, or up to
+// DefaultMaxErrorBodySize bytes if limit is not positive.
+func readBodyForError(r io.Reader, limit int) []byte {
+	data, _ := ioutil.ReadAll(io.LimitReader(noErrorReader{r}, int64(errorBodyLimit(limit))))
 	return data
 }
 
-// maxErrorBodySize holds the maximum amount of body that
-// we try to read for an error before extracting text from it.
+// DefaultMaxErrorBodySize holds the maximum amount of body that
+// we try to read for an error before extracting text from it
+// when Client.MaxErrorBodySize or Server.MaxErrorBodySize is zero.
 // It's reasonably large because:
 // a) HTML often has large embedded scripts which we want
 // to skip and
 // b) it should be an relatively unusual case so the size
 // shouldn't harm.
-//
-// It's defined as a variable so that it can be redefined in tests.
-var maxErrorBodySize = 200 * 1024
+const DefaultMaxErrorBodySize = 200 * 1024
+
+// errorBodyLimit returns the maximum amount of body to read
+// for an error given the configured limit, which is
+// DefaultMaxErrorBodySize if limit is not positive.
+func errorBodyLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultMaxErrorBodySize
+	}
+	return limit
+}
+
+type maxErrorBodySizeKey struct{}
+
+// contextWithMaxErrorBodySize returns a copy of ctx holding the
+// maximum amount of request body captured in a DecodeRequestError
+// by the handler serving the request.
+func contextWithMaxErrorBodySize(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, maxErrorBodySizeKey{}, limit)
+}
+
+// maxErrorBodySizeFromContext returns the limit stored in ctx by
+// contextWithMaxErrorBodySize, or zero if there is none.
+func maxErrorBodySizeFromContext(ctx context.Context) int {
+	limit, _ := ctx.Value(maxErrorBodySizeKey{}).(int)
+	return limit
+}
 
 // isJSONMediaType reports whether the content type of the given header implies
 // that the content is JSON.
@@ -157,6 +191,10 @@ func (e *fancyDecodeError) Error() string {
 		// Even if there's no media type, we want to see something useful.
 		mediaType = fmt.Sprintf("%q", e.contentType)
 	}
+	want := e.want
+	if want == "" {
+		want = "application/json"
+	}
 
 	// TODO use charset.NewReader to convert from non-utf8 content?
 	switch mediaType {
@@ -168,16 +206,16 @@ func (e *fancyDecodeError) Error() string {
 			// can fail is if there's a read error and we've
 			// removed that possibility by using
 			// noErrorReader above.
-			return fmt.Sprintf("unexpected (and invalid) content text/html; want application/json; content: %q", sizeLimit(e.body))
+			return fmt.Sprintf("unexpected (and invalid) content text/html; want %s; content: %q", want, sizeLimit(e.body))
 		}
 		if len(text) == 0 {
-			return fmt.Sprintf(`unexpected content type text/html; want application/json; content: %q`, sizeLimit(e.body))
+			return fmt.Sprintf(`unexpected content type text/html; want %s; content: %q`, want, sizeLimit(e.body))
 		}
-		return fmt.Sprintf(`unexpected content type text/html; want application/json; content: %s`, sizeLimit(text))
+		return fmt.Sprintf(`unexpected content type text/html; want %s; content: %s`, want, sizeLimit(text))
 	case "text/plain":
-		return fmt.Sprintf(`unexpected content type text/plain; want application/json; content: %s`, sizeLimit(sanitizeText(string(e.body), true)))
+		return fmt.Sprintf(`unexpected content type text/plain; want %s; content: %s`, want, sizeLimit(sanitizeText(string(e.body), true)))
 	default:
-		return fmt.Sprintf(`unexpected content type %s; want application/json; content: %q`, mediaType, sizeLimit(e.body))
+		return fmt.Sprintf(`unexpected content type %s; want %s; content: %q`, mediaType, want, sizeLimit(e.body))
 	}
 }
 
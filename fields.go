@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// FieldError describes a problem found when unmarshaling
+// a single field of a request.
+type FieldError struct {
+	// Field holds the name of the field.
+	Field string
+
+	// Code may hold a code that classifies the problem.
+	// It is CodeBadRequest unless the underlying error
+	// implements ErrorCoder.
+	Code string `json:",omitempty"`
+
+	// Message holds a description of the problem.
+	Message string
+}
+
+// FieldsError is used by Unmarshal to report problems with all
+// the fields of a request that could not be unmarshaled, rather
+// than just the first. When DefaultErrorMapper is used, the
+// fields are sent to the client in RemoteError.Fields.
+type FieldsError struct {
+	Fields []FieldError
+
+	// errs holds the original error for each field.
+	errs []error
+}
+
+// Error implements error.Error.
+func (e *FieldsError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = "cannot unmarshal into field " + f.Field + ": " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors found for each field.
+func (e *FieldsError) Unwrap() []error {
+	return e.errs
+}
+
+// add adds a problem with the given field to e.
+func (e *FieldsError) add(field string, err error) {
+	code := CodeBadRequest
+	if coder, ok := errgo.Cause(err).(ErrorCoder); ok && coder.ErrorCode() != "" {
+		code = coder.ErrorCode()
+	}
+	e.Fields = append(e.Fields, FieldError{
+		Field:   field,
+		Code:    code,
+		Message: err.Error(),
+	})
+	e.errs = append(e.errs, err)
+}
+
+// fieldsError returns the *FieldsError in the chain
+// of errors underlying err, or nil if there is none.
+func fieldsError(err error) *FieldsError {
+	for err != nil {
+		if ferr, ok := err.(*FieldsError); ok {
+			return ferr
+		}
+		u, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return nil
+		}
+		err = u.Underlying()
+	}
+	return nil
+}
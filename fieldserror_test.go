@@ -0,0 +1,81 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type fieldsErrorRequest struct {
+	A int    `httprequest:"a,form"`
+	B string `httprequest:"b,form"`
+	C int    `httprequest:"c,form"`
+}
+
+func TestUnmarshalFieldsError(t *testing.T) {
+	c := qt.New(t)
+
+	var x fieldsErrorRequest
+	err := httprequest.Unmarshal(httprequest.Params{
+		Request: &http.Request{
+			Form: url.Values{
+				"a": {"x"},
+				"b": {"ok"},
+				"c": {"y"},
+			},
+		},
+	}, &x)
+	c.Assert(errgo.Cause(err), qt.Equals, httprequest.ErrUnmarshal)
+	c.Assert(x.B, qt.Equals, "ok")
+
+	status, body := httprequest.DefaultErrorMapper(context.Background(), err)
+	c.Assert(status, qt.Equals, http.StatusInternalServerError)
+	c.Assert(body, qt.DeepEquals, &httprequest.RemoteError{
+		Message: `cannot unmarshal into field A: cannot parse "x" into int: expected integer; cannot unmarshal into field C: cannot parse "y" into int: expected integer`,
+		Fields: []httprequest.FieldError{{
+			Field:   "A",
+			Code:    httprequest.CodeBadRequest,
+			Message: `cannot parse "x" into int: expected integer`,
+		}, {
+			Field:   "C",
+			Code:    httprequest.CodeBadRequest,
+			Message: `cannot parse "y" into int: expected integer`,
+		}},
+	})
+}
+
+func TestUnmarshalFieldsErrorCode(t *testing.T) {
+	c := qt.New(t)
+
+	var x struct {
+		A forbiddenUnmarshaler `httprequest:"a,form"`
+	}
+	err := httprequest.Unmarshal(httprequest.Params{
+		Request: &http.Request{
+			Form: url.Values{
+				"a": {"x"},
+			},
+		},
+	}, &x)
+	_, body := httprequest.DefaultErrorMapper(context.Background(), err)
+	c.Assert(body.(*httprequest.RemoteError).Fields, qt.DeepEquals, []httprequest.FieldError{{
+		Field:   "A",
+		Code:    httprequest.CodeForbidden,
+		Message: "not allowed",
+	}})
+}
+
+type forbiddenUnmarshaler struct{}
+
+func (*forbiddenUnmarshaler) UnmarshalText([]byte) error {
+	return httprequest.Errorf(httprequest.CodeForbidden, "not allowed")
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Call is like Client.Call except that the response is returned as a
+// value of type Resp rather than being unmarshaled into a value passed
+// in, for example:
+//
+//	user, err := httprequest.Call[*params.GetUser, User](ctx, client, &params.GetUser{ID: id})
+//
+// The zero Resp is returned if the call fails.
+func Call[Req, Resp any](ctx context.Context, client *Client, req Req, opts ...CallOption) (Resp, error) {
+	var resp Resp
+	if err := client.Call(ctx, req, &resp, opts...); err != nil {
+		var zero Resp
+		return zero, errgo.Mask(err, errgo.Any)
+	}
+	return resp, nil
+}
+
+// Do is like Client.Do except that the response is returned
+// as a value of type Resp. See Call.
+func Do[Resp any](ctx context.Context, client *Client, req *http.Request, opts ...CallOption) (Resp, error) {
+	var resp Resp
+	if err := client.Do(ctx, req, &resp, opts...); err != nil {
+		var zero Resp
+		return zero, errgo.Mask(err, errgo.Any)
+	}
+	return resp, nil
+}
+
+// Get is like Client.Get except that the response is returned
+// as a value of type Resp. See Call.
+func Get[Resp any](ctx context.Context, client *Client, url string, opts ...CallOption) (Resp, error) {
+	var resp Resp
+	if err := client.Get(ctx, url, &resp, opts...); err != nil {
+		var zero Resp
+		return zero, errgo.Mask(err, errgo.Any)
+	}
+	return resp, nil
+}
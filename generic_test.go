@@ -0,0 +1,86 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type statusRequest struct {
+	httprequest.Route `httprequest:"GET /status/:code"`
+	Code              int `httprequest:"code,path"`
+}
+
+func TestGenericCall(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	code, err := httprequest.Call[*statusRequest, int](context.Background(), client, &statusRequest{Code: 201})
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 201)
+
+	code, err = httprequest.Call[*statusRequest, int](context.Background(), client, &statusRequest{Code: 201},
+		httprequest.WithExpectedStatus(http.StatusOK),
+	)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/status/201: unexpected HTTP response status: 201 Created`)
+	c.Assert(code, qt.Equals, 0)
+}
+
+func TestGenericCallError(t *testing.T) {
+	c := qt.New(t)
+
+	srv := batchServer(new(int32))
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	n, err := httprequest.Call[*batchItemRequest, int](context.Background(), client, &batchItemRequest{ID: -1})
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/item/-1: item -1 not found`)
+	c.Assert(errgo.Cause(err), qt.Satisfies, isRemoteError)
+	c.Assert(n, qt.Equals, 0)
+}
+
+func TestGenericGet(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	code, err := httprequest.Get[int](context.Background(), client, "/status/202")
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 202)
+
+	resp, err := httprequest.Get[*http.Response](context.Background(), client, "/status/200")
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+}
+
+func TestGenericDo(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	req, err := http.NewRequest("GET", "/status/200", nil)
+	c.Assert(err, qt.IsNil)
+	code, err := httprequest.Do[int](context.Background(), client, req)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 200)
+}
@@ -1,6 +1,6 @@
 module gopkg.in/httprequest.v1
 
-go 1.15
+go 1.22.0
 
 require (
 	github.com/frankban/quicktest v1.10.0
@@ -11,3 +11,11 @@ require (
 	golang.org/x/tools v0.0.0-20200505023115-26f46d2f7ef8
 	gopkg.in/errgo.v1 v1.0.0
 )
+
+require (
+	github.com/kr/pretty v0.2.0 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
+	gopkg.in/mgo.v2 v2.0.0-20190816093944-a6b53ec6cb22 // indirect
+	gopkg.in/yaml.v2 v2.2.7 // indirect
+)
@@ -6,10 +6,15 @@ package httprequest
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	errgo "gopkg.in/errgo.v1"
@@ -30,12 +35,254 @@ type Server struct {
 	// If this both this and ErrorWriter are nil, DefaultErrorMapper will be used.
 	ErrorMapper func(ctxt context.Context, err error) (httpStatus int, errorBody interface{})
 
+	// ErrorMapperWithRequest is like ErrorMapper except that it is
+	// also passed the HTTP request being served, so the error
+	// response can depend on the request's headers or path. If it is
+	// set, ErrorMapper is ignored.
+	//
+	// The request is nil when WriteError is called with a context
+	// that is not derived from a request served by a handler created
+	// by Handle, Handlers or NewRouter.
+	ErrorMapperWithRequest func(ctx context.Context, req *http.Request, err error) (httpStatus int, errorBody interface{})
+
+	// ErrorFormat selects the format used to write error bodies
+	// returned by ErrorMapper or ErrorMapperWithRequest. By default
+	// they are written as returned; with ErrorFormatProblem,
+	// *RemoteError bodies are written as RFC 7807 problem details.
+	ErrorFormat ErrorFormat
+
+	// Envelope, if non-nil, is called to wrap the bodies of
+	// responses from handlers created by Handle or Handlers before
+	// they are encoded, so that every response can follow the same
+	// structure without changes to individual handlers. It is
+	// called with the result of a successful handler and isError
+	// false, or with the error body returned by ErrorMapper or
+	// ErrorMapperWithRequest and isError true; the value it returns
+	// is encoded in place of the body. Any headers set by the body
+	// (see HeaderSetter) are still set on the response.
+	//
+	// Results that are not encoded, such as NoContent, Stream and
+	// channels, are not wrapped, and neither are errors written by
+	// ErrorWriter or as problem details (see ErrorFormat).
+	//
+	// DefaultEnvelope can be used to produce responses of the form
+	// {"data": ...} and {"error": ...}.
+	Envelope func(ctx context.Context, body interface{}, isError bool) interface{}
+
 	// ErrorWriter is a more general form of ErrorMapper. If this
-	// field is set, ErrorMapper will be ignored and any returned
-	// errors will be passed to ErrorWriter, which should use
-	// w to set the HTTP status and write an appropriate
-	// error response.
+	// field is set, ErrorMapper and ErrorMapperWithRequest will be
+	// ignored and any returned errors will be passed to ErrorWriter,
+	// which should use w to set the HTTP status and write an
+	// appropriate error response.
 	ErrorWriter func(ctx context.Context, w http.ResponseWriter, err error)
+
+	// NoContentForNil specifies that when a handler returns a
+	// nil pointer or interface value as its result, the response
+	// will have status http.StatusNoContent and an empty body
+	// rather than a JSON null body.
+	NoContentForNil bool
+
+	// SSEHeartbeatInterval holds the interval between heartbeat
+	// comments sent to keep server-sent event streams alive (see
+	// SSEEvent). If it is zero, DefaultSSEHeartbeatInterval is used.
+	SSEHeartbeatInterval time.Duration
+
+	// CompressionThreshold, if positive, enables compression of
+	// responses from handlers created by Handle or Handlers. A
+	// response is compressed, including error responses, if the
+	// request's Accept-Encoding header allows one of Compressors and
+	// the response body reaches this many bytes before the response
+	// is flushed. Streamed responses that flush before reaching the
+	// threshold, such as server-sent event streams, are sent
+	// uncompressed.
+	CompressionThreshold int
+
+	// Codecs holds the encodings that may be used for the bodies of
+	// responses from handlers created by Handle or Handlers,
+	// including error bodies returned by ErrorMapper. The codec is
+	// chosen according to the request's Accept header, with ties
+	// broken by order in Codecs. If Codecs is empty, or the request
+	// has no Accept header or accepts none of them, JSONCodec is
+	// used.
+	Codecs []Codec
+
+	// Compressors holds the content codings that may be used to
+	// compress responses, in order of preference. If it is empty,
+	// GzipCompressor is used.
+	Compressors []Compressor
+
+	// Logger, if non-nil, is used to log every error written by
+	// WriteError, along with any failures that cannot be reported
+	// to the client, such as an error response that cannot be
+	// marshaled. Log records include the route, HTTP status and
+	// error code where known.
+	Logger *slog.Logger
+
+	// DebugErrors specifies that each error written by WriteError
+	// is treated as an incident: the stack trace of the call to
+	// WriteError is captured and logged with the error by Logger,
+	// along with a new incident identifier. The stack trace is never
+	// sent to the client, but the error mapper can include the
+	// identifier in the response (see IncidentID), so that the
+	// failure seen by the client can be found in the log.
+	DebugErrors bool
+
+	// MaxBodySize, if positive, limits the size of request bodies
+	// read by handlers created by Handle or Handlers. A request
+	// with a larger body fails to unmarshal with an error with code
+	// CodeRequestTooLarge. The limit can be overridden for an
+	// individual route with the maxbodysize tag on its Route field
+	// (see RouteMetadata.MaxBodySize).
+	MaxBodySize int64
+
+	// MaxBodySizeByType holds limits on the size of request bodies
+	// that override MaxBodySize for requests with particular media
+	// types in their Content-Type header. Keys are media types,
+	// such as "application/json", or ranges such as "multipart/*";
+	// an exact match takes precedence over a range. As with
+	// MaxBodySize, a non-positive value means no limit, and the
+	// maxbodysize route tag takes precedence.
+	MaxBodySizeByType map[string]int64
+
+	// RouteFilter, if non-nil, is called for each method when
+	// creating handlers with Handlers or any of its variants, such
+	// as HandlersFromAll, with the HTTP method, path and metadata of
+	// the method's route. The state it returns determines whether
+	// the route is served, so that feature-flagged endpoints can be
+	// disabled without changes to handler types. It is not called
+	// by Handle.
+	RouteFilter func(method, path string, m RouteMetadata) RouteState
+
+	// Authorize is called before the request parameters are
+	// unmarshaled for any handler created by Handle or Handlers whose
+	// route declares authorization requirements with the auth tag on
+	// its Route field (see RouteMetadata.Auth). The requirements are
+	// passed as the final argument. If it returns an error, the error
+	// is written as the response and the handler is not called.
+	//
+	// If a route declares requirements and Authorize is nil, all
+	// requests to the route fail with an internal server error.
+	Authorize func(ctx context.Context, p Params, requirements []string) error
+
+	// ContextHook, if non-nil, is called before each request to a
+	// handler created by Handle or Handlers is authorized and
+	// unmarshaled. The context it returns is used as Params.Context
+	// (and so is passed to the Handlers root function). If it
+	// returns an error, the error is written as the response and
+	// the handler is not called. It can be used to attach loggers,
+	// tenants or deadlines to the context of every request.
+	ContextHook func(ctx context.Context, req *http.Request) (context.Context, error)
+
+	// RateLimit, if non-nil, is called before each request to a
+	// handler created by Handle or Handlers is unmarshaled, with the
+	// path pattern of the handler's route. If it returns an error,
+	// the error is written as the response and the handler is not
+	// called. Returning a *TooManyRequestsError causes a response
+	// with status http.StatusTooManyRequests (when using
+	// DefaultErrorMapper) and an appropriate Retry-After header.
+	RateLimit func(req *http.Request, pathPattern string) error
+
+	// Observe, if non-nil, is called after each request served by a
+	// handler created by Handle or Handlers has completed. It is
+	// provided with details of the request suitable for recording
+	// metrics such as request counts and latencies by route,
+	// including the sizes of the request and response bodies and
+	// the time spent decoding the request, in the handler and
+	// encoding the response.
+	Observe func(ctx context.Context, info RequestInfo)
+
+	// AccessLog, if non-nil, is used to log every request served
+	// by a handler created by Handle or Handlers after it has
+	// completed. Log entries include the route's path pattern as
+	// well as the request URI, so that requests can be grouped by
+	// route. See NewAccessLogger and NewSlogAccessLogger.
+	AccessLog AccessLogger
+
+	// ConcurrencyGroups holds the limiters used by routes that name
+	// a group in their concurrencygroup tag (see
+	// RouteMetadata.ConcurrencyGroup), so that a set of expensive
+	// routes can share a single limit on the number of requests
+	// served at once. Creating a handler for a route whose group is
+	// not in ConcurrencyGroups panics.
+	ConcurrencyGroups map[string]*ConcurrencyLimiter
+
+	// ConcurrencyWait holds how long a request to a route whose
+	// concurrency limit has been reached (see
+	// RouteMetadata.MaxConcurrent) waits for another request to
+	// finish. If the limit is still reached after that time, or
+	// ConcurrencyWait is zero, the request is rejected with a
+	// CodeServiceUnavailable error and a Retry-After header.
+	ConcurrencyWait time.Duration
+
+	// IdempotencyStore, if non-nil, is used to store the responses
+	// to requests with an Idempotency-Key header made to routes
+	// marked as idempotent (see RouteMetadata.Idempotent). When an
+	// authorized request is made with the same key, method, URL and
+	// body as one that has a stored response, that response is
+	// written again, with an Idempotent-Replayed header, instead of
+	// calling the handler. The request body is read into memory to
+	// compute the key, and response bodies larger than 1MiB are not
+	// stored.
+	IdempotencyStore IdempotencyStore
+
+	// StrictContentType specifies that requests to handlers created
+	// by Handle or Handlers whose body does not have the content type
+	// expected by the handler's argument are rejected with a
+	// CodeUnsupportedMediaType error before any attempt to unmarshal them.
+	// A JSON media type is expected when the argument has a body
+	// field, and a form media type when it has inbody form fields.
+	StrictContentType bool
+
+	// RejectWhileDraining specifies that once Drain has been
+	// called, new requests to handlers created by Handle or
+	// Handlers are rejected with a CodeServiceUnavailable error
+	// rather than being served.
+	RejectWhileDraining bool
+
+	// PropagateHeaders holds the names of request headers, such as
+	// X-Request-ID or Accept-Language, whose values are stored in
+	// the request context by handlers created by Handle or Handlers.
+	// A Client making a request with that context copies them onto
+	// the outgoing request (see ContextWithPropagatedHeaders), so
+	// that they are carried through calls to other services.
+	PropagateHeaders []string
+
+	// SerializeErrorCauses specifies that when an error written by
+	// WriteError is mapped to a *RemoteError without a Wrapped
+	// error, the errors that it wraps that have codes, such as
+	// errors returned by calls to other services, are included in
+	// the response as a chain of Wrapped errors, each with its code
+	// and message. A Client receiving the response can then find
+	// the code of the original failure with errors.Is, even when
+	// the error has passed through several services.
+	SerializeErrorCauses bool
+
+	// DeadlineHeader, if non-empty, holds the name of a request
+	// header, such as DefaultDeadlineHeader, that holds the time
+	// within which the request must be served, as sent by a Client
+	// with the same DeadlineHeader. Handlers created by Handle or
+	// Handlers are called with a context whose deadline is no later
+	// than that time, so that a Client making a request with that
+	// context propagates the deadline. The header holds a duration
+	// in the form accepted by time.ParseDuration; invalid values are
+	// ignored.
+	DeadlineHeader string
+
+	// MaxErrorBodySize holds the maximum number of bytes of a
+	// request body that are read in order to report it when the
+	// body of a request to a handler created by Handle or Handlers
+	// cannot be unmarshaled (see DecodeRequestError). If it is zero,
+	// DefaultMaxErrorBodySize is used.
+	MaxErrorBodySize int
+
+	// active holds the number of requests currently being
+	// served by handlers created by Handle or Handlers.
+	// It is accessed atomically.
+	active int64
+
+	// draining is set to 1 when Drain is called.
+	// It is accessed atomically.
+	draining int32
 }
 
 // Handler defines a HTTP handler that will handle the
@@ -44,6 +291,14 @@ type Handler struct {
 	Method string
 	Path   string
 	Handle httprouter.Handle
+
+	// Metadata holds any descriptive information
+	// found in the tags of the Route field.
+	Metadata RouteMetadata
+
+	// Params describes the parameters of the request,
+	// derived from the fields of the handler's argument.
+	Params []ParamInfo
 }
 
 // handlerFunc represents a function that can handle an HTTP request.
@@ -63,6 +318,17 @@ type handlerFunc struct {
 	// pathPattern holds the path pattern the function will
 	// be registered for.
 	pathPattern string
+
+	// metadata holds the metadata for the route.
+	metadata RouteMetadata
+
+	// params holds the parameters of the request.
+	params []ParamInfo
+
+	// handlerType and handlerMethod hold the names of the type and
+	// method that define the handler, if it is defined by a method.
+	handlerType   string
+	handlerMethod string
 }
 
 var (
@@ -82,18 +348,43 @@ func AddHandlers(r *httprouter.Router, hs []Handler) {
 	}
 }
 
+// NewRouter returns a new httprouter.Router with all the given
+// handlers added to it.
+//
+// Unlike a router created with httprouter.New, requests for paths that
+// have no handler are answered with an error with code CodeNotFound,
+// and requests for paths that have a handler but not for the requested
+// method are answered with an error with code CodeMethodNotAllowed.
+// Both errors are written with srv.WriteError, so they are formatted
+// consistently with other errors returned by srv. In the latter case
+// the Allow header of the response holds the methods that are
+// supported for the path.
+func (srv *Server) NewRouter(hs []Handler) *httprouter.Router {
+	r := httprouter.New()
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		srv.WriteError(contextWithRequest(req.Context(), req), w, Errorf(CodeNotFound, "no handler for %s %s", req.Method, req.URL.Path))
+	})
+	r.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		srv.WriteError(contextWithRequest(req.Context(), req), w, Errorf(CodeMethodNotAllowed, "method %s not allowed for %s", req.Method, req.URL.Path))
+	})
+	AddHandlers(r, hs)
+	return r
+}
+
 // Handle converts a function into a Handler. The argument f
-// must be a function of one of the following six forms, where ArgT
+// must be a function of one of the following eight forms, where ArgT
 // must be a struct type acceptable to Unmarshal and ResultT is a type
 // that can be marshaled as JSON:
 //
 //	func(p Params, arg *ArgT)
 //	func(p Params, arg *ArgT) error
 //	func(p Params, arg *ArgT) (ResultT, error)
+//	func(p Params, arg *ArgT) (ResultT, http.Header, error)
 //
 //	func(arg *ArgT)
 //	func(arg *ArgT) error
 //	func(arg *ArgT) (ResultT, error)
+//	func(arg *ArgT) (ResultT, http.Header, error)
 //
 // When processing a call to the returned handler, the provided
 // parameters are unmarshaled into a new ArgT value using Unmarshal,
@@ -111,8 +402,25 @@ func AddHandlers(r *httprouter.Router, hs []Handler) {
 // If an error is returned from f, it is passed through the error mapper
 // before writing as a JSON response.
 //
+// In the last form, when no error is returned, the returned headers
+// are added to the response before the result is written as below.
+//
 // In the third form, when no error is returned, the result is written
-// as a JSON response with status http.StatusOK. Also in this case, any
+// as a JSON response with status http.StatusOK, or the status returned
+// by its StatusCode method if it implements StatusCoder (see also
+// CustomStatus). If the result is NoContent, the response has status
+// http.StatusNoContent and no body. If the result implements ETagger,
+// the response has an ETag header and, if the tag matches the request's
+// If-None-Match header, status http.StatusNotModified and no body.
+// If the result is a Stream, an Attachment or an io.ReadCloser, its
+// contents are streamed to the client instead of being written as
+// JSON, and if the result is a <-chan SSEEvent, the events are sent as
+// a server-sent event stream. If the result is a Multipart, it is sent
+// as a multipart/mixed response, and if it is a Proxy, the request is
+// forwarded as it directs. If the result is any other receive-only
+// channel, each value received from it is written as one line of JSON
+// (application/x-ndjson) until the channel is closed or the request
+// context is done. Also in this case, any
 // calls to Params.Response.Write or Params.Response.WriteHeader will be
 // ignored, as the response code and data should be defined entirely by
 // the returned result and error.
@@ -126,24 +434,42 @@ func (srv *Server) Handle(f interface{}) Handler {
 		panic(errgo.Notef(err, "bad handler function"))
 	}
 	return Handler{
-		Method: hf.method,
-		Path:   hf.pathPattern,
-		Handle: func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
-			ctx := req.Context()
+		Method:   hf.method,
+		Path:     hf.pathPattern,
+		Metadata: hf.metadata,
+		Params:   hf.params,
+		Handle: srv.handle(hf, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+			ctx, err := srv.requestContext(req)
+			if err != nil {
+				srv.WriteError(ctx, w, err)
+				return
+			}
 			p1 := Params{
 				Response:    w,
 				Request:     req,
 				PathVar:     p,
 				PathPattern: hf.pathPattern,
+				Metadata:    hf.metadata,
 				Context:     ctx,
 			}
+			if err := srv.authorize(p1); err != nil {
+				srv.WriteError(ctx, w, err)
+				return
+			}
+			ikey, err := srv.idempotencyKey(p1)
+			if err != nil {
+				srv.WriteError(ctx, w, err)
+				return
+			}
 			argv, err := hf.unmarshal(p1)
 			if err != nil {
 				srv.WriteError(ctx, w, err)
 				return
 			}
-			hf.call(fv, argv, p1)
-		},
+			srv.serveIdempotent(p1, ikey, func(p1 Params) {
+				hf.call(fv, argv, p1)
+			})
+		}),
 	}
 }
 
@@ -166,6 +492,11 @@ func (srv *Server) Handle(f interface{}) Handler {
 //
 // Handlers will panic if f is not of the required form, no methods are
 // defined on T or any method defined on T is not suitable for Handle.
+// It will also panic, naming both methods, if the routes of any two
+// methods conflict: that is, if they have the same HTTP method and
+// paths that match the same requests (such as /items/:id and
+// /items/:name) or that cannot both be registered with httprouter
+// (such as /items/:id and /items/new).
 //
 // When any of the returned handlers is invoked, f will be called and
 // then the appropriate method will be called on the value it returns.
@@ -175,13 +506,203 @@ func (srv *Server) Handle(f interface{}) Handler {
 // If T implements io.Closer, its Close method will be called
 // after the request is completed.
 func (srv *Server) Handlers(f interface{}) []Handler {
+	return srv.handlers("", f, new(routeChecker), false)
+}
+
+// HandlersWithPrefix is like Handlers except that the given prefix is
+// prepended to the path of every handler, so that a single handler type
+// can be mounted at several places. The prefix must start with a slash.
+// It will also be included in the PathPattern field of the Params
+// passed to each handler.
+//
+// For example, if a method on T has a Route tag specifying "GET
+// /users/:id", then HandlersWithPrefix("/v1", f) will return a handler
+// with the path "/v1/users/:id".
+func (srv *Server) HandlersWithPrefix(prefix string, f interface{}) []Handler {
+	if !strings.HasPrefix(prefix, "/") {
+		panic(errgo.Newf("path prefix %q does not start with /", prefix))
+	}
+	return srv.handlers(strings.TrimSuffix(prefix, "/"), f, new(routeChecker), false)
+}
+
+// HandlersFromAll is like Handlers except that it accepts several root
+// functions, each of which must be of a form acceptable to Handlers,
+// and returns the handlers for all of them. This makes it possible to
+// compose a service from several handler types.
+//
+// As with Handlers, HandlersFromAll will panic if the routes of
+// any two handlers conflict, including handlers from different root
+// functions.
+func (srv *Server) HandlersFromAll(fs ...interface{}) []Handler {
+	var hs []Handler
+	routes := new(routeChecker)
+	for _, f := range fs {
+		hs = append(hs, srv.handlers("", f, routes, false)...)
+	}
+	return hs
+}
+
+// routeChecker holds the routes seen by Handlers and HandlersFromAll
+// so that conflicting routes can be reported naming the methods
+// that define them.
+type routeChecker []routeSource
+
+// routeSource records where a route was defined.
+type routeSource struct {
+	method string
+	path   string
+
+	// definedBy holds the name of the Go method
+	// that defines the route.
+	definedBy string
+}
+
+// add adds the route of h, defined by the given Go method, to rc. It
+// returns an error if a conflicting route has already been added.
+func (rc *routeChecker) add(h Handler, definedBy string) error {
+	for _, other := range *rc {
+		if other.method == h.Method && pathPatternsConflict(other.path, h.Path) {
+			return errgo.Newf("%s %s defined by %s conflicts with %s %s defined by %s", h.Method, h.Path, definedBy, other.method, other.path, other.definedBy)
+		}
+	}
+	*rc = append(*rc, routeSource{
+		method:    h.Method,
+		path:      h.Path,
+		definedBy: definedBy,
+	})
+	return nil
+}
+
+// pathPatternsConflict reports whether the given httprouter path
+// patterns cannot both be registered for the same method, either
+// because they match the same paths, such as /items/:id and
+// /items/:name, or because they overlap, such as /items/:id and
+// /items/new.
+func pathPatternsConflict(p1, p2 string) bool {
+	elems1 := strings.Split(p1, "/")
+	elems2 := strings.Split(p2, "/")
+	for i := 0; i < len(elems1) && i < len(elems2); i++ {
+		e1, e2 := elems1[i], elems2[i]
+		if e1 == e2 {
+			continue
+		}
+		// httprouter does not allow a wildcard to share its
+		// position with any other element.
+		return isWildcard(e1) || isWildcard(e2)
+	}
+	return len(elems1) == len(elems2)
+}
+
+// isWildcard reports whether the given path pattern
+// element is a parameter or catch-all.
+func isWildcard(elem string) bool {
+	return len(elem) > 0 && (elem[0] == ':' || elem[0] == '*')
+}
+
+// methodName returns the name of the method with the given name on
+// type t, as it would be written in a method expression.
+func methodName(t reflect.Type, name string) string {
+	if t.Kind() == reflect.Ptr {
+		return fmt.Sprintf("(%v).%s", t, name)
+	}
+	return fmt.Sprintf("%v.%s", t, name)
+}
+
+// handlers returns the handlers for the root function f, adding their
+// routes to routes. If versioned is true, the paths of methods whose
+// routes declare a version are prefixed with the version (see
+// VersionedHandlers).
+func (srv *Server) handlers(prefix string, f interface{}, routes *routeChecker, versioned bool) []Handler {
+	root := newHandlerRoot(f)
+	hs, omitted := srv.methodHandlers(prefix, root, routes, versioned)
+	if len(hs) == 0 && omitted == 0 {
+		panic(errgo.Newf("no exported methods defined on %s", root.t))
+	}
+	return hs
+}
+
+// handlerRoot obtains the value whose methods handle
+// requests for a handler type.
+type handlerRoot struct {
+	// t holds the type of the value.
+	t reflect.Type
+
+	// argInterfacet holds the interface type that the
+	// argument to each method must implement, or nil
+	// if there is no such constraint.
+	argInterfacet reflect.Type
+
+	// get returns the value for a request, given its parameters
+	// and the argument that will be passed to the method. It also
+	// returns the context to use for the request, which is valid
+	// even when the error is non-nil. Any values that must be
+	// closed when the request has completed are appended to
+	// closers.
+	get func(p Params, argv reflect.Value, closers *[]io.Closer) (reflect.Value, context.Context, error)
+}
+
+// newHandlerRoot returns the handlerRoot for f, which must be in
+// one of the forms accepted by Handlers.
+func newHandlerRoot(f interface{}) handlerRoot {
 	rootv := reflect.ValueOf(f)
 	wt, argInterfacet, err := checkHandlersWrapperFunc(rootv)
 	if err != nil {
 		panic(errgo.Notef(err, "bad handler function"))
 	}
 	hasClose := wt.Implements(ioCloserType)
-	hs := make([]Handler, 0, wt.NumMethod())
+	return handlerRoot{
+		t:             wt,
+		argInterfacet: argInterfacet,
+		get: func(p Params, argv reflect.Value, closers *[]io.Closer) (reflect.Value, context.Context, error) {
+			var outv []reflect.Value
+			if argInterfacet != nil {
+				outv = rootv.Call([]reflect.Value{
+					reflect.ValueOf(p),
+					// Pass the value to the root function so it can do wrappy things with it.
+					// Note that because of the checks we've applied earlier, we can be
+					// sure that the value will implement the interface type of this argument.
+					argv,
+				})
+			} else {
+				outv = rootv.Call([]reflect.Value{
+					reflect.ValueOf(p),
+				})
+			}
+			return rootResult(outv, p.Context, hasClose, closers)
+		},
+	}
+}
+
+// rootResult interprets the results of a call to a function returning
+// (T, context.Context, error) made with the given context. If hasClose
+// is true, T implements io.Closer and the value is appended to closers
+// when the call succeeded.
+func rootResult(outv []reflect.Value, ctx context.Context, hasClose bool, closers *[]io.Closer) (reflect.Value, context.Context, error) {
+	tv, ctxv, errv := outv[0], outv[1], outv[2]
+	// Get the context value robustly even if the
+	// handler stupidly decides to return nil, and fall
+	// back to the original context if it does.
+	ctx1, _ := ctxv.Interface().(context.Context)
+	if ctx1 != nil {
+		ctx = ctx1
+	}
+	if !errv.IsNil() {
+		return reflect.Value{}, ctx, errv.Interface().(error)
+	}
+	if hasClose {
+		*closers = append(*closers, tv.Interface().(io.Closer))
+	}
+	return tv, ctx, nil
+}
+
+// methodHandlers returns a handler for each exported method
+// on the type of the values obtained by root, adding their
+// routes to routes. It also returns the number of methods
+// omitted by srv.RouteFilter.
+func (srv *Server) methodHandlers(prefix string, root handlerRoot, routes *routeChecker, versioned bool) (hs []Handler, omitted int) {
+	wt := root.t
+	hasClose := wt.Implements(ioCloserType)
+	hs = make([]Handler, 0, wt.NumMethod())
 	for i := 0; i < wt.NumMethod(); i++ {
 		i := i
 		m := wt.Method(i)
@@ -201,84 +722,212 @@ func (srv *Server) Handlers(f interface{}) []Handler {
 			// so we hide it.
 			m.Type = withoutReceiver(m.Type)
 		}
-		h, err := srv.methodHandler(m, rootv, argInterfacet, hasClose)
+		h, err := srv.methodHandler(m, root, prefix, versioned)
 		if err != nil {
 			panic(err)
 		}
+		if h.Handle == nil {
+			omitted++
+			continue
+		}
+		if err := routes.add(h, methodName(wt, m.Name)); err != nil {
+			panic(err)
+		}
 		hs = append(hs, h)
 	}
-	if len(hs) == 0 {
-		panic(errgo.Newf("no exported methods defined on %s", wt))
-	}
-	return hs
+	return hs, omitted
 }
 
-func (srv *Server) methodHandler(m reflect.Method, rootv reflect.Value, argInterfacet reflect.Type, hasClose bool) (Handler, error) {
-	hf, err := srv.handlerFunc(m.Type, argInterfacet)
+// methodHandler returns the handler for the method m on the type of
+// the values obtained by root. If the route is omitted by
+// srv.RouteFilter, the returned Handler has a nil Handle.
+func (srv *Server) methodHandler(m reflect.Method, root handlerRoot, prefix string, versioned bool) (Handler, error) {
+	hf, err := srv.handlerFunc(m.Type, root.argInterfacet)
 	if err != nil {
 		return Handler{}, errgo.Notef(err, "bad type for method %s", m.Name)
 	}
 	if hf.method == "" || hf.pathPattern == "" {
 		return Handler{}, errgo.Notef(err, "method %s does not specify route method and path", m.Name)
 	}
+	if versioned && hf.metadata.Version != "" {
+		prefix += versionPrefix(hf.metadata.Version)
+	}
+	hf.pathPattern = prefix + hf.pathPattern
+	hf.handlerType = root.t.String()
+	hf.handlerMethod = m.Name
+	switch state := srv.routeState(hf); state {
+	case RouteOmitted:
+		return Handler{}, nil
+	case RouteNotFound, RouteNotImplemented:
+		return Handler{
+			Method:   hf.method,
+			Path:     hf.pathPattern,
+			Handle:   srv.handle(hf, srv.unavailableRoute(hf, state)),
+			Metadata: hf.metadata,
+			Params:   hf.params,
+		}, nil
+	}
 	handler := func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
-		ctx := req.Context()
+		ctx, err := srv.requestContext(req)
+		if err != nil {
+			srv.WriteError(ctx, w, err)
+			return
+		}
 		p1 := Params{
 			Response:    w,
 			Request:     req,
 			PathVar:     p,
 			PathPattern: hf.pathPattern,
+			Metadata:    hf.metadata,
 			Context:     ctx,
 		}
-		inv, err := hf.unmarshal(p1)
-		if err != nil {
+		if err := srv.authorize(p1); err != nil {
 			srv.WriteError(ctx, w, err)
 			return
 		}
-		var outv []reflect.Value
-		if argInterfacet != nil {
-			outv = rootv.Call([]reflect.Value{
-				reflect.ValueOf(p1),
-				// Pass the value to the root function so it can do wrappy things with it.
-				// Note that because of the checks we've applied earlier, we can be
-				// sure that the value will implement the interface type of this argument.
-				inv,
-			})
-		} else {
-			outv = rootv.Call([]reflect.Value{
-				reflect.ValueOf(p1),
-			})
-		}
-		tv, ctxv, errv := outv[0], outv[1], outv[2]
-		// Get the context value robustly even if the
-		// handler stupidly decides to return nil, and fall
-		// back to the original context if it does.
-		ctx1, _ := ctxv.Interface().(context.Context)
-		if ctx1 != nil {
-			ctx = ctx1
+		ikey, err := srv.idempotencyKey(p1)
+		if err != nil {
+			srv.WriteError(ctx, w, err)
+			return
 		}
-		if !errv.IsNil() {
-			srv.WriteError(ctx, w, errv.Interface().(error))
+		inv, err := hf.unmarshal(p1)
+		if err != nil {
+			srv.WriteError(ctx, w, err)
 			return
 		}
-		if hasClose {
-			defer tv.Interface().(io.Closer).Close()
+		var closers []io.Closer
+		defer func() {
+			for i := len(closers) - 1; i >= 0; i-- {
+				closers[i].Close()
+			}
+		}()
+		stats := statsFromContext(req.Context())
+		start := stats.start()
+		tv, ctx, err := root.get(p1, inv, &closers)
+		stats.addHandler(start)
+		if err != nil {
+			srv.WriteError(ctx, w, err)
+			return
 		}
-		hf.call(tv.Method(m.Index), inv, Params{
+		srv.serveIdempotent(Params{
 			Response:    w,
 			Request:     req,
 			PathVar:     p,
 			PathPattern: hf.pathPattern,
+			Metadata:    hf.metadata,
 			Context:     ctx,
+		}, ikey, func(p Params) {
+			hf.call(tv.Method(m.Index), inv, p)
 		})
 	}
 	return Handler{
-		Method: hf.method,
-		Path:   hf.pathPattern,
-		Handle: handler,
+		Method:   hf.method,
+		Path:     hf.pathPattern,
+		Handle:   srv.handle(hf, handler),
+		Metadata: hf.metadata,
+		Params:   hf.params,
 	}, nil
 }
 
+// handle wraps h, which serves requests for the given handler
+// function, with any request-wide behaviour configured on srv.
+func (srv *Server) handle(hf handlerFunc, h httprouter.Handle) httprouter.Handle {
+	route := &RouteInfo{
+		Method:        hf.method,
+		PathPattern:   hf.pathPattern,
+		Name:          hf.metadata.Name,
+		HandlerType:   hf.handlerType,
+		HandlerMethod: hf.handlerMethod,
+	}
+	h = srv.withBodyLimit(h, hf.metadata)
+	if l := srv.concurrencyLimiter(hf); l != nil {
+		h = srv.withConcurrencyLimit(h, l, hf)
+	}
+	if timeout := hf.metadata.Timeout; timeout > 0 {
+		h = srv.withTimeout(h, timeout)
+	}
+	h = srv.withRateLimit(h, hf.pathPattern)
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		ctx := contextWithRequest(contextWithRoute(req.Context(), route), req)
+		if len(srv.PropagateHeaders) > 0 {
+			ctx = contextWithPropagatedHeaders(ctx, req, srv.PropagateHeaders)
+		}
+		if srv.MaxErrorBodySize > 0 {
+			ctx = contextWithMaxErrorBodySize(ctx, srv.MaxErrorBodySize)
+		}
+		if srv.DeadlineHeader != "" {
+			var cancel context.CancelFunc
+			ctx, cancel = contextWithDeadlineHeader(ctx, req, srv.DeadlineHeader)
+			defer cancel()
+		}
+		req = req.WithContext(ctx)
+		if !srv.enter() {
+			srv.WriteError(req.Context(), w, Errorf(CodeServiceUnavailable, "server is shutting down"))
+			return
+		}
+		defer srv.exit()
+		if srv.Observe == nil && srv.AccessLog == nil {
+			srv.serve(w, req, p, h)
+			return
+		}
+		start := time.Now()
+		stats := new(requestStats)
+		req = req.WithContext(contextWithStats(req.Context(), stats))
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = countingReader{req.Body, stats}
+		}
+		w1 := &recordingResponseWriter{
+			ResponseWriter: w,
+		}
+		srv.serve(w1, req, p, h)
+		info := RequestInfo{
+			Method:          req.Method,
+			PathPattern:     hf.pathPattern,
+			Status:          w1.status(),
+			Duration:        time.Since(start),
+			BytesRead:       atomic.LoadInt64(&stats.read),
+			BytesWritten:    w1.written,
+			DecodeDuration:  time.Duration(atomic.LoadInt64(&stats.decode)),
+			HandlerDuration: time.Duration(atomic.LoadInt64(&stats.handler)),
+			EncodeDuration:  time.Duration(atomic.LoadInt64(&stats.encode)),
+		}
+		if srv.Observe != nil {
+			srv.Observe(req.Context(), info)
+		}
+		if srv.AccessLog != nil {
+			srv.AccessLog.LogAccess(req.Context(), newAccessLogEntry(req, start, w.Header(), info))
+		}
+	}
+}
+
+// serve calls h to serve req, choosing the response
+// encoding and compressing the response if appropriate.
+func (srv *Server) serve(w http.ResponseWriter, req *http.Request, p httprouter.Params, h httprouter.Handle) {
+	if len(srv.Codecs) > 0 {
+		w.Header().Add("Vary", "Accept")
+		req = req.WithContext(contextWithCodec(req.Context(), srv.codec(req)))
+	}
+	if srv.CompressionThreshold <= 0 {
+		h(w, req, p)
+		return
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	c := srv.compressor(req)
+	if c == nil {
+		h(w, req, p)
+		return
+	}
+	cw := &compressWriter{
+		ResponseWriter: w,
+		compressor:     c,
+		threshold:      srv.CompressionThreshold,
+	}
+	h(cw, req, p)
+	if err := cw.close(); err != nil {
+		srv.logFailure(req.Context(), "cannot write compressed response", err)
+	}
+}
+
 func checkHandlersWrapperFunc(fv reflect.Value) (returnt, argInterfacet reflect.Type, err error) {
 	ft := fv.Type()
 	if ft.Kind() != reflect.Func {
@@ -318,8 +967,8 @@ func checkHandleType(t, argInterfacet reflect.Type) (*requestType, error) {
 	if n := t.NumIn(); n != 1 && n != 2 {
 		return nil, errgo.Newf("has %d parameters, need 1 or 2", t.NumIn())
 	}
-	if t.NumOut() > 2 {
-		return nil, errgo.Newf("has %d result parameters, need 0, 1 or 2", t.NumOut())
+	if t.NumOut() > 3 {
+		return nil, errgo.Newf("has %d result parameters, need 0, 1, 2 or 3", t.NumOut())
 	}
 	if t.NumIn() == 2 {
 		if t.In(0) != paramsType {
@@ -341,10 +990,21 @@ func checkHandleType(t, argInterfacet reflect.Type) (*requestType, error) {
 	if t.NumOut() > 0 {
 		//	func(p Params, arg *ArgT) error
 		//	func(p Params, arg *ArgT) (ResultT, error)
+		//	func(p Params, arg *ArgT) (ResultT, http.Header, error)
 		if et := t.Out(t.NumOut() - 1); et != errorType {
 			return nil, errgo.Newf("final result parameter is %s, need error", et)
 		}
 	}
+	if t.NumOut() == 3 {
+		if ht := t.Out(1); ht != httpHeaderType {
+			return nil, errgo.Newf("second result parameter is %s, need http.Header", ht)
+		}
+	}
+	if t.NumOut() >= 2 {
+		if rt := t.Out(0); rt.Kind() == reflect.Chan && rt.Elem() == sseEventType && rt.ChanDir() != reflect.RecvDir {
+			return nil, errgo.Newf("first result parameter is %s, need <-chan httprequest.SSEEvent", rt)
+		}
+	}
 	return pt, nil
 }
 
@@ -356,25 +1016,47 @@ func (srv *Server) handlerFunc(ft, argInterfacet reflect.Type) (handlerFunc, err
 	if err != nil {
 		return handlerFunc{}, errgo.Mask(err)
 	}
+	if rt.metadata.Timeout > 0 && ft.NumOut() >= 2 {
+		if err := checkTimeoutResult(ft.Out(0)); err != nil {
+			return handlerFunc{}, errgo.Mask(err)
+		}
+	}
 	return handlerFunc{
-		unmarshal:   handlerUnmarshaler(ft, rt),
+		unmarshal:   srv.handlerUnmarshaler(ft, rt),
 		call:        srv.handlerCaller(ft, rt),
 		method:      rt.method,
 		pathPattern: rt.path,
+		metadata:    rt.metadata,
+		params:      rt.params,
 	}, nil
 }
 
-func handlerUnmarshaler(
+func (srv *Server) handlerUnmarshaler(
 	ft reflect.Type,
 	rt *requestType,
 ) func(p Params) (reflect.Value, error) {
 	argStructType := ft.In(ft.NumIn() - 1).Elem()
 	return func(p Params) (reflect.Value, error) {
+		if srv.StrictContentType {
+			if err := checkContentType(p.Request, rt); err != nil {
+				return reflect.Value{}, err
+			}
+		}
 		if err := p.Request.ParseForm(); err != nil {
+			if isBodyTooLarge(err) {
+				return reflect.Value{}, Errorf(CodeRequestTooLarge, "request body too large")
+			}
 			return reflect.Value{}, errgo.WithCausef(err, ErrUnmarshal, "cannot parse HTTP request form")
 		}
 		argv := reflect.New(argStructType)
-		if err := unmarshal(p, argv, rt); err != nil {
+		stats := statsFromContext(p.Request.Context())
+		start := stats.start()
+		err := unmarshal(p, argv, rt)
+		stats.addDecode(start)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				return reflect.Value{}, Errorf(CodeRequestTooLarge, "request body too large")
+			}
 			return reflect.Value{}, errgo.NoteMask(err, "cannot unmarshal parameters", errgo.Is(ErrUnmarshal))
 		}
 		return argv, nil
@@ -389,6 +1071,8 @@ func (srv *Server) handlerCaller(
 	needsParams := ft.In(0) == paramsType
 	respond := srv.handlerResponder(ft)
 	return func(fv, argv reflect.Value, p Params) {
+		stats := statsFromContext(p.Request.Context())
+		start := stats.start()
 		var rv []reflect.Value
 		if needsParams {
 			p := p
@@ -404,7 +1088,10 @@ func (srv *Server) handlerCaller(
 				argv,
 			})
 		}
+		stats.addHandler(start)
+		start = stats.start()
 		respond(p, rv)
+		stats.addEncode(start)
 	}
 }
 
@@ -422,14 +1109,26 @@ func (srv *Server) handlerResponder(ft reflect.Type) func(p Params, outv []refle
 				srv.WriteError(p.Context, p.Response, err.(error))
 			}
 		}
-	case 2:
+	case 2, 3:
 		// func(...) (ResultT, error)
+		// func(...) (ResultT, http.Header, error)
 		return func(p Params, outv []reflect.Value) {
-			if err := outv[1].Interface(); err != nil {
+			if err := outv[len(outv)-1].Interface(); err != nil {
 				srv.WriteError(p.Context, p.Response, err.(error))
 				return
 			}
-			if err := WriteJSON(p.Response, http.StatusOK, outv[0].Interface()); err != nil {
+			if len(outv) == 3 {
+				h := p.Response.Header()
+				for k, vs := range outv[1].Interface().(http.Header) {
+					for _, v := range vs {
+						h.Add(k, v)
+					}
+				}
+			}
+			if cc := p.Metadata.CacheControl; cc != "" {
+				p.Response.Header().Set("Cache-Control", cc)
+			}
+			if err := srv.writeResult(p.Response, p.Request, outv[0].Interface()); err != nil {
 				srv.WriteError(p.Context, p.Response, err)
 			}
 		}
@@ -475,7 +1174,7 @@ func (srv *Server) HandleJSON(handle JSONHandler) httprouter.Handle {
 			Context:  ctx,
 		})
 		if err == nil {
-			if err = WriteJSON(w, http.StatusOK, val); err == nil {
+			if err = srv.writeResult(w, req, val); err == nil {
 				return
 			}
 		}
@@ -507,7 +1206,7 @@ func (srv *Server) HandleErrors(handle ErrorHandler) httprouter.Handle {
 				// that we may be corrupting the
 				// response by appending a JSON error
 				// message to it.
-				// TODO log an error in this case.
+				srv.logFailure(ctx, "cannot write error after response header", err)
 				return
 			}
 			srv.WriteError(ctx, w, err)
@@ -519,29 +1218,47 @@ func (srv *Server) HandleErrors(handle ErrorHandler) httprouter.Handle {
 // status code, using srv.ErrorMapper to determine the actually written
 // response.
 //
-// It uses WriteJSON to write the error body returned from the
-// ErrorMapper so it is possible to add custom headers to the HTTP error
-// response by implementing HeaderSetter.
+// It writes the error body returned from the ErrorMapper as JSON, or
+// with the codec chosen for the request when ctx is derived from a
+// request served by a handler from Handle or Handlers (see
+// Server.Codecs). As with WriteJSON, it is possible to add custom
+// headers to the HTTP error response by implementing HeaderSetter.
+//
+// If err, or any error it wraps, implements RetryAfterer with
+// a positive duration, the Retry-After header is set accordingly.
 func (srv *Server) WriteError(ctx context.Context, w http.ResponseWriter, err error) {
+	if srv.DebugErrors {
+		ctx = contextWithIncident(ctx)
+	}
+	setRetryAfter(w.Header(), err)
+	if srv.Logger != nil {
+		w1 := &recordingResponseWriter{
+			ResponseWriter: w,
+		}
+		defer func() {
+			srv.logError(ctx, w1.status(), err)
+		}()
+		w = w1
+	}
 	if srv.ErrorWriter != nil {
 		srv.ErrorWriter(ctx, w, err)
 		return
 	}
-	errorMapper := srv.ErrorMapper
-	if errorMapper == nil {
-		errorMapper = DefaultErrorMapper
-	}
+	errorMapper := srv.errorMapper()
 	status, resp := errorMapper(ctx, err)
-	err1 := WriteJSON(w, status, resp)
+	if srv.SerializeErrorCauses {
+		resp = withErrorCauses(resp, err)
+	}
+	err1 := srv.writeErrorBody(ctx, w, status, resp)
 	if err1 == nil {
 		return
 	}
-	// TODO log an error ?
+	srv.logFailure(ctx, "cannot marshal error response", err1)
 
 	// JSON-marshaling the original error failed, so try to send that
 	// error instead; if that fails, give up and go home.
 	status1, resp1 := errorMapper(ctx, errgo.Notef(err1, "cannot marshal error response %q", err))
-	err2 := WriteJSON(w, status1, resp1)
+	err2 := srv.writeErrorBody(ctx, w, status1, resp1)
 	if err2 == nil {
 		return
 	}
@@ -550,6 +1267,84 @@ func (srv *Server) WriteError(ctx context.Context, w http.ResponseWriter, err er
 	w.Write([]byte(fmt.Sprintf("really cannot marshal error response %q: %v", err, err1)))
 }
 
+// errorMapper returns the function used by WriteError
+// to map errors to responses.
+func (srv *Server) errorMapper() func(ctx context.Context, err error) (int, interface{}) {
+	if srv.ErrorMapperWithRequest != nil {
+		return func(ctx context.Context, err error) (int, interface{}) {
+			return srv.ErrorMapperWithRequest(ctx, requestFromContext(ctx), err)
+		}
+	}
+	if srv.ErrorMapper != nil {
+		return srv.ErrorMapper
+	}
+	return DefaultErrorMapper
+}
+
+type requestKey struct{}
+
+// contextWithRequest returns a context that records the request
+// being served, for use by Server.ErrorMapperWithRequest.
+func contextWithRequest(ctx context.Context, req *http.Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+// requestFromContext returns the request recorded in ctx
+// by contextWithRequest, or nil if there is none.
+func requestFromContext(ctx context.Context) *http.Request {
+	req, _ := ctx.Value(requestKey{}).(*http.Request)
+	return req
+}
+
+// writeResult writes a result value returned by a
+// successful handler call as the response to req.
+func (srv *Server) writeResult(w http.ResponseWriter, req *http.Request, val interface{}) error {
+	switch val1 := val.(type) {
+	case NoContent:
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case Stream:
+		srv.writeStream(w, req, val1)
+		return nil
+	case io.ReadCloser:
+		srv.writeStream(w, req, Stream{
+			Body: val1,
+		})
+		return nil
+	case Attachment:
+		srv.writeAttachment(w, req, val1)
+		return nil
+	case <-chan SSEEvent:
+		srv.writeSSE(w, req, val1)
+		return nil
+	case Multipart:
+		return writeMultipart(w, val1)
+	case Proxy:
+		return srv.writeProxy(w, req, val1)
+	case EarlyHints:
+		WriteEarlyHints(w, val1.Links...)
+		return srv.writeResult(w, req, val1.Body)
+	}
+	if v := reflect.ValueOf(val); isRecvChan(v) {
+		srv.writeNDJSON(w, req, v)
+		return nil
+	}
+	if srv.NoContentForNil && isNil(val) {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	status := http.StatusOK
+	if statusCoder, ok := val.(StatusCoder); ok {
+		if code := statusCoder.StatusCode(); code != 0 {
+			status = code
+		}
+	}
+	if status == http.StatusOK && writeNotModified(w, req, val) {
+		return nil
+	}
+	return srv.writeEnveloped(req.Context(), w, status, val, false)
+}
+
 // WriteJSON writes the given value to the ResponseWriter
 // and sets the HTTP status to the given code.
 //
@@ -559,20 +1354,7 @@ func (srv *Server) WriteError(ctx context.Context, w http.ResponseWriter, err er
 // has been added, so can be used to override the content type
 // if required.
 func WriteJSON(w http.ResponseWriter, code int, val interface{}) error {
-	// TODO consider marshalling directly to w using json.NewEncoder.
-	// pro: this will not require a full buffer allocation.
-	// con: if there's an error after the first write, it will be lost.
-	data, err := json.Marshal(val)
-	if err != nil {
-		return errgo.Mask(err)
-	}
-	w.Header().Set("content-type", "application/json")
-	if headerSetter, ok := val.(HeaderSetter); ok {
-		headerSetter.SetHeader(w.Header())
-	}
-	w.WriteHeader(code)
-	w.Write(data)
-	return nil
+	return writeEncoded(w, &JSONCodec, code, val)
 }
 
 // HeaderSetter is the interface checked for by WriteJSON.
@@ -601,12 +1383,123 @@ func (h CustomHeader) MarshalJSON() ([]byte, error) {
 	return json.Marshal(h.Body)
 }
 
+// MarshalXML implements xml.Marshaler by marshaling
+// h.Body.
+func (h CustomHeader) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(h.Body)
+}
+
 // SetHeader implements HeaderSetter by calling
 // h.SetHeaderFunc.
 func (h CustomHeader) SetHeader(header http.Header) {
 	h.SetHeaderFunc(header)
 }
 
+// StatusCoder may be implemented by a value returned from a
+// handler to choose the HTTP status of a successful response,
+// which is otherwise http.StatusOK. A zero status is also
+// treated as http.StatusOK.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// CustomStatus is a type that allows a JSON value
+// returned from a handler to specify the HTTP status
+// of the response.
+type CustomStatus struct {
+	// Status holds the HTTP status code of the response.
+	// If it is zero, http.StatusOK is used.
+	Status int
+
+	// Body holds the JSON-marshaled body of the response.
+	// If it implements HeaderSetter, its SetHeader method
+	// will be called to set any custom headers on the response.
+	Body interface{}
+}
+
+// MarshalJSON implements json.Marshaler by marshaling
+// s.Body.
+func (s CustomStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Body)
+}
+
+// MarshalXML implements xml.Marshaler by marshaling
+// s.Body.
+func (s CustomStatus) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(s.Body)
+}
+
+// StatusCode implements StatusCoder by returning s.Status.
+func (s CustomStatus) StatusCode() int {
+	return s.Status
+}
+
+// SetHeader implements HeaderSetter by calling
+// s.Body.SetHeader if it is implemented.
+func (s CustomStatus) SetHeader(header http.Header) {
+	if headerSetter, ok := s.Body.(HeaderSetter); ok {
+		headerSetter.SetHeader(header)
+	}
+}
+
+// NoContent is a type that can be returned from a handler to cause
+// a response with status http.StatusNoContent and an empty body.
+type NoContent struct{}
+
+// isNil reports whether val is nil or holds a nil pointer.
+func isNil(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// Created is a type that can be returned from a handler to indicate
+// that a resource has been created. It causes a response with status
+// http.StatusCreated and a Location header referring to the new
+// resource. RouteURL can be used to determine the location from the
+// request type used to retrieve the resource.
+type Created struct {
+	// Location holds the URL of the created resource.
+	// If it is empty, no Location header is set.
+	Location string
+
+	// Body holds the JSON-marshaled body of the response.
+	// If it implements HeaderSetter, its SetHeader method
+	// will be called to set any custom headers on the response.
+	Body interface{}
+}
+
+// MarshalJSON implements json.Marshaler by marshaling
+// c.Body.
+func (c Created) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Body)
+}
+
+// MarshalXML implements xml.Marshaler by marshaling
+// c.Body.
+func (c Created) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(c.Body)
+}
+
+// StatusCode implements StatusCoder by returning
+// http.StatusCreated.
+func (c Created) StatusCode() int {
+	return http.StatusCreated
+}
+
+// SetHeader implements HeaderSetter by setting the Location header
+// and calling c.Body.SetHeader if it is implemented.
+func (c Created) SetHeader(header http.Header) {
+	if c.Location != "" {
+		header.Set("Location", c.Location)
+	}
+	if headerSetter, ok := c.Body.(HeaderSetter); ok {
+		headerSetter.SetHeader(header)
+	}
+}
+
 // Ensure statically that responseWriter does implement http.Flusher.
 var _ http.Flusher = (*responseWriter)(nil)
 
@@ -623,7 +1516,9 @@ func (w *responseWriter) Write(data []byte) (int, error) {
 }
 
 func (w *responseWriter) WriteHeader(code int) {
-	w.headerWritten = true
+	if code >= 200 {
+		w.headerWritten = true
+	}
 	w.ResponseWriter.WriteHeader(code)
 }
 
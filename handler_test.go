@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -28,14 +29,15 @@ type customError struct {
 }
 
 var handleTests = []struct {
-	about        string
-	f            func(c *qt.C) interface{}
-	req          *http.Request
-	pathVar      httprouter.Params
-	expectMethod string
-	expectPath   string
-	expectBody   interface{}
-	expectStatus int
+	about          string
+	f              func(c *qt.C) interface{}
+	req            *http.Request
+	pathVar        httprouter.Params
+	expectMethod   string
+	expectPath     string
+	expectMetadata httprequest.RouteMetadata
+	expectBody     interface{}
+	expectStatus   int
 }{{
 	about: "function with no return",
 	f: func(c *qt.C) interface{} {
@@ -374,8 +376,73 @@ var handleTests = []struct {
 	}},
 	expectMethod: "GET",
 	expectPath:   "/foo/:bar",
+}, {
+	about: "argument with route metadata",
+	f: func(c *qt.C) interface{} {
+		type testStruct struct {
+			httprequest.Route `httprequest:"POST /users" name:"CreateUser" summary:"Creates a user." tags:"users, admin"`
+		}
+		return func(p httprequest.Params, s *testStruct) {
+			c.Assert(p.Metadata, qt.DeepEquals, httprequest.RouteMetadata{
+				Name:    "CreateUser",
+				Summary: "Creates a user.",
+				Tags:    []string{"users", "admin"},
+			})
+		}
+	},
+	req:          &http.Request{},
+	expectMethod: "POST",
+	expectPath:   "/users",
+	expectMetadata: httprequest.RouteMetadata{
+		Name:    "CreateUser",
+		Summary: "Creates a user.",
+		Tags:    []string{"users", "admin"},
+	},
+}, {
+	about: "function returning CustomStatus",
+	f: func(c *qt.C) interface{} {
+		return func(s *struct{}) (httprequest.CustomStatus, error) {
+			return httprequest.CustomStatus{
+				Status: http.StatusCreated,
+				Body:   "created",
+			}, nil
+		}
+	},
+	req:          &http.Request{},
+	expectBody:   "created",
+	expectStatus: http.StatusCreated,
+}, {
+	about: "function returning CustomStatus with zero status",
+	f: func(c *qt.C) interface{} {
+		return func(s *struct{}) (httprequest.CustomStatus, error) {
+			return httprequest.CustomStatus{
+				Body: "ok",
+			}, nil
+		}
+	},
+	req:          &http.Request{},
+	expectBody:   "ok",
+	expectStatus: http.StatusOK,
+}, {
+	about: "function returning value implementing StatusCoder",
+	f: func(c *qt.C) interface{} {
+		return func(s *struct{}) (acceptedResult, error) {
+			return acceptedResult{ID: 99}, nil
+		}
+	},
+	req:          &http.Request{},
+	expectBody:   acceptedResult{ID: 99},
+	expectStatus: http.StatusAccepted,
 }}
 
+type acceptedResult struct {
+	ID int
+}
+
+func (acceptedResult) StatusCode() int {
+	return http.StatusAccepted
+}
+
 func TestHandle(t *testing.T) {
 	c := qt.New(t)
 
@@ -385,6 +452,7 @@ func TestHandle(t *testing.T) {
 			h := testServer.Handle(test.f(c))
 			c.Assert(h.Method, qt.Equals, test.expectMethod)
 			c.Assert(h.Path, qt.Equals, test.expectPath)
+			c.Assert(h.Metadata, qt.DeepEquals, test.expectMetadata)
 			rec := httptest.NewRecorder()
 			h.Handle(rec, test.req, test.pathVar)
 			if test.expectStatus == 0 {
@@ -444,8 +512,98 @@ var handlePanicTests = []struct {
 	expect: `bad handler function: last argument cannot be used for Unmarshal: bad tag "httprequest:\\"a,the-ether\\"" in field A: unknown tag flag "the-ether"`,
 }, {
 	name:   "too-many-results",
-	f:      func(httprequest.Params, *struct{}) (a, b, c struct{}) { return },
-	expect: `bad handler function: has 3 result parameters, need 0, 1 or 2`,
+	f:      func(httprequest.Params, *struct{}) (a, b, c, d struct{}) { return },
+	expect: `bad handler function: has 4 result parameters, need 0, 1, 2 or 3`,
+}, {
+	name:   "bad-header-result",
+	f:      func(httprequest.Params, *struct{}) (a struct{}, h map[string]string, err error) { return },
+	expect: `bad handler function: second result parameter is map\[string\]string, need http.Header`,
+}, {
+	name: "bad-timeout-tag",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" timeout:"soon"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"GET /foo\\" timeout:\\"soon\\"": bad timeout tag "soon"`,
+}, {
+	name: "bad-idempotent-tag",
+	f: func(*struct {
+		httprequest.Route `httprequest:"POST /foo" idempotent:"maybe"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"POST /foo\\" idempotent:\\"maybe\\"": bad idempotent tag "maybe"`,
+}, {
+	name: "bad-mergepatch-type",
+	f: func(*struct {
+		httprequest.Route `httprequest:"PATCH /foo"`
+		Body              map[string]interface{} `httprequest:",body,mergepatch"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: mergepatch body field has type map\[string\]interface \{\}, need httprequest.MergePatch`,
+}, {
+	name: "mergepatch-without-body",
+	f: func(*struct {
+		httprequest.Route `httprequest:"PATCH /foo"`
+		A                 string `httprequest:"a,form,mergepatch"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad tag "httprequest:\\"a,form,mergepatch\\"" in field A: can only use mergepatch or jsonpatch with body field`,
+}, {
+	name: "bad-multipart-type",
+	f: func(*struct {
+		httprequest.Route `httprequest:"POST /foo"`
+		Upload            multipart.Reader `httprequest:",multipart"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: multipart field Upload has type multipart.Reader, need \*multipart.Reader`,
+}, {
+	name: "multipart-with-inbody",
+	f: func(*struct {
+		httprequest.Route `httprequest:"POST /foo"`
+		Upload            *multipart.Reader `httprequest:",multipart"`
+		A                 string            `httprequest:"a,form,inbody"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: cannot specify multipart field with a body or inbody field`,
+}, {
+	name: "bad-version-tag",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" version:"2/beta"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"GET /foo\\" version:\\"2/beta\\"": bad version tag "2/beta"`,
+}, {
+	name: "bad-maxconcurrent-tag",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" maxconcurrent:"0"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"GET /foo\\" maxconcurrent:\\"0\\"": bad maxconcurrent tag "0"`,
+}, {
+	name: "maxconcurrent-with-concurrencygroup",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" maxconcurrent:"2" concurrencygroup:"reports"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"GET /foo\\" maxconcurrent:\\"2\\" concurrencygroup:\\"reports\\"": cannot specify both maxconcurrent and concurrencygroup tags`,
+}, {
+	name: "unknown-concurrencygroup",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" concurrencygroup:"reports"`
+	}) {
+	},
+	expect: `GET /foo: unknown concurrency group "reports"`,
+}, {
+	name: "bad-cache-tag",
+	f: func(*struct {
+		httprequest.Route `httprequest:"GET /foo" cache:"max-age=forever"`
+	}) {
+	},
+	expect: `bad handler function: last argument cannot be used for Unmarshal: bad route tag "httprequest:\\"GET /foo\\" cache:\\"max-age=forever\\"": bad cache tag: invalid directive "max-age=forever"`,
+}, {
+	name:   "bidirectional-sse-channel",
+	f:      func(*struct{}) (chan httprequest.SSEEvent, error) { return nil, nil },
+	expect: `bad handler function: first result parameter is chan httprequest.SSEEvent, need <-chan httprequest.SSEEvent`,
 }, {
 	name: "no-route-tag",
 	f: func(*struct {
@@ -548,18 +706,23 @@ func TestHandlers(t *testing.T) {
 	for i := range handlers1 {
 		handlers1[i].Handle = nil
 	}
+	pathParams := []httprequest.ParamInfo{{Name: "p", In: "path", Type: "integer"}}
 	expectHandlers := []httprequest.Handler{{
 		Method: "GET",
 		Path:   "/m1/:p",
+		Params: pathParams,
 	}, {
 		Method: "GET",
 		Path:   "/m2/:p",
+		Params: pathParams,
 	}, {
 		Method: "GET",
 		Path:   "/m3/:p",
+		Params: pathParams,
 	}, {
 		Method: "POST",
 		Path:   "/m3/:p",
+		Params: pathParams,
 	}}
 	c.Assert(handlers1, qt.DeepEquals, expectHandlers)
 	c.Assert(handlersTests, qt.HasLen, len(expectHandlers))
@@ -1391,3 +1554,335 @@ func parseErrorResponse(c *qt.C, body []byte) *httprequest.RemoteError {
 	c.Assert(err, qt.Equals, nil)
 	return errResp
 }
+
+func TestNewRouter(t *testing.T) {
+	c := qt.New(t)
+
+	router := testServer.NewRouter(testServer.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/items/1",
+		Handler:    router,
+		ExpectBody: "ok",
+	})
+	// The server's error mapper is used for errors from the
+	// router itself; testErrorMapper maps unknown errors
+	// to http.StatusInternalServerError.
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:          "/other",
+		Handler:      router,
+		ExpectStatus: http.StatusInternalServerError,
+		ExpectBody: &httprequest.RemoteError{
+			Message: "no handler for GET /other",
+		},
+	})
+}
+
+func TestNewRouterWithDefaultErrorMapper(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		Method:       "PUT",
+		URL:          "/items/1",
+		Handler:      router,
+		ExpectStatus: http.StatusMethodNotAllowed,
+		ExpectBody: &httprequest.RemoteError{
+			Message: "method PUT not allowed for /items/1",
+			Code:    httprequest.CodeMethodNotAllowed,
+		},
+		ExpectHeader: http.Header{
+			"Allow": {"DELETE, GET, OPTIONS"},
+		},
+	})
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:          "/other",
+		Handler:      router,
+		ExpectStatus: http.StatusNotFound,
+		ExpectBody: &httprequest.RemoteError{
+			Message: "no handler for GET /other",
+			Code:    httprequest.CodeNotFound,
+		},
+	})
+}
+
+func TestHandlersWithPrefix(t *testing.T) {
+	c := qt.New(t)
+
+	handleVal := testHandlers{
+		c: c,
+	}
+	handlers := testServer.HandlersWithPrefix("/v1/", func(p httprequest.Params) (*testHandlers, context.Context, error) {
+		handleVal.p = p
+		return &handleVal, p.Context, nil
+	})
+	var paths []string
+	for _, h := range handlers {
+		paths = append(paths, h.Method+" "+h.Path)
+	}
+	c.Assert(paths, qt.DeepEquals, []string{
+		"GET /v1/m1/:p",
+		"GET /v1/m2/:p",
+		"GET /v1/m3/:p",
+		"POST /v1/m3/:p",
+	})
+	router := httprouter.New()
+	httprequest.AddHandlers(router, handlers)
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/v1/m2/99",
+		Handler:    router,
+		ExpectBody: 999,
+	})
+	c.Assert(handleVal.calledMethod, qt.Equals, "M2")
+	c.Assert(handleVal.p.PathPattern, qt.Equals, "/v1/m2/:p")
+}
+
+func TestHandlersWithPrefixPanicsWithBadPrefix(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(func() {
+		testServer.HandlersWithPrefix("v1", func(p httprequest.Params) (*testHandlers, context.Context, error) {
+			return nil, p.Context, nil
+		})
+	}, qt.PanicMatches, `path prefix "v1" does not start with /`)
+}
+
+func TestHandlersFromAll(t *testing.T) {
+	c := qt.New(t)
+
+	handleVal := testHandlers{
+		c: c,
+	}
+	handlers := testServer.HandlersFromAll(
+		func(p httprequest.Params) (*testHandlers, context.Context, error) {
+			handleVal.p = p
+			return &handleVal, p.Context, nil
+		},
+		func(p httprequest.Params) (observeHandlers, context.Context, error) {
+			return observeHandlers{}, p.Context, nil
+		},
+	)
+	c.Assert(handlers, qt.HasLen, 6)
+	router := httprouter.New()
+	httprequest.AddHandlers(router, handlers)
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/m2/99",
+		Handler:    router,
+		ExpectBody: 999,
+	})
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/items/1",
+		Handler:    router,
+		ExpectBody: "ok",
+	})
+}
+
+func TestHandlersFromAllPanicsWithDuplicateRoutes(t *testing.T) {
+	c := qt.New(t)
+
+	f := func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}
+	c.Assert(func() {
+		testServer.HandlersFromAll(f, f)
+	}, qt.PanicMatches, `DELETE /items/:id defined by httprequest_test.observeHandlers.Delete conflicts with DELETE /items/:id defined by httprequest_test.observeHandlers.Delete`)
+}
+
+type renamedItemHandlers struct{}
+
+func (renamedItemHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /items/:name"`
+}) {
+}
+
+func TestHandlersFromAllPanicsWithRenamedParameters(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(func() {
+		testServer.HandlersFromAll(
+			func(p httprequest.Params) (observeHandlers, context.Context, error) {
+				return observeHandlers{}, p.Context, nil
+			},
+			func(p httprequest.Params) (*renamedItemHandlers, context.Context, error) {
+				return &renamedItemHandlers{}, p.Context, nil
+			},
+		)
+	}, qt.PanicMatches, `GET /items/:name defined by \(\*httprequest_test.renamedItemHandlers\).Get conflicts with GET /items/:id defined by httprequest_test.observeHandlers.Get`)
+}
+
+func TestCustomStatusWithCustomHeader(t *testing.T) {
+	c := qt.New(t)
+
+	rec := httptest.NewRecorder()
+	h := testServer.HandleJSON(func(p httprequest.Params) (interface{}, error) {
+		return httprequest.CustomStatus{
+			Status: http.StatusCreated,
+			Body:   HeaderNumber{1234},
+		}, nil
+	})
+	h(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusCreated)
+	c.Assert(rec.Body.String(), qt.Equals, `{"N":1234}`)
+	c.Assert(rec.Header().Get("some-custom-header"), qt.Equals, "yes")
+}
+
+func TestCreated(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct {
+		httprequest.Route `httprequest:"POST /users"`
+	}) (httprequest.Created, error) {
+		loc, err := httprequest.RouteURL(&struct {
+			httprequest.Route `httprequest:"GET /users/:id"`
+			ID                string `httprequest:"id,path"`
+		}{
+			ID: "bob",
+		})
+		if err != nil {
+			return httprequest.Created{}, err
+		}
+		return httprequest.Created{
+			Location: loc,
+			Body:     HeaderNumber{1},
+		}, nil
+	})
+	router := httprouter.New()
+	router.Handle(h.Method, h.Path, h.Handle)
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		Method:       "POST",
+		URL:          "/users",
+		Handler:      router,
+		ExpectStatus: http.StatusCreated,
+		ExpectBody:   HeaderNumber{1},
+		ExpectHeader: http.Header{
+			"Location":           {"/users/bob"},
+			"Some-Custom-Header": {"yes"},
+		},
+	})
+}
+
+var noContentTests = []struct {
+	about        string
+	srv          httprequest.Server
+	result       interface{}
+	expectStatus int
+	expectBody   string
+}{{
+	about:        "NoContent result",
+	result:       httprequest.NoContent{},
+	expectStatus: http.StatusNoContent,
+}, {
+	about:        "nil result",
+	result:       nil,
+	expectStatus: http.StatusOK,
+	expectBody:   "null",
+}, {
+	about: "nil result with NoContentForNil",
+	srv: httprequest.Server{
+		NoContentForNil: true,
+	},
+	result:       nil,
+	expectStatus: http.StatusNoContent,
+}, {
+	about: "nil pointer result with NoContentForNil",
+	srv: httprequest.Server{
+		NoContentForNil: true,
+	},
+	result:       (*HeaderNumber)(nil),
+	expectStatus: http.StatusNoContent,
+}, {
+	about: "non-nil result with NoContentForNil",
+	srv: httprequest.Server{
+		NoContentForNil: true,
+	},
+	result:       []int{},
+	expectStatus: http.StatusOK,
+	expectBody:   "[]",
+}}
+
+func TestNoContent(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range noContentTests {
+		c.Run(test.about, func(c *qt.C) {
+			h := test.srv.HandleJSON(func(p httprequest.Params) (interface{}, error) {
+				return test.result, nil
+			})
+			rec := httptest.NewRecorder()
+			h(rec, &http.Request{}, nil)
+			c.Assert(rec.Code, qt.Equals, test.expectStatus)
+			c.Assert(rec.Body.String(), qt.Equals, test.expectBody)
+		})
+	}
+}
+
+type duplicateRouteHandlers struct{}
+
+func (duplicateRouteHandlers) Fetch(*struct {
+	httprequest.Route `httprequest:"GET /things/:name"`
+}) {
+}
+
+func (duplicateRouteHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /things/:id"`
+}) {
+}
+
+type overlappingRouteHandlers struct{}
+
+func (overlappingRouteHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /things/:id"`
+}) {
+}
+
+func (overlappingRouteHandlers) New(*struct {
+	httprequest.Route `httprequest:"GET /things/new"`
+}) {
+}
+
+func (overlappingRouteHandlers) Put(*struct {
+	httprequest.Route `httprequest:"PUT /things/new"`
+}) {
+}
+
+func TestHandlersPanicsWithConflictingRoutes(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(func() {
+		testServer.Handlers(func(p httprequest.Params) (duplicateRouteHandlers, context.Context, error) {
+			return duplicateRouteHandlers{}, p.Context, nil
+		})
+	}, qt.PanicMatches, `GET /things/:id defined by httprequest_test.duplicateRouteHandlers.Get conflicts with GET /things/:name defined by httprequest_test.duplicateRouteHandlers.Fetch`)
+	c.Assert(func() {
+		testServer.Handlers(func(p httprequest.Params) (overlappingRouteHandlers, context.Context, error) {
+			return overlappingRouteHandlers{}, p.Context, nil
+		})
+	}, qt.PanicMatches, `GET /things/new defined by httprequest_test.overlappingRouteHandlers.New conflicts with GET /things/:id defined by httprequest_test.overlappingRouteHandlers.Get`)
+}
+
+var pathPatternsConflictTests = []struct {
+	p1, p2 string
+	expect bool
+}{
+	{"/a/b", "/a/b", true},
+	{"/a/:x", "/a/:y", true},
+	{"/a/:x", "/a/b", true},
+	{"/a/*x", "/a/b/c", true},
+	{"/a/:x/b", "/a/:x/c", false},
+	{"/a/:x", "/a/:x/b", false},
+	{"/a/b", "/a/c", false},
+	{"/a", "/a/", false},
+}
+
+func TestPathPatternsConflict(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range pathPatternsConflictTests {
+		c.Check(httprequest.PathPatternsConflict(test.p1, test.p2), qt.Equals, test.expect, qt.Commentf("%s %s", test.p1, test.p2))
+		c.Check(httprequest.PathPatternsConflict(test.p2, test.p1), qt.Equals, test.expect, qt.Commentf("%s %s", test.p2, test.p1))
+	}
+}
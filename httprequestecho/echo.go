@@ -0,0 +1,99 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package httprequestecho allows handlers created by
+// httprequest.Server to be served by the Echo web framework.
+package httprequestecho
+
+import (
+	"context"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/labstack/echo/v4"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// Router is the interface used by AddHandlers to register handlers.
+// It is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	Add(method, path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// AddHandlers registers all the given handlers on r.
+//
+// The httprouter path pattern of each handler is translated to the
+// syntax used by Echo, which differs only in that a trailing wildcard
+// element is unnamed, so "*name" becomes "*".
+func AddHandlers(r Router, hs []httprequest.Handler) {
+	for _, h := range hs {
+		path, _ := echoPath(h.Path)
+		r.Add(h.Method, path, HandlerFunc(h))
+	}
+}
+
+// HandlerFunc returns an Echo handler function that calls h. The
+// handler should be registered with Echo using the path returned by
+// Path.
+//
+// The path parameters found by Echo are passed to h as
+// httprequest.Params.PathVar, and the Echo context is made available
+// to h through the request context (see FromContext). As with
+// httprouter, the value of a trailing wildcard parameter always starts
+// with a slash.
+//
+// The returned function always returns a nil error because h
+// writes its own error responses.
+func HandlerFunc(h httprequest.Handler) echo.HandlerFunc {
+	_, wildcard := echoPath(h.Path)
+	return func(c echo.Context) error {
+		var p httprouter.Params
+		if names := c.ParamNames(); len(names) > 0 {
+			values := c.ParamValues()
+			p = make(httprouter.Params, len(names))
+			for i, name := range names {
+				val := values[i]
+				if name == "*" {
+					name, val = wildcard, "/"+val
+				}
+				p[i] = httprouter.Param{
+					Key:   name,
+					Value: val,
+				}
+			}
+		}
+		req := c.Request()
+		req = req.WithContext(context.WithValue(req.Context(), echoContextKey{}, c))
+		h.Handle(c.Response(), req, p)
+		return nil
+	}
+}
+
+// Path returns the Echo equivalent of the given httprouter path
+// pattern.
+func Path(path string) string {
+	path, _ = echoPath(path)
+	return path
+}
+
+// echoPath returns the Echo equivalent of the given httprouter
+// path pattern and the name of its trailing wildcard element,
+// if any.
+func echoPath(path string) (string, string) {
+	i := strings.LastIndex(path, "/*")
+	if i == -1 {
+		return path, ""
+	}
+	return path[:i+2], path[i+2:]
+}
+
+type echoContextKey struct{}
+
+// FromContext returns the Echo context associated with the given
+// request context by a handler returned from HandlerFunc. It reports
+// whether one was found.
+func FromContext(ctx context.Context) (echo.Context, bool) {
+	c, ok := ctx.Value(echoContextKey{}).(echo.Context)
+	return c, ok
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequestecho_test
+
+import (
+	"context"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/labstack/echo/v4"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/httprequestecho"
+)
+
+type handlers struct{}
+
+type repoResult struct {
+	User        string
+	Repo        string
+	Path        string
+	EchoPath    string
+	PathPattern string
+}
+
+func (handlers) Repo(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /users/:user/repos/:repo/*path"`
+	User              string `httprequest:"user,path"`
+	Repo              string `httprequest:"repo,path"`
+	Path              string `httprequest:"path,path"`
+}) (*repoResult, error) {
+	c, ok := httprequestecho.FromContext(p.Context)
+	if !ok {
+		return nil, httprequest.Errorf("", "no echo context")
+	}
+	return &repoResult{
+		User:        arg.User,
+		Repo:        arg.Repo,
+		Path:        arg.Path,
+		EchoPath:    c.Path(),
+		PathPattern: p.PathPattern,
+	}, nil
+}
+
+func newHandlers() []httprequest.Handler {
+	var srv httprequest.Server
+	return srv.Handlers(func(p httprequest.Params) (handlers, context.Context, error) {
+		return handlers{}, p.Context, nil
+	})
+}
+
+var addHandlersTests = []struct {
+	about      string
+	url        string
+	expectBody repoResult
+}{{
+	about: "several parameters and a wildcard",
+	url:   "/v1/users/bob/repos/proj/a/b",
+	expectBody: repoResult{
+		User:        "bob",
+		Repo:        "proj",
+		Path:        "/a/b",
+		EchoPath:    "/v1/users/:user/repos/:repo/*",
+		PathPattern: "/users/:user/repos/:repo/*path",
+	},
+}, {
+	about: "empty wildcard",
+	url:   "/v1/users/bob/repos/proj/",
+	expectBody: repoResult{
+		User:        "bob",
+		Repo:        "proj",
+		Path:        "/",
+		EchoPath:    "/v1/users/:user/repos/:repo/*",
+		PathPattern: "/users/:user/repos/:repo/*path",
+	},
+}}
+
+func TestAddHandlersWithGroup(t *testing.T) {
+	c := qt.New(t)
+
+	e := echo.New()
+	httprequestecho.AddHandlers(e.Group("/v1"), newHandlers())
+	for _, test := range addHandlersTests {
+		c.Run(test.about, func(c *qt.C) {
+			qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+				URL:        test.url,
+				Handler:    e,
+				ExpectBody: test.expectBody,
+			})
+		})
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	c := qt.New(t)
+
+	e := echo.New()
+	for _, h := range newHandlers() {
+		e.Add(h.Method, httprequestecho.Path(h.Path), httprequestecho.HandlerFunc(h))
+	}
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:     "/users/alice/repos/x/y",
+		Handler: e,
+		ExpectBody: repoResult{
+			User:        "alice",
+			Repo:        "x",
+			Path:        "/y",
+			EchoPath:    "/users/:user/repos/:repo/*",
+			PathPattern: "/users/:user/repos/:repo/*path",
+		},
+	})
+}
+
+var pathTests = []struct {
+	path   string
+	expect string
+}{{
+	path:   "/a/:b/c",
+	expect: "/a/:b/c",
+}, {
+	path:   "/a/:b/*rest",
+	expect: "/a/:b/*",
+}, {
+	path:   "/*rest",
+	expect: "/*",
+}}
+
+func TestPath(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range pathTests {
+		c.Assert(httprequestecho.Path(test.path), qt.Equals, test.expect, qt.Commentf("path %q", test.path))
+	}
+}
+
+func TestFromContextWithoutEcho(t *testing.T) {
+	c := qt.New(t)
+
+	_, ok := httprequestecho.FromContext(context.Background())
+	c.Assert(ok, qt.Equals, false)
+}
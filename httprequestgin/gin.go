@@ -0,0 +1,57 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package httprequestgin allows handlers created by
+// httprequest.Server to be served by the gin web framework.
+package httprequestgin
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// AddHandlers registers all the given handlers on r.
+//
+// Gin uses the same path pattern syntax as httprouter, so the
+// handler paths are used unchanged.
+func AddHandlers(r gin.IRoutes, hs []httprequest.Handler) {
+	for _, h := range hs {
+		r.Handle(h.Method, h.Path, HandlerFunc(h))
+	}
+}
+
+// HandlerFunc returns a gin handler function that calls h.
+//
+// The path parameters found by gin are passed to h as
+// httprequest.Params.PathVar, and the gin context is made available
+// to h through the request context (see FromContext).
+func HandlerFunc(h httprequest.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var p httprouter.Params
+		if len(c.Params) > 0 {
+			p = make(httprouter.Params, len(c.Params))
+			for i, param := range c.Params {
+				p[i] = httprouter.Param{
+					Key:   param.Key,
+					Value: param.Value,
+				}
+			}
+		}
+		req := c.Request.WithContext(context.WithValue(c.Request.Context(), ginContextKey{}, c))
+		h.Handle(c.Writer, req, p)
+	}
+}
+
+type ginContextKey struct{}
+
+// FromContext returns the gin context associated with
+// the given request context by a handler returned from
+// HandlerFunc. It reports whether one was found.
+func FromContext(ctx context.Context) (*gin.Context, bool) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c, ok
+}
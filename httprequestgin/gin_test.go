@@ -0,0 +1,85 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequestgin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/gin-gonic/gin"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/httprequestgin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type handlers struct{}
+
+func (handlers) Get(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /items/:id"`
+	ID                int `httprequest:"id,path"`
+}) (interface{}, error) {
+	c, ok := httprequestgin.FromContext(p.Context)
+	if !ok {
+		return nil, httprequest.Errorf("", "no gin context")
+	}
+	return map[string]interface{}{
+		"ID":          arg.ID,
+		"FullPath":    c.FullPath(),
+		"PathPattern": p.PathPattern,
+	}, nil
+}
+
+func (handlers) File(arg *struct {
+	httprequest.Route `httprequest:"GET /files/*path"`
+	Path              string `httprequest:"path,path"`
+}) (string, error) {
+	return arg.Path, nil
+}
+
+func (handlers) Delete(arg *struct {
+	httprequest.Route `httprequest:"DELETE /items/:id"`
+}) error {
+	return httprequest.Errorf(httprequest.CodeForbidden, "cannot delete")
+}
+
+func TestAddHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	engine := gin.New()
+	httprequestgin.AddHandlers(engine, srv.Handlers(func(p httprequest.Params) (handlers, context.Context, error) {
+		return handlers{}, p.Context, nil
+	}))
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:     "/items/99",
+		Handler: engine,
+		ExpectBody: map[string]interface{}{
+			"ID":          99,
+			"FullPath":    "/items/:id",
+			"PathPattern": "/items/:id",
+		},
+	})
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/files/a/b",
+		Handler:    engine,
+		ExpectBody: "/a/b",
+	})
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		Method:       "DELETE",
+		URL:          "/items/99",
+		Handler:      engine,
+		ExpectStatus: http.StatusForbidden,
+		ExpectBody: &httprequest.RemoteError{
+			Code:    httprequest.CodeForbidden,
+			Message: "cannot delete",
+		},
+	})
+}
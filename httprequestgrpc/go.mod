@@ -0,0 +1,25 @@
+module gopkg.in/httprequest.v1/httprequestgrpc
+
+go 1.22.0
+
+require (
+	github.com/frankban/quicktest v1.10.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250115164207-1a7da9e5054f
+	google.golang.org/grpc v1.71.1
+	google.golang.org/protobuf v1.36.4
+	gopkg.in/errgo.v1 v1.0.0
+	gopkg.in/httprequest.v1 v1.2.1
+)
+
+require (
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/kr/pretty v0.2.0 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+
+// Build against the httprequest package in the parent directory.
+replace gopkg.in/httprequest.v1 => ../
@@ -0,0 +1,341 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package httprequestgrpc translates errors between httprequest and
+// gRPC, so that gateways between services built with httprequest and
+// gRPC services report failures consistently in both directions.
+//
+// An error with a RemoteError code is converted to a gRPC status by
+// ToStatus, which records the code, any field errors and any wrapped
+// errors (see httprequest.Server.SerializeErrorCauses) in the status
+// details, so that FromStatus can restore them.
+package httprequestgrpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// ErrorDomain holds the domain of the errdetails.ErrorInfo details
+// that hold RemoteError codes in the statuses returned by ToStatus.
+const ErrorDomain = "httprequest"
+
+// Metadata keys used in the errdetails.ErrorInfo details.
+const (
+	codeKey    = "code"
+	messageKey = "message"
+)
+
+// grpcCodes maps RemoteError codes to gRPC codes.
+var grpcCodes = map[string]codes.Code{
+	httprequest.CodeBadRequest:           codes.InvalidArgument,
+	httprequest.CodeUnauthorized:         codes.Unauthenticated,
+	httprequest.CodeForbidden:            codes.PermissionDenied,
+	httprequest.CodeNotFound:             codes.NotFound,
+	httprequest.CodeMethodNotAllowed:     codes.Unimplemented,
+	httprequest.CodeRequestTooLarge:      codes.ResourceExhausted,
+	httprequest.CodeTimeout:              codes.DeadlineExceeded,
+	httprequest.CodeTooManyRequests:      codes.ResourceExhausted,
+	httprequest.CodeServiceUnavailable:   codes.Unavailable,
+	httprequest.CodeBadGateway:           codes.Unavailable,
+	httprequest.CodeUnsupportedMediaType: codes.InvalidArgument,
+	httprequest.CodeNotAcceptable:        codes.InvalidArgument,
+	httprequest.CodeNotImplemented:       codes.Unimplemented,
+}
+
+// errorCodes maps gRPC codes to RemoteError codes.
+var errorCodes = map[codes.Code]string{
+	codes.InvalidArgument:   httprequest.CodeBadRequest,
+	codes.Unauthenticated:   httprequest.CodeUnauthorized,
+	codes.PermissionDenied:  httprequest.CodeForbidden,
+	codes.NotFound:          httprequest.CodeNotFound,
+	codes.ResourceExhausted: httprequest.CodeTooManyRequests,
+	codes.DeadlineExceeded:  httprequest.CodeTimeout,
+	codes.Unavailable:       httprequest.CodeServiceUnavailable,
+	codes.Unimplemented:     httprequest.CodeNotImplemented,
+}
+
+// httpStatuses maps gRPC codes to HTTP statuses, as specified by
+// google.rpc.Code.
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// GRPCCode returns the gRPC code corresponding to the given
+// RemoteError code. Codes without a direct equivalent, such as those
+// registered with httprequest.RegisterErrorCode, are mapped using
+// their registered HTTP status (see FromHTTPStatus). Other codes map
+// to codes.Unknown.
+func GRPCCode(code string) codes.Code {
+	if c, ok := grpcCodes[code]; ok {
+		return c
+	}
+	if status, ok := httprequest.ErrorCodeStatus(code); ok {
+		return FromHTTPStatus(status)
+	}
+	return codes.Unknown
+}
+
+// ErrorCode returns the RemoteError code corresponding to the given
+// gRPC code. Codes without a direct equivalent are mapped to the code
+// registered for their HTTP status (see HTTPStatus), if any, or to
+// the empty string otherwise.
+func ErrorCode(c codes.Code) string {
+	if c == codes.OK {
+		return ""
+	}
+	if code, ok := errorCodes[c]; ok {
+		return code
+	}
+	code, _ := httprequest.StatusErrorCode(HTTPStatus(c))
+	return code
+}
+
+// HTTPStatus returns the HTTP status corresponding to the given gRPC
+// code, as specified by google.rpc.Code. Unknown codes map to
+// http.StatusInternalServerError.
+func HTTPStatus(c codes.Code) int {
+	if status, ok := httpStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FromHTTPStatus returns the gRPC code corresponding to the given HTTP
+// status, as specified by google.rpc.Code.
+func FromHTTPStatus(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499:
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	switch {
+	case 200 <= status && status < 300:
+		return codes.OK
+	case 400 <= status && status < 500:
+		return codes.FailedPrecondition
+	case 500 <= status && status < 600:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// ToStatus returns the gRPC status corresponding to err, which is
+// typically returned by a call made with an httprequest.Client or by
+// an httprequest handler. It returns nil if err is nil.
+//
+// If the cause of err (see errgo.Cause) already has a gRPC status,
+// its code and details are used. Otherwise the code is found from the
+// cause's RemoteError code, if it is a *httprequest.RemoteError or
+// implements httprequest.ErrorCoder, and the code, any field errors
+// and any wrapped RemoteErrors are recorded in the status details.
+// The status message is always err.Error().
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	cause := errgo.Cause(err)
+	if s, ok := status.FromError(cause); ok {
+		p := s.Proto()
+		p.Message = err.Error()
+		return status.FromProto(p)
+	}
+	var rerr *httprequest.RemoteError
+	switch cause := cause.(type) {
+	case *httprequest.RemoteError:
+		rerr = cause
+	case httprequest.ErrorCoder:
+		rerr = &httprequest.RemoteError{
+			Code: cause.ErrorCode(),
+		}
+	default:
+		rerr = &httprequest.RemoteError{}
+	}
+	s := status.New(GRPCCode(rerr.Code), err.Error())
+	if rerr.Code == "" && rerr.Wrapped == nil && len(rerr.Fields) == 0 {
+		return s
+	}
+	var details []protoadapt.MessageV1
+	for e := rerr; e != nil; e = e.Wrapped {
+		info := &errdetails.ErrorInfo{
+			Reason: reason(e.Code),
+			Domain: ErrorDomain,
+			Metadata: map[string]string{
+				codeKey: e.Code,
+			},
+		}
+		if e != rerr {
+			info.Metadata[messageKey] = e.Message
+		}
+		details = append(details, info)
+	}
+	if len(rerr.Fields) > 0 {
+		br := new(errdetails.BadRequest)
+		for _, f := range rerr.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      reason(f.Code),
+			})
+		}
+		details = append(details, br)
+	}
+	s1, err := s.WithDetails(details...)
+	if err != nil {
+		// This can only happen if the details cannot be
+		// marshaled, which is not the case for errdetails types.
+		return s
+	}
+	return s1
+}
+
+// FromStatus returns the RemoteError corresponding to s, as returned
+// by a gRPC service. It returns nil if s has code codes.OK.
+//
+// The code, field errors and wrapped errors recorded by ToStatus are
+// restored if they are present in the status details; otherwise the
+// code is found from the status code with ErrorCode.
+func FromStatus(s *status.Status) *httprequest.RemoteError {
+	if s.Code() == codes.OK {
+		return nil
+	}
+	rerr := &httprequest.RemoteError{
+		Message: s.Message(),
+		Code:    ErrorCode(s.Code()),
+	}
+	tail := rerr
+	found := false
+	for _, d := range s.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			if d.Domain != ErrorDomain {
+				continue
+			}
+			if !found {
+				rerr.Code = d.Metadata[codeKey]
+				found = true
+				continue
+			}
+			tail.Wrapped = &httprequest.RemoteError{
+				Code:    d.Metadata[codeKey],
+				Message: d.Metadata[messageKey],
+			}
+			tail = tail.Wrapped
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				rerr.Fields = append(rerr.Fields, httprequest.FieldError{
+					Field:   v.Field,
+					Code:    code(v.Reason),
+					Message: v.Description,
+				})
+			}
+		}
+	}
+	return rerr
+}
+
+// FromError returns the RemoteError corresponding to err, as returned
+// by a call to a gRPC service, and reports whether err has a gRPC
+// status. It returns nil, false if err is nil.
+func FromError(err error) (*httprequest.RemoteError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	s, ok := status.FromError(errgo.Cause(err))
+	if !ok {
+		return nil, false
+	}
+	return FromStatus(s), true
+}
+
+// ErrorMapper is an httprequest.Server.ErrorMapper for gateways that
+// serve HTTP requests by calling gRPC services. It writes errors with
+// a gRPC status as the RemoteError returned by FromStatus, with the
+// status registered for its code with httprequest.RegisterErrorCode
+// or, if there is none, the status returned by HTTPStatus. Other
+// errors are mapped by httprequest.DefaultErrorMapper.
+func ErrorMapper(ctx context.Context, err error) (int, interface{}) {
+	s, ok := status.FromError(errgo.Cause(err))
+	if !ok || s.Code() == codes.OK {
+		return httprequest.DefaultErrorMapper(ctx, err)
+	}
+	rerr := FromStatus(s)
+	rerr.Message = err.Error()
+	if status, ok := httprequest.ErrorCodeStatus(rerr.Code); ok {
+		return status, rerr
+	}
+	return HTTPStatus(s.Code()), rerr
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor that converts the
+// errors returned by handlers to gRPC statuses with ToStatus, for gRPC
+// services that call services built with httprequest.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// reason returns the errdetails.ErrorInfo reason for the given
+// RemoteError code, such as "NOT_FOUND" for httprequest.CodeNotFound.
+func reason(code string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return '_'
+		}
+		return r
+	}, code))
+}
+
+// code returns the RemoteError code for the given errdetails reason,
+// as returned by reason.
+func code(reason string) string {
+	return strings.ToLower(strings.ReplaceAll(reason, "_", " "))
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequestgrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/httprequestgrpc"
+)
+
+var codeTests = []struct {
+	code     string
+	grpcCode codes.Code
+}{
+	{httprequest.CodeBadRequest, codes.InvalidArgument},
+	{httprequest.CodeUnauthorized, codes.Unauthenticated},
+	{httprequest.CodeForbidden, codes.PermissionDenied},
+	{httprequest.CodeNotFound, codes.NotFound},
+	{httprequest.CodeTimeout, codes.DeadlineExceeded},
+	{httprequest.CodeTooManyRequests, codes.ResourceExhausted},
+	{httprequest.CodeServiceUnavailable, codes.Unavailable},
+	{httprequest.CodeNotImplemented, codes.Unimplemented},
+}
+
+func TestCodes(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range codeTests {
+		c.Check(httprequestgrpc.GRPCCode(test.code), qt.Equals, test.grpcCode, qt.Commentf("%q", test.code))
+		c.Check(httprequestgrpc.ErrorCode(test.grpcCode), qt.Equals, test.code, qt.Commentf("%v", test.grpcCode))
+	}
+	c.Assert(httprequestgrpc.GRPCCode(httprequest.CodeBadGateway), qt.Equals, codes.Unavailable)
+	c.Assert(httprequestgrpc.GRPCCode(""), qt.Equals, codes.Unknown)
+	c.Assert(httprequestgrpc.GRPCCode("no such code"), qt.Equals, codes.Unknown)
+	c.Assert(httprequestgrpc.ErrorCode(codes.OK), qt.Equals, "")
+	c.Assert(httprequestgrpc.ErrorCode(codes.Internal), qt.Equals, "")
+}
+
+func TestGRPCCodeRegisteredCode(t *testing.T) {
+	c := qt.New(t)
+	httprequest.RegisterErrorCode("grpc test conflict", http.StatusConflict)
+	c.Assert(httprequestgrpc.GRPCCode("grpc test conflict"), qt.Equals, codes.Aborted)
+	c.Assert(httprequestgrpc.ErrorCode(codes.AlreadyExists), qt.Equals, "grpc test conflict")
+}
+
+func TestHTTPStatus(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequestgrpc.HTTPStatus(codes.OK), qt.Equals, http.StatusOK)
+	c.Assert(httprequestgrpc.HTTPStatus(codes.NotFound), qt.Equals, http.StatusNotFound)
+	c.Assert(httprequestgrpc.HTTPStatus(codes.FailedPrecondition), qt.Equals, http.StatusBadRequest)
+	c.Assert(httprequestgrpc.HTTPStatus(codes.Code(100)), qt.Equals, http.StatusInternalServerError)
+
+	c.Assert(httprequestgrpc.FromHTTPStatus(http.StatusNoContent), qt.Equals, codes.OK)
+	c.Assert(httprequestgrpc.FromHTTPStatus(http.StatusGatewayTimeout), qt.Equals, codes.DeadlineExceeded)
+	c.Assert(httprequestgrpc.FromHTTPStatus(http.StatusTeapot), qt.Equals, codes.FailedPrecondition)
+	c.Assert(httprequestgrpc.FromHTTPStatus(http.StatusInsufficientStorage), qt.Equals, codes.Internal)
+	for _, code := range []codes.Code{codes.InvalidArgument, codes.NotFound, codes.PermissionDenied, codes.Unavailable, codes.Unimplemented} {
+		c.Check(httprequestgrpc.FromHTTPStatus(httprequestgrpc.HTTPStatus(code)), qt.Equals, code)
+	}
+}
+
+func TestStatusRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	rerr := &httprequest.RemoteError{
+		Code:    httprequest.CodeBadGateway,
+		Message: "cannot get user",
+		Fields: []httprequest.FieldError{{
+			Field:   "name",
+			Code:    httprequest.CodeBadRequest,
+			Message: "invalid name",
+		}},
+		Wrapped: &httprequest.RemoteError{
+			Code:    httprequest.CodeNotFound,
+			Message: "user not found",
+		},
+	}
+	s := httprequestgrpc.ToStatus(errgo.NoteMask(rerr, "cannot call", errgo.Any))
+	c.Assert(s.Code(), qt.Equals, codes.Unavailable)
+	c.Assert(s.Message(), qt.Equals, "cannot call: cannot get user")
+
+	got := httprequestgrpc.FromStatus(s)
+	c.Assert(got, qt.DeepEquals, &httprequest.RemoteError{
+		Code:    httprequest.CodeBadGateway,
+		Message: "cannot call: cannot get user",
+		Fields:  rerr.Fields,
+		Wrapped: rerr.Wrapped,
+	})
+	c.Assert(errors.Is(got, httprequest.ErrNotFound), qt.IsTrue)
+}
+
+func TestToStatus(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequestgrpc.ToStatus(nil), qt.IsNil)
+
+	s := httprequestgrpc.ToStatus(errgo.New("something failed"))
+	c.Assert(s.Code(), qt.Equals, codes.Unknown)
+	c.Assert(s.Message(), qt.Equals, "something failed")
+	c.Assert(s.Details(), qt.HasLen, 0)
+
+	// Errors that already have a gRPC status keep it.
+	err := status.Error(codes.AlreadyExists, "user exists")
+	s = httprequestgrpc.ToStatus(errgo.NoteMask(err, "cannot create user", errgo.Any))
+	c.Assert(s.Code(), qt.Equals, codes.AlreadyExists)
+	c.Assert(s.Message(), qt.Equals, "cannot create user: rpc error: code = AlreadyExists desc = user exists")
+}
+
+func TestFromStatus(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequestgrpc.FromStatus(status.New(codes.OK, "")) == nil, qt.IsTrue)
+	c.Assert(httprequestgrpc.FromStatus(status.New(codes.PermissionDenied, "denied")), qt.DeepEquals, &httprequest.RemoteError{
+		Code:    httprequest.CodeForbidden,
+		Message: "denied",
+	})
+
+	rerr, ok := httprequestgrpc.FromError(errgo.Mask(status.Error(codes.NotFound, "no user"), errgo.Any))
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(rerr.Code, qt.Equals, httprequest.CodeNotFound)
+
+	rerr, ok = httprequestgrpc.FromError(errgo.New("other"))
+	c.Assert(ok, qt.IsFalse)
+	c.Assert(rerr == nil, qt.IsTrue)
+}
+
+type getUserRequest struct {
+	httprequest.Route `httprequest:"GET /users/:name"`
+	Name              string `httprequest:"name,path"`
+}
+
+func TestErrorMapper(t *testing.T) {
+	c := qt.New(t)
+	srv := httprequest.Server{
+		ErrorMapper: httprequestgrpc.ErrorMapper,
+	}
+	hsrv := httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, arg *getUserRequest) (string, error) {
+			switch arg.Name {
+			case "missing":
+				// The error returned by a gRPC service.
+				return "", errgo.NoteMask(status.Error(codes.NotFound, "no such user"), "cannot get user", errgo.Any)
+			case "busy":
+				return "", status.Error(codes.Aborted, "try again")
+			}
+			return "", errgo.WithCausef(nil, httprequest.ErrForbidden, "access denied")
+		}),
+	}))
+	defer hsrv.Close()
+
+	client := &httprequest.Client{BaseURL: hsrv.URL}
+	err := client.Get(context.Background(), "/users/missing", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/users/missing: cannot get user: rpc error: code = NotFound desc = no such user`)
+	c.Assert(errors.Is(err, httprequest.ErrNotFound), qt.IsTrue)
+
+	// Codes without a RemoteError equivalent are
+	// written with the status given by HTTPStatus.
+	resp, err := http.Get(hsrv.URL + "/users/busy")
+	c.Assert(err, qt.IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, qt.Equals, http.StatusConflict)
+
+	err = client.Get(context.Background(), "/users/bob", nil)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsTrue)
+
+	// The error from the client converts back to a gRPC status.
+	s := httprequestgrpc.ToStatus(err)
+	c.Assert(s.Code(), qt.Equals, codes.PermissionDenied)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := qt.New(t)
+	interceptor := httprequestgrpc.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Users/Get"}
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errgo.NoteMask(httprequest.Errorf(httprequest.CodeNotFound, "no user"), "cannot get user", errgo.Any)
+	})
+	s, ok := status.FromError(err)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(s.Code(), qt.Equals, codes.NotFound)
+	c.Assert(s.Message(), qt.Equals, "cannot get user: no user")
+	c.Assert(httprequestgrpc.FromStatus(s).Code, qt.Equals, httprequest.CodeNotFound)
+
+	resp, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+}
@@ -0,0 +1,21 @@
+module gopkg.in/httprequest.v1/httprequestoauth2
+
+go 1.22.0
+
+require (
+	github.com/frankban/quicktest v1.10.0
+	golang.org/x/oauth2 v0.26.0
+	gopkg.in/errgo.v1 v1.0.0
+	gopkg.in/httprequest.v1 v1.2.1
+)
+
+require (
+	github.com/google/go-cmp v0.5.9 // indirect
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/kr/pretty v0.2.0 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	golang.org/x/net v0.0.0-20200505041828-1ed23360d12c // indirect
+)
+
+// Build against the httprequest package in the parent directory.
+replace gopkg.in/httprequest.v1 => ../
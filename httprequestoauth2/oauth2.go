@@ -0,0 +1,71 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package httprequestoauth2 allows an httprequest.Client to call
+// APIs protected by OAuth2, using token sources from
+// golang.org/x/oauth2.
+package httprequestoauth2
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// Doer is an httprequest.Doer that adds an access token obtained from
+// a token source to each request it makes. It can be used as the Doer
+// in an httprequest.Client, so that errors returned by the API are
+// still unmarshaled by the Client.
+type Doer struct {
+	// Source holds the source of the access tokens.
+	Source oauth2.TokenSource
+
+	// Doer holds the Doer used to make the requests. If it is nil,
+	// http.DefaultClient is used. If it implements
+	// httprequest.DoerWithContext, DoWithContext is used.
+	Doer httprequest.Doer
+}
+
+// NewClientCredentials returns a Doer that obtains access tokens with
+// the OAuth2 client credentials flow described by cfg, reusing each
+// token until it expires, and makes requests with doer. If doer is nil,
+// http.DefaultClient is used.
+//
+// Tokens are obtained with the HTTP client found in ctx under the
+// oauth2.HTTPClient key, if any.
+func NewClientCredentials(ctx context.Context, cfg *clientcredentials.Config, doer httprequest.Doer) *Doer {
+	return &Doer{
+		Source: cfg.TokenSource(ctx),
+		Doer:   doer,
+	}
+}
+
+// Do implements httprequest.Doer.Do.
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements httprequest.DoerWithContext.DoWithContext.
+// The request is not modified; a copy with an Authorization header
+// holding the access token is sent in its place.
+func (d *Doer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	token, err := d.Source.Token()
+	if err != nil {
+		return nil, errgo.NoteMask(err, "cannot obtain OAuth2 token", errgo.Any)
+	}
+	req = req.Clone(ctx)
+	token.SetAuthHeader(req)
+	doer := d.Doer
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	if ctxDoer, ok := doer.(httprequest.DoerWithContext); ok {
+		return ctxDoer.DoWithContext(ctx, req)
+	}
+	return doer.Do(req)
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequestoauth2_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/httprequestoauth2"
+)
+
+type getRequest struct {
+	httprequest.Route `httprequest:"GET /resource"`
+}
+
+// apiServer returns a server that responds with the request's
+// Authorization header if it holds the given token and with a
+// CodeUnauthorized error otherwise.
+func apiServer(token string) *httptest.Server {
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, arg *getRequest) (string, error) {
+			auth := p.Request.Header.Get("Authorization")
+			if auth != "Bearer "+token {
+				return "", httprequest.Errorf(httprequest.CodeUnauthorized, "bad token %q", auth)
+			}
+			return auth, nil
+		}),
+	}))
+}
+
+// tokenServer returns a server that issues the given token with the
+// client credentials flow, counting the tokens issued in n.
+func tokenServer(token string, n *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.FormValue("grant_type") != "client_credentials" {
+			http.Error(w, "bad grant type", http.StatusBadRequest)
+			return
+		}
+		*n++
+		httprequest.WriteJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+}
+
+func TestClientCredentials(t *testing.T) {
+	c := qt.New(t)
+
+	var issued int
+	tokens := tokenServer("tok", &issued)
+	defer tokens.Close()
+	api := apiServer("tok")
+	defer api.Close()
+
+	client := &httprequest.Client{
+		BaseURL: api.URL,
+		Doer: httprequestoauth2.NewClientCredentials(context.Background(), &clientcredentials.Config{
+			ClientID:     "id",
+			ClientSecret: "secret",
+			TokenURL:     tokens.URL,
+		}, nil),
+	}
+	for i := 0; i < 2; i++ {
+		var resp string
+		err := client.Call(context.Background(), &getRequest{}, &resp)
+		c.Assert(err, qt.IsNil)
+		c.Assert(resp, qt.Equals, "Bearer tok")
+	}
+	// The token is reused.
+	c.Assert(issued, qt.Equals, 1)
+}
+
+func TestErrorsAreUnmarshaled(t *testing.T) {
+	c := qt.New(t)
+
+	api := apiServer("tok")
+	defer api.Close()
+	client := &httprequest.Client{
+		BaseURL: api.URL,
+		Doer: &httprequestoauth2.Doer{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "other"}),
+		},
+	}
+	err := client.Call(context.Background(), &getRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/resource: bad token "Bearer other"`)
+	rerr, ok := errgo.Cause(err).(*httprequest.RemoteError)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(rerr.Code, qt.Equals, httprequest.CodeUnauthorized)
+}
+
+func TestTokenError(t *testing.T) {
+	c := qt.New(t)
+
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		httprequest.WriteJSON(w, http.StatusUnauthorized, map[string]string{
+			"error": "invalid_client",
+		})
+	}))
+	defer tokens.Close()
+	client := &httprequest.Client{
+		BaseURL: "http://0.1.2.3",
+		Doer: httprequestoauth2.NewClientCredentials(context.Background(), &clientcredentials.Config{
+			ClientID:     "id",
+			ClientSecret: "wrong",
+			TokenURL:     tokens.URL,
+		}, nil),
+	}
+	err := client.Call(context.Background(), &getRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://0.1.2.3/resource: cannot obtain OAuth2 token: .*invalid_client.*`)
+	_, ok := errgo.Cause(err).(*oauth2.RetrieveError)
+	c.Assert(ok, qt.IsTrue)
+}
+
+type recordingDoer struct {
+	ctx context.Context
+}
+
+func (d *recordingDoer) Do(req *http.Request) (*http.Response, error) {
+	panic("Do called")
+}
+
+func (d *recordingDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	d.ctx = ctx
+	return http.DefaultClient.Do(req)
+}
+
+type ctxKey struct{}
+
+func TestContextPropagated(t *testing.T) {
+	c := qt.New(t)
+
+	api := apiServer("tok")
+	defer api.Close()
+	doer := new(recordingDoer)
+	client := &httprequest.Client{
+		BaseURL: api.URL,
+		Doer: &httprequestoauth2.Doer{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "tok"}),
+			Doer:   doer,
+		},
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	var resp string
+	err := client.Call(ctx, &getRequest{}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "Bearer tok")
+	c.Assert(doer.ctx.Value(ctxKey{}), qt.Equals, "value")
+}
@@ -0,0 +1,22 @@
+module gopkg.in/httprequest.v1/httprequestsigv4
+
+go 1.22.0
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.36.5
+	github.com/frankban/quicktest v1.10.0
+	gopkg.in/errgo.v1 v1.0.0
+	gopkg.in/httprequest.v1 v1.2.1
+)
+
+require (
+	github.com/aws/smithy-go v1.22.4 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/julienschmidt/httprouter v1.3.0 // indirect
+	github.com/kr/pretty v0.2.0 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	golang.org/x/net v0.32.0 // indirect
+)
+
+// Build against the httprequest package in the parent directory.
+replace gopkg.in/httprequest.v1 => ../
@@ -0,0 +1,136 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package httprequestsigv4 allows an httprequest.Client to call APIs
+// protected by AWS Signature Version 4, such as those behind Amazon
+// API Gateway with IAM authorization, using credentials from the AWS
+// SDK for Go v2.
+package httprequestsigv4
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// emptyPayloadHash holds the SHA-256 hash of an empty body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Doer is an httprequest.Doer that signs each request it makes with
+// AWS Signature Version 4. It can be used as the Doer in an
+// httprequest.Client, so that errors returned by the API are still
+// unmarshaled by the Client. Each attempt of a retried request is
+// signed afresh.
+type Doer struct {
+	// Credentials holds the provider of the credentials used to sign
+	// requests, such as the Credentials field of an aws.Config
+	// obtained with the config.LoadDefaultConfig function of
+	// github.com/aws/aws-sdk-go-v2/config.
+	Credentials aws.CredentialsProvider
+
+	// Region holds the AWS region of the service, such as
+	// "eu-west-1".
+	Region string
+
+	// Service holds the name of the service used for signing, such as
+	// "execute-api" for Amazon API Gateway.
+	Service string
+
+	// Doer holds the Doer used to make the requests. If it is nil,
+	// http.DefaultClient is used. If it implements
+	// httprequest.DoerWithContext, DoWithContext is used.
+	Doer httprequest.Doer
+
+	// Signer holds the signer used to sign requests. If it is nil,
+	// one created with v4.NewSigner is used.
+	Signer *v4.Signer
+
+	// Now returns the time at which requests are signed. If it is
+	// nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Do implements httprequest.Doer.Do.
+func (d *Doer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements httprequest.DoerWithContext.DoWithContext.
+// The request is not modified; a signed copy is sent in its place.
+//
+// The request body is hashed as part of the signature, so it is read
+// before the request is sent. If req.GetBody is non-nil, as it is for
+// requests made by httprequest.Client.Call, it is used to obtain a
+// copy of the body to hash; otherwise the body is read into memory.
+func (d *Doer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	creds, err := d.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, errgo.NoteMask(err, "cannot retrieve AWS credentials", errgo.Any)
+	}
+	req = req.Clone(ctx)
+	payloadHash, err := hashBody(req)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	// Sign the hash of the payload too, as services such as S3
+	// require.
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signer := d.Signer
+	if signer == nil {
+		signer = v4.NewSigner()
+	}
+	now := time.Now
+	if d.Now != nil {
+		now = d.Now
+	}
+	if err := signer.SignHTTP(ctx, creds, req, payloadHash, d.Service, d.Region, now()); err != nil {
+		return nil, errgo.Notef(err, "cannot sign request")
+	}
+	doer := d.Doer
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	if ctxDoer, ok := doer.(httprequest.DoerWithContext); ok {
+		return ctxDoer.DoWithContext(ctx, req)
+	}
+	return doer.Do(req)
+}
+
+// hashBody returns the hex-encoded SHA-256 hash of the body of req,
+// leaving the body so that it can still be sent.
+func hashBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+	if req.GetBody == nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", errgo.Notef(err, "cannot read request body")
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", errgo.Notef(err, "cannot get request body")
+	}
+	defer body.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", errgo.Notef(err, "cannot read request body")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequestsigv4_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/httprequestsigv4"
+)
+
+var (
+	signTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	creds    = aws.Credentials{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+	}
+)
+
+type putRequest struct {
+	httprequest.Route `httprequest:"PUT /items/:id"`
+	ID                string            `httprequest:"id,path"`
+	Body              map[string]string `httprequest:",body"`
+}
+
+var signedHeadersPattern = regexp.MustCompile(`SignedHeaders=([^,]+)`)
+
+// apiServer returns a server that checks that requests are signed
+// with creds at signTime, responding with the request's Authorization
+// header if so.
+func apiServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		sum := sha256.Sum256(body)
+		if req.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			http.Error(w, "bad payload hash", http.StatusForbidden)
+			return
+		}
+		// Sign a copy of the request to check the signature.
+		auth := req.Header.Get("Authorization")
+		req1, _ := http.NewRequest(req.Method, "http://"+req.Host+req.RequestURI, nil)
+		req1.ContentLength = req.ContentLength
+		if m := signedHeadersPattern.FindStringSubmatch(auth); m != nil {
+			for _, h := range strings.Split(m[1], ";") {
+				if v := req.Header.Values(h); v != nil {
+					req1.Header[http.CanonicalHeaderKey(h)] = v
+				}
+			}
+		}
+		err := v4.NewSigner().SignHTTP(context.Background(), creds, req1, hex.EncodeToString(sum[:]), "execute-api", "eu-west-1", signTime)
+		if err != nil || req1.Header.Get("Authorization") != auth {
+			http.Error(w, "bad signature", http.StatusForbidden)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, auth)
+	}))
+}
+
+func newDoer(provider aws.CredentialsProvider) *httprequestsigv4.Doer {
+	return &httprequestsigv4.Doer{
+		Credentials: provider,
+		Region:      "eu-west-1",
+		Service:     "execute-api",
+		Now: func() time.Time {
+			return signTime
+		},
+	}
+}
+
+func TestDoer(t *testing.T) {
+	c := qt.New(t)
+
+	srv := apiServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Doer: newDoer(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return creds, nil
+		})),
+	}
+	var auth string
+	err := client.Call(context.Background(), &putRequest{
+		ID:   "a",
+		Body: map[string]string{"k": "v"},
+	}, &auth)
+	c.Assert(err, qt.IsNil)
+	c.Assert(auth, qt.Matches, `AWS4-HMAC-SHA256 Credential=AKID/20260102/eu-west-1/execute-api/aws4_request, SignedHeaders=.*host;x-amz-content-sha256;x-amz-date.*, Signature=[0-9a-f]+`)
+
+	// Requests without a body are signed too.
+	err = client.Get(context.Background(), "/items?x=1", &auth)
+	c.Assert(err, qt.IsNil)
+
+	// As are requests whose body cannot be replayed.
+	req, err := http.NewRequest("POST", "/items", ioutil.NopCloser(strings.NewReader("data")))
+	c.Assert(err, qt.IsNil)
+	err = client.Do(context.Background(), req, &auth)
+	c.Assert(err, qt.IsNil)
+	c.Assert(req.Header.Get("Authorization"), qt.Equals, "")
+}
+
+func TestDoerCredentialsError(t *testing.T) {
+	c := qt.New(t)
+
+	errExpired := errgo.New("credentials expired")
+	client := &httprequest.Client{
+		BaseURL: "http://0.1.2.3",
+		Doer: newDoer(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, errExpired
+		})),
+	}
+	err := client.Get(context.Background(), "/items", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://0.1.2.3/items: cannot retrieve AWS credentials: credentials expired`)
+	c.Assert(errgo.Cause(err), qt.Equals, errExpired)
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// IdempotencyKeyHeader holds the name of the request header holding
+// the key used to recognize retries of requests to idempotent routes.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore is used by Server to store responses to requests to
+// routes marked as idempotent (see RouteMetadata.Idempotent), so that
+// retried requests can be answered without calling the handler again.
+//
+// The store is consulted after the request has been authorized, with
+// a key that combines the Idempotency-Key header with a hash of the
+// request method, URL and body, so a key reused for a different request
+// does not replay the earlier response. Implementations should still
+// scope keys to the client making the request (for example by its
+// credentials) where keys might be shared or guessed. Concurrent
+// requests with the same key are not serialized by Server.
+type IdempotencyStore interface {
+	// Get returns the response stored for the given key and
+	// request, or nil if there is none.
+	Get(req *http.Request, key string) (*StoredResponse, error)
+
+	// Put stores the response to the given request with
+	// the given key.
+	Put(req *http.Request, key string, resp *StoredResponse) error
+}
+
+// StoredResponse holds a response recorded for an IdempotencyStore.
+type StoredResponse struct {
+	// Status holds the HTTP status of the response.
+	Status int
+
+	// Header holds the response headers.
+	Header http.Header
+
+	// Body holds the response body.
+	Body []byte
+}
+
+// maxStoredResponseSize holds the largest response body
+// that is stored in an IdempotencyStore.
+const maxStoredResponseSize = 1 << 20
+
+// idempotencyKey returns the key used to store the response to the
+// request in p, which has been authorized, or the empty string if the
+// response should not be stored because the route is not idempotent or
+// the request has no Idempotency-Key header. The key combines the
+// header with a hash of the request method, URL and body. The body is
+// read to compute the hash, and replaced with a reader of the same
+// data.
+func (srv *Server) idempotencyKey(p Params) (string, error) {
+	key := p.Request.Header.Get(IdempotencyKeyHeader)
+	if !p.Metadata.Idempotent || srv.IdempotencyStore == nil || key == "" {
+		return "", nil
+	}
+	req := p.Request
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.RequestURI())
+	if req.Body != nil && req.Body != http.NoBody {
+		// The body is read in full, but its size has
+		// already been limited by withBodyLimit.
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				return "", Errorf(CodeRequestTooLarge, "request body too large")
+			}
+			return "", errgo.Notef(err, "cannot read request body")
+		}
+		h.Write(body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return fmt.Sprintf("%s %x", key, h.Sum(nil)), nil
+}
+
+// serveIdempotent calls serve with p, which holds an authorized request,
+// unless there is a response stored with the given key, as returned by
+// idempotencyKey, in which case that response is written instead. The
+// response written by serve is stored with the key unless it has a 5xx
+// status, so that such requests may be retried, or a body larger than
+// maxStoredResponseSize. If key is empty, serve is just called.
+func (srv *Server) serveIdempotent(p Params, key string, serve func(p Params)) {
+	if key == "" {
+		serve(p)
+		return
+	}
+	stored, err := srv.IdempotencyStore.Get(p.Request, key)
+	if err != nil {
+		srv.WriteError(p.Context, p.Response, err)
+		return
+	}
+	if stored != nil {
+		header := p.Response.Header()
+		for k, vs := range stored.Header {
+			header[k] = append([]string(nil), vs...)
+		}
+		header.Set("Idempotent-Replayed", "true")
+		p.Response.WriteHeader(stored.Status)
+		p.Response.Write(stored.Body)
+		return
+	}
+	w := &teeResponseWriter{
+		ResponseWriter: p.Response,
+		limit:          maxStoredResponseSize,
+	}
+	p.Response = w
+	serve(p)
+	resp := w.response()
+	if resp == nil || resp.Status >= 500 {
+		return
+	}
+	if err := srv.IdempotencyStore.Put(p.Request, key, resp); err != nil {
+		srv.logFailure(p.Context, "cannot store idempotent response", err)
+	}
+}
+
+// teeResponseWriter records the response written to it
+// as well as passing it on to the underlying ResponseWriter.
+// No more than limit bytes of body are recorded.
+type teeResponseWriter struct {
+	http.ResponseWriter
+	limit  int
+	code   int
+	header http.Header
+	body   bytes.Buffer
+
+	// tooLarge records whether the body has
+	// exceeded the limit.
+	tooLarge bool
+}
+
+func (w *teeResponseWriter) WriteHeader(code int) {
+	if w.code == 0 && code >= 200 {
+		w.code = code
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *teeResponseWriter) Write(data []byte) (int, error) {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.tooLarge {
+		if w.body.Len()+len(data) > w.limit {
+			w.tooLarge = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap returns the underlying ResponseWriter so that
+// http.ResponseController can find any optional interfaces
+// it implements.
+func (w *teeResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// response returns the response that has been written,
+// or nil if its body was too large to record.
+func (w *teeResponseWriter) response() *StoredResponse {
+	if w.tooLarge {
+		return nil
+	}
+	if w.code == 0 {
+		w.code = http.StatusOK
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	return &StoredResponse{
+		Status: w.code,
+		Header: w.header,
+		Body:   w.body.Bytes(),
+	}
+}
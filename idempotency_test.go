@@ -0,0 +1,270 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type memoryIdempotencyStore struct {
+	responses map[string]*httprequest.StoredResponse
+	getErr    error
+}
+
+func (s *memoryIdempotencyStore) Get(req *http.Request, key string) (*httprequest.StoredResponse, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+	return s.responses[key], nil
+}
+
+func (s *memoryIdempotencyStore) Put(req *http.Request, key string, resp *httprequest.StoredResponse) error {
+	if s.responses == nil {
+		s.responses = make(map[string]*httprequest.StoredResponse)
+	}
+	s.responses[key] = resp
+	return nil
+}
+
+type paymentHandlers struct {
+	calls *int
+}
+
+func (h paymentHandlers) Pay(p httprequest.Params, req *struct {
+	httprequest.Route `httprequest:"POST /payments" idempotent:"true"`
+	Fail              bool `httprequest:"fail,form"`
+}) (httprequest.CustomStatus, error) {
+	*h.calls++
+	if req.Fail {
+		return httprequest.CustomStatus{}, errgo.New("payment failed")
+	}
+	p.Response.Header().Set("X-Call", "yes")
+	return httprequest.CustomStatus{
+		Status: http.StatusCreated,
+		Body:   *h.calls,
+	}, nil
+}
+
+func (h paymentHandlers) Refund(*struct {
+	httprequest.Route `httprequest:"POST /refunds"`
+}) (int, error) {
+	*h.calls++
+	return *h.calls, nil
+}
+
+func newPaymentRouter(srv *httprequest.Server, calls *int) http.Handler {
+	return srv.NewRouter(srv.Handlers(func(p httprequest.Params) (paymentHandlers, context.Context, error) {
+		return paymentHandlers{calls: calls}, p.Context, nil
+	}))
+}
+
+func postWithKey(h http.Handler, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, nil)
+	if key != "" {
+		req.Header.Set(httprequest.IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore: store,
+	}, &calls)
+
+	rec := postWithKey(router, "/payments", "k1")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusCreated, 1)
+	c.Assert(rec.Header().Get("X-Call"), qt.Equals, "yes")
+	c.Assert(rec.Header().Get("Idempotent-Replayed"), qt.Equals, "")
+
+	rec = postWithKey(router, "/payments", "k1")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusCreated, 1)
+	c.Assert(rec.Header().Get("X-Call"), qt.Equals, "yes")
+	c.Assert(rec.Header().Get("Idempotent-Replayed"), qt.Equals, "true")
+	c.Assert(calls, qt.Equals, 1)
+
+	// A different key calls the handler again.
+	rec = postWithKey(router, "/payments", "k2")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusCreated, 2)
+
+	// As does a request with no key.
+	rec = postWithKey(router, "/payments", "")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusCreated, 3)
+	c.Assert(calls, qt.Equals, 3)
+}
+
+func TestIdempotencyIgnoresOtherRoutes(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore: store,
+	}, &calls)
+	postWithKey(router, "/refunds", "k1")
+	postWithKey(router, "/refunds", "k1")
+	c.Assert(calls, qt.Equals, 2)
+	c.Assert(store.responses, qt.HasLen, 0)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore: store,
+	}, &calls)
+	rec := postWithKey(router, "/payments?fail=true", "k1")
+	c.Assert(rec.Code, qt.Equals, http.StatusInternalServerError)
+	rec = postWithKey(router, "/payments?fail=true", "k1")
+	c.Assert(rec.Code, qt.Equals, http.StatusInternalServerError)
+	c.Assert(calls, qt.Equals, 2)
+	c.Assert(store.responses, qt.HasLen, 0)
+}
+
+func TestIdempotencyStoreError(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore: &memoryIdempotencyStore{
+			getErr: httprequest.Errorf(httprequest.CodeServiceUnavailable, "store unavailable"),
+		},
+	}, &calls)
+	rec := postWithKey(router, "/payments", "k1")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusServiceUnavailable, &httprequest.RemoteError{
+		Code:    httprequest.CodeServiceUnavailable,
+		Message: "store unavailable",
+	})
+	c.Assert(calls, qt.Equals, 0)
+}
+
+func TestIdempotencyReplayIsAuthorized(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	srv := &httprequest.Server{
+		IdempotencyStore: store,
+		Authorize: func(ctx context.Context, p httprequest.Params, requirements []string) error {
+			if p.Request.Header.Get("X-User") != "alice" {
+				return httprequest.Errorf(httprequest.CodeUnauthorized, "not alice")
+			}
+			return nil
+		},
+	}
+	router := srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(*struct {
+			httprequest.Route `httprequest:"POST /secure" idempotent:"true" auth:"pay"`
+		}) (int, error) {
+			calls++
+			return calls, nil
+		}),
+	})
+	req := httptest.NewRequest("POST", "/secure", nil)
+	req.Header.Set(httprequest.IdempotencyKeyHeader, "k1")
+	req.Header.Set("X-User", "alice")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, 1)
+
+	// A retry with the same key is not answered
+	// unless it is authorized too.
+	rec = postWithKey(router, "/secure", "k1")
+	c.Assert(rec.Code, qt.Equals, http.StatusUnauthorized)
+	c.Assert(rec.Header().Get("Idempotent-Replayed"), qt.Equals, "")
+	c.Assert(calls, qt.Equals, 1)
+}
+
+func TestIdempotencyKeyScopedToRequest(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore: store,
+	}, &calls)
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/payments", strings.NewReader(body))
+		req.Header.Set(httprequest.IdempotencyKeyHeader, "k1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	qthttptest.AssertJSONResponse(c, post("a"), http.StatusCreated, 1)
+	qthttptest.AssertJSONResponse(c, post("a"), http.StatusCreated, 1)
+
+	// The same key used for a request with another
+	// body or URL does not replay the response.
+	qthttptest.AssertJSONResponse(c, post("b"), http.StatusCreated, 2)
+	qthttptest.AssertJSONResponse(c, postWithKey(router, "/payments?x=1", "k1"), http.StatusCreated, 3)
+	c.Assert(calls, qt.Equals, 3)
+	c.Assert(store.responses, qt.HasLen, 3)
+}
+
+func TestIdempotencyDoesNotStoreLargeResponses(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	store := &memoryIdempotencyStore{}
+	srv := &httprequest.Server{
+		IdempotencyStore: store,
+	}
+	router := srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(*struct {
+			httprequest.Route `httprequest:"POST /large" idempotent:"true"`
+		}) (string, error) {
+			calls++
+			return strings.Repeat("x", 2<<20), nil
+		}),
+	})
+	rec := postWithKey(router, "/large", "k1")
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Body.Len() > 2<<20, qt.IsTrue)
+	postWithKey(router, "/large", "k1")
+	c.Assert(calls, qt.Equals, 2)
+	c.Assert(store.responses, qt.HasLen, 0)
+}
+
+func TestIdempotencyReplayIsCompressedPerRequest(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	router := newPaymentRouter(&httprequest.Server{
+		IdempotencyStore:     &memoryIdempotencyStore{},
+		CompressionThreshold: 1,
+	}, &calls)
+	req := httptest.NewRequest("POST", "/payments", nil)
+	req.Header.Set(httprequest.IdempotencyKeyHeader, "k1")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	c.Assert(rec.Code, qt.Equals, http.StatusCreated)
+	c.Assert(rec.Header().Get("Content-Encoding"), qt.Equals, "gzip")
+
+	// The stored response is not compressed, so it
+	// can be replayed to a client that does not
+	// accept gzip.
+	rec = postWithKey(router, "/payments", "k1")
+	qthttptest.AssertJSONResponse(c, rec, http.StatusCreated, 1)
+	c.Assert(rec.Header().Get("Content-Encoding"), qt.Equals, "")
+	c.Assert(rec.Header().Get("Idempotent-Replayed"), qt.Equals, "true")
+	c.Assert(calls, qt.Equals, 1)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// incident holds the details of an error written by a
+// Server with DebugErrors set.
+type incident struct {
+	id    string
+	stack []byte
+}
+
+type incidentKey struct{}
+
+// IncidentID returns the identifier of the error being written in
+// ctx, as passed to the error mapper by a Server with DebugErrors set,
+// or the empty string if there is none. The error mapper can include
+// it in the response, so that clients can report it, and the failure
+// can then be found in the Server's log. For example:
+//
+//	srv.ErrorMapper = func(ctx context.Context, err error) (int, interface{}) {
+//		status, body := httprequest.DefaultErrorMapper(ctx, err)
+//		if id := httprequest.IncidentID(ctx); id != "" && status >= http.StatusInternalServerError {
+//			rerr := *body.(*httprequest.RemoteError)
+//			rerr.Message = fmt.Sprintf("internal error (incident %s)", id)
+//			body = &rerr
+//		}
+//		return status, body
+//	}
+func IncidentID(ctx context.Context) string {
+	if inc := incidentFromContext(ctx); inc != nil {
+		return inc.id
+	}
+	return ""
+}
+
+// contextWithIncident returns ctx with a new incident that records the
+// current stack trace.
+func contextWithIncident(ctx context.Context) context.Context {
+	// An incident can still be logged without an identifier,
+	// so ignore any error.
+	id, _ := newRequestID()
+	return context.WithValue(ctx, incidentKey{}, &incident{
+		id:    id,
+		stack: debug.Stack(),
+	})
+}
+
+// incidentFromContext returns the incident
+// stored in ctx, if any.
+func incidentFromContext(ctx context.Context) *incident {
+	inc, _ := ctx.Value(incidentKey{}).(*incident)
+	return inc
+}
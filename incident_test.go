@@ -0,0 +1,74 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestDebugErrors(t *testing.T) {
+	c := qt.New(t)
+
+	logger, records := newTestLogger()
+	var mapperID string
+	srv := httprequest.Server{
+		Logger:      logger,
+		DebugErrors: true,
+		ErrorMapper: func(ctx context.Context, err error) (int, interface{}) {
+			mapperID = httprequest.IncidentID(ctx)
+			return http.StatusInternalServerError, &httprequest.RemoteError{
+				Message: fmt.Sprintf("internal error (incident %s)", mapperID),
+			}
+		},
+	}
+	rec := httptest.NewRecorder()
+	srv.WriteError(context.Background(), rec, errgo.New("database failure"))
+	c.Assert(rec.Code, qt.Equals, http.StatusInternalServerError)
+	c.Assert(mapperID, qt.Matches, `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`)
+	c.Assert(rec.Body.String(), qt.Equals, `{"Message":"internal error (incident `+mapperID+`)"}`)
+	c.Assert(rec.Body.String(), qt.Not(qt.Contains), "goroutine")
+
+	recs := records(c)
+	c.Assert(recs, qt.HasLen, 1)
+	c.Assert(recs[0].Error, qt.Equals, "database failure")
+	c.Assert(recs[0].Incident, qt.Equals, mapperID)
+	c.Assert(recs[0].Stack, qt.Contains, "TestDebugErrors")
+
+	// Each error is a new incident.
+	firstID := mapperID
+	srv.WriteError(context.Background(), httptest.NewRecorder(), errgo.New("another failure"))
+	recs = records(c)
+	c.Assert(recs, qt.HasLen, 1)
+	c.Assert(recs[0].Incident, qt.Equals, mapperID)
+	c.Assert(recs[0].Incident, qt.Not(qt.Equals), firstID)
+}
+
+func TestDebugErrorsDisabled(t *testing.T) {
+	c := qt.New(t)
+
+	logger, records := newTestLogger()
+	var mapperID string
+	srv := httprequest.Server{
+		Logger: logger,
+		ErrorMapper: func(ctx context.Context, err error) (int, interface{}) {
+			mapperID = httprequest.IncidentID(ctx)
+			return httprequest.DefaultErrorMapper(ctx, err)
+		},
+	}
+	srv.WriteError(context.Background(), httptest.NewRecorder(), errgo.New("failure"))
+	c.Assert(mapperID, qt.Equals, "")
+	recs := records(c)
+	c.Assert(recs, qt.HasLen, 1)
+	c.Assert(recs[0].Incident, qt.Equals, "")
+	c.Assert(recs[0].Stack, qt.Equals, "")
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// InMemoryDoer is a Doer that sends requests directly to an
+// http.Handler, such as one returned by Server.NewRouter, in the same
+// process, without using the network. It can be used as Client.Doer
+// to test clients and servers together, or to compose services in a
+// single process.
+//
+// The handler is called in its own goroutine with a context holding
+// the values in the request's context, which is canceled when the
+// request's context is done, when the response body is closed or when
+// the handler returns. The response is streamed to the client as the handler
+// writes it, so handlers that stream their responses, such as those
+// returning channels, work as they would over the network.
+type InMemoryDoer struct {
+	// Handler holds the handler that serves requests.
+	Handler http.Handler
+}
+
+// Do implements Doer.Do.
+func (d InMemoryDoer) Do(req *http.Request) (*http.Response, error) {
+	// The handler's context is canceled when the request's context is
+	// done, as it would be if the connection was closed, but only
+	// after the response body has been made to return the request's
+	// context error.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+	sreq := req.Clone(ctx)
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = "127.0.0.1:0"
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+	pr, pw := io.Pipe()
+	stop := context.AfterFunc(req.Context(), func() {
+		pw.CloseWithError(req.Context().Err())
+		cancel()
+	})
+	w := &inMemoryResponseWriter{
+		header: make(http.Header),
+		pw:     pw,
+		ready:  make(chan struct{}),
+	}
+	go func() {
+		defer cancel()
+		defer stop()
+		defer func() {
+			if v := recover(); v != nil {
+				err := errgo.Newf("handler panicked: %v", v)
+				w.fail(err)
+				pw.CloseWithError(err)
+				return
+			}
+			w.writeHeader(http.StatusOK)
+			pw.Close()
+		}()
+		d.Handler.ServeHTTP(w, sreq)
+	}()
+	select {
+	case <-w.ready:
+	case <-req.Context().Done():
+		return nil, errgo.Mask(req.Context().Err(), errgo.Any)
+	}
+	if w.err != nil {
+		return nil, errgo.Mask(w.err, errgo.Any)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.status, http.StatusText(w.status)),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.sentHeader,
+		Body:          cancelReadCloser{pr, cancel},
+		ContentLength: -1,
+		Request:       req,
+	}, nil
+}
+
+// inMemoryResponseWriter is the http.ResponseWriter passed to handlers
+// by InMemoryDoer. The response body is written to pw; ready is closed
+// when the response header has been written.
+type inMemoryResponseWriter struct {
+	header http.Header
+	pw     *io.PipeWriter
+
+	once       sync.Once
+	ready      chan struct{}
+	status     int
+	sentHeader http.Header
+	err        error
+}
+
+// Header implements http.ResponseWriter.Header.
+func (w *inMemoryResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader implements http.ResponseWriter.WriteHeader.
+func (w *inMemoryResponseWriter) WriteHeader(status int) {
+	w.writeHeader(status)
+}
+
+// Write implements http.ResponseWriter.Write.
+func (w *inMemoryResponseWriter) Write(data []byte) (int, error) {
+	w.once.Do(func() {
+		if w.header.Get("Content-Type") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(data))
+		}
+		w.sendHeader(http.StatusOK)
+	})
+	return w.pw.Write(data)
+}
+
+// Flush implements http.Flusher.Flush. The response is written
+// to the client as it is written, so it only writes the header.
+func (w *inMemoryResponseWriter) Flush() {
+	w.writeHeader(http.StatusOK)
+}
+
+// writeHeader sends the response header with the given
+// status if it has not already been sent.
+func (w *inMemoryResponseWriter) writeHeader(status int) {
+	w.once.Do(func() {
+		w.sendHeader(status)
+	})
+}
+
+// fail makes the response fail with the given error
+// if its header has not already been sent.
+func (w *inMemoryResponseWriter) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		close(w.ready)
+	})
+}
+
+func (w *inMemoryResponseWriter) sendHeader(status int) {
+	w.status = status
+	w.sentHeader = w.header.Clone()
+	close(w.ready)
+}
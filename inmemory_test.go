@@ -0,0 +1,151 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type inMemoryWaitRequest struct {
+	httprequest.Route `httprequest:"GET /wait"`
+}
+
+type inMemoryPanicRequest struct {
+	httprequest.Route `httprequest:"GET /panic"`
+}
+
+type inMemoryTextRequest struct {
+	httprequest.Route `httprequest:"GET /text"`
+}
+
+// inMemoryClient returns a client that sends requests directly to
+// handlers that serve /item/:id as batchServer does, stream items
+// from /items, block until the request is done on /wait (sending the
+// request's context error on done), panic on /panic and write plain
+// text on /text.
+func inMemoryClient(done chan<- error) *httprequest.Client {
+	srv := httprequest.Server{
+		ErrorMapper: func(ctx context.Context, err error) (int, interface{}) {
+			return http.StatusInternalServerError, &httprequest.RemoteError{
+				Message: err.Error(),
+			}
+		},
+	}
+	router := srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *batchItemRequest) (int, error) {
+			if req.ID < 0 {
+				return 0, httprequest.Errorf(httprequest.CodeNotFound, "item %d not found", req.ID)
+			}
+			return req.ID * 10, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *ndjsonItemsRequest) (<-chan ndjsonItem, error) {
+			items := make(chan ndjsonItem)
+			go func() {
+				defer close(items)
+				for i := 0; req.Count == 0 || i < req.Count; i++ {
+					select {
+					case items <- ndjsonItem{N: i}:
+					case <-p.Context.Done():
+						return
+					}
+				}
+			}()
+			return items, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *inMemoryWaitRequest) {
+			<-p.Context.Done()
+			done <- p.Context.Err()
+		}),
+		srv.Handle(func(p httprequest.Params, req *inMemoryPanicRequest) {
+			panic("oops")
+		}),
+		srv.Handle(func(p httprequest.Params, req *inMemoryTextRequest) {
+			io.WriteString(p.Response, "hello")
+		}),
+	})
+	return &httprequest.Client{
+		BaseURL: "http://example.com",
+		Doer: httprequest.InMemoryDoer{
+			Handler: router,
+		},
+	}
+}
+
+func TestInMemoryDoer(t *testing.T) {
+	c := qt.New(t)
+
+	client := inMemoryClient(nil)
+	var n int
+	err := client.Call(context.Background(), &batchItemRequest{ID: 3}, &n)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 30)
+
+	err = client.Call(context.Background(), &batchItemRequest{ID: -3}, &n)
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/item/-3: item -3 not found`)
+	c.Assert(errgo.Cause(err), qt.Satisfies, isRemoteError)
+
+	var resp *http.Response
+	err = client.Call(context.Background(), &inMemoryTextRequest{}, &resp)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Content-Type"), qt.Equals, "text/plain; charset=utf-8")
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "hello")
+}
+
+func TestInMemoryDoerStreaming(t *testing.T) {
+	c := qt.New(t)
+
+	client := inMemoryClient(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make(chan ndjsonItem)
+	errc := make(chan error, 1)
+	go func() {
+		// The stream never ends unless the request is canceled.
+		errc <- client.CallStream(ctx, &ndjsonItemsRequest{}, items)
+	}()
+	for i := 0; i < 3; i++ {
+		c.Assert(<-items, qt.Equals, ndjsonItem{N: i})
+	}
+	cancel()
+	for range items {
+	}
+	c.Assert(<-errc, qt.ErrorMatches, `.*context canceled`)
+}
+
+func TestInMemoryDoerContextDone(t *testing.T) {
+	c := qt.New(t)
+
+	done := make(chan error, 1)
+	client := inMemoryClient(done)
+	err := client.Call(context.Background(), &inMemoryWaitRequest{}, nil, httprequest.WithTimeout(10*time.Millisecond))
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/wait: context deadline exceeded`)
+	select {
+	case err := <-done:
+		c.Assert(err, qt.Equals, context.Canceled)
+	case <-time.After(5 * time.Second):
+		c.Fatalf("handler context not done")
+	}
+}
+
+func TestInMemoryDoerPanic(t *testing.T) {
+	c := qt.New(t)
+
+	client := inMemoryClient(nil)
+	err := client.Call(context.Background(), &inMemoryPanicRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/panic: .*oops.*`)
+}
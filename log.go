@@ -0,0 +1,59 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// logError logs an error that has been written to the
+// client with the given HTTP status.
+func (srv *Server) logError(ctx context.Context, status int, err error) {
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	route := routeFromContext(ctx)
+	attrs := []slog.Attr{
+		slog.String("method", route.Method),
+		slog.String("route", route.PathPattern),
+		slog.Int("status", status),
+		slog.String("code", errorCode(err)),
+		slog.String("error", err.Error()),
+	}
+	if inc := incidentFromContext(ctx); inc != nil {
+		attrs = append(attrs,
+			slog.String("incident", inc.id),
+			slog.String("stack", string(inc.stack)),
+		)
+	}
+	srv.Logger.LogAttrs(ctx, level, "httprequest: error response", attrs...)
+}
+
+// logFailure logs an error that could not be reported
+// to the client.
+func (srv *Server) logFailure(ctx context.Context, msg string, err error) {
+	if srv.Logger == nil {
+		return
+	}
+	route := routeFromContext(ctx)
+	srv.Logger.LogAttrs(ctx, slog.LevelError, "httprequest: "+msg,
+		slog.String("method", route.Method),
+		slog.String("route", route.PathPattern),
+		slog.String("error", err.Error()),
+	)
+}
+
+// errorCode returns the error code associated with err,
+// or the empty string if there is none.
+func errorCode(err error) string {
+	if coder, ok := errgo.Cause(err).(ErrorCoder); ok {
+		return coder.ErrorCode()
+	}
+	return ""
+}
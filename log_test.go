@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type logRecord struct {
+	Level  string `json:"level"`
+	Msg    string `json:"msg"`
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Error  string `json:"error"`
+
+	Incident string `json:"incident"`
+	Stack    string `json:"stack"`
+}
+
+func newTestLogger() (*slog.Logger, func(c *qt.C) []logRecord) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	return logger, func(c *qt.C) []logRecord {
+		var records []logRecord
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var r logRecord
+			err := dec.Decode(&r)
+			c.Assert(err, qt.IsNil)
+			records = append(records, r)
+		}
+		return records
+	}
+}
+
+func TestLoggerLogsErrorResponses(t *testing.T) {
+	c := qt.New(t)
+
+	logger, records := newTestLogger()
+	srv := httprequest.Server{
+		Logger: logger,
+	}
+	router := httprouter.New()
+	httprequest.AddHandlers(router, srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	router.Handle("GET", "/fail", srv.HandleErrors(func(p httprequest.Params) error {
+		return errgo.New("internal failure")
+	}))
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		Method:       "DELETE",
+		URL:          "/items/1",
+		Handler:      router,
+		ExpectStatus: http.StatusInternalServerError,
+		ExpectBody: &httprequest.RemoteError{
+			Message: "no deletions",
+		},
+	})
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:          "/fail",
+		Handler:      router,
+		ExpectStatus: http.StatusInternalServerError,
+		ExpectBody: &httprequest.RemoteError{
+			Message: "internal failure",
+		},
+	})
+	c.Assert(records(c), qt.DeepEquals, []logRecord{{
+		Level:  "ERROR",
+		Msg:    "httprequest: error response",
+		Method: "DELETE",
+		Route:  "/items/:id",
+		Status: http.StatusInternalServerError,
+		Error:  "no deletions",
+	}, {
+		Level:  "ERROR",
+		Msg:    "httprequest: error response",
+		Status: http.StatusInternalServerError,
+		Error:  "internal failure",
+	}})
+}
+
+func TestLoggerLogsBadRequest(t *testing.T) {
+	c := qt.New(t)
+
+	logger, records := newTestLogger()
+	srv := httprequest.Server{
+		ErrorMapper: testErrorMapper,
+		Logger:      logger,
+	}
+	h := srv.Handle(func(*struct {
+		httprequest.Route `httprequest:"GET /x/:n"`
+		N                 int `httprequest:"n,path"`
+	}) {
+	})
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/x/foo", nil)
+	h.Handle(rec, req, httprouter.Params{{Key: "n", Value: "foo"}})
+	c.Assert(rec.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(records(c), qt.DeepEquals, []logRecord{{
+		Level:  "INFO",
+		Msg:    "httprequest: error response",
+		Method: "GET",
+		Route:  "/x/:n",
+		Status: http.StatusBadRequest,
+		Error:  `cannot unmarshal parameters: cannot unmarshal into field N: cannot parse "foo" into int: expected integer`,
+	}})
+}
+
+func TestLoggerLogsUnmarshalableErrorResponse(t *testing.T) {
+	c := qt.New(t)
+
+	logger, records := newTestLogger()
+	srv := httprequest.Server{
+		ErrorMapper: testErrorMapper,
+		Logger:      logger,
+	}
+	rec := httptest.NewRecorder()
+	srv.WriteError(context.Background(), rec, errUnmarshalableError)
+	recs := records(c)
+	c.Assert(recs, qt.HasLen, 2)
+	c.Assert(recs[0].Msg, qt.Equals, "httprequest: cannot marshal error response")
+	c.Assert(recs[0].Level, qt.Equals, "ERROR")
+	c.Assert(recs[1].Msg, qt.Equals, "httprequest: error response")
+	c.Assert(recs[1].Status, qt.Equals, rec.Code)
+}
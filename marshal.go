@@ -86,6 +86,13 @@ import (
 // It is an error if there is a field specified in the URL that is not
 // found in x.
 func Marshal(baseURL, method string, x interface{}) (*http.Request, error) {
+	return marshalRequest(baseURL, method, x, nil)
+}
+
+// marshalRequest is the internal version of Marshal. The values of
+// path parameters in pathVars are used in preference to those
+// in x.
+func marshalRequest(baseURL, method string, x interface{}, pathVars httprouter.Params) (*http.Request, error) {
 	var xv reflect.Value
 	if ch, ok := x.(*CustomHeader); ok {
 		xv = reflect.ValueOf(ch.Body)
@@ -111,6 +118,7 @@ func Marshal(baseURL, method string, x interface{}) (*http.Request, error) {
 	}
 	p := &Params{
 		Request: req,
+		PathVar: append(httprouter.Params(nil), pathVars...),
 	}
 	if err := marshal(p, xv, pt); err != nil {
 		return nil, errgo.Mask(err, errgo.Is(ErrUnmarshal))
@@ -129,6 +137,34 @@ func Marshal(baseURL, method string, x interface{}) (*http.Request, error) {
 	return p.Request, nil
 }
 
+// RouteURL returns the URL, relative to the server root, to which
+// Client.Call would send a request holding x, which must be a pointer
+// to a struct with a Route field. This can be used to refer
+// to a resource, for example in the Location field of Created.
+//
+// For example, given:
+//
+//	type GetUserRequest struct {
+//		httprequest.Route `httprequest:"GET /users/:id"`
+//		ID string `httprequest:"id,path"`
+//	}
+//
+// RouteURL(&GetUserRequest{ID: "bob"}) will return "/users/bob".
+func RouteURL(x interface{}) (string, error) {
+	rt, err := getRequestType(reflect.TypeOf(x))
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	if rt.method == "" {
+		return "", errgo.Newf("type %T has no httprequest.Route field", x)
+	}
+	req, err := Marshal(rt.path, rt.method, x)
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	return req.URL.String(), nil
+}
+
 // marshal is the internal version of Marshal.
 func marshal(p *Params, xv reflect.Value, pt *requestType) error {
 	xv = xv.Elem()
@@ -218,6 +254,10 @@ func getMarshaler(tag tag, t reflect.Type) (marshaler, error) {
 	switch {
 	case tag.source == sourceNone:
 		return marshalNop, nil
+	case tag.source == sourceMultipart:
+		return marshalMultipart, nil
+	case tag.source == sourceBody && tag.bodyContentType != "":
+		return marshalBodyWithContentType(tag.bodyContentType), nil
 	case tag.source == sourceBody:
 		return marshalBody, nil
 	case t == reflect.TypeOf([]string(nil)):
@@ -245,6 +285,19 @@ func marshalNop(v reflect.Value, p *Params) error {
 	return nil
 }
 
+// marshalBodyWithContentType returns a marshaler that marshals
+// the specified value into the body of the http request
+// with the given content type.
+func marshalBodyWithContentType(contentType string) marshaler {
+	return func(v reflect.Value, p *Params) error {
+		if err := marshalBody(v, p); err != nil {
+			return err
+		}
+		p.Request.Header.Set("Content-Type", contentType)
+		return nil
+	}
+}
+
 // marshalBody marshals the specified value into the body of the http request.
 func marshalBody(v reflect.Value, p *Params) error {
 	// TODO allow body types that aren't necessarily JSON.
@@ -544,3 +544,49 @@ type stringer int
 func (s stringer) String() string {
 	return fmt.Sprintf("str%d", int(s))
 }
+
+var routeURLTests = []struct {
+	about       string
+	val         interface{}
+	expectURL   string
+	expectError string
+}{{
+	about: "path and form parameters",
+	val: &struct {
+		httprequest.Route `httprequest:"GET /users/:id/items/*rest"`
+		ID                string `httprequest:"id,path"`
+		Rest              string `httprequest:"rest,path"`
+		Limit             int    `httprequest:"limit,form,omitempty"`
+	}{
+		ID:    "bob",
+		Rest:  "/a/b",
+		Limit: 10,
+	},
+	expectURL: "/users/bob/items/a/b?limit=10",
+}, {
+	about:       "no route",
+	val:         &struct{}{},
+	expectError: `type \*struct {} has no httprequest.Route field`,
+}, {
+	about: "missing path parameter",
+	val: &struct {
+		httprequest.Route `httprequest:"GET /users/:id"`
+	}{},
+	expectError: `missing value for path parameter "id"`,
+}}
+
+func TestRouteURL(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range routeURLTests {
+		c.Run(test.about, func(c *qt.C) {
+			u, err := httprequest.RouteURL(test.val)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(u, qt.Equals, test.expectURL)
+		})
+	}
+}
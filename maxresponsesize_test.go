@@ -0,0 +1,113 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// sizedResponseServer returns a server that responds to /ok with a
+// JSON string of 100 characters and to /error with an error with a
+// message of 100 characters.
+func sizedResponseServer() *httptest.Server {
+	msg := strings.Repeat("x", 100)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/error" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Message":"` + msg + `"}`))
+			return
+		}
+		w.Write([]byte(`"` + msg + `"`))
+	}))
+}
+
+var maxResponseSizeTests = []struct {
+	about          string
+	clientMax      int64
+	path           string
+	opts           []httprequest.CallOption
+	expectError    string
+	expectTooLarge int64
+}{{
+	about:          "client limit",
+	clientMax:      50,
+	path:           "/ok",
+	expectError:    `Get http://.*/ok: response body exceeds 50 bytes`,
+	expectTooLarge: 50,
+}, {
+	about:     "client limit not reached",
+	clientMax: 200,
+	path:      "/ok",
+}, {
+	about:          "call limit overrides client limit",
+	clientMax:      200,
+	path:           "/ok",
+	opts:           []httprequest.CallOption{httprequest.WithMaxResponseSize(20)},
+	expectError:    `Get http://.*/ok: response body exceeds 20 bytes`,
+	expectTooLarge: 20,
+}, {
+	about:     "negative call limit disables client limit",
+	clientMax: 50,
+	path:      "/ok",
+	opts:      []httprequest.CallOption{httprequest.WithMaxResponseSize(-1)},
+}, {
+	about:       "error responses not limited",
+	clientMax:   50,
+	path:        "/error",
+	expectError: `Get http://.*/error: x{100}`,
+}}
+
+func TestClientMaxResponseSize(t *testing.T) {
+	c := qt.New(t)
+	srv := sizedResponseServer()
+	defer srv.Close()
+	for _, test := range maxResponseSizeTests {
+		c.Run(test.about, func(c *qt.C) {
+			client := &httprequest.Client{
+				BaseURL:         srv.URL,
+				MaxResponseSize: test.clientMax,
+			}
+			var resp string
+			err := client.Get(context.Background(), test.path, &resp, test.opts...)
+			if test.expectError == "" {
+				c.Assert(err, qt.IsNil)
+				c.Assert(resp, qt.HasLen, 100)
+				return
+			}
+			c.Assert(err, qt.ErrorMatches, test.expectError)
+			if test.expectTooLarge != 0 {
+				c.Assert(errgo.Cause(err), qt.DeepEquals, &httprequest.ResponseTooLargeError{Limit: test.expectTooLarge})
+			}
+		})
+	}
+}
+
+func TestClientMaxResponseSizeRawResponse(t *testing.T) {
+	c := qt.New(t)
+	srv := sizedResponseServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:         srv.URL,
+		MaxResponseSize: 50,
+	}
+	var resp *http.Response
+	err := client.Get(context.Background(), "/ok", &resp)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.DeepEquals, &httprequest.ResponseTooLargeError{Limit: 50})
+	c.Assert(data, qt.HasLen, 50)
+}
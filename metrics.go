@@ -0,0 +1,182 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RequestInfo holds information about a request that has been served
+// by a handler created by Server.Handle or Server.Handlers. It is
+// passed to Server.Observe.
+type RequestInfo struct {
+	// Method holds the HTTP method of the request.
+	Method string
+
+	// PathPattern holds the path pattern of the route that served
+	// the request. It is empty if the handler was created with
+	// Server.Handle from a function whose argument has no Route
+	// field.
+	PathPattern string
+
+	// Status holds the HTTP status code of the response.
+	Status int
+
+	// Duration holds the total time taken to serve the request.
+	Duration time.Duration
+
+	// BytesRead holds the number of bytes of request body
+	// read while serving the request.
+	BytesRead int64
+
+	// BytesWritten holds the number of bytes of response body
+	// written, after any compression.
+	BytesWritten int64
+
+	// DecodeDuration holds the time taken to unmarshal the
+	// request parameters, including reading the request body.
+	DecodeDuration time.Duration
+
+	// HandlerDuration holds the time taken by the handler itself,
+	// including the root function passed to Server.Handlers.
+	HandlerDuration time.Duration
+
+	// EncodeDuration holds the time taken to marshal and write
+	// the handler's result or error.
+	EncodeDuration time.Duration
+}
+
+// StatusClass returns the class of the response status in the form
+// used by common metrics conventions, for example "2xx" or "5xx".
+func (info RequestInfo) StatusClass() string {
+	return fmt.Sprintf("%dxx", info.Status/100)
+}
+
+// Ensure statically that recordingResponseWriter does implement http.Flusher.
+var _ http.Flusher = (*recordingResponseWriter)(nil)
+
+// recordingResponseWriter wraps http.ResponseWriter and records
+// the status code of the response.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	code    int
+	written int64
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.written += int64(n)
+	return n, err
+}
+
+func (w *recordingResponseWriter) WriteHeader(code int) {
+	if w.code == 0 && code >= 200 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher.Flush.
+func (w *recordingResponseWriter) Flush() {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter so that
+// http.ResponseController can find any optional interfaces
+// it implements.
+func (w *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// status returns the status code written to the response.
+// If nothing has been written, the net/http server will
+// respond with http.StatusOK.
+func (w *recordingResponseWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// requestStats accumulates the measurements reported in RequestInfo
+// while a request is served. Its fields are accessed atomically
+// because a handler with a timeout may still be running when
+// the request completes. The methods may be called on a nil
+// *requestStats, in which case nothing is recorded.
+type requestStats struct {
+	read    int64
+	decode  int64
+	handler int64
+	encode  int64
+}
+
+type statsKey struct{}
+
+// contextWithStats returns a context that records stats
+// for use by statsFromContext.
+func contextWithStats(ctx context.Context, stats *requestStats) context.Context {
+	return context.WithValue(ctx, statsKey{}, stats)
+}
+
+// statsFromContext returns the stats recorded in ctx by
+// contextWithStats, or nil if there are none.
+func statsFromContext(ctx context.Context) *requestStats {
+	stats, _ := ctx.Value(statsKey{}).(*requestStats)
+	return stats
+}
+
+// start returns the time at which a measured phase starts.
+func (s *requestStats) start() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// addDecode records the end of a decoding phase begun at start.
+func (s *requestStats) addDecode(start time.Time) {
+	if s != nil {
+		atomic.AddInt64(&s.decode, int64(time.Since(start)))
+	}
+}
+
+// addHandler records the end of a handler phase begun at start.
+func (s *requestStats) addHandler(start time.Time) {
+	if s != nil {
+		atomic.AddInt64(&s.handler, int64(time.Since(start)))
+	}
+}
+
+// addEncode records the end of an encoding phase begun at start.
+func (s *requestStats) addEncode(start time.Time) {
+	if s != nil {
+		atomic.AddInt64(&s.encode, int64(time.Since(start)))
+	}
+}
+
+// countingReader wraps a request body and counts
+// the bytes read from it into stats.
+type countingReader struct {
+	io.ReadCloser
+	stats *requestStats
+}
+
+func (r countingReader) Read(data []byte) (int, error) {
+	n, err := r.ReadCloser.Read(data)
+	atomic.AddInt64(&r.stats.read, int64(n))
+	return n, err
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type observeHandlers struct{}
+
+func (observeHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /items/:id"`
+	ID                string `httprequest:"id,path"`
+}) (string, error) {
+	return "ok", nil
+}
+
+func (observeHandlers) Delete(*struct {
+	httprequest.Route `httprequest:"DELETE /items/:id"`
+	ID                string `httprequest:"id,path"`
+}) error {
+	return errgo.WithCausef(nil, errUnauth, "no deletions")
+}
+
+var observeTests = []struct {
+	about        string
+	method       string
+	url          string
+	expectStatus int
+	expectBody   interface{}
+	expectInfo   httprequest.RequestInfo
+	expectClass  string
+}{{
+	about:        "success",
+	method:       "GET",
+	url:          "/items/1",
+	expectStatus: http.StatusOK,
+	expectBody:   "ok",
+	expectInfo: httprequest.RequestInfo{
+		Method:       "GET",
+		PathPattern:  "/items/:id",
+		Status:       http.StatusOK,
+		BytesWritten: int64(len(`"ok"`)),
+	},
+	expectClass: "2xx",
+}, {
+	about:        "error",
+	method:       "DELETE",
+	url:          "/items/1",
+	expectStatus: http.StatusUnauthorized,
+	expectBody: &httprequest.RemoteError{
+		Message: "no deletions",
+		Code:    "unauthorized",
+	},
+	expectInfo: httprequest.RequestInfo{
+		Method:       "DELETE",
+		PathPattern:  "/items/:id",
+		Status:       http.StatusUnauthorized,
+		BytesWritten: int64(len(`{"Message":"no deletions","Code":"unauthorized"}`)),
+	},
+	expectClass: "4xx",
+}}
+
+func TestObserve(t *testing.T) {
+	c := qt.New(t)
+
+	var infos []httprequest.RequestInfo
+	srv := httprequest.Server{
+		ErrorMapper: testErrorMapper,
+		Observe: func(ctx context.Context, info httprequest.RequestInfo) {
+			infos = append(infos, info)
+		},
+	}
+	router := httprouter.New()
+	httprequest.AddHandlers(router, srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	for _, test := range observeTests {
+		c.Run(test.about, func(c *qt.C) {
+			infos = nil
+			qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+				Method:       test.method,
+				URL:          test.url,
+				Handler:      router,
+				ExpectStatus: test.expectStatus,
+				ExpectBody:   test.expectBody,
+			})
+			c.Assert(infos, qt.HasLen, 1)
+			c.Assert(infos[0].Duration > 0, qt.Equals, true)
+			infos[0].Duration = 0
+			infos[0].DecodeDuration = 0
+			infos[0].HandlerDuration = 0
+			infos[0].EncodeDuration = 0
+			c.Assert(infos[0], qt.DeepEquals, test.expectInfo)
+			c.Assert(infos[0].StatusClass(), qt.Equals, test.expectClass)
+		})
+	}
+}
+
+// slowMarshaler is a result that takes a known
+// time to marshal.
+type slowMarshaler struct{}
+
+func (slowMarshaler) MarshalJSON() ([]byte, error) {
+	time.Sleep(20 * time.Millisecond)
+	return []byte(`"slow"`), nil
+}
+
+func TestObserveStats(t *testing.T) {
+	c := qt.New(t)
+
+	var infos []httprequest.RequestInfo
+	srv := httprequest.Server{
+		Observe: func(ctx context.Context, info httprequest.RequestInfo) {
+			infos = append(infos, info)
+		},
+	}
+	h := srv.Handle(func(arg *struct {
+		httprequest.Route `httprequest:"PUT /stats"`
+		Body              []string `httprequest:",body"`
+	}) (slowMarshaler, error) {
+		time.Sleep(50 * time.Millisecond)
+		return slowMarshaler{}, nil
+	})
+	body := `["a","b","c"]`
+	req := httptest.NewRequest("PUT", "/stats", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.NewRouter([]httprequest.Handler{h}).ServeHTTP(rec, req)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+
+	c.Assert(infos, qt.HasLen, 1)
+	info := infos[0]
+	c.Assert(info.BytesRead, qt.Equals, int64(len(body)))
+	c.Assert(info.BytesWritten, qt.Equals, int64(len(`"slow"`)))
+	c.Assert(info.DecodeDuration > 0, qt.IsTrue)
+	c.Assert(info.HandlerDuration >= 50*time.Millisecond, qt.IsTrue, qt.Commentf("handler %v", info.HandlerDuration))
+	c.Assert(info.HandlerDuration < info.Duration, qt.IsTrue)
+	c.Assert(info.EncodeDuration >= 20*time.Millisecond, qt.IsTrue, qt.Commentf("encode %v", info.EncodeDuration))
+	c.Assert(info.EncodeDuration < 50*time.Millisecond, qt.IsTrue, qt.Commentf("encode %v", info.EncodeDuration))
+	c.Assert(info.DecodeDuration+info.HandlerDuration+info.EncodeDuration <= info.Duration, qt.IsTrue)
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Mount describes a child handler set that is mounted under a path
+// prefix by HandlersWithMounts.
+type Mount struct {
+	// Prefix holds the path prefix, relative to the parent's, under
+	// which the child's routes are mounted. It must start with a
+	// slash and may contain path parameters, such as
+	// "/projects/:projectid". Path parameters in the prefixes are
+	// available to the child's methods alongside those in their
+	// own routes, and to the functions that return the parent
+	// values.
+	Prefix string
+
+	// Handlers holds a function that returns the value whose
+	// methods handle requests to the child, given the value
+	// returned for the parent. It must be of the form:
+	//
+	// 	func(parent T, p httprequest.Params) (C, context.Context, error)
+	//
+	// where T is the type returned for the parent. Each exported
+	// method defined on C defines a handler as for Handlers.
+	//
+	// If the parent function takes a handlerArg parameter, the
+	// argument to each method on C must be compatible with its
+	// type too.
+	Handlers interface{}
+
+	// Mounts holds any handler sets to be mounted under the child.
+	Mounts []Mount
+}
+
+// HandlersWithMounts is like Handlers except that the handler sets
+// described by mounts are mounted under the parent handler type
+// returned by f, so that large APIs can be composed from several
+// handler types.
+//
+// When a request is made to a method on a mounted type, f is called
+// first, and the value it returns is passed to the function in the
+// Mount, and so on down to the type that defines the method. The
+// context returned at each level is used as Params.Context at the
+// next. If any of the functions returns an error, it is written as
+// the response. If any of the returned values implements io.Closer,
+// it is closed after the request is completed, innermost first.
+//
+// For example, given a Mount with the prefix "/projects/:projectid",
+// a method on the mounted type with the route "GET /issues/:id" will
+// have the path "/projects/:projectid/issues/:id", and f can use the
+// projectid path parameter to find the project that is passed to the
+// mounted type.
+//
+// Unlike Handlers, HandlersWithMounts does not require T to have any
+// methods so long as some mounted type does. It will panic if the
+// routes of any two methods conflict, including methods on different
+// types.
+func (srv *Server) HandlersWithMounts(f interface{}, mounts ...Mount) []Handler {
+	root := newHandlerRoot(f)
+	routes := new(routeChecker)
+	hs, omitted := srv.methodHandlers("", root, routes, false)
+	mhs, mountOmitted := srv.mountHandlers("", root, mounts, routes)
+	hs = append(hs, mhs...)
+	if len(hs) == 0 && omitted+mountOmitted == 0 {
+		panic(errgo.Newf("no exported methods defined on %s or any mounted type", root.t))
+	}
+	return hs
+}
+
+// mountHandlers returns the handlers for the given mounts under the
+// given parent, which is mounted at prefix, and the number of methods
+// omitted by srv.RouteFilter.
+func (srv *Server) mountHandlers(prefix string, parent handlerRoot, mounts []Mount, routes *routeChecker) (hs []Handler, omitted int) {
+	for _, m := range mounts {
+		if !strings.HasPrefix(m.Prefix, "/") {
+			panic(errgo.Newf("mount prefix %q does not start with /", m.Prefix))
+		}
+		root, err := newMountRoot(parent, m.Handlers)
+		if err != nil {
+			panic(errgo.Notef(err, "bad mount function for %s", m.Prefix))
+		}
+		mountPrefix := prefix + strings.TrimSuffix(m.Prefix, "/")
+		mhs, n := srv.methodHandlers(mountPrefix, root, routes, false)
+		hs, omitted = append(hs, mhs...), omitted+n
+		mhs, n = srv.mountHandlers(mountPrefix, root, m.Mounts, routes)
+		hs, omitted = append(hs, mhs...), omitted+n
+	}
+	return hs, omitted
+}
+
+// newMountRoot returns the handlerRoot for the mount function f,
+// which obtains its values from those obtained by parent.
+func newMountRoot(parent handlerRoot, f interface{}) (handlerRoot, error) {
+	fv := reflect.ValueOf(f)
+	ct, err := checkMountFunc(fv, parent.t)
+	if err != nil {
+		return handlerRoot{}, errgo.Mask(err)
+	}
+	hasClose := ct.Implements(ioCloserType)
+	return handlerRoot{
+		t:             ct,
+		argInterfacet: parent.argInterfacet,
+		get: func(p Params, argv reflect.Value, closers *[]io.Closer) (reflect.Value, context.Context, error) {
+			pv, ctx, err := parent.get(p, argv, closers)
+			if err != nil {
+				return reflect.Value{}, ctx, err
+			}
+			p.Context = ctx
+			outv := fv.Call([]reflect.Value{pv, reflect.ValueOf(p)})
+			return rootResult(outv, ctx, hasClose, closers)
+		},
+	}, nil
+}
+
+// checkMountFunc checks that fv is a mount function for the
+// parent type pt and returns the type of the values it returns.
+func checkMountFunc(fv reflect.Value, pt reflect.Type) (reflect.Type, error) {
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		return nil, errgo.Newf("expected function, got %v", ft)
+	}
+	if fv.IsNil() {
+		return nil, errgo.Newf("function is nil")
+	}
+	if n := ft.NumIn(); n != 2 {
+		return nil, errgo.Newf("got %d arguments, want 2", n)
+	}
+	if n := ft.NumOut(); n != 3 {
+		return nil, errgo.Newf("function returns %d values, want (<T>, context.Context, error)", n)
+	}
+	if t := ft.In(0); !pt.AssignableTo(t) {
+		return nil, errgo.Newf("invalid first argument, want %v, got %v", pt, t)
+	}
+	if t := ft.In(1); t != paramsType {
+		return nil, errgo.Newf("invalid second argument, want httprequest.Params, got %v", t)
+	}
+	if t := ft.Out(1); !t.Implements(contextType) {
+		return nil, errgo.Newf("second return parameter of type %v does not implement context.Context", t)
+	}
+	if t := ft.Out(2); t != errorType {
+		return nil, errgo.Newf("invalid third return parameter, want error, got %v", t)
+	}
+	return ft.Out(0), nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type orgHandlers struct {
+	org string
+	log *[]string
+}
+
+func (h *orgHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /"`
+}) (string, error) {
+	return h.org, nil
+}
+
+func (h *orgHandlers) Close() error {
+	*h.log = append(*h.log, "close org")
+	return nil
+}
+
+type projectHandlers struct {
+	org     *orgHandlers
+	project string
+}
+
+func (h *projectHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /"`
+}) (string, error) {
+	return h.org.org + "/" + h.project, nil
+}
+
+func (h *projectHandlers) Close() error {
+	*h.org.log = append(*h.org.log, "close project")
+	return nil
+}
+
+type issueHandlers struct {
+	project *projectHandlers
+}
+
+func (h issueHandlers) GetIssue(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /issues/:id"`
+	OrgID             string `httprequest:"orgid,path"`
+	ProjectID         string `httprequest:"projectid,path"`
+	ID                int    `httprequest:"id,path"`
+}) (interface{}, error) {
+	return map[string]interface{}{
+		"org":       arg.OrgID,
+		"project":   arg.ProjectID,
+		"id":        arg.ID,
+		"loaded":    h.project.org.org + "/" + h.project.project,
+		"tenant":    p.Context.Value(tenantKey{}),
+		"pattern":   p.PathPattern,
+		"projectid": p.PathVar.ByName("projectid"),
+	}, nil
+}
+
+func mountedHandlers(srv *httprequest.Server, log *[]string) []httprequest.Handler {
+	return srv.HandlersWithMounts(
+		func(p httprequest.Params) (*orgHandlers, context.Context, error) {
+			return &orgHandlers{
+				org: p.PathVar.ByName("orgid"),
+				log: log,
+			}, p.Context, nil
+		},
+		httprequest.Mount{
+			Prefix: "/orgs/:orgid/projects/:projectid/",
+			Handlers: func(org *orgHandlers, p httprequest.Params) (*projectHandlers, context.Context, error) {
+				project := p.PathVar.ByName("projectid")
+				if project == "missing" {
+					return nil, nil, httprequest.Errorf(httprequest.CodeNotFound, "project %q not found", project)
+				}
+				return &projectHandlers{
+					org:     org,
+					project: project,
+				}, context.WithValue(p.Context, tenantKey{}, org.org), nil
+			},
+			Mounts: []httprequest.Mount{{
+				Prefix: "/tracker",
+				Handlers: func(project *projectHandlers, p httprequest.Params) (issueHandlers, context.Context, error) {
+					return issueHandlers{project}, p.Context, nil
+				},
+			}},
+		},
+	)
+}
+
+func TestHandlersWithMounts(t *testing.T) {
+	c := qt.New(t)
+
+	var log []string
+	hs := mountedHandlers(&testServer, &log)
+	var routes []string
+	for _, h := range hs {
+		routes = append(routes, h.Method+" "+h.Path)
+	}
+	c.Assert(routes, qt.DeepEquals, []string{
+		"GET /",
+		"GET /orgs/:orgid/projects/:projectid/",
+		"GET /orgs/:orgid/projects/:projectid/tracker/issues/:id",
+	})
+
+	router := testServer.NewRouter(hs)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/orgs/acme/projects/rocket/tracker/issues/42", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, map[string]interface{}{
+		"org":       "acme",
+		"project":   "rocket",
+		"id":        42,
+		"loaded":    "acme/rocket",
+		"tenant":    "acme",
+		"pattern":   "/orgs/:orgid/projects/:projectid/tracker/issues/:id",
+		"projectid": "rocket",
+	})
+	c.Assert(log, qt.DeepEquals, []string{"close project", "close org"})
+
+	log = nil
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/orgs/acme/projects/rocket/", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "acme/rocket")
+	c.Assert(log, qt.DeepEquals, []string{"close project", "close org"})
+}
+
+func TestHandlersWithMountsParentError(t *testing.T) {
+	c := qt.New(t)
+
+	var log []string
+	var srv httprequest.Server
+	router := srv.NewRouter(mountedHandlers(&srv, &log))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/orgs/acme/projects/missing/tracker/issues/42", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusNotFound, &httprequest.RemoteError{
+		Code:    httprequest.CodeNotFound,
+		Message: `project "missing" not found`,
+	})
+	// The org was obtained successfully so it is still closed.
+	c.Assert(log, qt.DeepEquals, []string{"close org"})
+}
+
+type noMethodHandlers struct{}
+
+var handlersWithMountsPanicTests = []struct {
+	about  string
+	f      interface{}
+	mounts []httprequest.Mount
+	expect string
+}{{
+	about: "bad prefix",
+	f: func(p httprequest.Params) (*orgHandlers, context.Context, error) {
+		return nil, p.Context, nil
+	},
+	mounts: []httprequest.Mount{{
+		Prefix: "projects",
+		Handlers: func(*orgHandlers, httprequest.Params) (*projectHandlers, context.Context, error) {
+			return nil, nil, nil
+		},
+	}},
+	expect: `mount prefix "projects" does not start with /`,
+}, {
+	about: "wrong parent type",
+	f: func(p httprequest.Params) (*orgHandlers, context.Context, error) {
+		return nil, p.Context, nil
+	},
+	mounts: []httprequest.Mount{{
+		Prefix: "/projects/:projectid",
+		Handlers: func(*projectHandlers, httprequest.Params) (issueHandlers, context.Context, error) {
+			return issueHandlers{}, nil, nil
+		},
+	}},
+	expect: `bad mount function for /projects/:projectid: invalid first argument, want \*httprequest_test.orgHandlers, got \*httprequest_test.projectHandlers`,
+}, {
+	about: "missing params",
+	f: func(p httprequest.Params) (*orgHandlers, context.Context, error) {
+		return nil, p.Context, nil
+	},
+	mounts: []httprequest.Mount{{
+		Prefix: "/projects/:projectid",
+		Handlers: func(*orgHandlers) (*projectHandlers, context.Context, error) {
+			return nil, nil, nil
+		},
+	}},
+	expect: `bad mount function for /projects/:projectid: got 1 arguments, want 2`,
+}, {
+	about: "conflict with mounted route",
+	f: func(p httprequest.Params) (*orgHandlers, context.Context, error) {
+		return nil, p.Context, nil
+	},
+	mounts: []httprequest.Mount{{
+		Prefix: "/",
+		Handlers: func(*orgHandlers, httprequest.Params) (*projectHandlers, context.Context, error) {
+			return nil, nil, nil
+		},
+	}},
+	expect: `GET / defined by \(\*httprequest_test.projectHandlers\).Get conflicts with GET / defined by \(\*httprequest_test.orgHandlers\).Get`,
+}, {
+	about: "no methods anywhere",
+	f: func(p httprequest.Params) (noMethodHandlers, context.Context, error) {
+		return noMethodHandlers{}, p.Context, nil
+	},
+	expect: `no exported methods defined on httprequest_test.noMethodHandlers or any mounted type`,
+}}
+
+func TestHandlersWithMountsPanics(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range handlersWithMountsPanicTests {
+		c.Run(test.about, func(c *qt.C) {
+			c.Assert(func() {
+				testServer.HandlersWithMounts(test.f, test.mounts...)
+			}, qt.PanicMatches, test.expect)
+		})
+	}
+}
+
+func TestHandlersWithMountsWithoutParentMethods(t *testing.T) {
+	c := qt.New(t)
+
+	hs := testServer.HandlersWithMounts(
+		func(p httprequest.Params) (noMethodHandlers, context.Context, error) {
+			return noMethodHandlers{}, p.Context, nil
+		},
+		httprequest.Mount{
+			Prefix: "/tenants/:tenant",
+			Handlers: func(_ noMethodHandlers, p httprequest.Params) (tenantHandlers, context.Context, error) {
+				tenant := p.PathVar.ByName("tenant")
+				return tenantHandlers{tenant}, context.WithValue(p.Context, tenantKey{}, tenant), nil
+			},
+		},
+	)
+	rec := httptest.NewRecorder()
+	testServer.NewRouter(hs).ServeHTTP(rec, httptest.NewRequest("GET", "/tenants/acme/tenant", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "acme")
+}
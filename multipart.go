@@ -0,0 +1,170 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Multipart is a type that can be returned from a handler to send a
+// JSON value together with a number of attachments as a
+// multipart/mixed response. The first part holds the JSON-marshaled
+// Body and each following part holds one of Parts.
+//
+// A Client can decode such a response when passed a *Multipart as the
+// response value; Body should then hold a pointer to the value to
+// unmarshal the first part into, and Parts will be filled in with the
+// remaining parts. See also UnmarshalMultipartResponse.
+type Multipart struct {
+	// Body holds the value sent as JSON in the first part.
+	Body interface{}
+
+	// Parts holds the attachments sent after Body.
+	Parts []Part
+}
+
+// Part holds an attachment in a Multipart response.
+type Part struct {
+	// Name holds the name of the attachment. If it is not empty,
+	// it is sent as the filename parameter in the Content-Disposition
+	// header of the part.
+	Name string
+
+	// ContentType holds the content type of the part. If it is
+	// empty, "application/octet-stream" is used.
+	ContentType string
+
+	// Data holds the contents of the part.
+	Data []byte
+}
+
+// writeMultipart writes m as a multipart/mixed response.
+func writeMultipart(w http.ResponseWriter, m Multipart) error {
+	// Marshal the body before writing anything so that any
+	// error can still be sent as an error response.
+	body, err := json.Marshal(m.Body)
+	if err != nil {
+		return errgo.Notef(err, "cannot marshal multipart body")
+	}
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{
+		"boundary": mw.Boundary(),
+	}))
+	w.WriteHeader(http.StatusOK)
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"application/json"},
+	})
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if _, err := pw.Write(body); err != nil {
+		return errgo.Mask(err)
+	}
+	for _, p := range m.Parts {
+		contentType := p.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := textproto.MIMEHeader{
+			"Content-Type": {contentType},
+		}
+		if p.Name != "" {
+			h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+				"filename": p.Name,
+			}))
+		}
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		if _, err := pw.Write(p.Data); err != nil {
+			return errgo.Mask(err)
+		}
+	}
+	return errgo.Mask(mw.Close())
+}
+
+// UnmarshalMultipartResponse unmarshals a multipart/mixed response,
+// as written for a Multipart result, into m. The first part is
+// unmarshaled as JSON into m.Body, which should hold a pointer
+// to the result to be unmarshaled into (or nil to ignore it).
+// The remaining parts are appended to m.Parts.
+//
+// If the response cannot be unmarshaled, an error of type
+// *DecodeResponseError will be returned.
+func UnmarshalMultipartResponse(resp *http.Response, m *Multipart) error {
+	return unmarshalMultipartResponse(resp, m, 0)
+}
+
+// unmarshalMultipartResponse is the internal version of
+// UnmarshalMultipartResponse. Up to limit bytes of the body are
+// captured in any error (see readBodyForError).
+func unmarshalMultipartResponse(resp *http.Response, m *Multipart, limit int) error {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		return newDecodeResponseError(resp, nil, errgo.Newf("unexpected content type %q; want multipart/mixed", resp.Header.Get("Content-Type")), limit)
+	}
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+	for i := 0; ; i++ {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			if i == 0 {
+				return newDecodeResponseError(resp, nil, errgo.New("no parts found in multipart response"), limit)
+			}
+			return nil
+		}
+		if err != nil {
+			return newDecodeResponseError(resp, nil, errgo.Notef(err, "cannot read multipart response"), limit)
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			return newDecodeResponseError(resp, nil, errgo.Notef(err, "cannot read multipart response"), limit)
+		}
+		if i == 0 {
+			if m.Body == nil {
+				continue
+			}
+			if !isJSONMediaType(http.Header(p.Header)) {
+				return newDecodeResponseError(resp, nil, errgo.Newf("unexpected content type %q for first part; want application/json", p.Header.Get("Content-Type")), limit)
+			}
+			if err := json.Unmarshal(data, m.Body); err != nil {
+				return newDecodeResponseError(resp, data, err, limit)
+			}
+			continue
+		}
+		m.Parts = append(m.Parts, Part{
+			Name:        p.FileName(),
+			ContentType: p.Header.Get("Content-Type"),
+			Data:        data,
+		})
+	}
+}
+
+var multipartReaderType = reflect.TypeOf((*multipart.Reader)(nil))
+
+// unmarshalMultipart sets v, which must be of type *multipart.Reader,
+// to a reader for the parts of the request body. The body is not
+// read until the handler reads the parts.
+func unmarshalMultipart(v reflect.Value, p Params, makeResult resultMaker) error {
+	mr, err := p.Request.MultipartReader()
+	if err != nil {
+		return errgo.Notef(err, "cannot read multipart body")
+	}
+	v.Set(reflect.ValueOf(mr))
+	return nil
+}
+
+// marshalMultipart returns an error because a multipart reader
+// cannot be sent as the body of a request.
+func marshalMultipart(v reflect.Value, p *Params) error {
+	return errgo.New("cannot marshal multipart field")
+}
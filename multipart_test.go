@@ -0,0 +1,94 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type multipartReport struct {
+	Total int
+}
+
+type multipartReportRequest struct {
+	httprequest.Route `httprequest:"GET /report"`
+}
+
+func TestMultipartRoundTrip(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(*multipartReportRequest) (httprequest.Multipart, error) {
+		return httprequest.Multipart{
+			Body: multipartReport{Total: 3},
+			Parts: []httprequest.Part{{
+				Name:        "source.csv",
+				ContentType: "text/csv",
+				Data:        []byte("a,1\nb,2\n"),
+			}, {
+				Data: []byte{0, 1, 2},
+			}},
+		}, nil
+	})
+	srv := httptest.NewServer(httprequest.ToHTTP(h.Handle))
+	defer srv.Close()
+
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var report multipartReport
+	m := httprequest.Multipart{
+		Body: &report,
+	}
+	err := client.Call(context.Background(), &multipartReportRequest{}, &m)
+	c.Assert(err, qt.IsNil)
+	c.Assert(report, qt.DeepEquals, multipartReport{Total: 3})
+	c.Assert(m.Parts, qt.DeepEquals, []httprequest.Part{{
+		Name:        "source.csv",
+		ContentType: "text/csv",
+		Data:        []byte("a,1\nb,2\n"),
+	}, {
+		ContentType: "application/octet-stream",
+		Data:        []byte{0, 1, 2},
+	}})
+}
+
+func TestMultipartBodyMarshalError(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(*struct{}) (httprequest.Multipart, error) {
+		return httprequest.Multipart{
+			Body: make(chan int),
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusInternalServerError)
+	c.Assert(rec.Body.String(), qt.Matches, `.*cannot marshal multipart body.*`)
+}
+
+func TestUnmarshalMultipartResponseWithBadContentType(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		httprequest.WriteJSON(w, http.StatusOK, "not multipart")
+	}))
+	defer srv.Close()
+
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var m httprequest.Multipart
+	err := client.Get(context.Background(), "/", &m)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: unexpected content type "application/json"; want multipart/mixed`)
+	_, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+	c.Assert(ok, qt.IsTrue)
+}
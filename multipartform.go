@@ -0,0 +1,262 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// MultipartForm holds the contents of a multipart/form-data request
+// body sent by Client.CallMultipart, as read by a handler through a
+// multipart field (see Unmarshal).
+type MultipartForm struct {
+	// Fields holds the form fields, which are sent
+	// before any files in order of their names.
+	Fields url.Values
+
+	// Files holds the files, which are sent in order.
+	Files []FormFile
+}
+
+// FormFile holds a file in a MultipartForm.
+type FormFile struct {
+	// Field holds the name of the form field holding the file.
+	Field string
+
+	// Name holds the name of the file.
+	Name string
+
+	// ContentType holds the content type of the file. If it is
+	// empty, application/octet-stream is used.
+	ContentType string
+
+	// Open, if non-nil, is called to obtain the contents of the
+	// file each time the request is sent, so requests with files
+	// that have it can be retried.
+	Open func() (io.ReadCloser, error)
+
+	// Reader holds the contents of the file if Open is nil.
+	// It can only be read once, so the request is not retried.
+	Reader io.Reader
+
+	// Size holds the size of the contents in bytes. If it is zero
+	// or negative and Open is nil, it is taken from the Len method
+	// of Reader, if it has one, as implemented by *bytes.Reader,
+	// *bytes.Buffer and *strings.Reader. If the sizes of all the
+	// files are known, the request is sent with a Content-Length;
+	// otherwise it is sent using chunked encoding.
+	Size int64
+}
+
+// NewFormFile returns a FormFile for the given form field that reads
+// the file at the given path when the request is sent. The file's
+// content type is derived from its extension.
+func NewFormFile(field, path string) (FormFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FormFile{}, errgo.Mask(err, os.IsNotExist)
+	}
+	if !info.Mode().IsRegular() {
+		return FormFile{}, errgo.Newf("%q is not a regular file", path)
+	}
+	return FormFile{
+		Field:       field,
+		Name:        filepath.Base(path),
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+		Size: info.Size(),
+	}, nil
+}
+
+// CallMultipart is like Call except that the request body is the given
+// form, sent as multipart/form-data. The parameters should not have
+// body or inbody fields; a multipart field, if any, should be nil.
+//
+// The files are streamed as the request is sent rather than being
+// held in memory.
+func (c *Client) CallMultipart(ctx context.Context, params interface{}, form *MultipartForm, resp interface{}, opts ...CallOption) error {
+	opts = append(opts[:len(opts):len(opts)], func(o *callOptions) {
+		o.multipartForm = form
+	})
+	return c.Call(ctx, params, resp, opts...)
+}
+
+// setBody sets the body of req to the form.
+func (f *MultipartForm) setBody(req *http.Request) {
+	boundary := multipart.NewWriter(nil).Boundary()
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+	req.ContentLength = f.contentLength(boundary)
+	if req.ContentLength == 0 {
+		// The length isn't known.
+		req.ContentLength = -1
+	}
+	req.Body = f.body(boundary)
+	req.GetBody = nil
+	if f.replayable() {
+		req.GetBody = func() (io.ReadCloser, error) {
+			return f.body(boundary), nil
+		}
+	}
+}
+
+// body returns a reader that streams the form encoded as
+// multipart/form-data with the given boundary.
+func (f *MultipartForm) body(boundary string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(f.write(pw, boundary, false))
+	}()
+	return pr
+}
+
+// write writes the form encoded as multipart/form-data with the given
+// boundary to w. If framingOnly is true, the contents of the files
+// are omitted.
+func (f *MultipartForm) write(w io.Writer, boundary string, framingOnly bool) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(boundary); err != nil {
+		return errgo.Mask(err)
+	}
+	names := make([]string, 0, len(f.Fields))
+	for name := range f.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range f.Fields[name] {
+			if err := mw.WriteField(name, v); err != nil {
+				return errgo.Mask(err)
+			}
+		}
+	}
+	for _, file := range f.Files {
+		pw, err := mw.CreatePart(file.header())
+		if err != nil {
+			return errgo.Mask(err)
+		}
+		if framingOnly {
+			continue
+		}
+		if err := file.copy(pw); err != nil {
+			return errgo.Mask(err)
+		}
+	}
+	return errgo.Mask(mw.Close())
+}
+
+// contentLength returns the length of the form encoded with the given
+// boundary, or zero if it is not known.
+func (f *MultipartForm) contentLength(boundary string) int64 {
+	var n int64
+	for _, file := range f.Files {
+		size := file.size()
+		if size <= 0 {
+			return 0
+		}
+		n += size
+	}
+	cw := &countingWriter{}
+	if err := f.write(cw, boundary, true); err != nil {
+		return 0
+	}
+	return n + cw.n
+}
+
+// replayable reports whether the form can be sent more than once.
+func (f *MultipartForm) replayable() bool {
+	for _, file := range f.Files {
+		if file.Open == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// header returns the MIME header of the part holding the file.
+func (file FormFile) header() textproto.MIMEHeader {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     file.Field,
+		"filename": file.Name,
+	}))
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// size returns the size of the file's contents,
+// or zero if it is not known.
+func (file FormFile) size() int64 {
+	if file.Size > 0 {
+		return file.Size
+	}
+	if r, ok := file.Reader.(interface{ Len() int }); ok && file.Open == nil {
+		return int64(r.Len())
+	}
+	return 0
+}
+
+// copy copies the contents of the file to w, checking that
+// their size is as expected if it is known.
+func (file FormFile) copy(w io.Writer) error {
+	r := file.Reader
+	if file.Open != nil {
+		rc, err := file.Open()
+		if err != nil {
+			return errgo.Notef(err, "cannot open file %q", file.Name)
+		}
+		defer rc.Close()
+		r = rc
+	}
+	if r == nil {
+		r = strings.NewReader("")
+	}
+	size := file.size()
+	if size <= 0 {
+		if _, err := io.Copy(w, r); err != nil {
+			return errgo.Notef(err, "cannot read file %q", file.Name)
+		}
+		return nil
+	}
+	n, err := io.Copy(w, io.LimitReader(r, size))
+	if err != nil {
+		return errgo.Notef(err, "cannot read file %q", file.Name)
+	}
+	if n != size {
+		return errgo.Newf("file %q has %d bytes, expected %d", file.Name, n, size)
+	}
+	if extra, _ := io.Copy(ioutil.Discard, io.LimitReader(r, 1)); extra > 0 {
+		return errgo.Newf("file %q is larger than %d bytes", file.Name, size)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+// Write implements io.Writer.Write.
+func (w *countingWriter) Write(buf []byte) (int, error) {
+	w.n += int64(len(buf))
+	return len(buf), nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type uploadRequest struct {
+	httprequest.Route `httprequest:"POST /uploads/:bucket"`
+	Bucket            string            `httprequest:"bucket,path"`
+	Upload            *multipart.Reader `httprequest:",multipart"`
+}
+
+type uploadedPart struct {
+	Name     string
+	FileName string `json:",omitempty"`
+	Size     int64
+}
+
+func readUpload(r *uploadRequest) ([]uploadedPart, error) {
+	var parts []uploadedPart
+	for {
+		part, err := r.Upload.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		n, err := io.Copy(io.Discard, part)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, uploadedPart{
+			Name:     part.FormName(),
+			FileName: part.FileName(),
+			Size:     n,
+		})
+	}
+}
+
+func newUploadBody(c *qt.C) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	c.Assert(mw.WriteField("comment", "hello"), qt.IsNil)
+	fw, err := mw.CreateFormFile("file", "data.bin")
+	c.Assert(err, qt.IsNil)
+	_, err = fw.Write(bytes.Repeat([]byte("x"), 100000))
+	c.Assert(err, qt.IsNil)
+	c.Assert(mw.Close(), qt.IsNil)
+	return &buf, mw.FormDataContentType()
+}
+
+func TestMultipartUpload(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		StrictContentType: true,
+	}
+	router := srv.NewRouter([]httprequest.Handler{srv.Handle(func(p httprequest.Params, r *uploadRequest) ([]uploadedPart, error) {
+		if r.Bucket != "photos" {
+			return nil, httprequest.Errorf(httprequest.CodeNotFound, "no bucket %q", r.Bucket)
+		}
+		return readUpload(r)
+	})})
+
+	body, contentType := newUploadBody(c)
+	req := httptest.NewRequest("POST", "/uploads/photos", body)
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, []uploadedPart{{
+		Name: "comment",
+		Size: 5,
+	}, {
+		Name:     "file",
+		FileName: "data.bin",
+		Size:     100000,
+	}})
+
+	req = httptest.NewRequest("POST", "/uploads/photos", strings.NewReader("a=b"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusUnsupportedMediaType, &httprequest.RemoteError{
+		Code:    httprequest.CodeUnsupportedMediaType,
+		Message: `unsupported content type "application/x-www-form-urlencoded"; want multipart/form-data`,
+	})
+}
+
+func TestMultipartUploadNotMultipart(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	h := srv.Handle(func(p httprequest.Params, r *uploadRequest) ([]uploadedPart, error) {
+		return readUpload(r)
+	})
+	req := httptest.NewRequest("POST", "/uploads/photos", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.NewRouter([]httprequest.Handler{h}).ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusInternalServerError, &httprequest.RemoteError{
+		Message: `cannot unmarshal parameters: cannot unmarshal into field Upload: cannot read multipart body: request Content-Type isn't multipart/form-data`,
+		Fields: []httprequest.FieldError{{
+			Field:   "Upload",
+			Code:    httprequest.CodeBadRequest,
+			Message: `cannot read multipart body: request Content-Type isn't multipart/form-data`,
+		}},
+	})
+}
+
+func TestMultipartUploadIsStreamed(t *testing.T) {
+	c := qt.New(t)
+
+	// The client writes the file part in two halves and waits
+	// for the handler to read the first half before writing
+	// the second, which can only happen if the handler is called
+	// before the body has been read in full.
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	firstHalfRead := make(chan struct{})
+	go func() {
+		fw, err := mw.CreateFormFile("file", "big.bin")
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		fw.Write([]byte("first half;"))
+		<-firstHalfRead
+		fw.Write([]byte("second half"))
+		pw.CloseWithError(mw.Close())
+	}()
+
+	var srv httprequest.Server
+	h := srv.Handle(func(p httprequest.Params, r *uploadRequest) (string, error) {
+		part, err := r.Upload.NextPart()
+		if err != nil {
+			return "", err
+		}
+		buf := make([]byte, len("first half;"))
+		if _, err := io.ReadFull(part, buf); err != nil {
+			return "", err
+		}
+		close(firstHalfRead)
+		rest, err := io.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+		return string(buf) + string(rest), nil
+	})
+	req := httptest.NewRequest("POST", "/uploads/photos", pr)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	srv.NewRouter([]httprequest.Handler{h}).ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "first half;second half")
+}
+
+// uploadServer returns a server that serves uploadRequest, recording
+// the content length of each request in *contentLengths.
+func uploadServer(contentLengths *[]int64) *httptest.Server {
+	var srv httprequest.Server
+	router := srv.NewRouter([]httprequest.Handler{srv.Handle(func(p httprequest.Params, r *uploadRequest) ([]uploadedPart, error) {
+		return readUpload(r)
+	})})
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		*contentLengths = append(*contentLengths, req.ContentLength)
+		router.ServeHTTP(w, req)
+	}))
+}
+
+func TestCallMultipart(t *testing.T) {
+	c := qt.New(t)
+
+	var contentLengths []int64
+	srv := uploadServer(&contentLengths)
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	path := filepath.Join(c.TempDir(), "photo.jpg")
+	err := ioutil.WriteFile(path, bytes.Repeat([]byte("x"), 100000), 0666)
+	c.Assert(err, qt.IsNil)
+	file, err := httprequest.NewFormFile("photo", path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Name, qt.Equals, "photo.jpg")
+	c.Assert(file.ContentType, qt.Equals, "image/jpeg")
+	c.Assert(file.Size, qt.Equals, int64(100000))
+
+	var parts []uploadedPart
+	err = client.CallMultipart(context.Background(), &uploadRequest{
+		Bucket: "photos",
+	}, &httprequest.MultipartForm{
+		Fields: url.Values{
+			"comment": {"hello"},
+		},
+		Files: []httprequest.FormFile{file, {
+			Field:  "notes",
+			Name:   "notes.txt",
+			Reader: strings.NewReader("some notes"),
+		}},
+	}, &parts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts, qt.DeepEquals, []uploadedPart{{
+		Name: "comment",
+		Size: 5,
+	}, {
+		Name:     "photo",
+		FileName: "photo.jpg",
+		Size:     100000,
+	}, {
+		Name:     "notes",
+		FileName: "notes.txt",
+		Size:     10,
+	}})
+	c.Assert(contentLengths, qt.HasLen, 1)
+	c.Assert(contentLengths[0] > 100010, qt.IsTrue, qt.Commentf("%d", contentLengths[0]))
+}
+
+func TestCallMultipartWithUnknownSize(t *testing.T) {
+	c := qt.New(t)
+
+	var contentLengths []int64
+	srv := uploadServer(&contentLengths)
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	var parts []uploadedPart
+	err := client.CallMultipart(context.Background(), &uploadRequest{
+		Bucket: "photos",
+	}, &httprequest.MultipartForm{
+		Files: []httprequest.FormFile{{
+			Field:  "data",
+			Name:   "data.bin",
+			Reader: io.MultiReader(strings.NewReader("streamed data")),
+		}},
+	}, &parts)
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts, qt.DeepEquals, []uploadedPart{{
+		Name:     "data",
+		FileName: "data.bin",
+		Size:     13,
+	}})
+	c.Assert(contentLengths, qt.DeepEquals, []int64{-1})
+}
+
+func TestCallMultipartWithWrongSize(t *testing.T) {
+	c := qt.New(t)
+
+	var contentLengths []int64
+	srv := uploadServer(&contentLengths)
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	err := client.CallMultipart(context.Background(), &uploadRequest{
+		Bucket: "photos",
+	}, &httprequest.MultipartForm{
+		Files: []httprequest.FormFile{{
+			Field:  "data",
+			Name:   "data.bin",
+			Reader: io.MultiReader(strings.NewReader("short")),
+			Size:   100,
+		}},
+	}, nil)
+	c.Assert(err, qt.ErrorMatches, `Post "?http://.*/uploads/photos"?: .*file "data.bin" has 5 bytes, expected 100`)
+}
+
+func TestCallMultipartRetry(t *testing.T) {
+	c := qt.New(t)
+
+	var contentLengths []int64
+	srv := uploadServer(&contentLengths)
+	defer srv.Close()
+	attempts := 0
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				// Read some of the body before failing.
+				req.Body.Read(make([]byte, 10))
+				req.Body.Close()
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     make(http.Header),
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}
+			return http.DefaultClient.Do(req)
+		}),
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     func(int) time.Duration { return 0 },
+		},
+	}
+	path := filepath.Join(c.TempDir(), "data.txt")
+	err := ioutil.WriteFile(path, []byte("some data"), 0666)
+	c.Assert(err, qt.IsNil)
+	file, err := httprequest.NewFormFile("data", path)
+	c.Assert(err, qt.IsNil)
+
+	var parts []uploadedPart
+	err = client.CallMultipart(context.Background(), &uploadRequest{
+		Bucket: "photos",
+	}, &httprequest.MultipartForm{
+		Files: []httprequest.FormFile{file},
+	}, &parts, httprequest.WithIdempotencyKey("upload-1"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(attempts, qt.Equals, 2)
+	c.Assert(parts, qt.DeepEquals, []uploadedPart{{
+		Name:     "data",
+		FileName: "data.txt",
+		Size:     9,
+	}})
+}
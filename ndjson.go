@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// isRecvChan reports whether v is a receive-only channel.
+func isRecvChan(v reflect.Value) bool {
+	return v.Kind() == reflect.Chan && v.Type().ChanDir() == reflect.RecvDir
+}
+
+// writeNDJSON writes each value received from the channel ch as one
+// line of JSON in the response to req. It returns when the channel is
+// closed or the request context is done.
+func (srv *Server) writeNDJSON(w http.ResponseWriter, req *http.Request, ch reflect.Value) {
+	ctx := req.Context()
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	rc.Flush()
+	if ch.IsNil() {
+		return
+	}
+	cases := []reflect.SelectCase{{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	}, {
+		Dir:  reflect.SelectRecv,
+		Chan: ch,
+	}}
+	enc := json.NewEncoder(w)
+	for {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 || !ok {
+			return
+		}
+		if err := enc.Encode(v.Interface()); err != nil {
+			srv.logFailure(ctx, "cannot write streamed value", err)
+			return
+		}
+		rc.Flush()
+	}
+}
+
+// CallStream is like Call except that the response is expected to
+// hold a stream of JSON values, such as the application/x-ndjson
+// response sent by a handler that returns a receive-only channel.
+// Each value is unmarshaled into a new value of the channel's element
+// type and sent on ch, which must be a channel that can be sent on,
+// such as chan T or chan<- T.
+//
+// CallStream returns when the stream ends, when ctx is done or when a
+// value cannot be unmarshaled, and closes ch before returning. It
+// returns nil only if the whole stream was received. Values are sent
+// as they are received, so the caller would usually call CallStream
+// in a separate goroutine, for example:
+//
+//	items := make(chan Item)
+//	errc := make(chan error, 1)
+//	go func() {
+//		errc <- client.CallStream(ctx, &params.ListItems{}, items)
+//	}()
+//	for item := range items {
+//		...
+//	}
+//	if err := <-errc; err != nil {
+//		...
+//	}
+//
+// Both newline-delimited JSON and concatenated JSON values are
+// accepted; the response must have a JSON media type (see
+// UnmarshalJSONResponse), or one of application/x-ndjson,
+// application/jsonl or application/x-jsonlines.
+func (c *Client) CallStream(ctx context.Context, params, ch interface{}, opts ...CallOption) error {
+	chv := reflect.ValueOf(ch)
+	if chv.Kind() != reflect.Chan || chv.Type().ChanDir()&reflect.SendDir == 0 {
+		return errgo.Newf("cannot stream into value of type %T", ch)
+	}
+	defer chv.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opts = append(opts[:len(opts):len(opts)], WithHeader("Accept", "application/x-ndjson"))
+	var httpResp *http.Response
+	if err := c.Call(ctx, params, &httpResp, opts...); err != nil {
+		return errgo.Mask(err, errgo.Any)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if !isJSONStreamMediaType(httpResp.Header) {
+		fancyErr := newFancyDecodeError(httpResp.Header, httpResp.Body, c.MaxErrorBodySize)
+		return errgo.Mask(urlError(newDecodeResponseError(httpResp, fancyErr.body, fancyErr, c.MaxErrorBodySize), httpResp.Request), isDecodeResponseError)
+	}
+	dec := json.NewDecoder(httpResp.Body)
+	elemType := chv.Type().Elem()
+	cases := []reflect.SelectCase{{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ctx.Done()),
+	}, {
+		Dir:  reflect.SelectSend,
+		Chan: chv,
+	}}
+	for {
+		v := reflect.New(elemType)
+		if err := dec.Decode(v.Interface()); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return errgo.Mask(ctx.Err(), errgo.Any)
+			}
+			return errgo.Mask(urlError(newDecodeResponseError(httpResp, []byte{}, err, c.MaxErrorBodySize), httpResp.Request), isDecodeResponseError)
+		}
+		cases[1].Send = v.Elem()
+		if chosen, _, _ := reflect.Select(cases); chosen == 0 {
+			return errgo.Mask(ctx.Err(), errgo.Any)
+		}
+	}
+}
+
+// isJSONStreamMediaType reports whether the Content-Type in the given
+// header holds a stream of JSON values.
+func isJSONStreamMediaType(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return true
+	}
+	return isJSONMediaType(header)
+}
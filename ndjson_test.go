@@ -0,0 +1,205 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type ndjsonItem struct {
+	N int `json:"n"`
+}
+
+func TestChannelResult(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct{}) (<-chan ndjsonItem, error) {
+		items := make(chan ndjsonItem)
+		go func() {
+			defer close(items)
+			for i := 0; i < 3; i++ {
+				items <- ndjsonItem{N: i}
+			}
+		}()
+		return items, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "application/x-ndjson")
+	c.Assert(rec.Flushed, qt.Equals, true)
+	c.Assert(rec.Body.String(), qt.Equals, `{"n":0}
+{"n":1}
+{"n":2}
+`)
+}
+
+func TestNilChannelResult(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct{}) (<-chan ndjsonItem, error) {
+		return nil, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), qt.Equals, "")
+}
+
+func TestChannelResultContextDone(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct{}) (<-chan ndjsonItem, error) {
+		return make(chan ndjsonItem), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	req := (&http.Request{}).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Handle(httptest.NewRecorder(), req, nil)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatalf("stream not closed after request context done")
+	}
+}
+
+type ndjsonItemsRequest struct {
+	httprequest.Route `httprequest:"GET /items"`
+	Count             int `httprequest:"count,form,omitempty"`
+}
+
+type ndjsonTextRequest struct {
+	httprequest.Route `httprequest:"GET /text"`
+}
+
+// ndjsonServer returns a server that streams the number of items
+// requested from /items, or items until the request is done if
+// no count is specified, and responds to /text with plain text.
+func ndjsonServer() *httptest.Server {
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *ndjsonItemsRequest) (<-chan ndjsonItem, error) {
+			if req.Count < 0 {
+				return nil, httprequest.Errorf(httprequest.CodeBadRequest, "negative count")
+			}
+			items := make(chan ndjsonItem)
+			go func() {
+				defer close(items)
+				for i := 0; req.Count == 0 || i < req.Count; i++ {
+					select {
+					case items <- ndjsonItem{N: i}:
+					case <-p.Context.Done():
+						return
+					}
+				}
+			}()
+			return items, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *ndjsonTextRequest) {
+			p.Response.Header().Set("Content-Type", "text/plain")
+			io.WriteString(p.Response, "not json")
+		}),
+	}))
+}
+
+func TestCallStream(t *testing.T) {
+	c := qt.New(t)
+
+	srv := ndjsonServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	items := make(chan ndjsonItem)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.CallStream(context.Background(), &ndjsonItemsRequest{Count: 3}, items)
+	}()
+	var got []ndjsonItem
+	for item := range items {
+		got = append(got, item)
+	}
+	c.Assert(<-errc, qt.IsNil)
+	c.Assert(got, qt.DeepEquals, []ndjsonItem{{N: 0}, {N: 1}, {N: 2}})
+}
+
+func TestCallStreamErrorResponse(t *testing.T) {
+	c := qt.New(t)
+
+	srv := ndjsonServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	items := make(chan ndjsonItem, 1)
+	err := client.CallStream(context.Background(), &ndjsonItemsRequest{Count: -1}, items)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/items\?count=-1: negative count`)
+	_, ok := <-items
+	c.Assert(ok, qt.Equals, false)
+}
+
+func TestCallStreamNotJSON(t *testing.T) {
+	c := qt.New(t)
+
+	srv := ndjsonServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	err := client.CallStream(context.Background(), &ndjsonTextRequest{}, make(chan ndjsonItem))
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/text: unexpected content type text/plain; want application/json; content: not json`)
+	_, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+	c.Assert(ok, qt.Equals, true)
+}
+
+func TestCallStreamContextDone(t *testing.T) {
+	c := qt.New(t)
+
+	srv := ndjsonServer()
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := make(chan ndjsonItem)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- client.CallStream(ctx, &ndjsonItemsRequest{}, items)
+	}()
+	c.Assert(<-items, qt.Equals, ndjsonItem{N: 0})
+	cancel()
+	select {
+	case err := <-errc:
+		c.Assert(err, qt.ErrorMatches, `.*context canceled`)
+	case <-time.After(5 * time.Second):
+		c.Fatalf("stream not closed after context done")
+	}
+	for range items {
+	}
+}
+
+func TestCallStreamBadChannel(t *testing.T) {
+	c := qt.New(t)
+
+	client := &httprequest.Client{}
+	err := client.CallStream(context.Background(), &ndjsonItemsRequest{}, make(<-chan ndjsonItem))
+	c.Assert(err, qt.ErrorMatches, `cannot stream into value of type <-chan httprequest_test.ndjsonItem`)
+	err = client.CallStream(context.Background(), &ndjsonItemsRequest{}, []ndjsonItem{})
+	c.Assert(err, qt.ErrorMatches, `cannot stream into value of type \[\]httprequest_test.ndjsonItem`)
+}
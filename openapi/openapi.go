@@ -0,0 +1,250 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package openapi generates OpenAPI documents describing handlers
+// created by httprequest.Server and provides handlers that serve them.
+//
+// The generated documents are derived only from the method, path
+// and metadata of each handler (see httprequest.RouteMetadata). They
+// describe path parameters, but not query, form or header parameters,
+// and they contain no request or response body schemas.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// Version holds the version of the OpenAPI specification
+// that generated documents conform to.
+const Version = "3.0.3"
+
+// DefaultSpecPath holds the path used to serve the
+// OpenAPI document when Config.SpecPath is empty.
+const DefaultSpecPath = "/openapi.json"
+
+// Info holds general information about an API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document represents an OpenAPI document. Only the
+// subset of the specification that can be derived from
+// a set of handlers is represented.
+type Document struct {
+	OpenAPI string `json:"openapi"`
+	Info    Info   `json:"info"`
+
+	// Paths maps from each path in OpenAPI syntax
+	// to the operations defined on it, keyed by lower
+	// case HTTP method.
+	Paths map[string]map[string]*Operation `json:"paths"`
+}
+
+// Operation describes a single API operation.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required,omitempty"`
+	Schema   Schema `json:"schema"`
+}
+
+// Schema describes the type of a value.
+type Schema struct {
+	Type string `json:"type"`
+}
+
+// Response describes a response from an operation.
+type Response struct {
+	Description string            `json:"description"`
+	Headers     map[string]Header `json:"headers,omitempty"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Description string `json:"description,omitempty"`
+	Schema      Schema `json:"schema"`
+}
+
+// NewDocument returns an OpenAPI document describing the
+// given handlers. Each operation takes its ID, summary and tags
+// from the metadata of the corresponding handler, and any
+// Cache-Control header declared in the metadata is described
+// as a response header.
+func NewDocument(info Info, hs []httprequest.Handler) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+	}
+	for _, h := range hs {
+		path, params := openAPIPath(h.Path)
+		ops := doc.Paths[path]
+		if ops == nil {
+			ops = make(map[string]*Operation)
+			doc.Paths[path] = ops
+		}
+		resp := Response{
+			Description: "The result of the operation, or an error.",
+		}
+		if cc := h.Metadata.CacheControl; cc != "" {
+			resp.Headers = map[string]Header{
+				"Cache-Control": {
+					Description: "Set to " + cc + " on successful responses.",
+					Schema: Schema{
+						Type: "string",
+					},
+				},
+			}
+		}
+		ops[strings.ToLower(h.Method)] = &Operation{
+			OperationID: h.Metadata.Name,
+			Summary:     h.Metadata.Summary,
+			Tags:        h.Metadata.Tags,
+			Parameters:  params,
+			Responses: map[string]Response{
+				"default": resp,
+			},
+		}
+	}
+	return doc
+}
+
+// openAPIPath returns the OpenAPI equivalent of the given
+// httprouter path pattern, along with its path parameters.
+func openAPIPath(path string) (string, []Parameter) {
+	var params []Parameter
+	elems := strings.Split(path, "/")
+	for i, elem := range elems {
+		if len(elem) < 2 || (elem[0] != ':' && elem[0] != '*') {
+			continue
+		}
+		params = append(params, Parameter{
+			Name:     elem[1:],
+			In:       "path",
+			Required: true,
+			Schema: Schema{
+				Type: "string",
+			},
+		})
+		elems[i] = "{" + elem[1:] + "}"
+	}
+	return strings.Join(elems, "/"), params
+}
+
+// Config holds configuration for Handlers.
+type Config struct {
+	// Info holds general information about the API.
+	Info Info
+
+	// SpecPath holds the path at which to serve the OpenAPI
+	// document. If it is empty, DefaultSpecPath is used.
+	SpecPath string
+
+	// UIPath holds the path at which to serve an HTML page that
+	// uses Swagger UI to display the OpenAPI document. If it is
+	// empty, no such page is served.
+	UIPath string
+
+	// SwaggerUIURL holds the base URL from which the Swagger UI
+	// assets (swagger-ui.css and swagger-ui-bundle.js) are loaded by
+	// the UI page. It must be set if UIPath is set. It should refer
+	// to a fixed version of the assets, for example
+	// "https://unpkg.com/swagger-ui-dist@5.17.14", or to a copy
+	// served by the application itself.
+	SwaggerUIURL string
+
+	// SwaggerUICSSIntegrity and SwaggerUIJSIntegrity optionally hold
+	// subresource integrity hashes (for example "sha384-...") for
+	// the Swagger UI stylesheet and script. When set, the browser
+	// refuses to use assets that do not match.
+	SwaggerUICSSIntegrity string
+	SwaggerUIJSIntegrity  string
+}
+
+// Handlers returns handlers that serve an OpenAPI document describing
+// the given handlers and, optionally, a documentation page. The
+// returned handlers can be added to a router alongside hs so that
+// a service documents itself:
+//
+//	hs := srv.Handlers(f)
+//	hs = append(hs, openapi.Handlers(openapi.Config{...}, hs)...)
+//
+// The document and page are generated when Handlers is called.
+// Handlers panics if cfg.UIPath is set without cfg.SwaggerUIURL.
+func Handlers(cfg Config, hs []httprequest.Handler) []httprequest.Handler {
+	if cfg.SpecPath == "" {
+		cfg.SpecPath = DefaultSpecPath
+	}
+	if cfg.UIPath != "" && cfg.SwaggerUIURL == "" {
+		panic(errgo.New("openapi: Config.SwaggerUIURL must be set when Config.UIPath is set"))
+	}
+	doc, err := json.Marshal(NewDocument(cfg.Info, hs))
+	if err != nil {
+		panic(errgo.Notef(err, "cannot marshal OpenAPI document"))
+	}
+	docHandlers := []httprequest.Handler{{
+		Method: "GET",
+		Path:   cfg.SpecPath,
+		Handle: contentHandler("application/json", doc),
+	}}
+	if cfg.UIPath != "" {
+		var page bytes.Buffer
+		if err := uiTemplate.Execute(&page, cfg); err != nil {
+			panic(errgo.Notef(err, "cannot generate Swagger UI page"))
+		}
+		docHandlers = append(docHandlers, httprequest.Handler{
+			Method: "GET",
+			Path:   cfg.UIPath,
+			Handle: contentHandler("text/html; charset=utf-8", page.Bytes()),
+		})
+	}
+	return docHandlers
+}
+
+// contentHandler returns a handler that responds with the given
+// content. As the content is generated in advance, the only error that
+// can occur when serving it is a failure to write to the client, which
+// cannot be reported.
+func contentHandler(contentType string, data []byte) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}
+}
+
+var uiTemplate = template.Must(template.New("").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Info.Title}}</title>
+<link rel="stylesheet" href="{{.SwaggerUIURL}}/swagger-ui.css"{{with .SwaggerUICSSIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.SwaggerUIURL}}/swagger-ui-bundle.js"{{with .SwaggerUIJSIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+<script>
+SwaggerUIBundle({url: {{.SpecPath}}, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`))
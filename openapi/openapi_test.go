@@ -0,0 +1,160 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/openapi"
+)
+
+type handlers struct{}
+
+func (handlers) GetUser(*struct {
+	httprequest.Route `httprequest:"GET /users/:id" name:"GetUser" summary:"Gets a user." tags:"users"`
+}) {
+}
+
+func (handlers) DeleteUser(*struct {
+	httprequest.Route `httprequest:"DELETE /users/:id" name:"DeleteUser"`
+}) {
+}
+
+func (handlers) GetFile(*struct {
+	httprequest.Route `httprequest:"GET /files/*path" cache:"max-age=3600"`
+}) {
+}
+
+var testInfo = openapi.Info{
+	Title:   "Test API",
+	Version: "1.0",
+}
+
+func testHandlers() []httprequest.Handler {
+	var srv httprequest.Server
+	return srv.Handlers(func(p httprequest.Params) (handlers, context.Context, error) {
+		return handlers{}, p.Context, nil
+	})
+}
+
+var defaultResponses = map[string]openapi.Response{
+	"default": {
+		Description: "The result of the operation, or an error.",
+	},
+}
+
+var idParam = []openapi.Parameter{{
+	Name:     "id",
+	In:       "path",
+	Required: true,
+	Schema:   openapi.Schema{Type: "string"},
+}}
+
+func TestNewDocument(t *testing.T) {
+	c := qt.New(t)
+
+	doc := openapi.NewDocument(testInfo, testHandlers())
+	c.Assert(doc, qt.DeepEquals, &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    testInfo,
+		Paths: map[string]map[string]*openapi.Operation{
+			"/users/{id}": {
+				"get": {
+					OperationID: "GetUser",
+					Summary:     "Gets a user.",
+					Tags:        []string{"users"},
+					Parameters:  idParam,
+					Responses:   defaultResponses,
+				},
+				"delete": {
+					OperationID: "DeleteUser",
+					Parameters:  idParam,
+					Responses:   defaultResponses,
+				},
+			},
+			"/files/{path}": {
+				"get": {
+					Parameters: []openapi.Parameter{{
+						Name:     "path",
+						In:       "path",
+						Required: true,
+						Schema:   openapi.Schema{Type: "string"},
+					}},
+					Responses: map[string]openapi.Response{
+						"default": {
+							Description: "The result of the operation, or an error.",
+							Headers: map[string]openapi.Header{
+								"Cache-Control": {
+									Description: "Set to max-age=3600 on successful responses.",
+									Schema:      openapi.Schema{Type: "string"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	hs := testHandlers()
+	hs = append(hs, openapi.Handlers(openapi.Config{
+		Info:                 testInfo,
+		UIPath:               "/docs",
+		SwaggerUIURL:         "https://example.com/swagger-ui@5.0.0",
+		SwaggerUIJSIntegrity: "sha384-xxx",
+	}, hs)...)
+	router := httprouter.New()
+	httprequest.AddHandlers(router, hs)
+
+	qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+		URL:        "/openapi.json",
+		Handler:    router,
+		ExpectBody: openapi.NewDocument(testInfo, testHandlers()),
+	})
+
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/docs", nil)
+	c.Assert(err, qt.IsNil)
+	router.ServeHTTP(rec, req)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "text/html; charset=utf-8")
+	c.Assert(rec.Body.String(), qt.Contains, `url: "/openapi.json"`)
+	c.Assert(rec.Body.String(), qt.Contains, `<script src="https://example.com/swagger-ui@5.0.0/swagger-ui-bundle.js" integrity="sha384-xxx" crossorigin="anonymous"></script>`)
+	c.Assert(rec.Body.String(), qt.Contains, `<link rel="stylesheet" href="https://example.com/swagger-ui@5.0.0/swagger-ui.css">`)
+}
+
+func TestHandlersUIWithoutSwaggerUIURL(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(func() {
+		openapi.Handlers(openapi.Config{
+			Info:   testInfo,
+			UIPath: "/docs",
+		}, testHandlers())
+	}, qt.PanicMatches, `openapi: Config.SwaggerUIURL must be set when Config.UIPath is set`)
+}
+
+func TestHandlersWithoutUI(t *testing.T) {
+	c := qt.New(t)
+
+	hs := openapi.Handlers(openapi.Config{
+		Info:     testInfo,
+		SpecPath: "/spec",
+	}, testHandlers())
+	c.Assert(hs, qt.HasLen, 1)
+	c.Assert(hs[0].Method, qt.Equals, "GET")
+	c.Assert(hs[0].Path, qt.Equals, "/spec")
+}
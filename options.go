@@ -0,0 +1,153 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// ParamInfo describes a parameter of a request.
+type ParamInfo struct {
+	// Name holds the name of the parameter.
+	Name string `json:"name"`
+
+	// In holds where the parameter is found in the request:
+	// one of "path", "form", "header", "body" or "multipart".
+	In string `json:"in"`
+
+	// Type holds the JSON type of the parameter: one of "string",
+	// "integer", "number", "boolean", "array" or "object".
+	Type string `json:"type"`
+}
+
+var sourceNames = map[tagSource]string{
+	sourcePath:      "path",
+	sourceForm:      "form",
+	sourceFormBody:  "form",
+	sourceHeader:    "header",
+	sourceBody:      "body",
+	sourceMultipart: "multipart",
+}
+
+// paramType returns the JSON type of a parameter
+// of type t, as reported in ParamInfo.Type.
+func paramType(t reflect.Type) string {
+	if implementsTextUnmarshaler(t) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return "object"
+	}
+	return "string"
+}
+
+// ResourceDescription is the body of the responses from handlers
+// returned by OptionsHandlers when descriptions are enabled.
+type ResourceDescription struct {
+	// Path holds the path pattern of the resource.
+	Path string `json:"path"`
+
+	// Methods describes each method allowed on the resource.
+	Methods []MethodDescription `json:"methods"`
+}
+
+// MethodDescription describes a method allowed on a resource.
+type MethodDescription struct {
+	Method  string      `json:"method"`
+	Name    string      `json:"name,omitempty"`
+	Summary string      `json:"summary,omitempty"`
+	Params  []ParamInfo `json:"params,omitempty"`
+}
+
+// OptionsHandlers returns a handler for the OPTIONS method for each
+// path in hs that does not already have one, so that clients can
+// discover the methods allowed on a resource. Responses have an
+// Allow header listing the methods of the handlers in hs with the
+// path, as well as OPTIONS itself.
+//
+// If describe is false, responses have status http.StatusNoContent.
+// Otherwise they have a JSON ResourceDescription body describing the
+// parameters of each method (see Handler.Params).
+//
+// The returned handlers should be added to the same router as hs,
+// for example:
+//
+//	router := srv.NewRouter(append(hs, srv.OptionsHandlers(hs, true)...))
+func (srv *Server) OptionsHandlers(hs []Handler, describe bool) []Handler {
+	var paths []string
+	byPath := make(map[string][]Handler)
+	for _, h := range hs {
+		if byPath[h.Path] == nil {
+			paths = append(paths, h.Path)
+		}
+		byPath[h.Path] = append(byPath[h.Path], h)
+	}
+	var ohs []Handler
+	for _, path := range paths {
+		phs := byPath[path]
+		methods := []string{http.MethodOptions}
+		hasOptions := false
+		for _, h := range phs {
+			if h.Method == http.MethodOptions {
+				hasOptions = true
+				break
+			}
+			methods = append(methods, h.Method)
+		}
+		if hasOptions {
+			continue
+		}
+		sort.Strings(methods)
+		desc := &ResourceDescription{
+			Path: path,
+		}
+		sort.SliceStable(phs, func(i, j int) bool {
+			return phs[i].Method < phs[j].Method
+		})
+		for _, h := range phs {
+			desc.Methods = append(desc.Methods, MethodDescription{
+				Method:  h.Method,
+				Name:    h.Metadata.Name,
+				Summary: h.Metadata.Summary,
+				Params:  h.Params,
+			})
+		}
+		ohs = append(ohs, Handler{
+			Method: http.MethodOptions,
+			Path:   path,
+			Handle: srv.optionsHandle(strings.Join(methods, ", "), desc, describe),
+		})
+	}
+	return ohs
+}
+
+// optionsHandle returns a handler that responds to OPTIONS requests
+// with the given Allow header and, if describe is true, desc.
+func (srv *Server) optionsHandle(allow string, desc *ResourceDescription, describe bool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+		w.Header().Set("Allow", allow)
+		if !describe {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if err := WriteJSON(w, http.StatusOK, desc); err != nil {
+			srv.WriteError(contextWithRequest(req.Context(), req), w, err)
+		}
+	}
+}
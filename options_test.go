@@ -0,0 +1,107 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type optionsHandlers struct{}
+
+func (optionsHandlers) GetItem(*struct {
+	httprequest.Route `httprequest:"GET /items/:id" name:"GetItem" summary:"Returns an item."`
+	ID                int       `httprequest:"id,path"`
+	Fields            []string  `httprequest:"fields,form"`
+	Since             time.Time `httprequest:"since,form"`
+	Token             string    `httprequest:"X-Token,header"`
+}) (string, error) {
+	return "item", nil
+}
+
+func (optionsHandlers) PutItem(*struct {
+	httprequest.Route `httprequest:"PUT /items/:id"`
+	ID                int `httprequest:"id,path"`
+	Item              struct {
+		Price float64 `json:"price"`
+	} `httprequest:",body"`
+}) error {
+	return nil
+}
+
+func (optionsHandlers) Ping(*struct {
+	httprequest.Route `httprequest:"GET /ping"`
+}) error {
+	return nil
+}
+
+func TestOptionsHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	hs := srv.Handlers(func(p httprequest.Params) (optionsHandlers, context.Context, error) {
+		return optionsHandlers{}, p.Context, nil
+	})
+	hs = append(hs, httprequest.Handler{
+		Method: "OPTIONS",
+		Path:   "/ping",
+		Handle: func(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {},
+	})
+	ohs := srv.OptionsHandlers(hs, true)
+	var routes []string
+	for _, h := range ohs {
+		routes = append(routes, h.Method+" "+h.Path)
+	}
+	// There is no handler for /ping because one is already defined.
+	c.Assert(routes, qt.DeepEquals, []string{"OPTIONS /items/:id"})
+
+	router := srv.NewRouter(append(hs, ohs...))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("OPTIONS", "/items/42", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, httprequest.ResourceDescription{
+		Path: "/items/:id",
+		Methods: []httprequest.MethodDescription{{
+			Method:  "GET",
+			Name:    "GetItem",
+			Summary: "Returns an item.",
+			Params: []httprequest.ParamInfo{
+				{Name: "id", In: "path", Type: "integer"},
+				{Name: "fields", In: "form", Type: "array"},
+				{Name: "since", In: "form", Type: "string"},
+				{Name: "X-Token", In: "header", Type: "string"},
+			},
+		}, {
+			Method: "PUT",
+			Params: []httprequest.ParamInfo{
+				{Name: "id", In: "path", Type: "integer"},
+				{Name: "Item", In: "body", Type: "object"},
+			},
+		}},
+	})
+	c.Assert(rec.Header().Get("Allow"), qt.Equals, "GET, OPTIONS, PUT")
+}
+
+func TestOptionsHandlersWithoutDescription(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	hs := srv.Handlers(func(p httprequest.Params) (optionsHandlers, context.Context, error) {
+		return optionsHandlers{}, p.Context, nil
+	})
+	router := srv.NewRouter(append(hs, srv.OptionsHandlers(hs, false)...))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("OPTIONS", "/items/42", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusNoContent)
+	c.Assert(rec.Body.Len(), qt.Equals, 0)
+	c.Assert(rec.Header().Get("Allow"), qt.Equals, "GET, OPTIONS, PUT")
+}
@@ -0,0 +1,455 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// These constants hold the content types of the patch documents
+// accepted by body fields with the mergepatch and jsonpatch tag
+// flags respectively (see Unmarshal).
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var (
+	mergePatchType = reflect.TypeOf(MergePatch(nil))
+	jsonPatchType  = reflect.TypeOf(JSONPatch(nil))
+)
+
+// MergePatch holds an RFC 7386 JSON merge patch document. It is
+// the type of body fields tagged with the mergepatch flag.
+type MergePatch json.RawMessage
+
+// MarshalJSON implements json.Marshaler.
+func (p MergePatch) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *MergePatch) UnmarshalJSON(data []byte) error {
+	*p = append((*p)[:0], data...)
+	return nil
+}
+
+// Apply applies the patch to the value pointed to by x, which must
+// be able to be marshaled to and unmarshaled from JSON. Members set to
+// null in the patch are removed from the value and other members
+// replace or are merged into those of the value.
+func (p MergePatch) Apply(x interface{}) error {
+	doc, err := jsonDocument(x)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	patch, err := decodeJSON(p)
+	if err != nil {
+		return Errorf(CodeBadRequest, "invalid merge patch: %v", err)
+	}
+	return errgo.Mask(setJSONDocument(x, mergePatch(doc, patch)))
+}
+
+// mergePatch returns the result of applying patch to target
+// as described in RFC 7386.
+func mergePatch(target, patch interface{}) interface{} {
+	pm, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]interface{})
+	if !ok {
+		tm = make(map[string]interface{})
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = mergePatch(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// JSONPatch holds an RFC 6902 JSON patch document. It is the type
+// of body fields tagged with the jsonpatch flag. The operations are
+// checked for validity when the document is unmarshaled.
+type JSONPatch []PatchOperation
+
+// PatchOperation holds a single operation in a JSONPatch.
+type PatchOperation struct {
+	// Op holds the operation: one of "add", "remove",
+	// "replace", "move", "copy" or "test".
+	Op string `json:"op"`
+
+	// Path holds the JSON pointer to the target location
+	// of the operation.
+	Path string `json:"path"`
+
+	// From holds the JSON pointer to the source
+	// location of "move" and "copy" operations.
+	From string `json:"from,omitempty"`
+
+	// Value holds the value used by "add", "replace"
+	// and "test" operations.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler by unmarshaling
+// the operations and checking that they are valid.
+func (p *JSONPatch) UnmarshalJSON(data []byte) error {
+	var ops []PatchOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return err
+	}
+	for i, op := range ops {
+		if err := op.check(); err != nil {
+			return errgo.Notef(err, "invalid operation %d", i)
+		}
+	}
+	*p = ops
+	return nil
+}
+
+// check checks that op is a valid operation.
+func (op PatchOperation) check() error {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errgo.Newf("missing value in %q operation", op.Op)
+		}
+	case "move", "copy":
+		if _, err := parseJSONPointer(op.From); err != nil {
+			return errgo.Notef(err, "bad from")
+		}
+	case "remove":
+	default:
+		return errgo.Newf("unknown op %q", op.Op)
+	}
+	if _, err := parseJSONPointer(op.Path); err != nil {
+		return errgo.Notef(err, "bad path")
+	}
+	return nil
+}
+
+// Apply applies the operations in the patch in order to the value
+// pointed to by x, which must be able to be marshaled to and
+// unmarshaled from JSON. If any operation fails, x is left unchanged
+// and an error with code CodeBadRequest is returned.
+func (p JSONPatch) Apply(x interface{}) error {
+	doc, err := jsonDocument(x)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	for i, op := range p {
+		doc, err = op.apply(doc)
+		if err != nil {
+			return Errorf(CodeBadRequest, "cannot apply patch operation %d (%s %s): %v", i, op.Op, op.Path, err)
+		}
+	}
+	return errgo.Mask(setJSONDocument(x, doc))
+}
+
+// apply applies op to doc and returns the resulting document.
+func (op PatchOperation) apply(doc interface{}) (interface{}, error) {
+	if err := op.check(); err != nil {
+		return nil, err
+	}
+	path, _ := parseJSONPointer(op.Path)
+	switch op.Op {
+	case "add":
+		v, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(doc, path, v)
+	case "remove":
+		doc, _, err := removeJSONValue(doc, path)
+		return doc, err
+	case "replace":
+		v, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		doc, _, err = removeJSONValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(doc, path, v)
+	case "move":
+		from, _ := parseJSONPointer(op.From)
+		doc, v, err := removeJSONValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(doc, path, v)
+	case "copy":
+		from, _ := parseJSONPointer(op.From)
+		v, err := getJSONValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		// Copy the value so that later operations
+		// on either location do not affect the other.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		v, err = decodeJSON(data)
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(doc, path, v)
+	case "test":
+		want, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		got, err := getJSONValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(got, want) {
+			return nil, errgo.New("test failed")
+		}
+		return doc, nil
+	}
+	panic("unreachable")
+}
+
+// parseJSONPointer parses an RFC 6901 JSON pointer
+// into its reference tokens.
+func parseJSONPointer(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, errgo.Newf("JSON pointer %q does not start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getJSONValue returns the value at the given path in doc.
+func getJSONValue(doc interface{}, path []string) (interface{}, error) {
+	for _, t := range path {
+		switch d := doc.(type) {
+		case map[string]interface{}:
+			v, ok := d[t]
+			if !ok {
+				return nil, errgo.Newf("member %q not found", t)
+			}
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(t, len(d))
+			if err != nil {
+				return nil, err
+			}
+			doc = d[i]
+		default:
+			return nil, errgo.Newf("cannot find %q in non-container value", t)
+		}
+	}
+	return doc, nil
+}
+
+// addJSONValue returns the result of adding v
+// at the given path in doc.
+func addJSONValue(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	t, rest := path[0], path[1:]
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			d[t] = v
+			return d, nil
+		}
+		child, ok := d[t]
+		if !ok {
+			return nil, errgo.Newf("member %q not found", t)
+		}
+		child, err := addJSONValue(child, rest, v)
+		if err != nil {
+			return nil, err
+		}
+		d[t] = child
+		return d, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			i := len(d)
+			if t != "-" {
+				var err error
+				// Adding is allowed at the index just past the end.
+				if i, err = arrayIndex(t, len(d)+1); err != nil {
+					return nil, err
+				}
+			}
+			d = append(d, nil)
+			copy(d[i+1:], d[i:])
+			d[i] = v
+			return d, nil
+		}
+		i, err := arrayIndex(t, len(d))
+		if err != nil {
+			return nil, err
+		}
+		child, err := addJSONValue(d[i], rest, v)
+		if err != nil {
+			return nil, err
+		}
+		d[i] = child
+		return d, nil
+	}
+	return nil, errgo.Newf("cannot add %q to non-container value", t)
+}
+
+// removeJSONValue returns the result of removing the value at the
+// given path in doc, and the value that was removed.
+func removeJSONValue(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	t, rest := path[0], path[1:]
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		child, ok := d[t]
+		if !ok {
+			return nil, nil, errgo.Newf("member %q not found", t)
+		}
+		if len(rest) == 0 {
+			delete(d, t)
+			return d, child, nil
+		}
+		child, removed, err := removeJSONValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		d[t] = child
+		return d, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(t, len(d))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := d[i]
+			return append(d[:i], d[i+1:]...), removed, nil
+		}
+		child, removed, err := removeJSONValue(d[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		d[i] = child
+		return d, removed, nil
+	}
+	return nil, nil, errgo.Newf("cannot remove %q from non-container value", t)
+}
+
+// arrayIndex parses t as an index into an array of length n.
+func arrayIndex(t string, n int) (int, error) {
+	i, err := strconv.Atoi(t)
+	if err != nil || i < 0 || (len(t) > 1 && t[0] == '0') {
+		return 0, errgo.Newf("invalid array index %q", t)
+	}
+	if i >= n {
+		return 0, errgo.Newf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// jsonEqual reports whether the decoded JSON values a and b are equal.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, err1 := a.Float64()
+		bf, err2 := b.Float64()
+		return err1 == nil && err2 == nil && af == bf
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// decodeJSON decodes data into a generic JSON value,
+// preserving the precision of numbers.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// jsonDocument returns the value pointed to by x
+// as a generic JSON value.
+func jsonDocument(x interface{}) (interface{}, error) {
+	if v := reflect.ValueOf(x); v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, errgo.Newf("cannot apply patch to non-pointer %T", x)
+	}
+	data, err := json.Marshal(x)
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot marshal patch target")
+	}
+	return decodeJSON(data)
+}
+
+// setJSONDocument sets the value pointed to by x from the generic
+// JSON value doc. The value is zeroed first so that members removed
+// from the document are cleared.
+func setJSONDocument(x interface{}, doc interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return errgo.Notef(err, "cannot marshal patched value")
+	}
+	xv := reflect.ValueOf(x).Elem()
+	xv1 := reflect.New(xv.Type())
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(xv1.Interface()); err != nil {
+		return Errorf(CodeBadRequest, "cannot unmarshal patched value: %v", err)
+	}
+	xv.Set(xv1.Elem())
+	return nil
+}
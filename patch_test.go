@@ -0,0 +1,352 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var mergePatchTests = []struct {
+	about  string
+	target string
+	patch  string
+	expect string
+}{{
+	about:  "replace member",
+	target: `{"a":"b"}`,
+	patch:  `{"a":"c"}`,
+	expect: `{"a":"c"}`,
+}, {
+	about:  "add member",
+	target: `{"a":"b"}`,
+	patch:  `{"b":"c"}`,
+	expect: `{"a":"b","b":"c"}`,
+}, {
+	about:  "remove member",
+	target: `{"a":"b","b":"c"}`,
+	patch:  `{"a":null}`,
+	expect: `{"b":"c"}`,
+}, {
+	about:  "replace array",
+	target: `{"a":["b"]}`,
+	patch:  `{"a":["c","d"]}`,
+	expect: `{"a":["c","d"]}`,
+}, {
+	about:  "nested merge",
+	target: `{"a":{"b":"c","d":"e"}}`,
+	patch:  `{"a":{"b":"x","d":null}}`,
+	expect: `{"a":{"b":"x"}}`,
+}, {
+	about:  "replace non-object",
+	target: `{"a":"b"}`,
+	patch:  `["c"]`,
+	expect: `["c"]`,
+}, {
+	about:  "large numbers preserved",
+	target: `{"id":9007199254740993}`,
+	patch:  `{"a":1}`,
+	expect: `{"a":1,"id":9007199254740993}`,
+}}
+
+func TestMergePatchApply(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range mergePatchTests {
+		c.Run(test.about, func(c *qt.C) {
+			var target interface{}
+			dec := json.NewDecoder(strings.NewReader(test.target))
+			dec.UseNumber()
+			c.Assert(dec.Decode(&target), qt.IsNil)
+			err := httprequest.MergePatch(test.patch).Apply(&target)
+			c.Assert(err, qt.IsNil)
+			data, err := json.Marshal(target)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(data), qt.Equals, test.expect)
+		})
+	}
+}
+
+type patchUser struct {
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestMergePatchApplyToStruct(t *testing.T) {
+	c := qt.New(t)
+
+	u := patchUser{
+		Name:  "bob",
+		Email: "bob@example.com",
+		Tags:  []string{"a"},
+	}
+	err := httprequest.MergePatch(`{"email":null,"tags":["b","c"]}`).Apply(&u)
+	c.Assert(err, qt.IsNil)
+	c.Assert(u, qt.DeepEquals, patchUser{
+		Name: "bob",
+		Tags: []string{"b", "c"},
+	})
+
+	err = httprequest.MergePatch(`{"name":1}`).Apply(&u)
+	c.Assert(err, qt.ErrorMatches, `cannot unmarshal patched value: .*`)
+	c.Assert(u.Name, qt.Equals, "bob")
+}
+
+var jsonPatchTests = []struct {
+	about       string
+	target      string
+	patch       string
+	expect      string
+	expectError string
+}{{
+	about:  "add object member",
+	target: `{"foo":"bar"}`,
+	patch:  `[{"op":"add","path":"/baz","value":"qux"}]`,
+	expect: `{"baz":"qux","foo":"bar"}`,
+}, {
+	about:  "add array element",
+	target: `{"foo":["bar","baz"]}`,
+	patch:  `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+	expect: `{"foo":["bar","qux","baz"]}`,
+}, {
+	about:  "append array element",
+	target: `{"foo":["bar"]}`,
+	patch:  `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+	expect: `{"foo":["bar",["abc","def"]]}`,
+}, {
+	about:  "remove object member",
+	target: `{"baz":"qux","foo":"bar"}`,
+	patch:  `[{"op":"remove","path":"/baz"}]`,
+	expect: `{"foo":"bar"}`,
+}, {
+	about:  "remove array element",
+	target: `{"foo":["bar","qux","baz"]}`,
+	patch:  `[{"op":"remove","path":"/foo/1"}]`,
+	expect: `{"foo":["bar","baz"]}`,
+}, {
+	about:  "replace value",
+	target: `{"baz":"qux","foo":"bar"}`,
+	patch:  `[{"op":"replace","path":"/baz","value":"boo"}]`,
+	expect: `{"baz":"boo","foo":"bar"}`,
+}, {
+	about:  "move value",
+	target: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+	patch:  `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+	expect: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+}, {
+	about:  "move array element",
+	target: `{"foo":["all","grass","cows","eat"]}`,
+	patch:  `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+	expect: `{"foo":["all","cows","eat","grass"]}`,
+}, {
+	about:  "copy value",
+	target: `{"a":{"b":1}}`,
+	patch:  `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+	expect: `{"a":{"b":1},"c":{"b":2}}`,
+}, {
+	about:  "test success",
+	target: `{"baz":"qux","foo":["a",2,"c"]}`,
+	patch:  `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`,
+	expect: `{"baz":"qux","foo":["a",2,"c"]}`,
+}, {
+	about:       "test failure",
+	target:      `{"baz":"qux"}`,
+	patch:       `[{"op":"test","path":"/baz","value":"bar"}]`,
+	expectError: `cannot apply patch operation 0 \(test /baz\): test failed`,
+}, {
+	about:  "escaped pointer",
+	target: `{"a/b":{"m~n":1}}`,
+	patch:  `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`,
+	expect: `{"a/b":{"m~n":2}}`,
+}, {
+	about:  "add null value",
+	target: `{}`,
+	patch:  `[{"op":"add","path":"/a","value":null}]`,
+	expect: `{"a":null}`,
+}, {
+	about:       "add to missing parent",
+	target:      `{"foo":"bar"}`,
+	patch:       `[{"op":"add","path":"/baz/bat","value":"qux"}]`,
+	expectError: `cannot apply patch operation 0 \(add /baz/bat\): member "baz" not found`,
+}, {
+	about:       "remove missing member",
+	target:      `{}`,
+	patch:       `[{"op":"remove","path":"/a"}]`,
+	expectError: `cannot apply patch operation 0 \(remove /a\): member "a" not found`,
+}, {
+	about:       "array index out of range",
+	target:      `{"foo":["bar"]}`,
+	patch:       `[{"op":"add","path":"/foo/2","value":"x"}]`,
+	expectError: `cannot apply patch operation 0 \(add /foo/2\): array index 2 out of range`,
+}, {
+	about:       "bad array index",
+	target:      `{"foo":["bar"]}`,
+	patch:       `[{"op":"replace","path":"/foo/01","value":"x"}]`,
+	expectError: `cannot apply patch operation 0 \(replace /foo/01\): invalid array index "01"`,
+}}
+
+func TestJSONPatchApply(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range jsonPatchTests {
+		c.Run(test.about, func(c *qt.C) {
+			var target interface{}
+			c.Assert(json.Unmarshal([]byte(test.target), &target), qt.IsNil)
+			var patch httprequest.JSONPatch
+			c.Assert(json.Unmarshal([]byte(test.patch), &patch), qt.IsNil)
+			err := patch.Apply(&target)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				data, err := json.Marshal(target)
+				c.Assert(err, qt.IsNil)
+				c.Assert(string(data), qt.JSONEquals, json.RawMessage(test.target))
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			data, err := json.Marshal(target)
+			c.Assert(err, qt.IsNil)
+			c.Assert(string(data), qt.Equals, test.expect)
+		})
+	}
+}
+
+var jsonPatchUnmarshalErrorTests = []struct {
+	about       string
+	patch       string
+	expectError string
+}{{
+	about:       "unknown op",
+	patch:       `[{"op":"frob","path":"/a"}]`,
+	expectError: `invalid operation 0: unknown op "frob"`,
+}, {
+	about:       "missing value",
+	patch:       `[{"op":"remove","path":"/a"},{"op":"add","path":"/a"}]`,
+	expectError: `invalid operation 1: missing value in "add" operation`,
+}, {
+	about:       "bad path",
+	patch:       `[{"op":"remove","path":"a"}]`,
+	expectError: `invalid operation 0: bad path: JSON pointer "a" does not start with /`,
+}, {
+	about:       "bad from",
+	patch:       `[{"op":"move","from":"a","path":"/b"}]`,
+	expectError: `invalid operation 0: bad from: JSON pointer "a" does not start with /`,
+}}
+
+func TestJSONPatchUnmarshalErrors(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range jsonPatchUnmarshalErrorTests {
+		c.Run(test.about, func(c *qt.C) {
+			var patch httprequest.JSONPatch
+			err := json.Unmarshal([]byte(test.patch), &patch)
+			c.Assert(err, qt.ErrorMatches, test.expectError)
+		})
+	}
+}
+
+type patchUserHandlers struct {
+	user *patchUser
+}
+
+type mergePatchUserRequest struct {
+	httprequest.Route `httprequest:"PATCH /user"`
+	Patch             httprequest.MergePatch `httprequest:",body,mergepatch"`
+}
+
+func (h patchUserHandlers) MergePatchUser(req *mergePatchUserRequest) (*patchUser, error) {
+	if err := req.Patch.Apply(h.user); err != nil {
+		return nil, err
+	}
+	return h.user, nil
+}
+
+type jsonPatchUserRequest struct {
+	httprequest.Route `httprequest:"POST /user/patch"`
+	Patch             httprequest.JSONPatch `httprequest:",body,jsonpatch"`
+}
+
+func (h patchUserHandlers) JSONPatchUser(req *jsonPatchUserRequest) (*patchUser, error) {
+	if err := req.Patch.Apply(h.user); err != nil {
+		return nil, err
+	}
+	return h.user, nil
+}
+
+func TestPatchBodyRoundTrip(t *testing.T) {
+	c := qt.New(t)
+
+	user := &patchUser{
+		Name:  "bob",
+		Email: "bob@example.com",
+	}
+	srv := httprequest.Server{
+		StrictContentType: true,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (patchUserHandlers, context.Context, error) {
+		return patchUserHandlers{user: user}, p.Context, nil
+	}))
+	hsrv := httptest.NewServer(router)
+	defer hsrv.Close()
+	client := httprequest.Client{
+		BaseURL: hsrv.URL,
+	}
+
+	var resp patchUser
+	err := client.Call(context.Background(), &mergePatchUserRequest{
+		Patch: httprequest.MergePatch(`{"email":null,"tags":["x"]}`),
+	}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, patchUser{
+		Name: "bob",
+		Tags: []string{"x"},
+	})
+
+	resp = patchUser{}
+	err = client.Call(context.Background(), &jsonPatchUserRequest{
+		Patch: httprequest.JSONPatch{{
+			Op:    "replace",
+			Path:  "/name",
+			Value: json.RawMessage(`"alice"`),
+		}, {
+			Op:   "remove",
+			Path: "/tags/0",
+		}},
+	}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, patchUser{
+		Name: "alice",
+	})
+	c.Assert(user, qt.DeepEquals, &patchUser{
+		Name: "alice",
+		Tags: []string{},
+	})
+
+	err = client.Call(context.Background(), &jsonPatchUserRequest{
+		Patch: httprequest.JSONPatch{{
+			Op:   "remove",
+			Path: "/missing",
+		}},
+	}, &resp)
+	c.Assert(err, qt.ErrorMatches, `Post http://.*: cannot apply patch operation 0 \(remove /missing\): member "missing" not found`)
+
+	// A plain JSON body is rejected for a merge patch field.
+	req := httptest.NewRequest("PATCH", "/user", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusUnsupportedMediaType, &httprequest.RemoteError{
+		Code:    httprequest.CodeUnsupportedMediaType,
+		Message: `unsupported content type "application/json"; want application/merge-patch+json`,
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// ErrorFormat selects the format of error responses
+// written by Server.WriteError. See Server.ErrorFormat.
+type ErrorFormat int
+
+const (
+	// ErrorFormatDefault writes error bodies as returned
+	// by the error mapper.
+	ErrorFormatDefault ErrorFormat = iota
+
+	// ErrorFormatProblem writes *RemoteError bodies returned by
+	// the error mapper as RFC 7807 problem details documents
+	// (see Problem) with content type application/problem+json.
+	ErrorFormatProblem
+)
+
+// Problem represents an RFC 7807 problem details document.
+//
+// A Client using DefaultErrorUnmarshaler returns a *RemoteError for
+// error responses holding a problem details document. Its Message
+// holds the problem's Detail or, if that is empty, its Title, and its
+// Code and Info hold the problem's Code and Info. If the problem has
+// no Info, the RemoteError's Info holds the whole document, so that
+// its type and any extension members can be found with
+// RemoteError.UnmarshalInfo.
+type Problem struct {
+	// Type holds a URI reference that identifies the problem type.
+	// Problems derived from a RemoteError use "about:blank", meaning
+	// that the problem is described by the HTTP status alone.
+	Type string `json:"type"`
+
+	// Title holds a short summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status holds the HTTP status code of the response.
+	Status int `json:"status,omitempty"`
+
+	// Detail holds an explanation specific to
+	// this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance holds a URI reference that identifies this
+	// occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+
+	// Code holds the error code, as found in RemoteError.Code.
+	Code string `json:"code,omitempty"`
+
+	// Info holds any other information associated with the error,
+	// as found in RemoteError.Info.
+	Info *json.RawMessage `json:"info,omitempty"`
+}
+
+// ProblemContentType holds the content type of
+// RFC 7807 problem details documents.
+const ProblemContentType = "application/problem+json"
+
+var problemCodec = Codec{
+	ContentType: ProblemContentType,
+	Marshal:     json.Marshal,
+}
+
+// newProblem returns the problem details document corresponding to
+// the given error response. The instance is taken from the path of the
+// request being served in ctx, if known.
+func newProblem(ctx context.Context, status int, e *RemoteError) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: e.Message,
+		Code:   e.Code,
+		Info:   e.Info,
+	}
+	if req := requestFromContext(ctx); req != nil && req.URL != nil {
+		p.Instance = req.URL.Path
+	}
+	return p
+}
+
+// writeErrorBody writes the error body returned by the error mapper
+// in the format selected by srv.ErrorFormat.
+func (srv *Server) writeErrorBody(ctx context.Context, w http.ResponseWriter, status int, body interface{}) error {
+	if srv.ErrorFormat == ErrorFormatProblem {
+		if e, ok := body.(*RemoteError); ok {
+			return writeEncoded(w, &problemCodec, status, newProblem(ctx, status, e))
+		}
+	}
+	return srv.writeEnveloped(ctx, w, status, body, true)
+}
+
+// isProblemResponse reports whether the body of resp
+// holds a problem details document.
+func isProblemResponse(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == ProblemContentType
+}
+
+// unmarshalProblemResponse returns the RemoteError corresponding to the
+// problem details document held in resp, capturing up to limit bytes
+// of the body in any error (see readBodyForError).
+func unmarshalProblemResponse(resp *http.Response, limit int) error {
+	var data json.RawMessage
+	if err := unmarshalJSONResponse(resp, &data, limit); err != nil {
+		return errgo.NoteMask(err, fmt.Sprintf("cannot unmarshal error response (status %s)", resp.Status), isDecodeResponseError)
+	}
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		err = newDecodeResponseError(resp, data, err, limit)
+		return errgo.NoteMask(err, fmt.Sprintf("cannot unmarshal error response (status %s)", resp.Status), isDecodeResponseError)
+	}
+	rerr := &RemoteError{
+		Message: p.Detail,
+		Code:    p.Code,
+		Info:    p.Info,
+	}
+	if rerr.Message == "" {
+		rerr.Message = p.Title
+	}
+	if rerr.Code == "" {
+		rerr.Code, _ = StatusErrorCode(resp.StatusCode)
+	}
+	if rerr.Info == nil {
+		rerr.Info = &data
+	}
+	return rerr
+}
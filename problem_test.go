@@ -0,0 +1,174 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestProblemErrorFormat(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		ErrorFormat: httprequest.ErrorFormatProblem,
+	}
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+		return observeHandlers{}, p.Context, nil
+	}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/other", nil))
+	c.Assert(rec.Code, qt.Equals, http.StatusNotFound)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, httprequest.ProblemContentType)
+	var p httprequest.Problem
+	err := json.Unmarshal(rec.Body.Bytes(), &p)
+	c.Assert(err, qt.IsNil)
+	c.Assert(p, qt.DeepEquals, httprequest.Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "no handler for GET /other",
+		Instance: "/other",
+		Code:     httprequest.CodeNotFound,
+	})
+}
+
+func TestProblemErrorFormatWithErrorMapper(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		ErrorMapper: func(ctx context.Context, err error) (int, interface{}) {
+			return http.StatusTeapot, &httprequest.RemoteError{
+				Message: err.Error(),
+				Code:    "teapot",
+			}
+		},
+		ErrorFormat: httprequest.ErrorFormatProblem,
+	}
+	rec := httptest.NewRecorder()
+	srv.WriteError(context.Background(), rec, errgo.New("plain"))
+	c.Assert(rec.Code, qt.Equals, http.StatusTeapot)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, httprequest.ProblemContentType)
+	c.Assert(rec.Body.String(), qt.Equals, `{"type":"about:blank","title":"I'm a teapot","status":418,"detail":"plain","code":"teapot"}`)
+}
+
+func TestProblemErrorFormatIgnoresOtherBodies(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		ErrorMapper: func(ctx context.Context, err error) (int, interface{}) {
+			return http.StatusBadRequest, map[string]string{"error": err.Error()}
+		},
+		ErrorFormat: httprequest.ErrorFormatProblem,
+	}
+	rec := httptest.NewRecorder()
+	srv.WriteError(context.Background(), rec, errgo.New("plain"))
+	c.Assert(rec.Code, qt.Equals, http.StatusBadRequest)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "application/json")
+	c.Assert(rec.Body.String(), qt.Equals, `{"error":"plain"}`)
+}
+
+func TestClientProblemResponse(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", httprequest.ProblemContentType)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","detail":"Your current balance is 30, but that costs 50.","balance":30}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	err := client.Get(context.Background(), "/account", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/account: Your current balance is 30, but that costs 50.`)
+	c.Assert(errors.Is(err, httprequest.ErrForbidden), qt.IsTrue)
+	rerr, ok := errgo.Cause(err).(*httprequest.RemoteError)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(rerr.Code, qt.Equals, httprequest.CodeForbidden)
+
+	// The whole document is available as the error's info.
+	var info struct {
+		Type    string `json:"type"`
+		Balance int    `json:"balance"`
+	}
+	err = rerr.UnmarshalInfo(&info)
+	c.Assert(err, qt.IsNil)
+	c.Assert(info.Type, qt.Equals, "https://example.com/probs/out-of-credit")
+	c.Assert(info.Balance, qt.Equals, 30)
+}
+
+func TestClientProblemResponseFromServer(t *testing.T) {
+	c := qt.New(t)
+	srv := httprequest.Server{
+		ErrorFormat: httprequest.ErrorFormatProblem,
+	}
+	hsrv := httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, arg *struct {
+			httprequest.Route `httprequest:"GET /quota"`
+		}) error {
+			info := json.RawMessage(`{"limit":10}`)
+			return &httprequest.RemoteError{
+				Code:    "problem test quota",
+				Message: "quota exceeded",
+				Info:    &info,
+			}
+		}),
+	}))
+	defer hsrv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: hsrv.URL,
+	}
+	err := client.Get(context.Background(), "/quota", nil)
+	info := json.RawMessage(`{"limit":10}`)
+	c.Assert(errgo.Cause(err), qt.DeepEquals, &httprequest.RemoteError{
+		Code:    "problem test quota",
+		Message: "quota exceeded",
+		Info:    &info,
+	})
+}
+
+func TestClientProblemResponseTitleOnly(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", httprequest.ProblemContentType+"; charset=utf-8")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(`{"type":"about:blank","title":"I'm a teapot"}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	err := client.Get(context.Background(), "/", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: I'm a teapot`)
+}
+
+func TestClientProblemResponseBadJSON(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", httprequest.ProblemContentType)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"title":1}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	err := client.Get(context.Background(), "/", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: cannot unmarshal error response \(status 400 Bad Request\): .*`)
+	_, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+	c.Assert(ok, qt.IsTrue)
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// Progress holds the progress of the transfer of a request or
+// response body, as reported to the functions passed to
+// WithUploadProgress and WithDownloadProgress.
+type Progress struct {
+	// Transferred holds the number of bytes transferred so far.
+	Transferred int64
+
+	// Total holds the total number of bytes to be transferred,
+	// or -1 if it is not known.
+	Total int64
+}
+
+// WithUploadProgress returns a CallOption that calls f as the request
+// body is sent. The total is taken from the request's ContentLength,
+// which is set by Client.Call for marshaled bodies. If the request is
+// retried, the progress of each attempt is reported from zero.
+//
+// f is called synchronously from the goroutine sending the body, so
+// it should not block.
+func WithUploadProgress(f func(Progress)) CallOption {
+	return func(o *callOptions) {
+		o.uploadProgress = f
+	}
+}
+
+// WithDownloadProgress returns a CallOption that calls f as the body
+// of the response is read, whether by the Client when unmarshaling it
+// or by the caller when it is returned as an *http.Response. The total
+// is taken from the response's ContentLength.
+//
+// f is called synchronously from the goroutine reading the body, so
+// it should not block.
+func WithDownloadProgress(f func(Progress)) CallOption {
+	return func(o *callOptions) {
+		o.downloadProgress = f
+	}
+}
+
+// reportDownload arranges for the progress of reading the body of
+// resp to be reported as specified by the options.
+func (o *callOptions) reportDownload(resp *http.Response) {
+	if o.downloadProgress == nil || resp.Body == nil || resp.Body == http.NoBody {
+		return
+	}
+	resp.Body = &progressReadCloser{
+		ReadCloser: resp.Body,
+		report:     o.downloadProgress,
+		progress:   Progress{Total: contentLength(resp.ContentLength)},
+	}
+}
+
+// progressDoer is the Doer used for calls made with
+// WithUploadProgress. It reports the progress of sending the body of
+// each attempt made with the underlying Doer.
+type progressDoer struct {
+	report func(Progress)
+	doer   Doer
+}
+
+// Do implements Doer.Do.
+func (d progressDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext.
+func (d progressDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req1 := *req
+		req1.Body = &progressReadCloser{
+			ReadCloser: req.Body,
+			report:     d.report,
+			progress:   Progress{Total: contentLength(req.ContentLength)},
+		}
+		req = &req1
+	}
+	return send(ctx, d.doer, req)
+}
+
+// contentLength returns the total to report for a body with the given
+// ContentLength, which is zero or negative when it is not known.
+func contentLength(n int64) int64 {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+// progressReadCloser reports the number of bytes read from
+// the ReadCloser.
+type progressReadCloser struct {
+	io.ReadCloser
+	report   func(Progress)
+	progress Progress
+}
+
+// Read implements io.Reader.Read.
+func (r *progressReadCloser) Read(buf []byte) (int, error) {
+	n, err := r.ReadCloser.Read(buf)
+	if n > 0 {
+		r.progress.Transferred += int64(n)
+		r.report(r.progress)
+	}
+	return n, err
+}
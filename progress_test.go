@@ -0,0 +1,145 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// progressServer returns a server that responds to each request with
+// a JSON string holding the request body, streamed without a
+// Content-Length when the request has a "chunked" query parameter.
+func progressServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		body := []byte(`"` + string(data) + `"`)
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Query().Get("chunked") == "" {
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		}
+		w.Write(body)
+	}))
+}
+
+// recordProgress returns a function that records the progress
+// reported to it, and the recorded values.
+func recordProgress() (func(httprequest.Progress), *[]httprequest.Progress) {
+	var ps []httprequest.Progress
+	return func(p httprequest.Progress) {
+		ps = append(ps, p)
+	}, &ps
+}
+
+func assertProgress(c *qt.C, ps []httprequest.Progress, total int64) {
+	c.Assert(len(ps) > 0, qt.IsTrue)
+	for i := 1; i < len(ps); i++ {
+		c.Assert(ps[i].Transferred > ps[i-1].Transferred, qt.IsTrue, qt.Commentf("%v", ps))
+	}
+	last := ps[len(ps)-1]
+	c.Assert(last.Total, qt.Equals, total)
+	if total >= 0 {
+		c.Assert(last.Transferred, qt.Equals, total)
+	}
+}
+
+func TestUploadAndDownloadProgress(t *testing.T) {
+	c := qt.New(t)
+
+	srv := progressServer()
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	data := strings.Repeat("x", 100*1024)
+	req, err := http.NewRequest("PUT", "/", strings.NewReader(data))
+	c.Assert(err, qt.IsNil)
+	upload, uploaded := recordProgress()
+	download, downloaded := recordProgress()
+	var resp string
+	err = client.Do(context.Background(), req, &resp, httprequest.WithUploadProgress(upload), httprequest.WithDownloadProgress(download))
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, data)
+	assertProgress(c, *uploaded, int64(len(data)))
+	assertProgress(c, *downloaded, int64(len(data)+2))
+}
+
+func TestProgressWithUnknownLength(t *testing.T) {
+	c := qt.New(t)
+
+	srv := progressServer()
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+	}
+
+	data := strings.Repeat("y", 100*1024)
+	// Hide the length of the body from http.NewRequest.
+	req, err := http.NewRequest("PUT", "/?chunked=1", io.MultiReader(strings.NewReader(data)))
+	c.Assert(err, qt.IsNil)
+	upload, uploaded := recordProgress()
+	download, downloaded := recordProgress()
+	var resp *http.Response
+	err = client.Do(context.Background(), req, &resp, httprequest.WithUploadProgress(upload), httprequest.WithDownloadProgress(download))
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	c.Assert(*downloaded, qt.HasLen, 0)
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(len(body), qt.Equals, len(data)+2)
+	assertProgress(c, *uploaded, -1)
+	assertProgress(c, *downloaded, -1)
+	c.Assert((*uploaded)[len(*uploaded)-1].Transferred, qt.Equals, int64(len(data)))
+	c.Assert((*downloaded)[len(*downloaded)-1].Transferred, qt.Equals, int64(len(body)))
+}
+
+func TestUploadProgressWithRetry(t *testing.T) {
+	c := qt.New(t)
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, "ok")
+	}))
+	defer srv.Close()
+	client := httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff:     func(int) time.Duration { return 0 },
+		},
+	}
+	data := []byte("hello")
+	req, err := http.NewRequest("PUT", "/", bytes.NewReader(data))
+	c.Assert(err, qt.IsNil)
+	upload, uploaded := recordProgress()
+	err = client.Do(context.Background(), req, nil, httprequest.WithUploadProgress(upload))
+	c.Assert(err, qt.IsNil)
+	c.Assert(attempts, qt.Equals, 2)
+	c.Assert(*uploaded, qt.DeepEquals, []httprequest.Progress{{
+		Transferred: 5,
+		Total:       5,
+	}, {
+		Transferred: 5,
+		Total:       5,
+	}})
+}
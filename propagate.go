@@ -0,0 +1,71 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+)
+
+type propagatedHeadersKey struct{}
+
+// ContextWithPropagatedHeaders returns a copy of ctx that holds the
+// given headers. A Client copies them onto each request it makes
+// with the returned context, unless the request already has a header
+// with the same name.
+//
+// Handlers created by Server.Handle, Server.Handlers and related
+// methods call this with the headers named in Server.PropagateHeaders,
+// so that requests made to other services while handling a request
+// carry them on.
+func ContextWithPropagatedHeaders(ctx context.Context, h http.Header) context.Context {
+	return context.WithValue(ctx, propagatedHeadersKey{}, h.Clone())
+}
+
+// PropagatedHeaders returns the headers stored in ctx by
+// ContextWithPropagatedHeaders, or nil if there are none.
+// The returned value should not be modified.
+func PropagatedHeaders(ctx context.Context) http.Header {
+	h, _ := ctx.Value(propagatedHeadersKey{}).(http.Header)
+	return h
+}
+
+// contextWithPropagatedHeaders returns a copy of ctx holding the
+// values in req of the headers named in names. It returns ctx
+// unchanged if there are none.
+func contextWithPropagatedHeaders(ctx context.Context, req *http.Request, names []string) context.Context {
+	var h http.Header
+	for _, name := range names {
+		vs := req.Header.Values(name)
+		if len(vs) == 0 {
+			continue
+		}
+		if h == nil {
+			h = make(http.Header)
+		}
+		h[http.CanonicalHeaderKey(name)] = append([]string(nil), vs...)
+	}
+	if h == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, propagatedHeadersKey{}, h)
+}
+
+// addPropagatedHeaders adds the headers stored in ctx to req,
+// except those that req already has.
+func addPropagatedHeaders(ctx context.Context, req *http.Request) {
+	h := PropagatedHeaders(ctx)
+	if len(h) == 0 {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	for k, vs := range h {
+		if _, ok := req.Header[k]; ok {
+			continue
+		}
+		req.Header[k] = append([]string(nil), vs...)
+	}
+}
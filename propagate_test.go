@@ -0,0 +1,93 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// echoHeadersServer returns a server that responds
+// with the headers of each request it receives.
+func echoHeadersServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		httprequest.WriteJSON(w, http.StatusOK, req.Header)
+	}))
+}
+
+func TestPropagateHeaders(t *testing.T) {
+	c := qt.New(t)
+
+	downstream := echoHeadersServer()
+	defer downstream.Close()
+
+	srv := httprequest.Server{
+		PropagateHeaders: []string{"X-Request-ID", "accept-language"},
+	}
+	router := httprouter.New()
+	for _, h := range []httprequest.Handler{srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /relay"`
+	}) (http.Header, error) {
+		client := httprequest.Client{
+			BaseURL: downstream.URL,
+		}
+		var h http.Header
+		err := client.Get(p.Context, "/", &h)
+		return h, err
+	})} {
+		router.Handle(h.Method, h.Path, h.Handle)
+	}
+	upstream := httptest.NewServer(router)
+	defer upstream.Close()
+
+	req, err := http.NewRequest("GET", upstream.URL+"/relay", nil)
+	c.Assert(err, qt.IsNil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Add("Accept-Language", "en")
+	req.Header.Add("Accept-Language", "fr")
+	req.Header.Set("X-Other", "other")
+	var h http.Header
+	err = (&httprequest.Client{}).Do(context.Background(), req, &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get("X-Request-ID"), qt.Equals, "req-1")
+	c.Assert(h.Values("Accept-Language"), qt.DeepEquals, []string{"en", "fr"})
+	c.Assert(h.Get("X-Other"), qt.Equals, "")
+}
+
+func TestPropagatedHeadersDoNotOverride(t *testing.T) {
+	c := qt.New(t)
+
+	downstream := echoHeadersServer()
+	defer downstream.Close()
+
+	ctx := httprequest.ContextWithPropagatedHeaders(context.Background(), http.Header{
+		"X-Request-Id":  {"req-1"},
+		"Authorization": {"Bearer propagated"},
+		"X-Tenant":      {"propagated"},
+	})
+	c.Assert(httprequest.PropagatedHeaders(ctx).Get("X-Request-ID"), qt.Equals, "req-1")
+
+	client := httprequest.Client{
+		BaseURL: downstream.URL,
+		BasicAuth: &httprequest.BasicAuth{
+			Username: "user",
+			Password: "pass",
+		},
+	}
+	var h http.Header
+	err := client.Get(ctx, "/", &h, httprequest.WithHeader("X-Tenant", "explicit"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get("X-Request-ID"), qt.Equals, "req-1")
+	c.Assert(h.Values("X-Tenant"), qt.DeepEquals, []string{"explicit"})
+	c.Assert(h.Get("Authorization"), qt.Equals, "Basic dXNlcjpwYXNz")
+
+	c.Assert(httprequest.PropagatedHeaders(context.Background()), qt.IsNil)
+}
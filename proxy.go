@@ -0,0 +1,56 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Proxy is a type that can be returned from a handler to forward the
+// request to another server, after it has been authorized and
+// unmarshaled, instead of writing a response itself. The response from
+// the other server is streamed back to the client.
+//
+// Note that the request body is only forwarded if it has not already
+// been consumed by unmarshaling a body or form field.
+type Proxy struct {
+	// Target holds the URL to forward the request to. The
+	// forwarded request has exactly this URL, so the handler is
+	// responsible for including any path or query parameters from
+	// the original request that should be passed on.
+	Target *url.URL
+
+	// ReverseProxy, if non-nil, is used to forward the request
+	// instead of a proxy created to forward to Target, which is
+	// then ignored.
+	ReverseProxy *httputil.ReverseProxy
+}
+
+// writeProxy forwards req as directed by p.
+func (srv *Server) writeProxy(w http.ResponseWriter, req *http.Request, p Proxy) error {
+	rp := p.ReverseProxy
+	if rp == nil {
+		if p.Target == nil {
+			return errgo.New("proxy has no target")
+		}
+		target := *p.Target
+		rp = &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				u := target
+				pr.Out.URL = &u
+				pr.Out.Host = ""
+				pr.SetXForwarded()
+			},
+			ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+				srv.WriteError(req.Context(), w, Errorf(CodeBadGateway, "cannot forward request: %v", err))
+			},
+		}
+	}
+	rp.ServeHTTP(w, req)
+	return nil
+}
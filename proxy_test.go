@@ -0,0 +1,110 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	"github.com/julienschmidt/httprouter"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type proxyRequest struct {
+	httprequest.Route `httprequest:"GET /items/:id" auth:"read"`
+	ID                string `httprequest:"id,path"`
+}
+
+func TestProxy(t *testing.T) {
+	c := qt.New(t)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Backend", "yes")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "%s %s %s", req.Method, req.URL.RequestURI(), req.Header.Get("X-Forwarded-Host"))
+	}))
+	defer backend.Close()
+	backendURL, err := url.Parse(backend.URL)
+	c.Assert(err, qt.IsNil)
+
+	var authorized []string
+	srv := httprequest.Server{
+		Authorize: func(_ context.Context, p httprequest.Params, requirements []string) error {
+			authorized = requirements
+			return nil
+		},
+	}
+	h := srv.Handle(func(req *proxyRequest) (httprequest.Proxy, error) {
+		target := *backendURL
+		target.Path = "/v2/items/" + req.ID
+		return httprequest.Proxy{
+			Target: &target,
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "http://gateway.example/items/42", nil)
+	h.Handle(rec, req, httprouter.Params{{Key: "id", Value: "42"}})
+	c.Assert(rec.Code, qt.Equals, http.StatusAccepted)
+	c.Assert(rec.Header().Get("X-Backend"), qt.Equals, "yes")
+	c.Assert(rec.Body.String(), qt.Equals, "GET /v2/items/42 gateway.example")
+	c.Assert(authorized, qt.DeepEquals, []string{"read"})
+}
+
+func TestProxyWithReverseProxy(t *testing.T) {
+	c := qt.New(t)
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s", req.URL.Path)
+	}))
+	defer backend.Close()
+	backendURL, err := url.Parse(backend.URL)
+	c.Assert(err, qt.IsNil)
+
+	h := testServer.Handle(func(*struct{}) (httprequest.Proxy, error) {
+		return httprequest.Proxy{
+			ReverseProxy: httputil.NewSingleHostReverseProxy(backendURL),
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/some/path", nil), nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), qt.Equals, "/some/path")
+}
+
+func TestProxyErrors(t *testing.T) {
+	c := qt.New(t)
+
+	backend := httptest.NewServer(nil)
+	backendURL, err := url.Parse(backend.URL)
+	c.Assert(err, qt.IsNil)
+	backend.Close()
+
+	var srv httprequest.Server
+	h := srv.Handle(func(*struct{}) (httprequest.Proxy, error) {
+		return httprequest.Proxy{
+			Target: backendURL,
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusBadGateway)
+	c.Assert(rec.Body.String(), qt.Matches, `\{"Message":"cannot forward request: .*","Code":"bad gateway"\}\n?`)
+
+	h = srv.Handle(func(*struct{}) (httprequest.Proxy, error) {
+		return httprequest.Proxy{}, nil
+	})
+	rec = httptest.NewRecorder()
+	h.Handle(rec, httptest.NewRequest("GET", "/", nil), nil)
+	qthttptest.AssertJSONResponse(c, rec, http.StatusInternalServerError, &httprequest.RemoteError{
+		Message: "proxy has no target",
+	})
+}
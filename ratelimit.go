@@ -0,0 +1,55 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// TooManyRequestsError may be returned by Server.RateLimit to reject
+// a request. With DefaultErrorMapper, it results in a response with
+// status http.StatusTooManyRequests.
+type TooManyRequestsError struct {
+	// RetryAfter holds how long the client should wait before
+	// retrying the request. If it is positive, it is sent to the
+	// client in the Retry-After header.
+	RetryAfter time.Duration
+}
+
+// Error implements error.Error.
+func (e *TooManyRequestsError) Error() string {
+	return "too many requests"
+}
+
+// RetryAfterDuration implements RetryAfterer
+// by returning e.RetryAfter.
+func (e *TooManyRequestsError) RetryAfterDuration() time.Duration {
+	return e.RetryAfter
+}
+
+// ErrorCode implements ErrorCoder by returning
+// CodeTooManyRequests.
+func (e *TooManyRequestsError) ErrorCode() string {
+	return CodeTooManyRequests
+}
+
+// withRateLimit returns a handler that consults srv.RateLimit
+// before calling h to serve a request for the given path pattern.
+func (srv *Server) withRateLimit(h httprouter.Handle, pathPattern string) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		if srv.RateLimit == nil {
+			h(w, req, p)
+			return
+		}
+		err := srv.RateLimit(req, pathPattern)
+		if err == nil {
+			h(w, req, p)
+			return
+		}
+		srv.WriteError(req.Context(), w, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var rateLimitTests = []struct {
+	about            string
+	limitErr         error
+	expectStatus     int
+	expectBody       interface{}
+	expectRetryAfter string
+}{{
+	about:        "allowed",
+	expectStatus: http.StatusOK,
+	expectBody:   "ok",
+}, {
+	about: "too many requests",
+	limitErr: &httprequest.TooManyRequestsError{
+		RetryAfter: 1500 * time.Millisecond,
+	},
+	expectStatus: http.StatusTooManyRequests,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTooManyRequests,
+		Message: "too many requests",
+	},
+	expectRetryAfter: "2",
+}, {
+	about:        "too many requests without retry time",
+	limitErr:     &httprequest.TooManyRequestsError{},
+	expectStatus: http.StatusTooManyRequests,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTooManyRequests,
+		Message: "too many requests",
+	},
+}, {
+	about:        "other error",
+	limitErr:     httprequest.Errorf(httprequest.CodeForbidden, "blocked"),
+	expectStatus: http.StatusForbidden,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeForbidden,
+		Message: "blocked",
+	},
+}}
+
+func TestRateLimit(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range rateLimitTests {
+		c.Run(test.about, func(c *qt.C) {
+			var gotPattern string
+			srv := httprequest.Server{
+				RateLimit: func(req *http.Request, pathPattern string) error {
+					gotPattern = pathPattern
+					return test.limitErr
+				},
+			}
+			router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (observeHandlers, context.Context, error) {
+				return observeHandlers{}, p.Context, nil
+			}))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", "/items/1", nil))
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+			c.Assert(rec.Header().Get("Retry-After"), qt.Equals, test.expectRetryAfter)
+			c.Assert(gotPattern, qt.Equals, "/items/:id")
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// RequestCompression specifies how a Client compresses request bodies
+// (see Client.RequestCompression). Bodies are only compressed when the
+// server is known to accept the compression, either because
+// AssumeAccepted is set or because the server has listed the encoding
+// in the Accept-Encoding header of a response, as described in RFC
+// 7694, typically that of a http.StatusUnsupportedMediaType response.
+//
+// When a compressed request is rejected with the status
+// http.StatusUnsupportedMediaType, the Client records that the server
+// does not accept the encoding and sends the request again
+// uncompressed.
+//
+// A RequestCompression records what it learns about each server, so
+// the same value should be used for all requests to the same servers.
+// It must not be copied after first use.
+type RequestCompression struct {
+	// Compressors holds the content codings that may be used to
+	// compress request bodies, in order of preference. If it is
+	// empty, GzipCompressor is used. Other codings, such as zstd,
+	// can be used by supplying their own Compressor.
+	Compressors []Compressor
+
+	// MinSize holds the size that a request body must reach for it
+	// to be compressed. Bodies whose size is not known in advance
+	// (see http.Request.ContentLength) are never compressed.
+	MinSize int
+
+	// AssumeAccepted specifies that servers are assumed to accept
+	// the first of Compressors until they show otherwise.
+	AssumeAccepted bool
+
+	mu sync.Mutex
+	// accepted holds the encodings that each server, identified
+	// by its host, is known to accept.
+	accepted map[string][]string
+}
+
+// compressor returns the compressor to use for requests to the given
+// host, or nil if their bodies should not be compressed.
+func (rc *RequestCompression) compressor(host string) *Compressor {
+	compressors := rc.Compressors
+	if len(compressors) == 0 {
+		compressors = []Compressor{GzipCompressor}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	accepted, ok := rc.accepted[host]
+	if !ok {
+		if rc.AssumeAccepted {
+			return &compressors[0]
+		}
+		return nil
+	}
+	for i, c := range compressors {
+		for _, encoding := range accepted {
+			if c.Encoding == encoding {
+				return &compressors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// learn records the encodings that the server that sent resp
+// accepts, if the response lists them.
+func (rc *RequestCompression) learn(host string, resp *http.Response) {
+	if resp.Header["Accept-Encoding"] == nil {
+		return
+	}
+	accepted := []string{}
+	for _, v := range parseAccept(resp.Header["Accept-Encoding"]) {
+		if v.q > 0 {
+			accepted = append(accepted, v.value)
+		}
+	}
+	rc.setAccepted(host, accepted)
+}
+
+// setAccepted records that the given host accepts
+// the given encodings.
+func (rc *RequestCompression) setAccepted(host string, accepted []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.accepted == nil {
+		rc.accepted = make(map[string][]string)
+	}
+	rc.accepted[host] = accepted
+}
+
+// compressDoer is the Doer used by a Client with a RequestCompression.
+// It compresses the bodies of requests to servers that accept it.
+type compressDoer struct {
+	compression *RequestCompression
+	doer        Doer
+}
+
+// Do implements Doer.Do.
+func (d compressDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext. The request
+// is not modified; a copy with the compressed body is sent in its
+// place. If the request is rejected with the status
+// http.StatusUnsupportedMediaType and the encodings that the server
+// accepts turn out to differ from those assumed, it is sent once more
+// accordingly.
+func (d compressDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	resp, used, err := d.attempt(ctx, req, d.compression.compressor(host))
+	if err != nil || resp.StatusCode != http.StatusUnsupportedMediaType {
+		return resp, err
+	}
+	if used != nil && resp.Header["Accept-Encoding"] == nil {
+		// The server does not accept the encoding and
+		// has not said which encodings it does accept.
+		d.compression.setAccepted(host, []string{})
+	}
+	next := d.compression.compressor(host)
+	if encoding(next) == encoding(used) {
+		// The request was rejected for some other reason.
+		return resp, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			// The body can't be sent again.
+			return resp, nil
+		}
+		if req.Body, err = req.GetBody(); err != nil {
+			resp.Body.Close()
+			return nil, errgo.Notef(err, "cannot get request body for retry")
+		}
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 8*1024))
+	resp.Body.Close()
+	resp, _, err = d.attempt(ctx, req, next)
+	return resp, err
+}
+
+// attempt sends req, with its body compressed by c if c is non-nil
+// and the body is known to be large enough, returning the response
+// and the compressor that was used, if any. The body is compressed as
+// it is sent, so the compressed request has no Content-Length. It
+// learns which encodings the server accepts from the response.
+func (d compressDoer) attempt(ctx context.Context, req *http.Request, c *Compressor) (*http.Response, *Compressor, error) {
+	if c == nil || req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" || unknownLength(req) || req.ContentLength < int64(d.compression.MinSize) {
+		resp, err := d.send(ctx, req)
+		return resp, nil, err
+	}
+	creq := req.Clone(ctx)
+	creq.Body = compressedBody(req.Body, c)
+	creq.GetBody = nil
+	if req.GetBody != nil {
+		creq.GetBody = func() (io.ReadCloser, error) {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			return compressedBody(body, c), nil
+		}
+	}
+	creq.ContentLength = -1
+	creq.Header.Set("Content-Encoding", c.Encoding)
+	resp, err := d.send(ctx, creq)
+	return resp, c, err
+}
+
+// compressedBody returns a reader of the data read from body,
+// compressed by c as it is read. The body is closed when
+// the returned reader has been read or closed.
+func compressedBody(body io.ReadCloser, c *Compressor) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+		w := c.NewWriter(pw)
+		_, err := io.Copy(w, body)
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			err = errgo.Notef(err, "cannot compress request body")
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// send sends req with the underlying Doer, learning which encodings
+// the server accepts from the response.
+func (d compressDoer) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	resp, err := send(ctx, d.doer, req)
+	if err == nil {
+		d.compression.learn(req.URL.Host, resp)
+	}
+	return resp, err
+}
+
+// encoding returns the encoding of c, or "" if c is nil.
+func encoding(c *Compressor) string {
+	if c == nil {
+		return ""
+	}
+	return c.Encoding
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// compressRequest holds details of a request received by
+// compressServer.
+type compressRequest struct {
+	Encoding string
+	Body     string
+}
+
+// compressServer returns a server that responds to requests with a
+// JSON string holding the request body, decoding gzip-encoded bodies
+// if acceptGzip is set and rejecting others, as well as uncompressed
+// bodies if requireGzip is set, with http.StatusUnsupportedMediaType.
+// Rejections list the accepted encodings in the Accept-Encoding header
+// if advertise is set.
+func compressServer(acceptGzip, requireGzip, advertise bool, reqs *[]compressRequest) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		encoding := req.Header.Get("Content-Encoding")
+		data, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		*reqs = append(*reqs, compressRequest{encoding, string(data)})
+		mu.Unlock()
+		reject := func() {
+			if advertise {
+				if acceptGzip {
+					w.Header().Set("Accept-Encoding", "gzip")
+				} else {
+					w.Header().Set("Accept-Encoding", "identity")
+				}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			w.Write([]byte(`{"Message":"unsupported encoding"}`))
+		}
+		switch {
+		case encoding == "gzip" && acceptGzip:
+			r, err := gzip.NewReader(strings.NewReader(string(data)))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ = ioutil.ReadAll(r)
+		case encoding != "" || requireGzip:
+			reject()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`"` + string(data) + `"`))
+	}))
+}
+
+// postCompressed posts body to the given server with client, returning
+// the response.
+func postCompressed(c *qt.C, client *httprequest.Client, url, body string) (string, error) {
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
+	c.Assert(err, qt.IsNil)
+	var resp string
+	err = client.Do(context.Background(), req, &resp)
+	return resp, err
+}
+
+var body1K = strings.Repeat("a", 1024)
+
+func TestRequestCompressionAssumeAccepted(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := compressServer(true, false, false, &reqs)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{
+			AssumeAccepted: true,
+			MinSize:        100,
+		},
+	}
+	resp, err := postCompressed(c, client, srv.URL, body1K)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, body1K)
+	resp, err = postCompressed(c, client, srv.URL, "small")
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "small")
+	c.Assert(reqs, qt.HasLen, 2)
+	c.Assert(reqs[0].Encoding, qt.Equals, "gzip")
+	c.Assert(len(reqs[0].Body) < len(body1K), qt.IsTrue)
+	c.Assert(reqs[1], qt.Equals, compressRequest{"", "small"})
+}
+
+func TestRequestCompressionNotAssumed(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := compressServer(true, false, false, &reqs)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{},
+	}
+	resp, err := postCompressed(c, client, srv.URL, body1K)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, body1K)
+	c.Assert(reqs, qt.DeepEquals, []compressRequest{{"", body1K}})
+}
+
+func TestRequestCompressionLearnedFrom415(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := compressServer(true, true, true, &reqs)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{},
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := postCompressed(c, client, srv.URL, body1K)
+		c.Assert(err, qt.IsNil)
+		c.Assert(resp, qt.Equals, body1K)
+	}
+	c.Assert(reqs, qt.HasLen, 3)
+	c.Assert(reqs[0], qt.Equals, compressRequest{"", body1K})
+	c.Assert(reqs[1].Encoding, qt.Equals, "gzip")
+	c.Assert(reqs[2].Encoding, qt.Equals, "gzip")
+}
+
+func TestRequestCompressionRejected(t *testing.T) {
+	c := qt.New(t)
+	for _, advertise := range []bool{false, true} {
+		c.Run("", func(c *qt.C) {
+			var reqs []compressRequest
+			srv := compressServer(false, false, advertise, &reqs)
+			defer srv.Close()
+
+			client := &httprequest.Client{
+				RequestCompression: &httprequest.RequestCompression{
+					AssumeAccepted: true,
+				},
+			}
+			for i := 0; i < 2; i++ {
+				resp, err := postCompressed(c, client, srv.URL, body1K)
+				c.Assert(err, qt.IsNil)
+				c.Assert(resp, qt.Equals, body1K)
+			}
+			c.Assert(reqs, qt.HasLen, 3)
+			c.Assert(reqs[0].Encoding, qt.Equals, "gzip")
+			c.Assert(reqs[1], qt.Equals, compressRequest{"", body1K})
+			c.Assert(reqs[2], qt.Equals, compressRequest{"", body1K})
+		})
+	}
+}
+
+func TestRequestCompressionOtherUnsupportedMediaType(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reqs = append(reqs, compressRequest{Encoding: req.Header.Get("Content-Encoding")})
+		w.Header().Set("Accept-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		w.Write([]byte(`{"Message":"unsupported content type"}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{
+			AssumeAccepted: true,
+		},
+	}
+	_, err := postCompressed(c, client, srv.URL, body1K)
+	c.Assert(err, qt.ErrorMatches, `Post http://.*: unsupported content type`)
+	c.Assert(reqs, qt.DeepEquals, []compressRequest{{Encoding: "gzip"}})
+}
+
+func TestRequestCompressionUnknownLength(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := compressServer(true, false, false, &reqs)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{
+			AssumeAccepted: true,
+		},
+	}
+	// A body of unknown length is sent as it is
+	// rather than being read to find its size.
+	req, err := http.NewRequest("POST", srv.URL, ioutil.NopCloser(strings.NewReader(body1K)))
+	c.Assert(err, qt.IsNil)
+	var resp string
+	err = client.Do(context.Background(), req, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, body1K)
+	c.Assert(reqs, qt.DeepEquals, []compressRequest{{"", body1K}})
+}
+
+func TestRequestCompressionSigned(t *testing.T) {
+	c := qt.New(t)
+	var reqs []compressRequest
+	srv := compressServer(true, false, false, &reqs)
+	defer srv.Close()
+
+	var signed []string
+	client := &httprequest.Client{
+		RequestCompression: &httprequest.RequestCompression{
+			AssumeAccepted: true,
+		},
+		Signer: func(req *http.Request, body []byte) error {
+			signed = append(signed, string(body))
+			return nil
+		},
+	}
+	resp, err := postCompressed(c, client, srv.URL, body1K)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, body1K)
+	c.Assert(reqs, qt.HasLen, 1)
+	c.Assert(reqs[0].Encoding, qt.Equals, "gzip")
+	// The signer is passed the compressed body.
+	c.Assert(signed, qt.DeepEquals, []string{reqs[0].Body})
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// setRequestID sets the RequestIDHeader of req to a newly generated
+// identifier if c.GenerateRequestID is set and req does not already
+// have one, and returns the request's identifier.
+func (c *Client) setRequestID(req *http.Request) (string, error) {
+	if id := req.Header.Get(RequestIDHeader); id != "" || !c.GenerateRequestID {
+		return id, nil
+	}
+	id, err := newRequestID()
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+	req.Header.Set(RequestIDHeader, id)
+	return id, nil
+}
+
+// newRequestID returns a random (version 4) UUID.
+func newRequestID() (string, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", errgo.Notef(err, "cannot generate request ID")
+	}
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:]), nil
+}
+
+// requestIDError returns err annotated with the given request
+// identifier if c.GenerateRequestID is set.
+func (c *Client) requestIDError(err error, id string) error {
+	if !c.GenerateRequestID || id == "" {
+		return err
+	}
+	return errgo.NoteMask(err, "request "+id, errgo.Any)
+}
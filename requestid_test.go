@@ -0,0 +1,112 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+const uuidPattern = `[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}`
+
+func TestClientGenerateRequestID(t *testing.T) {
+	c := qt.New(t)
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ids = append(ids, req.Header.Get(httprequest.RequestIDHeader))
+		if req.URL.Path == "/error" {
+			httprequest.WriteJSON(w, http.StatusBadRequest, &httprequest.RemoteError{
+				Message: "bad request",
+			})
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, "ok")
+	}))
+	defer srv.Close()
+
+	var reqInfos []httprequest.ClientRequestInfo
+	var respInfos []httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL:           srv.URL,
+		GenerateRequestID: true,
+		OnRequest: func(ctx context.Context, info httprequest.ClientRequestInfo) {
+			reqInfos = append(reqInfos, info)
+		},
+		OnResponse: func(ctx context.Context, info httprequest.ClientResponseInfo) {
+			respInfos = append(respInfos, info)
+		},
+	}
+	err := client.Get(context.Background(), "/ok", nil)
+	c.Assert(err, qt.IsNil)
+	err = client.Get(context.Background(), "/error", nil)
+	c.Assert(err, qt.ErrorMatches, `request `+uuidPattern+`: Get http://.*/error: bad request`)
+	_, ok := errgo.Cause(err).(*httprequest.RemoteError)
+	c.Assert(ok, qt.IsTrue)
+
+	c.Assert(ids, qt.HasLen, 2)
+	c.Assert(ids[0], qt.Matches, uuidPattern)
+	c.Assert(ids[1], qt.Matches, uuidPattern)
+	c.Assert(ids[0], qt.Not(qt.Equals), ids[1])
+	c.Assert(err, qt.ErrorMatches, `request `+ids[1]+`: .*`)
+	c.Assert(reqInfos[0].RequestID, qt.Equals, ids[0])
+	c.Assert(reqInfos[1].RequestID, qt.Equals, ids[1])
+	c.Assert(respInfos[1].Request.RequestID, qt.Equals, ids[1])
+	c.Assert(respInfos[1].Err, qt.Equals, err)
+}
+
+func TestClientGenerateRequestIDReusesExisting(t *testing.T) {
+	c := qt.New(t)
+	srv := echoHeadersServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:           srv.URL,
+		GenerateRequestID: true,
+	}
+	ctx := httprequest.ContextWithPropagatedHeaders(context.Background(), http.Header{
+		httprequest.RequestIDHeader: {"from-context"},
+	})
+	var h http.Header
+	err := client.Get(ctx, "/", &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get(httprequest.RequestIDHeader), qt.Equals, "from-context")
+
+	h = nil
+	err = client.Get(context.Background(), "/", &h, httprequest.WithHeader(httprequest.RequestIDHeader, "explicit"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get(httprequest.RequestIDHeader), qt.Equals, "explicit")
+}
+
+func TestClientGenerateRequestIDTransportError(t *testing.T) {
+	c := qt.New(t)
+	client := &httprequest.Client{
+		GenerateRequestID: true,
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errgo.New("connection refused")
+		}),
+	}
+	err := client.Get(context.Background(), "http://0.1.2.3/x", nil, httprequest.WithHeader(httprequest.RequestIDHeader, "id1"))
+	c.Assert(err, qt.ErrorMatches, `request id1: Get http://0.1.2.3/x: connection refused`)
+}
+
+func TestClientWithoutGenerateRequestID(t *testing.T) {
+	c := qt.New(t)
+	srv := echoHeadersServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var h http.Header
+	err := client.Get(context.Background(), "/", &h)
+	c.Assert(err, qt.IsNil)
+	c.Assert(h.Get(httprequest.RequestIDHeader), qt.Equals, "")
+}
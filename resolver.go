@@ -0,0 +1,166 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// Resolver is implemented by types that find the endpoints that can
+// serve a Client's requests, for example by looking up DNS SRV records
+// or querying a service registry. See Client.Resolver.
+type Resolver interface {
+	// Resolve returns the base URLs of the endpoints,
+	// in the form of Client.BaseURL.
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// StaticResolver is a Resolver that always
+// returns the base URLs it holds.
+type StaticResolver []string
+
+// Resolve implements Resolver.Resolve.
+func (r StaticResolver) Resolve(ctx context.Context) ([]string, error) {
+	return r, nil
+}
+
+// Balancer is implemented by types that choose the endpoint that
+// serves a request. See Client.Balancer. Implementations must be safe
+// to call concurrently.
+type Balancer interface {
+	// Pick returns the endpoint to send a request to, which must be
+	// one of the given endpoints, of which there is at least one.
+	// It also returns a function that is called when the request
+	// has completed.
+	Pick(endpoints []string) (endpoint string, done func())
+}
+
+// NewRoundRobinBalancer returns a Balancer that picks each
+// endpoint in turn.
+func NewRoundRobinBalancer() Balancer {
+	return new(roundRobinBalancer)
+}
+
+type roundRobinBalancer struct {
+	n uint64
+}
+
+// Pick implements Balancer.Pick.
+func (b *roundRobinBalancer) Pick(endpoints []string) (string, func()) {
+	n := atomic.AddUint64(&b.n, 1) - 1
+	return endpoints[n%uint64(len(endpoints))], func() {}
+}
+
+// NewLeastPendingBalancer returns a Balancer that picks the endpoint
+// with the fewest requests in progress, preferring earlier endpoints
+// when there is more than one.
+func NewLeastPendingBalancer() Balancer {
+	return &leastPendingBalancer{
+		pending: make(map[string]int),
+	}
+}
+
+type leastPendingBalancer struct {
+	mu      sync.Mutex
+	pending map[string]int
+}
+
+// Pick implements Balancer.Pick.
+func (b *leastPendingBalancer) Pick(endpoints []string) (string, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	endpoint := endpoints[0]
+	for _, e := range endpoints[1:] {
+		if b.pending[e] < b.pending[endpoint] {
+			endpoint = e
+		}
+	}
+	b.pending[endpoint]++
+	var once sync.Once
+	return endpoint, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.pending[endpoint]--; b.pending[endpoint] == 0 {
+				delete(b.pending, endpoint)
+			}
+		})
+	}
+}
+
+// randomBalancer is the Balancer used when Client.Balancer is nil.
+type randomBalancer struct{}
+
+// Pick implements Balancer.Pick.
+func (randomBalancer) Pick(endpoints []string) (string, func()) {
+	return endpoints[rand.Intn(len(endpoints))], func() {}
+}
+
+// resolvingDoer is the Doer used by a Client with a Resolver. It
+// sends each request, which has a relative URL, to an endpoint chosen
+// from those found by the Resolver.
+type resolvingDoer struct {
+	resolver Resolver
+	balancer Balancer
+	doer     Doer
+}
+
+// Do implements Doer.Do.
+func (d resolvingDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext.
+func (d resolvingDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	endpoints, err := d.resolver.Resolve(ctx)
+	if err != nil {
+		return nil, errgo.NoteMask(err, "cannot resolve endpoints", errgo.Any)
+	}
+	if len(endpoints) == 0 {
+		return nil, errgo.Newf("no endpoints available")
+	}
+	balancer := d.balancer
+	if balancer == nil {
+		balancer = randomBalancer{}
+	}
+	endpoint, done := balancer.Pick(endpoints)
+	u, err := appendURL(endpoint, req.URL.String())
+	if err != nil {
+		done()
+		return nil, errgo.Mask(err)
+	}
+	req1 := req.Clone(ctx)
+	req1.URL = u
+	req1.Host = ""
+	resp, err := send(ctx, d.doer, req1)
+	if err != nil {
+		done()
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	resp.Body = doneReadCloser{
+		ReadCloser: resp.Body,
+		done:       done,
+	}
+	return resp, nil
+}
+
+// doneReadCloser calls done when it is closed.
+type doneReadCloser struct {
+	io.ReadCloser
+	done func()
+}
+
+// Close implements io.Closer.Close.
+func (r doneReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.done()
+	return err
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type nameRequest struct {
+	httprequest.Route `httprequest:"GET /name"`
+}
+
+// namedServer returns a server that responds to /name
+// with the given name.
+func namedServer(name string) *httptest.Server {
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *nameRequest) (string, error) {
+			return name, nil
+		}),
+	}))
+}
+
+func TestResolverRoundRobin(t *testing.T) {
+	c := qt.New(t)
+
+	srv0 := namedServer("srv0")
+	defer srv0.Close()
+	srv1 := namedServer("srv1")
+	defer srv1.Close()
+	client := &httprequest.Client{
+		BaseURL:  "http://0.1.2.3",
+		Resolver: httprequest.StaticResolver{srv0.URL, srv1.URL},
+		Balancer: httprequest.NewRoundRobinBalancer(),
+	}
+	var names []string
+	for i := 0; i < 4; i++ {
+		var name string
+		err := client.Call(context.Background(), &nameRequest{}, &name)
+		c.Assert(err, qt.IsNil)
+		names = append(names, name)
+	}
+	c.Assert(names, qt.DeepEquals, []string{"srv0", "srv1", "srv0", "srv1"})
+
+	// Requests with relative URLs made by Get use the resolver too.
+	var name string
+	err := client.Get(context.Background(), "/name", &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv0")
+
+	// Requests with absolute URLs don't.
+	err = client.Get(context.Background(), srv1.URL+"/name", &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv1")
+}
+
+func TestResolverRandom(t *testing.T) {
+	c := qt.New(t)
+
+	srv := namedServer("srv")
+	defer srv.Close()
+	client := &httprequest.Client{
+		Resolver: httprequest.StaticResolver{srv.URL},
+	}
+	var name string
+	err := client.Call(context.Background(), &nameRequest{}, &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv")
+}
+
+func TestResolverRetryFailover(t *testing.T) {
+	c := qt.New(t)
+
+	down := namedServer("down")
+	down.Close()
+	srv := namedServer("srv")
+	defer srv.Close()
+	client := &httprequest.Client{
+		Resolver: httprequest.StaticResolver{down.URL, srv.URL},
+		Balancer: httprequest.NewRoundRobinBalancer(),
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	var name string
+	err := client.Call(context.Background(), &nameRequest{}, &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv")
+}
+
+func TestResolverErrors(t *testing.T) {
+	c := qt.New(t)
+
+	client := &httprequest.Client{
+		Resolver: httprequest.StaticResolver{},
+	}
+	err := client.Call(context.Background(), &nameRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get /name: no endpoints available`)
+
+	client.Resolver = errorResolver{}
+	err = client.Call(context.Background(), &nameRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get /name: cannot resolve endpoints: registry unavailable`)
+	c.Assert(errgo.Cause(err), qt.Equals, errRegistryUnavailable)
+}
+
+var errRegistryUnavailable = errgo.New("registry unavailable")
+
+type errorResolver struct{}
+
+func (errorResolver) Resolve(ctx context.Context) ([]string, error) {
+	return nil, errRegistryUnavailable
+}
+
+func TestLeastPendingBalancer(t *testing.T) {
+	c := qt.New(t)
+
+	b := httprequest.NewLeastPendingBalancer()
+	endpoints := []string{"a", "b", "c"}
+	e0, done0 := b.Pick(endpoints)
+	c.Assert(e0, qt.Equals, "a")
+	e1, done1 := b.Pick(endpoints)
+	c.Assert(e1, qt.Equals, "b")
+	e2, _ := b.Pick(endpoints)
+	c.Assert(e2, qt.Equals, "c")
+	done1()
+	// Calling done more than once has no further effect.
+	done1()
+	e3, _ := b.Pick(endpoints)
+	c.Assert(e3, qt.Equals, "b")
+	done0()
+	e4, _ := b.Pick(endpoints)
+	c.Assert(e4, qt.Equals, "a")
+}
+
+func TestLeastPendingBalancerWithClient(t *testing.T) {
+	c := qt.New(t)
+
+	srv0 := namedServer("srv0")
+	defer srv0.Close()
+	srv1 := namedServer("srv1")
+	defer srv1.Close()
+	client := &httprequest.Client{
+		Resolver: httprequest.StaticResolver{srv0.URL, srv1.URL},
+		Balancer: httprequest.NewLeastPendingBalancer(),
+	}
+	// While the first response body is open, its request is pending,
+	// so the next request goes to the other endpoint.
+	var resp *http.Response
+	err := client.Call(context.Background(), &nameRequest{}, &resp)
+	c.Assert(err, qt.IsNil)
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, `"srv0"`)
+
+	var name string
+	err = client.Call(context.Background(), &nameRequest{}, &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv1")
+
+	resp.Body.Close()
+	err = client.Call(context.Background(), &nameRequest{}, &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "srv0")
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// responseHeaderFields holds the header fields of response types,
+// keyed by reflect.Type, as returned by getResponseHeaderFields.
+var responseHeaderFields sync.Map
+
+// getResponseHeaderFields returns the fields of the struct type t that
+// are tagged as header fields, caching the result.
+func getResponseHeaderFields(t reflect.Type) ([]field, error) {
+	if fs, ok := responseHeaderFields.Load(t); ok {
+		return fs.([]field), nil
+	}
+	var fs []field
+	for _, f := range fields(t) {
+		if f.PkgPath != "" {
+			continue
+		}
+		tag, err := parseTag(f.Tag, f.Name)
+		if err != nil {
+			return nil, errgo.Notef(err, "bad tag %q in field %s", f.Tag, f.Name)
+		}
+		if tag.source != sourceHeader {
+			continue
+		}
+		field := field{
+			index:      f.Index,
+			name:       f.Name,
+			makeResult: makeValueResult,
+		}
+		if f.Type.Kind() == reflect.Ptr {
+			field.makeResult = makePointerResult
+			field.isPointer = true
+			f.Type = f.Type.Elem()
+		}
+		field.unmarshal, err = getUnmarshaler(tag, f.Type)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+		fs = append(fs, field)
+	}
+	responseHeaderFields.Store(t, fs)
+	return fs, nil
+}
+
+// unmarshalResponseHeader sets the header fields of resp, if it is a
+// pointer to a struct, from the given response header.
+func unmarshalResponseHeader(h http.Header, resp interface{}) error {
+	v := reflect.ValueOf(resp)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	fs, err := getResponseHeaderFields(v.Type())
+	if err != nil {
+		return errgo.Notef(err, "bad response type %s", v.Type())
+	}
+	if len(fs) == 0 {
+		return nil
+	}
+	p := Params{
+		Request: &http.Request{
+			Header: h,
+		},
+	}
+	for _, f := range fs {
+		if err := f.unmarshal(v.FieldByIndex(f.index), p, f.makeResult); err != nil {
+			return errgo.Notef(err, "cannot unmarshal response header into field %s", f.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type itemsPage struct {
+	Items      []string
+	TotalCount int        `httprequest:"X-Total-Count,header" json:"-"`
+	Links      []string   `httprequest:"Link,header" json:"-"`
+	Remaining  *int       `httprequest:"X-Ratelimit-Remaining,header" json:"-"`
+	Reset      *time.Time `httprequest:"X-Ratelimit-Reset,header" json:"-"`
+	Missing    string     `httprequest:"X-Missing,header" json:"-"`
+}
+
+// headerServer returns a server that responds with the given
+// status, headers and body.
+func headerServer(status int, h http.Header, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for k, v := range h {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+}
+
+func TestUnmarshalResponseHeaders(t *testing.T) {
+	c := qt.New(t)
+
+	srv := headerServer(http.StatusOK, http.Header{
+		"Content-Type":          {"application/json"},
+		"X-Total-Count":         {"42"},
+		"Link":                  {`</items?page=2>; rel="next"`, `</items?page=5>; rel="last"`},
+		"X-Ratelimit-Remaining": {"99"},
+		"X-Ratelimit-Reset":     {"2026-01-02T03:04:05Z"},
+	}, `{"Items":["a","b"]}`)
+	defer srv.Close()
+
+	var page itemsPage
+	err := (&httprequest.Client{}).Get(context.Background(), srv.URL, &page)
+	c.Assert(err, qt.IsNil)
+	remaining := 99
+	reset := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.Assert(page, qt.DeepEquals, itemsPage{
+		Items:      []string{"a", "b"},
+		TotalCount: 42,
+		Links:      []string{`</items?page=2>; rel="next"`, `</items?page=5>; rel="last"`},
+		Remaining:  &remaining,
+		Reset:      &reset,
+	})
+}
+
+func TestUnmarshalResponseHeadersWithNoContent(t *testing.T) {
+	c := qt.New(t)
+
+	srv := headerServer(http.StatusNoContent, http.Header{
+		"X-Total-Count": {"7"},
+	}, "")
+	defer srv.Close()
+
+	var page itemsPage
+	err := (&httprequest.Client{}).Get(context.Background(), srv.URL, &page)
+	c.Assert(err, qt.IsNil)
+	c.Assert(page.TotalCount, qt.Equals, 7)
+}
+
+func TestUnmarshalResponseHeadersWithBodyField(t *testing.T) {
+	c := qt.New(t)
+
+	srv := headerServer(http.StatusOK, http.Header{
+		"X-Total-Count": {"3"},
+	}, "some data")
+	defer srv.Close()
+
+	var resp struct {
+		Body       io.ReadCloser `httprequest:",body"`
+		TotalCount int           `httprequest:"X-Total-Count,header"`
+	}
+	err := (&httprequest.Client{}).Get(context.Background(), srv.URL, &resp)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.TotalCount, qt.Equals, 3)
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "some data")
+}
+
+func TestUnmarshalResponseHeadersError(t *testing.T) {
+	c := qt.New(t)
+
+	srv := headerServer(http.StatusOK, http.Header{
+		"Content-Type":  {"application/json"},
+		"X-Total-Count": {"lots"},
+	}, `{}`)
+	defer srv.Close()
+
+	var page itemsPage
+	err := (&httprequest.Client{}).Get(context.Background(), srv.URL, &page)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*: cannot unmarshal response header into field TotalCount: cannot parse "lots" into int: expected integer`)
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseInfo holds details of the response to a call made with
+// Client.CallWithResponse.
+type ResponseInfo struct {
+	// StatusCode holds the HTTP status of the response.
+	StatusCode int
+
+	// Header holds the response headers.
+	Header http.Header
+
+	// Duration holds the time taken by the call, including any
+	// retries and unmarshaling the response.
+	Duration time.Duration
+
+	// Timing holds the timing of the network activity of the
+	// request. It is nil if the Client's Doer does not support
+	// net/http/httptrace.
+	Timing *ConnectionTiming
+}
+
+// CallWithResponse is like Call except that it also returns details
+// of the response, such as its headers, so that callers that need
+// them can still have the response body unmarshaled into resp. The
+// details are returned whenever a response was received, including
+// when the response holds an error, and are nil otherwise.
+//
+// If the response is returned from c.Cache, the details are those of
+// the cached response.
+func (c *Client) CallWithResponse(ctx context.Context, params, resp interface{}, opts ...CallOption) (*ResponseInfo, error) {
+	var info *ResponseInfo
+	opts = append(opts, func(o *callOptions) {
+		o.responseInfo = &info
+	})
+	err := c.Call(ctx, params, resp, opts...)
+	return info, err
+}
+
+// setResponseInfo records details of resp for CallWithResponse,
+// if requested by the options.
+func (o *callOptions) setResponseInfo(resp *http.Response, timing *ConnectionTiming, start time.Time) {
+	if o.responseInfo == nil || resp == nil {
+		return
+	}
+	*o.responseInfo = &ResponseInfo{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Duration:   time.Since(start),
+		Timing:     timing,
+	}
+}
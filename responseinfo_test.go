@@ -0,0 +1,103 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type responseInfoParams struct {
+	httprequest.Route `httprequest:"GET /items/:id"`
+	ID                string `httprequest:"id,path"`
+}
+
+func responseInfoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Total-Count", "42")
+		if req.URL.Path == "/items/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"Message":"not found","Code":"not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`"item"`))
+	}))
+}
+
+func TestCallWithResponse(t *testing.T) {
+	c := qt.New(t)
+	srv := responseInfoServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var resp string
+	info, err := client.CallWithResponse(context.Background(), &responseInfoParams{ID: "x"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "item")
+	c.Assert(info.StatusCode, qt.Equals, http.StatusCreated)
+	c.Assert(info.Header.Get("X-Total-Count"), qt.Equals, "42")
+	c.Assert(info.Duration > 0, qt.IsTrue)
+	c.Assert(info.Timing, qt.Not(qt.IsNil))
+	c.Assert(info.Timing.RemoteAddr, qt.Equals, srv.Listener.Addr().String())
+}
+
+func TestCallWithResponseError(t *testing.T) {
+	c := qt.New(t)
+	srv := responseInfoServer()
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var resp string
+	info, err := client.CallWithResponse(context.Background(), &responseInfoParams{ID: "missing"}, &resp)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/items/missing: not found`)
+	c.Assert(info.StatusCode, qt.Equals, http.StatusNotFound)
+	c.Assert(info.Header.Get("X-Total-Count"), qt.Equals, "42")
+}
+
+func TestCallWithResponseTransportError(t *testing.T) {
+	c := qt.New(t)
+	client := &httprequest.Client{
+		BaseURL: "http://0.1.2.3",
+		Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errgo.New("connection refused")
+		}),
+	}
+	var resp string
+	info, err := client.CallWithResponse(context.Background(), &responseInfoParams{ID: "x"}, &resp)
+	c.Assert(err, qt.ErrorMatches, `Get http://0.1.2.3/items/x: connection refused`)
+	c.Assert(info, qt.IsNil)
+}
+
+func TestCallWithResponseDoesNotSetHookTiming(t *testing.T) {
+	c := qt.New(t)
+	srv := responseInfoServer()
+	defer srv.Close()
+
+	var hookInfo httprequest.ClientResponseInfo
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		OnResponse: func(ctx context.Context, info httprequest.ClientResponseInfo) {
+			hookInfo = info
+		},
+	}
+	var resp string
+	info, err := client.CallWithResponse(context.Background(), &responseInfoParams{ID: "x"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(info.Timing, qt.Not(qt.IsNil))
+	c.Assert(hookInfo.Status, qt.Equals, http.StatusCreated)
+	c.Assert(hookInfo.Timing, qt.IsNil)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type resultHeaderRequest struct {
+	httprequest.Route `httprequest:"GET /thing" cache:"no-cache"`
+	Fail              bool `httprequest:"fail,form"`
+}
+
+var resultHeaderTests = []struct {
+	about        string
+	header       http.Header
+	fail         bool
+	expectStatus int
+	expectBody   interface{}
+	expectHeader http.Header
+}{{
+	about: "headers added to response",
+	header: http.Header{
+		"X-Thing": {"a", "b"},
+		"Link":    {"</other>; rel=next"},
+	},
+	expectStatus: http.StatusOK,
+	expectBody:   "thing",
+	expectHeader: http.Header{
+		"X-Thing":       {"a", "b"},
+		"Link":          {"</other>; rel=next"},
+		"Cache-Control": {"no-cache"},
+	},
+}, {
+	about:        "nil headers",
+	expectStatus: http.StatusOK,
+	expectBody:   "thing",
+	expectHeader: http.Header{
+		"Cache-Control": {"no-cache"},
+	},
+}, {
+	about: "headers ignored on error",
+	header: http.Header{
+		"X-Thing": {"a"},
+	},
+	fail:         true,
+	expectStatus: http.StatusBadRequest,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeBadRequest,
+		Message: "failed",
+	},
+	expectHeader: http.Header{},
+}}
+
+func TestResultHeader(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range resultHeaderTests {
+		c.Run(test.about, func(c *qt.C) {
+			var srv httprequest.Server
+			h := srv.Handle(func(req *resultHeaderRequest) (string, http.Header, error) {
+				if req.Fail {
+					return "", test.header, httprequest.Errorf(httprequest.CodeBadRequest, "failed")
+				}
+				return "thing", test.header, nil
+			})
+			path := "/thing"
+			if test.fail {
+				path += "?fail=true"
+			}
+			rec := httptest.NewRecorder()
+			h.Handle(rec, httptest.NewRequest("GET", path, nil), nil)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+			for k := range test.expectHeader {
+				c.Assert(rec.Header()[k], qt.DeepEquals, test.expectHeader[k], qt.Commentf("header %s", k))
+			}
+			c.Assert(rec.Header().Get("X-Thing") != "", qt.Equals, test.expectHeader.Get("X-Thing") != "")
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// RetryPolicy specifies how a Client retries requests that fail
+// with transient errors.
+type RetryPolicy struct {
+	// MaxAttempts holds the maximum number of times a request is
+	// sent, including the first attempt. If it is less than 2, the
+	// request is not retried.
+	MaxAttempts int
+
+	// Backoff returns how long to wait before the given retry,
+	// counting from 1. If it is nil, DefaultBackoff is used. When the
+	// response to the previous attempt has a Retry-After header, the
+	// time it specifies is used instead.
+	Backoff func(retry int) time.Duration
+
+	// ShouldRetry reports whether req should be sent again after an
+	// attempt that returned the given response or error. If it is
+	// nil, DefaultShouldRetry is used.
+	//
+	// Requests whose body cannot be sent again because req.GetBody
+	// is nil are never retried.
+	ShouldRetry func(req *http.Request, resp *http.Response, err error) bool
+}
+
+// DefaultBackoff returns an exponentially increasing wait that starts
+// at 100ms and is capped at 5s.
+func DefaultBackoff(retry int) time.Duration {
+	const max = 5 * time.Second
+	d := 100 * time.Millisecond
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
+
+// DefaultShouldRetry reports whether req uses an idempotent method or
+// has an Idempotency-Key header, and either failed to obtain a
+// response (other than because its context was done) or received a
+// response with one of the statuses http.StatusTooManyRequests,
+// http.StatusBadGateway, http.StatusServiceUnavailable or
+// http.StatusGatewayTimeout.
+func DefaultShouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if !isIdempotentRequest(req) {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isIdempotentRequest reports whether req may safely be sent more
+// than once.
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// setIdempotencyKey sets the Idempotency-Key header of req to a new
+// random key if req is a POST or PATCH request that may be retried
+// according to policy and does not already have one. The same key is
+// sent with every attempt, so that the server can recognize retries.
+func setIdempotencyKey(req *http.Request, policy *RetryPolicy) error {
+	if policy == nil || policy.MaxAttempts < 2 || (req.Method != "POST" && req.Method != "PATCH") {
+		return nil
+	}
+	if req.Header.Get(IdempotencyKeyHeader) != "" {
+		return nil
+	}
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return errgo.Notef(err, "cannot generate idempotency key")
+	}
+	req.Header.Set(IdempotencyKeyHeader, hex.EncodeToString(buf[:]))
+	return nil
+}
+
+// doWithRetry sends req with doer, retrying it as specified by
+// policy, and returns the final response or error.
+func (c *Client) doWithRetry(ctx context.Context, doer Doer, req *http.Request, policy *RetryPolicy) (*http.Response, error) {
+	if policy == nil || policy.MaxAttempts < 2 || (req.GetBody == nil && req.Body != nil && req.Body != http.NoBody) {
+		return send(ctx, doer, req)
+	}
+	shouldRetry := policy.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultShouldRetry
+	}
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := send(ctx, doer, req)
+		if attempt >= policy.MaxAttempts || !shouldRetry(req.WithContext(ctx), resp, err) {
+			return resp, err
+		}
+		wait := backoff(attempt)
+		if resp != nil {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = d
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			// There's no time to make another attempt,
+			// so return what we have.
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 8*1024))
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, errgo.Mask(err, errgo.Any)
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, errgo.Notef(err, "cannot get request body for retry")
+			}
+			req.Body = body
+		}
+	}
+}
+
+// send sends req once with doer.
+func send(ctx context.Context, doer Doer, req *http.Request) (*http.Response, error) {
+	if ctxDoer, ok := doer.(DoerWithContext); ok {
+		return ctxDoer.DoWithContext(ctx, req)
+	}
+	return doer.Do(req.WithContext(ctx))
+}
+
+// sleep waits for d to elapse or ctx to be done,
+// returning the context's error in the latter case.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which
+// holds either a number of seconds or an HTTP date, returning the
+// time to wait from now.
+func parseRetryAfter(s string, now time.Time) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(s)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
@@ -0,0 +1,325 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var parseRetryAfterTests = []struct {
+	about    string
+	value    string
+	expect   time.Duration
+	expectOK bool
+}{{
+	about: "empty",
+}, {
+	about:    "seconds",
+	value:    "120",
+	expect:   2 * time.Minute,
+	expectOK: true,
+}, {
+	about:    "zero seconds",
+	value:    "0",
+	expectOK: true,
+}, {
+	about: "negative seconds",
+	value: "-1",
+}, {
+	about:    "date",
+	value:    "Fri, 16 Oct 2026 12:00:30 GMT",
+	expect:   30 * time.Second,
+	expectOK: true,
+}, {
+	about:    "past date",
+	value:    "Fri, 16 Oct 2026 11:00:00 GMT",
+	expectOK: true,
+}, {
+	about: "invalid",
+	value: "soon",
+}}
+
+func TestParseRetryAfter(t *testing.T) {
+	c := qt.New(t)
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, test := range parseRetryAfterTests {
+		c.Run(test.about, func(c *qt.C) {
+			d, ok := httprequest.ParseRetryAfter(test.value, now)
+			c.Assert(ok, qt.Equals, test.expectOK)
+			c.Assert(d, qt.Equals, test.expect)
+		})
+	}
+}
+
+// retryServer returns a server that responds to the first len(statuses)
+// requests with the given statuses, setting the Retry-After header to
+// retryAfter, and to later requests with "ok". It records the bodies
+// of the requests in bodies.
+func retryServer(statuses []int, retryAfter string, bodies *[]string) *httptest.Server {
+	n := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := ioutil.ReadAll(req.Body)
+		*bodies = append(*bodies, string(data))
+		if n < len(statuses) {
+			n++
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			httprequest.WriteJSON(w, statuses[n-1], &httprequest.RemoteError{
+				Message: fmt.Sprintf("attempt %d failed", n),
+			})
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, "ok")
+	}))
+}
+
+type retryRequest struct {
+	httprequest.Route `httprequest:"PUT /item"`
+	Body              string `httprequest:",body"`
+}
+
+func TestClientRetry(t *testing.T) {
+	c := qt.New(t)
+
+	var bodies []string
+	srv := retryServer([]int{http.StatusServiceUnavailable, http.StatusBadGateway}, "", &bodies)
+	defer srv.Close()
+
+	var backoffs []int
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 3,
+			Backoff: func(retry int) time.Duration {
+				backoffs = append(backoffs, retry)
+				return time.Millisecond
+			},
+		},
+	}
+	var resp string
+	err := client.Call(context.Background(), &retryRequest{Body: "hello"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+	c.Assert(bodies, qt.DeepEquals, []string{`"hello"`, `"hello"`, `"hello"`})
+	c.Assert(backoffs, qt.DeepEquals, []int{1, 2})
+}
+
+func TestClientRetryGivesUp(t *testing.T) {
+	c := qt.New(t)
+
+	var bodies []string
+	srv := retryServer([]int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, "", &bodies)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	err := client.Get(context.Background(), "/", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/: attempt 2 failed`)
+	c.Assert(bodies, qt.HasLen, 2)
+}
+
+var defaultShouldRetryTests = []struct {
+	about  string
+	method string
+	header http.Header
+	status int
+	err    error
+	expect bool
+}{{
+	about:  "idempotent method with retryable status",
+	method: "GET",
+	status: http.StatusServiceUnavailable,
+	expect: true,
+}, {
+	about:  "idempotent method with other status",
+	method: "PUT",
+	status: http.StatusInternalServerError,
+}, {
+	about:  "idempotent method with error",
+	method: "DELETE",
+	err:    errgo.New("connection reset"),
+	expect: true,
+}, {
+	about:  "non-idempotent method",
+	method: "POST",
+	status: http.StatusServiceUnavailable,
+}, {
+	about:  "non-idempotent method with idempotency key",
+	method: "POST",
+	header: http.Header{"Idempotency-Key": {"k"}},
+	status: http.StatusTooManyRequests,
+	expect: true,
+}}
+
+func TestDefaultShouldRetry(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range defaultShouldRetryTests {
+		c.Run(test.about, func(c *qt.C) {
+			req, err := http.NewRequest(test.method, "http://example.com", nil)
+			c.Assert(err, qt.IsNil)
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			var resp *http.Response
+			if test.err == nil {
+				resp = &http.Response{StatusCode: test.status}
+			}
+			c.Assert(httprequest.DefaultShouldRetry(req, resp, test.err), qt.Equals, test.expect)
+		})
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	c := qt.New(t)
+
+	var bodies []string
+	srv := retryServer([]int{http.StatusTooManyRequests}, "1", &bodies)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	start := time.Now()
+	var resp string
+	err := client.Get(context.Background(), "/", &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+	c.Assert(time.Since(start) >= time.Second, qt.IsTrue)
+	c.Assert(bodies, qt.HasLen, 2)
+}
+
+func TestClientRetryAfterBeyondDeadline(t *testing.T) {
+	c := qt.New(t)
+
+	var bodies []string
+	srv := retryServer([]int{http.StatusTooManyRequests}, "3600", &bodies)
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	err := client.Get(ctx, "/", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/: attempt 1 failed`)
+	c.Assert(time.Since(start) < 5*time.Second, qt.IsTrue)
+	c.Assert(bodies, qt.HasLen, 1)
+}
+
+// idempotencyServer returns a server that responds to the first
+// request with http.StatusServiceUnavailable and to later requests
+// with "ok", recording the Idempotency-Key header of each request
+// in keys.
+func idempotencyServer(keys *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		*keys = append(*keys, req.Header.Get(httprequest.IdempotencyKeyHeader))
+		if len(*keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, "ok")
+	}))
+}
+
+type createRequest struct {
+	httprequest.Route `httprequest:"POST /items"`
+	Body              string `httprequest:",body"`
+}
+
+func TestClientRetryIdempotencyKey(t *testing.T) {
+	c := qt.New(t)
+
+	var keys []string
+	srv := idempotencyServer(&keys)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	var resp string
+	err := client.Call(context.Background(), &createRequest{Body: "x"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "ok")
+	c.Assert(keys, qt.HasLen, 2)
+	c.Assert(keys[0], qt.Matches, "[0-9a-f]{32}")
+	c.Assert(keys[1], qt.Equals, keys[0])
+
+	// Each call has a different key.
+	firstKey := keys[0]
+	keys = nil
+	err = client.Call(context.Background(), &createRequest{Body: "x"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(keys, qt.HasLen, 2)
+	c.Assert(keys[0], qt.Not(qt.Equals), firstKey)
+}
+
+func TestClientRetryWithIdempotencyKey(t *testing.T) {
+	c := qt.New(t)
+
+	var keys []string
+	srv := idempotencyServer(&keys)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	err := client.Call(context.Background(), &createRequest{Body: "x"}, nil, httprequest.WithIdempotencyKey("key1"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(keys, qt.DeepEquals, []string{"key1", "key1"})
+}
+
+func TestClientNoIdempotencyKeyWithoutRetry(t *testing.T) {
+	c := qt.New(t)
+
+	var keys []string
+	srv := idempotencyServer(&keys)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	err := client.Call(context.Background(), &createRequest{Body: "x"}, nil)
+	c.Assert(err, qt.ErrorMatches, `Post http://.*/items: .*503 Service Unavailable.*`)
+	c.Assert(keys, qt.DeepEquals, []string{""})
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// RetryAfterer may be implemented by errors that tell the client how
+// long to wait before retrying a request. When Server.WriteError is
+// called with such an error (or an error wrapping one), a positive
+// duration is sent to the client in the Retry-After header.
+type RetryAfterer interface {
+	RetryAfterDuration() time.Duration
+}
+
+// WithRetryAfter returns an error that wraps err and implements
+// RetryAfterer by returning d. The cause of the returned error is
+// the cause of err, so it maps to the same response status.
+func WithRetryAfter(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{
+		err: err,
+		d:   d,
+	}
+}
+
+type retryAfterError struct {
+	err error
+	d   time.Duration
+}
+
+// Error implements error.Error.
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+// Cause implements errgo.Causer.
+func (e *retryAfterError) Cause() error {
+	return errgo.Cause(e.err)
+}
+
+// Underlying returns the wrapped error.
+func (e *retryAfterError) Underlying() error {
+	return e.err
+}
+
+// RetryAfterDuration implements RetryAfterer.
+func (e *retryAfterError) RetryAfterDuration() time.Duration {
+	return e.d
+}
+
+// retryAfter returns the retry duration of the first RetryAfterer
+// found in the chain of errors underlying err, or its cause.
+func retryAfter(err error) (time.Duration, bool) {
+	for e := err; e != nil; {
+		if r, ok := e.(RetryAfterer); ok {
+			return r.RetryAfterDuration(), true
+		}
+		u, ok := e.(interface {
+			Underlying() error
+		})
+		if !ok {
+			break
+		}
+		e = u.Underlying()
+	}
+	if r, ok := errgo.Cause(err).(RetryAfterer); ok {
+		return r.RetryAfterDuration(), true
+	}
+	return 0, false
+}
+
+// setRetryAfter sets the Retry-After header from err
+// if it has a positive retry duration.
+func setRetryAfter(h http.Header, err error) {
+	if d, ok := retryAfter(err); ok && d > 0 {
+		h.Set("Retry-After", strconv.Itoa(retryAfterSeconds(d)))
+	}
+}
+
+// retryAfterSeconds returns d as a whole number of
+// seconds, rounded up.
+func retryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
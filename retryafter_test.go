@@ -0,0 +1,90 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+var retryAfterTests = []struct {
+	about            string
+	err              error
+	expectStatus     int
+	expectBody       *httprequest.RemoteError
+	expectRetryAfter string
+}{{
+	about: "too many requests error",
+	err: &httprequest.TooManyRequestsError{
+		RetryAfter: 3 * time.Second,
+	},
+	expectStatus: http.StatusTooManyRequests,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTooManyRequests,
+		Message: "too many requests",
+	},
+	expectRetryAfter: "3",
+}, {
+	about:        "wrapped error keeps its status",
+	err:          httprequest.WithRetryAfter(httprequest.Errorf(httprequest.CodeTimeout, "busy"), 100*time.Millisecond),
+	expectStatus: http.StatusServiceUnavailable,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTimeout,
+		Message: "busy",
+	},
+	expectRetryAfter: "1",
+}, {
+	about:        "wrapped error annotated further",
+	err:          errgo.NoteMask(httprequest.WithRetryAfter(httprequest.Errorf(httprequest.CodeTimeout, "busy"), time.Minute), "cannot serve", errgo.Any),
+	expectStatus: http.StatusServiceUnavailable,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTimeout,
+		Message: "cannot serve: busy",
+	},
+	expectRetryAfter: "60",
+}, {
+	about:        "zero duration",
+	err:          httprequest.WithRetryAfter(httprequest.Errorf(httprequest.CodeTimeout, "busy"), 0),
+	expectStatus: http.StatusServiceUnavailable,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeTimeout,
+		Message: "busy",
+	},
+}, {
+	about:        "no retry information",
+	err:          httprequest.Errorf(httprequest.CodeNotFound, "gone"),
+	expectStatus: http.StatusNotFound,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeNotFound,
+		Message: "gone",
+	},
+}}
+
+func TestRetryAfter(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range retryAfterTests {
+		c.Run(test.about, func(c *qt.C) {
+			var srv httprequest.Server
+			rec := httptest.NewRecorder()
+			srv.WriteError(context.Background(), rec, test.err)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+			c.Assert(rec.Header().Get("Retry-After"), qt.Equals, test.expectRetryAfter)
+		})
+	}
+}
+
+func TestWithRetryAfterNil(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(httprequest.WithRetryAfter(nil, time.Second), qt.IsNil)
+}
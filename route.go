@@ -0,0 +1,115 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"sync"
+)
+
+// RouteInfo describes the route serving a request. Handlers created
+// by Server.Handle, Server.Handlers and related methods store it in
+// the request context; see RouteFromContext and RouteRecorder.
+type RouteInfo struct {
+	// Method holds the HTTP method of the route.
+	Method string
+
+	// PathPattern holds the path pattern of the route,
+	// such as "/users/:id".
+	PathPattern string
+
+	// Name holds the name of the route from its name tag
+	// (see RouteMetadata.Name), if any.
+	Name string
+
+	// HandlerType holds the name of the type that defines the
+	// method serving the route, such as "*api.Handlers". It is
+	// empty for routes created by Server.Handle.
+	HandlerType string
+
+	// HandlerMethod holds the name of the method serving the
+	// route. It is empty for routes created by Server.Handle.
+	HandlerMethod string
+}
+
+type routeInfoKey struct{}
+
+func contextWithRoute(ctx context.Context, route *RouteInfo) context.Context {
+	if r, ok := ctx.Value(routeRecorderKey{}).(*RouteRecorder); ok {
+		r.record(route)
+	}
+	return context.WithValue(ctx, routeInfoKey{}, route)
+}
+
+// routeFromContext returns the route stored in ctx,
+// or a zero RouteInfo if there is none.
+func routeFromContext(ctx context.Context) *RouteInfo {
+	route, _ := ctx.Value(routeInfoKey{}).(*RouteInfo)
+	if route == nil {
+		return &RouteInfo{}
+	}
+	return route
+}
+
+// RouteFromContext returns the route serving the request with the
+// given context, and reports whether there is one. It can be used by
+// code called from a handler, such as Server.Authorize or a handler
+// method itself.
+//
+// Middleware that wraps the router sees the request context from
+// before the route is chosen, so it should use a RouteRecorder
+// instead.
+func RouteFromContext(ctx context.Context) (RouteInfo, bool) {
+	route, _ := ctx.Value(routeInfoKey{}).(*RouteInfo)
+	if route == nil {
+		return RouteInfo{}, false
+	}
+	return *route, true
+}
+
+// RouteRecorder records the route that serves a request, so that
+// middleware wrapping the router, such as logging or metrics, can
+// label requests by route pattern rather than by raw path. For
+// example:
+//
+//	func instrument(h http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+//			ctx, recorder := httprequest.ContextWithRouteRecorder(req.Context())
+//			h.ServeHTTP(w, req.WithContext(ctx))
+//			route, _ := recorder.Route()
+//			requests.WithLabelValues(route.PathPattern).Inc()
+//		})
+//	}
+type RouteRecorder struct {
+	mu    sync.Mutex
+	route *RouteInfo
+}
+
+type routeRecorderKey struct{}
+
+// ContextWithRouteRecorder returns a context holding a new
+// RouteRecorder, which records the route of any request served with
+// the context by a handler created by the Server.
+func ContextWithRouteRecorder(ctx context.Context) (context.Context, *RouteRecorder) {
+	r := new(RouteRecorder)
+	return context.WithValue(ctx, routeRecorderKey{}, r), r
+}
+
+// Route returns the recorded route and reports whether a route has
+// been recorded. If the request was passed between several
+// handlers, the last route is returned.
+func (r *RouteRecorder) Route() (RouteInfo, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.route == nil {
+		return RouteInfo{}, false
+	}
+	return *r.route, true
+}
+
+func (r *RouteRecorder) record(route *RouteInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.route = route
+}
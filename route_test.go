@@ -0,0 +1,92 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type routeHandlers struct{}
+
+func (routeHandlers) GetUser(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /users/:id" name:"GetUser"`
+}) (httprequest.RouteInfo, error) {
+	route, ok := httprequest.RouteFromContext(p.Context)
+	if !ok {
+		return httprequest.RouteInfo{}, httprequest.Errorf("", "no route in context")
+	}
+	return route, nil
+}
+
+func TestRouteFromContext(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (*routeHandlers, context.Context, error) {
+		return &routeHandlers{}, p.Context, nil
+	}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/users/42", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, httprequest.RouteInfo{
+		Method:        "GET",
+		PathPattern:   "/users/:id",
+		Name:          "GetUser",
+		HandlerType:   "*httprequest_test.routeHandlers",
+		HandlerMethod: "GetUser",
+	})
+
+	_, ok := httprequest.RouteFromContext(context.Background())
+	c.Assert(ok, qt.IsFalse)
+}
+
+func TestRouteRecorder(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	hs := srv.Handlers(func(p httprequest.Params) (*routeHandlers, context.Context, error) {
+		return &routeHandlers{}, p.Context, nil
+	})
+	hs = append(hs, srv.Handle(func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"DELETE /items/:id"`
+	}) error {
+		return nil
+	}))
+	router := srv.NewRouter(hs)
+
+	var recorded []httprequest.RouteInfo
+	var found []bool
+	h := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, recorder := httprequest.ContextWithRouteRecorder(req.Context())
+		router.ServeHTTP(w, req.WithContext(ctx))
+		route, ok := recorder.Route()
+		recorded = append(recorded, route)
+		found = append(found, ok)
+	})
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/users/42", nil),
+		httptest.NewRequest("DELETE", "/items/1", nil),
+		httptest.NewRequest("GET", "/nothing", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	c.Assert(recorded, qt.DeepEquals, []httprequest.RouteInfo{{
+		Method:        "GET",
+		PathPattern:   "/users/:id",
+		Name:          "GetUser",
+		HandlerType:   "*httprequest_test.routeHandlers",
+		HandlerMethod: "GetUser",
+	}, {
+		Method:      "DELETE",
+		PathPattern: "/items/:id",
+	}, {}})
+	c.Assert(found, qt.DeepEquals, []bool{true, true, false})
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// RouteState is returned by Server.RouteFilter to determine
+// how a route is served.
+type RouteState int
+
+const (
+	// RouteEnabled specifies that the route is served
+	// by its method as usual.
+	RouteEnabled RouteState = iota
+
+	// RouteOmitted specifies that no handler is created for the
+	// route, so that another handler may be registered with the
+	// same path.
+	RouteOmitted
+
+	// RouteNotFound specifies that requests to the route fail with
+	// an error with code CodeNotFound, without calling the method.
+	RouteNotFound
+
+	// RouteNotImplemented specifies that requests to the route fail
+	// with an error with code CodeNotImplemented, without calling
+	// the method.
+	RouteNotImplemented
+)
+
+// routeState returns the state of the route of hf
+// according to srv.RouteFilter.
+func (srv *Server) routeState(hf handlerFunc) RouteState {
+	if srv.RouteFilter == nil {
+		return RouteEnabled
+	}
+	return srv.RouteFilter(hf.method, hf.pathPattern, hf.metadata)
+}
+
+// unavailableRoute returns a handler that responds to all requests
+// to the route of hf with the error appropriate to the given state.
+func (srv *Server) unavailableRoute(hf handlerFunc, state RouteState) httprouter.Handle {
+	var err error
+	if state == RouteNotImplemented {
+		err = Errorf(CodeNotImplemented, "%s %s not implemented", hf.method, hf.pathPattern)
+	} else {
+		err = Errorf(CodeNotFound, "not found")
+	}
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		srv.WriteError(req.Context(), w, err)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type featureHandlers struct{}
+
+func (featureHandlers) Stable(*struct {
+	httprequest.Route `httprequest:"GET /stable"`
+}) (string, error) {
+	return "stable", nil
+}
+
+func (featureHandlers) Beta(*struct {
+	httprequest.Route `httprequest:"GET /beta" tags:"beta"`
+}) (string, error) {
+	return "beta", nil
+}
+
+func (featureHandlers) Search(*struct {
+	httprequest.Route `httprequest:"GET /search" name:"search"`
+}) (string, error) {
+	return "old search", nil
+}
+
+type newSearchHandlers struct{}
+
+func (newSearchHandlers) Search(*struct {
+	httprequest.Route `httprequest:"GET /search" name:"search-v2"`
+}) (string, error) {
+	return "new search", nil
+}
+
+func hasTag(m httprequest.RouteMetadata, tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+var routeFilterTests = []struct {
+	about        string
+	filter       func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState
+	path         string
+	expectStatus int
+	expectBody   interface{}
+}{{
+	about:        "no filter",
+	path:         "/beta",
+	expectStatus: http.StatusOK,
+	expectBody:   "beta",
+}, {
+	about: "enabled",
+	filter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		return httprequest.RouteEnabled
+	},
+	path:         "/beta",
+	expectStatus: http.StatusOK,
+	expectBody:   "beta",
+}, {
+	about: "not found",
+	filter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		if hasTag(m, "beta") {
+			return httprequest.RouteNotFound
+		}
+		return httprequest.RouteEnabled
+	},
+	path:         "/beta",
+	expectStatus: http.StatusNotFound,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeNotFound,
+		Message: "not found",
+	},
+}, {
+	about: "not implemented",
+	filter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		if hasTag(m, "beta") {
+			return httprequest.RouteNotImplemented
+		}
+		return httprequest.RouteEnabled
+	},
+	path:         "/beta",
+	expectStatus: http.StatusNotImplemented,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeNotImplemented,
+		Message: "GET /beta not implemented",
+	},
+}, {
+	about: "other routes unaffected",
+	filter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		if hasTag(m, "beta") {
+			return httprequest.RouteNotImplemented
+		}
+		return httprequest.RouteEnabled
+	},
+	path:         "/stable",
+	expectStatus: http.StatusOK,
+	expectBody:   "stable",
+}, {
+	about: "replaced by another route",
+	filter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		if m.Name == "search" {
+			return httprequest.RouteOmitted
+		}
+		return httprequest.RouteEnabled
+	},
+	path:         "/search",
+	expectStatus: http.StatusOK,
+	expectBody:   "new search",
+}}
+
+func TestRouteFilter(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range routeFilterTests {
+		c.Run(test.about, func(c *qt.C) {
+			srv := httprequest.Server{
+				RouteFilter: test.filter,
+			}
+			fs := []interface{}{
+				func(p httprequest.Params) (featureHandlers, context.Context, error) {
+					return featureHandlers{}, p.Context, nil
+				},
+			}
+			if test.about == "replaced by another route" {
+				fs = append(fs, func(p httprequest.Params) (newSearchHandlers, context.Context, error) {
+					return newSearchHandlers{}, p.Context, nil
+				})
+			}
+			rec := httptest.NewRecorder()
+			srv.NewRouter(srv.HandlersFromAll(fs...)).ServeHTTP(rec, httptest.NewRequest("GET", test.path, nil))
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
+
+func TestRouteFilterOmitsHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	var calls []string
+	srv := httprequest.Server{
+		RouteFilter: func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+			calls = append(calls, method+" "+path)
+			if path == "/api/stable" {
+				return httprequest.RouteEnabled
+			}
+			return httprequest.RouteOmitted
+		},
+	}
+	hs := srv.HandlersWithPrefix("/api", func(p httprequest.Params) (featureHandlers, context.Context, error) {
+		return featureHandlers{}, p.Context, nil
+	})
+	c.Assert(calls, qt.DeepEquals, []string{"GET /api/beta", "GET /api/search", "GET /api/stable"})
+	c.Assert(hs, qt.HasLen, 1)
+	c.Assert(hs[0].Path, qt.Equals, "/api/stable")
+
+	srv.RouteFilter = func(method, path string, m httprequest.RouteMetadata) httprequest.RouteState {
+		return httprequest.RouteOmitted
+	}
+	// Omitting every route is not an error.
+	hs = srv.Handlers(func(p httprequest.Params) (featureHandlers, context.Context, error) {
+		return featureHandlers{}, p.Context, nil
+	})
+	c.Assert(hs, qt.HasLen, 0)
+}
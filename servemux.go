@@ -0,0 +1,90 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// AddServeMuxHandlers adds all the handlers in the given slice to mux,
+// which allows handlers to be served without using httprouter.
+//
+// The httprouter path pattern of each handler is translated to the
+// pattern syntax used by http.ServeMux, so a ":name" element becomes
+// "{name}" and a trailing "*name" element becomes "{name...}". When a
+// request is served, the path values extracted by mux are passed to the
+// handler as Params.PathVar. As with httprouter, the value of a
+// trailing wildcard element always starts with a slash.
+//
+// AddServeMuxHandlers will panic if mux reports that any of the
+// patterns conflict.
+func AddServeMuxHandlers(mux *http.ServeMux, hs []Handler) {
+	for _, h := range hs {
+		pattern, vars := serveMuxPattern(h.Method, h.Path)
+		mux.Handle(pattern, serveMuxHandler(h.Handle, vars))
+	}
+}
+
+// pathVar describes a path variable in a route pattern.
+type pathVar struct {
+	name     string
+	wildcard bool
+}
+
+// serveMuxPattern returns the http.ServeMux pattern equivalent to
+// the given method and httprouter path pattern, along with the path
+// variables that it contains.
+func serveMuxPattern(method, path string) (string, []pathVar) {
+	var vars []pathVar
+	elems := strings.Split(path, "/")
+	for i, elem := range elems {
+		if len(elem) < 2 {
+			continue
+		}
+		switch elem[0] {
+		case ':':
+			vars = append(vars, pathVar{name: elem[1:]})
+			elems[i] = "{" + elem[1:] + "}"
+		case '*':
+			vars = append(vars, pathVar{name: elem[1:], wildcard: true})
+			elems[i] = "{" + elem[1:] + "...}"
+		}
+	}
+	if strings.HasSuffix(path, "/") {
+		// A trailing slash in a ServeMux pattern matches all
+		// paths with that prefix, which isn't what httprouter
+		// does, so match the path exactly.
+		elems[len(elems)-1] = "{$}"
+	}
+	pattern := strings.Join(elems, "/")
+	if method != "" {
+		pattern = method + " " + pattern
+	}
+	return pattern, vars
+}
+
+// serveMuxHandler returns an http.Handler that calls h with the
+// given path variables taken from the request.
+func serveMuxHandler(h httprouter.Handle, vars []pathVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var p httprouter.Params
+		if len(vars) > 0 {
+			p = make(httprouter.Params, len(vars))
+			for i, v := range vars {
+				val := req.PathValue(v.name)
+				if v.wildcard {
+					val = "/" + val
+				}
+				p[i] = httprouter.Param{
+					Key:   v.name,
+					Value: val,
+				}
+			}
+		}
+		h(w, req, p)
+	})
+}
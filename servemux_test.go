@@ -0,0 +1,100 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type serveMuxHandlers struct{}
+
+func (serveMuxHandlers) Item(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /items/:id/parts/:part"`
+	ID                int    `httprequest:"id,path"`
+	Part              string `httprequest:"part,path"`
+}) (interface{}, error) {
+	return map[string]interface{}{
+		"ID":          arg.ID,
+		"Part":        arg.Part,
+		"PathPattern": p.PathPattern,
+	}, nil
+}
+
+func (serveMuxHandlers) File(arg *struct {
+	httprequest.Route `httprequest:"GET /files/*path"`
+	Path              string `httprequest:"path,path"`
+}) (string, error) {
+	return arg.Path, nil
+}
+
+func (serveMuxHandlers) Dir(arg *struct {
+	httprequest.Route `httprequest:"PUT /dir/"`
+}) (string, error) {
+	return "dir", nil
+}
+
+var serveMuxTests = []struct {
+	about      string
+	method     string
+	url        string
+	expectBody interface{}
+}{{
+	about: "path variables",
+	url:   "/items/99/parts/wheel",
+	expectBody: map[string]interface{}{
+		"ID":          99,
+		"Part":        "wheel",
+		"PathPattern": "/items/:id/parts/:part",
+	},
+}, {
+	about:      "wildcard path variable",
+	url:        "/files/a/b/c",
+	expectBody: "/a/b/c",
+}, {
+	about:      "trailing slash",
+	method:     "PUT",
+	url:        "/dir/",
+	expectBody: "dir",
+}}
+
+func TestAddServeMuxHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	mux := http.NewServeMux()
+	httprequest.AddServeMuxHandlers(mux, testServer.Handlers(func(p httprequest.Params) (serveMuxHandlers, context.Context, error) {
+		return serveMuxHandlers{}, p.Context, nil
+	}))
+	for _, test := range serveMuxTests {
+		c.Run(test.about, func(c *qt.C) {
+			qthttptest.AssertJSONCall(c, qthttptest.JSONCallParams{
+				Method:     test.method,
+				URL:        test.url,
+				Handler:    mux,
+				ExpectBody: test.expectBody,
+			})
+		})
+	}
+}
+
+func TestAddServeMuxHandlersTrailingSlashMatchesExactly(t *testing.T) {
+	c := qt.New(t)
+
+	mux := http.NewServeMux()
+	httprequest.AddServeMuxHandlers(mux, testServer.Handlers(func(p httprequest.Params) (serveMuxHandlers, context.Context, error) {
+		return serveMuxHandlers{}, p.Context, nil
+	}))
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequest("PUT", "/dir/x", nil)
+	c.Assert(err, qt.IsNil)
+	mux.ServeHTTP(rec, req)
+	c.Assert(rec.Code, qt.Equals, http.StatusNotFound)
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// CanonicalRequest returns a canonical representation of req, which
+// has the given body, suitable for signing by a Client.Signer. It
+// holds the following lines, separated by newlines:
+//
+//   - the request method;
+//   - the escaped URL path;
+//   - the query parameters, sorted by name and then by value;
+//   - for each of the given headers, in order, its lower-cased name
+//     and its values separated by commas, in the form name:values;
+//   - the hex-encoded SHA-256 hash of the body.
+func CanonicalRequest(req *http.Request, body []byte, headers ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(req.Method)
+	buf.WriteByte('\n')
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(path)
+	buf.WriteByte('\n')
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for j, v := range values {
+			if i > 0 || j > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(queryEscape(name))
+			buf.WriteByte('=')
+			buf.WriteString(queryEscape(v))
+		}
+	}
+	buf.WriteByte('\n')
+	for _, h := range headers {
+		buf.WriteString(strings.ToLower(h))
+		buf.WriteByte(':')
+		if strings.EqualFold(h, "Host") {
+			buf.WriteString(requestHost(req))
+		} else {
+			buf.WriteString(strings.Join(req.Header.Values(h), ","))
+		}
+		buf.WriteByte('\n')
+	}
+	sum := sha256.Sum256(body)
+	buf.WriteString(hex.EncodeToString(sum[:]))
+	return buf.Bytes()
+}
+
+// queryEscape escapes s for use in a canonical query string,
+// encoding spaces as %20 rather than +.
+func queryEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// requestHost returns the host that req is sent to.
+func requestHost(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+// signingDoer is the Doer used by a Client with a Signer. It signs
+// each request before sending it.
+type signingDoer struct {
+	sign func(req *http.Request, body []byte) error
+	doer Doer
+}
+
+// Do implements Doer.Do.
+func (d signingDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.DoWithContext(req.Context(), req)
+}
+
+// DoWithContext implements DoerWithContext.DoWithContext. The body
+// of the request is read into memory to be passed to the signing
+// function unless its length is unknown and it cannot be obtained
+// again with req.GetBody, in which case the function is passed a nil
+// body and the request body is streamed unchanged.
+func (d signingDoer) DoWithContext(ctx context.Context, req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.GetBody != nil || !unknownLength(req) {
+		var err error
+		body, err = replayableBody(req)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+	}
+	if err := d.sign(req, body); err != nil {
+		return nil, errgo.NoteMask(err, "cannot sign request", errgo.Any)
+	}
+	return send(ctx, d.doer, req)
+}
+
+// replayableBody returns the body of req without consuming it, making
+// it possible to send req with the same body again if it is not
+// already.
+func replayableBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return nil, errgo.Notef(err, "cannot get request body")
+		}
+		defer r.Close()
+		body, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, errgo.Notef(err, "cannot read request body")
+		}
+		return body, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot read request body")
+	}
+	req.Body = BytesReaderCloser{bytes.NewReader(body)}
+	req.GetBody = func() (io.ReadCloser, error) {
+		return BytesReaderCloser{bytes.NewReader(body)}, nil
+	}
+	return body, nil
+}
+
+// unknownLength reports whether req has a body whose length
+// is not known before it is sent.
+func unknownLength(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength <= 0
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	c := qt.New(t)
+
+	req, err := http.NewRequest("POST", "http://example.com/a%20b/c?z=1&a=2&a=1&q=x+y", nil)
+	c.Assert(err, qt.IsNil)
+	req.Header.Add("X-Date", "today")
+	req.Header.Add("X-Multi", "1")
+	req.Header.Add("X-Multi", "2")
+	got := httprequest.CanonicalRequest(req, []byte("body"), "Host", "X-Date", "X-Multi", "X-Missing")
+	sum := sha256.Sum256([]byte("body"))
+	c.Assert(string(got), qt.Equals, `POST
+/a%20b/c
+a=1&a=2&q=x%20y&z=1
+host:example.com
+x-date:today
+x-multi:1,2
+x-missing:
+`+hex.EncodeToString(sum[:]))
+}
+
+var signingKey = []byte("secret")
+
+// hmacSign signs requests with signingKey, setting the X-Signature
+// header. It counts the number of times it is called in n.
+func hmacSign(n *int) func(req *http.Request, body []byte) error {
+	return func(req *http.Request, body []byte) error {
+		*n++
+		req.Header.Set("X-Nonce", fmt.Sprint(*n))
+		mac := hmac.New(sha256.New, signingKey)
+		mac.Write(httprequest.CanonicalRequest(req, body, "Host", "X-Nonce"))
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+		return nil
+	}
+}
+
+// signedServer returns a server that verifies the signatures made by
+// hmacSign, responding with the request body and nonce if it is
+// valid. It fails the first request with the given nonce (if any) with
+// http.StatusServiceUnavailable.
+func signedServer(failNonce string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mac := hmac.New(sha256.New, signingKey)
+		mac.Write(httprequest.CanonicalRequest(req, body, "Host", "X-Nonce"))
+		if req.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			httprequest.WriteJSON(w, http.StatusUnauthorized, &httprequest.RemoteError{
+				Code:    httprequest.CodeUnauthorized,
+				Message: "bad signature",
+			})
+			return
+		}
+		nonce := req.Header.Get("X-Nonce")
+		if nonce == failNonce {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		httprequest.WriteJSON(w, http.StatusOK, []string{string(body), nonce})
+	}))
+}
+
+type signedRequest struct {
+	httprequest.Route `httprequest:"PUT /items/:id"`
+	ID                string            `httprequest:"id,path"`
+	Filter            string            `httprequest:"filter,form"`
+	Body              map[string]string `httprequest:",body"`
+}
+
+func TestSigner(t *testing.T) {
+	c := qt.New(t)
+
+	srv := signedServer("")
+	defer srv.Close()
+	var n int
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Signer:  hmacSign(&n),
+	}
+	var resp []string
+	err := client.Call(context.Background(), &signedRequest{
+		ID:     "a b",
+		Filter: "x",
+		Body:   map[string]string{"k": "v"},
+	}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, []string{`{"k":"v"}`, "1"})
+
+	// Without the signature, the request is rejected.
+	client.Signer = nil
+	err = client.Call(context.Background(), &signedRequest{ID: "a"}, &resp)
+	c.Assert(err, qt.ErrorMatches, `Put http://.*/items/a\?filter=: bad signature`)
+}
+
+func TestSignerRetry(t *testing.T) {
+	c := qt.New(t)
+
+	srv := signedServer("1")
+	defer srv.Close()
+	var n int
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Signer:  hmacSign(&n),
+		Retry: &httprequest.RetryPolicy{
+			MaxAttempts: 2,
+			Backoff: func(int) time.Duration {
+				return 0
+			},
+		},
+	}
+	var resp []string
+	err := client.Call(context.Background(), &signedRequest{
+		ID:   "a",
+		Body: map[string]string{"k": "v"},
+	}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, []string{`{"k":"v"}`, "2"})
+	c.Assert(n, qt.Equals, 2)
+}
+
+func TestSignerWithResolver(t *testing.T) {
+	c := qt.New(t)
+
+	srv := signedServer("")
+	defer srv.Close()
+	var n int
+	client := &httprequest.Client{
+		Resolver: httprequest.StaticResolver{srv.URL},
+		Signer:   hmacSign(&n),
+	}
+	var resp []string
+	err := client.Call(context.Background(), &signedRequest{ID: "a"}, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, []string{`null`, "1"})
+}
+
+func TestSignerUnreplayableBody(t *testing.T) {
+	c := qt.New(t)
+
+	srv := signedServer("")
+	defer srv.Close()
+	var n int
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Signer:  hmacSign(&n),
+	}
+	req, err := http.NewRequest("POST", "/items", ioutil.NopCloser(strings.NewReader("data")))
+	c.Assert(err, qt.IsNil)
+	req.ContentLength = 4
+	var resp []string
+	err = client.Do(context.Background(), req, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.DeepEquals, []string{"data", "1"})
+}
+
+func TestSignerUnknownLengthBody(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		httprequest.WriteJSON(w, http.StatusOK, string(body))
+	}))
+	defer srv.Close()
+	var signed [][]byte
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Signer: func(req *http.Request, body []byte) error {
+			signed = append(signed, body)
+			return nil
+		},
+	}
+	// The body is streamed rather than being read
+	// into memory, so it is not signed.
+	req, err := http.NewRequest("POST", "/items", ioutil.NopCloser(strings.NewReader("data")))
+	c.Assert(err, qt.IsNil)
+	var resp string
+	err = client.Do(context.Background(), req, &resp)
+	c.Assert(err, qt.IsNil)
+	c.Assert(resp, qt.Equals, "data")
+	c.Assert(signed, qt.DeepEquals, [][]byte{nil})
+}
+
+var errNoKey = errgo.New("no signing key")
+
+func TestSignerError(t *testing.T) {
+	c := qt.New(t)
+
+	client := &httprequest.Client{
+		BaseURL: "http://0.1.2.3",
+		Signer: func(req *http.Request, body []byte) error {
+			return errNoKey
+		},
+	}
+	err := client.Do(context.Background(), mustNewRequest("/items", "POST", bytes.NewReader(nil)), nil)
+	c.Assert(err, qt.ErrorMatches, `Post http://0.1.2.3/items: cannot sign request: no signing key`)
+	c.Assert(errgo.Cause(err), qt.Equals, errNoKey)
+}
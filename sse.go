@@ -0,0 +1,347 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// DefaultSSEHeartbeatInterval holds the interval between
+// heartbeats sent on server-sent event streams when
+// Server.SSEHeartbeatInterval is zero.
+const DefaultSSEHeartbeatInterval = 15 * time.Second
+
+// DefaultSSEReconnectDelay holds the time an SSEStream waits before
+// reconnecting when the server has not specified one.
+const DefaultSSEReconnectDelay = 3 * time.Second
+
+// SSEEvent represents a server-sent event. A handler that returns a
+// result of type <-chan SSEEvent will have all the events received on
+// the channel sent to the client as a text/event-stream response, as
+// specified by https://html.spec.whatwg.org/multipage/server-sent-events.html.
+//
+// The result type must be exactly <-chan SSEEvent; a handler
+// declared to return chan SSEEvent is rejected by Server.Handle.
+//
+// The stream ends when the channel is closed or the request context
+// is done; the handler should stop sending events when Params.Context
+// is done.
+type SSEEvent struct {
+	// ID holds the event ID. If it is non-empty, the client will send it
+	// in the Last-Event-ID header if it reconnects.
+	ID string
+
+	// Event holds the event type. If it is empty, the
+	// client treats the event as a "message" event.
+	Event string
+
+	// Data holds the event data. If it is a string or a []byte, it is
+	// sent as is; otherwise it is sent marshaled as JSON. In events
+	// received by an SSEStream, it always holds a string.
+	Data interface{}
+
+	// Retry, if positive, tells the client how long to wait
+	// before reconnecting if the connection is lost.
+	Retry time.Duration
+}
+
+var sseEventType = reflect.TypeOf(SSEEvent{})
+
+// marshal returns the wire representation of e.
+func (e SSEEvent) marshal() ([]byte, error) {
+	var data []byte
+	switch d := e.Data.(type) {
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	default:
+		var err error
+		data, err = json.Marshal(d)
+		if err != nil {
+			return nil, errgo.Mask(err)
+		}
+	}
+	var buf bytes.Buffer
+	if e.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", oneLine(e.ID))
+	}
+	if e.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", oneLine(e.Event))
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %d\n", e.Retry/time.Millisecond)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// oneLine removes any line breaks from s so
+// that it can be used as an event field value.
+func oneLine(s string) string {
+	return strings.NewReplacer("\n", "", "\r", "").Replace(s)
+}
+
+// writeSSE writes all the events received from events as the
+// response to req.
+func (srv *Server) writeSSE(w http.ResponseWriter, req *http.Request, events <-chan SSEEvent) {
+	ctx := req.Context()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	rc.Flush()
+	interval := srv.SSEHeartbeatInterval
+	if interval == 0 {
+		interval = DefaultSSEHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+	for {
+		var data []byte
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			data = []byte(":\n\n")
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			var err error
+			data, err = e.marshal()
+			if err != nil {
+				srv.logFailure(ctx, "cannot marshal server-sent event", err)
+				return
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return
+		}
+		rc.Flush()
+	}
+}
+
+// SSEStream iterates over the events of a server-sent event stream
+// received by Client.CallSSE. If the connection is lost, the stream
+// reconnects, sending the ID of the last event received in the
+// Last-Event-ID header. A stream that the server ends, as Server does
+// when a handler's event channel is closed, is not reconnected.
+//
+// An SSEStream is not safe to use concurrently.
+type SSEStream struct {
+	client *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	params interface{}
+	opts   []CallOption
+
+	body        io.ReadCloser
+	scanner     *bufio.Scanner
+	event       SSEEvent
+	lastEventID string
+	retry       time.Duration
+	closed      bool
+	err         error
+}
+
+// CallSSE invokes the endpoint implied by the given params, as
+// Client.Call does, and returns a stream of the server-sent events in
+// the text/event-stream response. The stream must be closed after use.
+//
+// For example:
+//
+//	stream, err := client.CallSSE(ctx, &params.WatchItems{})
+//	if err != nil {
+//		...
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		event := stream.Event()
+//		...
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+//
+// The stream ends when ctx is done. If the server responds with
+// http.StatusNoContent, the stream holds no events.
+func (c *Client) CallSSE(ctx context.Context, params interface{}, opts ...CallOption) (*SSEStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &SSEStream{
+		client: c,
+		ctx:    ctx,
+		cancel: cancel,
+		params: params,
+		opts:   opts,
+		retry:  DefaultSSEReconnectDelay,
+	}
+	if err := s.connect(); err != nil {
+		cancel()
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	return s, nil
+}
+
+// connect makes the request for the stream.
+func (s *SSEStream) connect() error {
+	opts := append(s.opts[:len(s.opts):len(s.opts)], WithHeader("Accept", "text/event-stream"))
+	if s.lastEventID != "" {
+		opts = append(opts, WithHeader("Last-Event-ID", s.lastEventID))
+	}
+	var httpResp *http.Response
+	if err := s.client.Call(s.ctx, s.params, &httpResp, opts...); err != nil {
+		return errgo.Mask(err, errgo.Any)
+	}
+	if httpResp.StatusCode == http.StatusNoContent {
+		httpResp.Body.Close()
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(httpResp.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		defer httpResp.Body.Close()
+		fancyErr := newFancyDecodeError(httpResp.Header, httpResp.Body, s.client.MaxErrorBodySize)
+		fancyErr.want = "text/event-stream"
+		return errgo.Mask(urlError(newDecodeResponseError(httpResp, fancyErr.body, fancyErr, s.client.MaxErrorBodySize), httpResp.Request), isDecodeResponseError)
+	}
+	s.body = httpResp.Body
+	s.scanner = bufio.NewScanner(httpResp.Body)
+	return nil
+}
+
+// Next advances to the next event, which is then available from the
+// Event method. It returns false when the stream ends or an error
+// occurs, after which the Err method returns the error, if any.
+func (s *SSEStream) Next() bool {
+	for s.body != nil {
+		if s.readEvent() {
+			return true
+		}
+		err := s.scanner.Err()
+		s.body.Close()
+		s.body = nil
+		if s.closed {
+			return false
+		}
+		if s.ctx.Err() != nil {
+			s.err = errgo.Mask(s.ctx.Err(), errgo.Any)
+			return false
+		}
+		if err == nil {
+			// The server ended the stream.
+			return false
+		}
+		if err == bufio.ErrTooLong {
+			s.err = errgo.Notef(err, "cannot read event stream")
+			return false
+		}
+		// The connection was lost, so reconnect.
+		if err := sleep(s.ctx, s.retry); err != nil {
+			s.err = errgo.Mask(err, errgo.Any)
+			return false
+		}
+		if err := s.connect(); err != nil {
+			s.err = errgo.Notef(err, "cannot reconnect to event stream")
+			return false
+		}
+	}
+	return false
+}
+
+// readEvent reads the next event from the stream into s.event,
+// reporting whether it found one. See
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+func (s *SSEStream) readEvent() bool {
+	var (
+		event   SSEEvent
+		data    strings.Builder
+		hasData bool
+	)
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			if !hasData {
+				// There's no data, so reset without
+				// dispatching an event.
+				event = SSEEvent{}
+				continue
+			}
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			event.Retry = 0
+			s.event = event
+			return true
+		}
+		if strings.HasPrefix(line, ":") {
+			// It's a comment, such as a heartbeat.
+			continue
+		}
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+			hasData = true
+		case "event":
+			event.Event = value
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				event.ID = value
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return false
+}
+
+// Event returns the event most recently read by Next.
+func (s *SSEStream) Event() SSEEvent {
+	return s.event
+}
+
+// LastEventID returns the ID of the most recent event received that
+// had one.
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Err returns the error that ended the stream, if any.
+func (s *SSEStream) Err() error {
+	return s.err
+}
+
+// Close closes the stream. Next returns false after
+// the stream is closed.
+func (s *SSEStream) Close() error {
+	s.closed = true
+	s.cancel()
+	if s.body != nil {
+		s.body.Close()
+		s.body = nil
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+func TestSSEResult(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(p httprequest.Params, arg *struct{}) (<-chan httprequest.SSEEvent, error) {
+		events := make(chan httprequest.SSEEvent, 3)
+		events <- httprequest.SSEEvent{
+			Data: "hello\nworld",
+		}
+		events <- httprequest.SSEEvent{
+			ID:    "2",
+			Event: "item",
+			Data: map[string]int{
+				"n": 1,
+			},
+			Retry: 3 * time.Second,
+		}
+		events <- httprequest.SSEEvent{
+			Data: []byte("bytes"),
+		}
+		close(events)
+		return events, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "text/event-stream")
+	c.Assert(rec.Header().Get("Cache-Control"), qt.Equals, "no-cache")
+	c.Assert(rec.Flushed, qt.Equals, true)
+	c.Assert(rec.Body.String(), qt.Equals, `data: hello
+data: world
+
+id: 2
+event: item
+retry: 3000
+data: {"n":1}
+
+data: bytes
+
+`)
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httprequest.Server{
+		SSEHeartbeatInterval: time.Millisecond,
+	}
+	events := make(chan httprequest.SSEEvent)
+	h := srv.Handle(func(arg *struct{}) (<-chan httprequest.SSEEvent, error) {
+		return events, nil
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.Handle(w, req, nil)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	line, err := r.ReadString('\n')
+	c.Assert(err, qt.IsNil)
+	c.Assert(line, qt.Equals, ":\n")
+	close(events)
+}
+
+func TestSSEClientDisconnect(t *testing.T) {
+	c := qt.New(t)
+
+	done := make(chan struct{})
+	h := testServer.Handle(func(p httprequest.Params, arg *struct{}) (<-chan httprequest.SSEEvent, error) {
+		// Note: the channel is never closed, so the stream
+		// only ends when the request context is done.
+		return make(chan httprequest.SSEEvent), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	req := (&http.Request{}).WithContext(ctx)
+	go func() {
+		defer close(done)
+		h.Handle(httptest.NewRecorder(), req, nil)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatalf("event stream not closed after request context done")
+	}
+}
+
+type sseEventsRequest struct {
+	httprequest.Route `httprequest:"GET /events"`
+}
+
+type sseFlakyRequest struct {
+	httprequest.Route `httprequest:"GET /flaky"`
+}
+
+type sseWaitRequest struct {
+	httprequest.Route `httprequest:"GET /wait"`
+}
+
+type sseEmptyRequest struct {
+	httprequest.Route `httprequest:"GET /empty"`
+}
+
+type sseTextRequest struct {
+	httprequest.Route `httprequest:"GET /text"`
+}
+
+// sseServer returns a server that sends a fixed set of events from
+// /events, waits for the request to be done after sending one event
+// from /wait, and loses the connection to /flaky after sending an
+// event to clients that don't send a Last-Event-ID header. The
+// Last-Event-ID headers received by /flaky are sent on lastEventIDs.
+func sseServer(lastEventIDs chan<- string) *httptest.Server {
+	var srv httprequest.Server
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *sseEventsRequest) (<-chan httprequest.SSEEvent, error) {
+			events := make(chan httprequest.SSEEvent, 3)
+			events <- httprequest.SSEEvent{
+				Data: "hello\nworld",
+			}
+			events <- httprequest.SSEEvent{
+				ID:    "2",
+				Event: "item",
+				Data: map[string]int{
+					"n": 1,
+				},
+				Retry: 3 * time.Second,
+			}
+			events <- httprequest.SSEEvent{
+				Data: "",
+			}
+			close(events)
+			return events, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *sseWaitRequest) (<-chan httprequest.SSEEvent, error) {
+			events := make(chan httprequest.SSEEvent, 1)
+			events <- httprequest.SSEEvent{
+				Data: "first",
+			}
+			return events, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *sseEmptyRequest) {
+			p.Response.WriteHeader(http.StatusNoContent)
+		}),
+		srv.Handle(func(p httprequest.Params, req *sseTextRequest) {
+			p.Response.Header().Set("Content-Type", "text/plain")
+			io.WriteString(p.Response, "no events")
+		}),
+	}))
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, req *http.Request) {
+		lastEventID := req.Header.Get("Last-Event-ID")
+		lastEventIDs <- lastEventID
+		w.Header().Set("Content-Type", "text/event-stream")
+		if lastEventID == "" {
+			io.WriteString(w, "retry: 1\nid: 1\ndata: before\n\n")
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		io.WriteString(w, ": comment\nid: 2\ndata: after\n\n")
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCallSSE(t *testing.T) {
+	c := qt.New(t)
+
+	srv := sseServer(nil)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	stream, err := client.CallSSE(context.Background(), &sseEventsRequest{})
+	c.Assert(err, qt.IsNil)
+	defer stream.Close()
+	var events []httprequest.SSEEvent
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+	c.Assert(stream.Err(), qt.IsNil)
+	c.Assert(events, qt.DeepEquals, []httprequest.SSEEvent{{
+		Data: "hello\nworld",
+	}, {
+		ID:    "2",
+		Event: "item",
+		Data:  `{"n":1}`,
+	}, {
+		Data: "",
+	}})
+	c.Assert(stream.LastEventID(), qt.Equals, "2")
+}
+
+func TestCallSSEReconnect(t *testing.T) {
+	c := qt.New(t)
+
+	lastEventIDs := make(chan string, 2)
+	srv := sseServer(lastEventIDs)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	stream, err := client.CallSSE(context.Background(), &sseFlakyRequest{})
+	c.Assert(err, qt.IsNil)
+	defer stream.Close()
+	var data []interface{}
+	for stream.Next() {
+		data = append(data, stream.Event().Data)
+	}
+	c.Assert(stream.Err(), qt.IsNil)
+	c.Assert(data, qt.DeepEquals, []interface{}{"before", "after"})
+	c.Assert(<-lastEventIDs, qt.Equals, "")
+	c.Assert(<-lastEventIDs, qt.Equals, "1")
+}
+
+func TestCallSSEContextDone(t *testing.T) {
+	c := qt.New(t)
+
+	srv := sseServer(nil)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.CallSSE(ctx, &sseWaitRequest{})
+	c.Assert(err, qt.IsNil)
+	defer stream.Close()
+	c.Assert(stream.Next(), qt.Equals, true)
+	c.Assert(stream.Event().Data, qt.Equals, "first")
+	cancel()
+	c.Assert(stream.Next(), qt.Equals, false)
+	c.Assert(stream.Err(), qt.ErrorMatches, `context canceled`)
+}
+
+func TestCallSSEClose(t *testing.T) {
+	c := qt.New(t)
+
+	srv := sseServer(nil)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	stream, err := client.CallSSE(context.Background(), &sseWaitRequest{})
+	c.Assert(err, qt.IsNil)
+	c.Assert(stream.Next(), qt.Equals, true)
+	c.Assert(stream.Close(), qt.IsNil)
+	c.Assert(stream.Next(), qt.Equals, false)
+	c.Assert(stream.Err(), qt.IsNil)
+}
+
+func TestCallSSENoContent(t *testing.T) {
+	c := qt.New(t)
+
+	srv := sseServer(nil)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	stream, err := client.CallSSE(context.Background(), &sseEmptyRequest{})
+	c.Assert(err, qt.IsNil)
+	defer stream.Close()
+	c.Assert(stream.Next(), qt.Equals, false)
+	c.Assert(stream.Err(), qt.IsNil)
+}
+
+func TestCallSSENotEventStream(t *testing.T) {
+	c := qt.New(t)
+
+	srv := sseServer(nil)
+	defer srv.Close()
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	_, err := client.CallSSE(context.Background(), &sseTextRequest{})
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/text: unexpected content type text/plain; want text/event-stream; content: no events`)
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Stream is a type that can be returned from a handler to stream an
+// arbitrary response body to the client instead of writing a JSON
+// value. A handler may also return an io.ReadCloser directly, which is
+// equivalent to returning a Stream holding it with no other fields set.
+type Stream struct {
+	// ContentType holds the content type of the body. If it is
+	// empty, "application/octet-stream" is used.
+	ContentType string
+
+	// Length holds the length of the body. If it is greater than
+	// zero, it is sent as the Content-Length header.
+	Length int64
+
+	// Body holds the body of the response. It is always closed
+	// after the response has been written. If it is nil, the
+	// response body is empty.
+	Body io.ReadCloser
+
+	// Trailer holds any HTTP trailers to send after the body.
+	// As with http.Request.Trailer, its keys are announced to
+	// the client in the Trailer header before the body is sent,
+	// and its values are sent after the body has been read to
+	// the end, so they may be filled in while Body is being read
+	// (for example to send a checksum of the body). Keys with
+	// no values are not sent.
+	Trailer http.Header
+}
+
+// writeStream writes the contents of s as the response to req.
+func (srv *Server) writeStream(w http.ResponseWriter, req *http.Request, s Stream) {
+	if s.Body != nil {
+		defer s.Body.Close()
+	}
+	contentType := s.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if s.Length > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(s.Length, 10))
+	}
+	for k := range s.Trailer {
+		w.Header().Add("Trailer", k)
+	}
+	w.WriteHeader(http.StatusOK)
+	if s.Body != nil {
+		if _, err := io.Copy(w, s.Body); err != nil {
+			// The header has already been written, so all
+			// we can do is log the error.
+			srv.logFailure(req.Context(), "cannot stream response body", err)
+		}
+	}
+	// The HTTP server sends the values of declared
+	// trailers found in the header after the body.
+	for k, vs := range s.Trailer {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+}
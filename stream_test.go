@@ -0,0 +1,129 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestStreamResult(t *testing.T) {
+	c := qt.New(t)
+
+	body := &closeRecorder{
+		Reader: strings.NewReader("some data"),
+	}
+	h := testServer.Handle(func(p httprequest.Params, arg *struct{}) (httprequest.Stream, error) {
+		p.Response.Header().Set("X-Custom", "yes")
+		return httprequest.Stream{
+			ContentType: "text/plain",
+			Length:      9,
+			Body:        body,
+		}, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), qt.Equals, "some data")
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "text/plain")
+	c.Assert(rec.Header().Get("Content-Length"), qt.Equals, "9")
+	c.Assert(rec.Header().Get("X-Custom"), qt.Equals, "yes")
+	c.Assert(body.closed, qt.Equals, true)
+}
+
+func TestReadCloserResult(t *testing.T) {
+	c := qt.New(t)
+
+	body := &closeRecorder{
+		Reader: strings.NewReader("some data"),
+	}
+	h := testServer.Handle(func(arg *struct{}) (io.ReadCloser, error) {
+		return body, nil
+	})
+	rec := httptest.NewRecorder()
+	h.Handle(rec, &http.Request{}, nil)
+	c.Assert(rec.Code, qt.Equals, http.StatusOK)
+	c.Assert(rec.Body.String(), qt.Equals, "some data")
+	c.Assert(rec.Header().Get("Content-Type"), qt.Equals, "application/octet-stream")
+	c.Assert(rec.Header().Get("Content-Length"), qt.Equals, "")
+	c.Assert(body.closed, qt.Equals, true)
+}
+
+// checksumReader fills in the X-Checksum trailer
+// once its underlying reader is exhausted.
+type checksumReader struct {
+	r       io.Reader
+	hash    hash.Hash
+	trailer http.Header
+}
+
+func (r *checksumReader) Read(buf []byte) (int, error) {
+	n, err := r.r.Read(buf)
+	r.hash.Write(buf[:n])
+	if err == io.EOF {
+		r.trailer.Set("X-Checksum", fmt.Sprintf("%x", r.hash.Sum(nil)))
+	}
+	return n, err
+}
+
+func (r *checksumReader) Close() error {
+	return nil
+}
+
+func TestStreamTrailer(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct{}) (httprequest.Stream, error) {
+		trailer := http.Header{
+			"X-Checksum": nil,
+			"X-Unset":    nil,
+		}
+		return httprequest.Stream{
+			Body: &checksumReader{
+				r:       strings.NewReader("some data"),
+				hash:    sha256.New(),
+				trailer: trailer,
+			},
+			Trailer: trailer,
+		}, nil
+	})
+	srv := httptest.NewServer(httprequest.ToHTTP(h.Handle))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	c.Assert(resp.StatusCode, qt.Equals, http.StatusOK)
+	c.Assert(resp.Trailer, qt.DeepEquals, http.Header{
+		"X-Checksum": nil,
+		"X-Unset":    nil,
+	})
+	data, err := io.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Equals, "some data")
+	c.Assert(resp.Trailer, qt.DeepEquals, http.Header{
+		"X-Checksum": {fmt.Sprintf("%x", sha256.Sum256([]byte("some data")))},
+		"X-Unset":    nil,
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unmarshalJSONBody unmarshals the JSON body of resp, which holds a
+// successful response, into x, checking it strictly if
+// c.StrictResponses is set.
+func (c *Client) unmarshalJSONBody(resp *http.Response, x interface{}) error {
+	if !c.StrictResponses || x == nil {
+		return unmarshalJSONResponse(resp, x, c.MaxErrorBodySize)
+	}
+	limit := errorBodyLimit(c.MaxErrorBodySize)
+	if !isJSONMediaType(resp.Header) {
+		fancyErr := newFancyDecodeError(resp.Header, resp.Body, limit)
+		return newDecodeResponseError(resp, fancyErr.body, fancyErr, limit)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	bodyData := data
+	if len(bodyData) > limit {
+		bodyData = bodyData[:limit]
+	}
+	if err != nil {
+		return newDecodeResponseError(resp, bodyData, errgo.Notef(err, "error reading response body"), limit)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(x); err != nil {
+		return newDecodeResponseError(resp, bodyData, err, limit)
+	}
+	var v interface{}
+	dec = json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return newDecodeResponseError(resp, bodyData, err, limit)
+	}
+	if err := checkJSONNulls(v, reflect.TypeOf(x), ""); err != nil {
+		return newDecodeResponseError(resp, bodyData, err, limit)
+	}
+	return nil
+}
+
+// checkJSONNulls returns an error if v, a value decoded from JSON
+// into an interface{}, holds a null for a required field of the
+// corresponding value of type t. A field is required unless it is
+// tagged with omitempty or its type can represent null, as pointer,
+// interface, slice and map types and types implementing
+// json.Unmarshaler can. The path holds the location of v, for use in
+// errors.
+func checkJSONNulls(v interface{}, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		if t.Implements(jsonUnmarshalerType) {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for name, fv := range v {
+				f, ok := lookupJSONField(fields, name)
+				if !ok {
+					continue
+				}
+				fpath := joinJSONPath(path, f.name)
+				if fv == nil {
+					if !f.omitempty && !isNullable(f.t) {
+						return errgo.Newf("null value for required field %s", fpath)
+					}
+					continue
+				}
+				if err := checkJSONNulls(fv, f.t, fpath); err != nil {
+					return err
+				}
+			}
+		case reflect.Map:
+			for k, ev := range v {
+				if ev == nil {
+					continue
+				}
+				if err := checkJSONNulls(ev, t.Elem(), joinJSONPath(path, k)); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for i, ev := range v {
+			if ev == nil {
+				continue
+			}
+			if err := checkJSONNulls(ev, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isNullable reports whether a JSON null can be
+// unmarshaled meaningfully into a value of type t.
+func isNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType)
+}
+
+// jsonField holds a struct field as seen by encoding/json.
+type jsonField struct {
+	name      string
+	t         reflect.Type
+	omitempty bool
+}
+
+// jsonFields returns the fields of the struct type t that are
+// unmarshaled by encoding/json, including those promoted from
+// embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(ft)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{
+			name:      name,
+			t:         f.Type,
+			omitempty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// lookupJSONField returns the field that encoding/json unmarshals
+// the object member with the given name into.
+func lookupJSONField(fields []jsonField, name string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// joinJSONPath returns the path of the member
+// with the given name of the value at path.
+func joinJSONPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type strictInner struct {
+	N int `json:"n"`
+}
+
+type strictEmbedded struct {
+	E string
+}
+
+type strictResponse struct {
+	strictEmbedded
+	Name     string                 `json:"name"`
+	Count    int                    `json:"count,omitempty"`
+	Ptr      *int                   `json:"ptr"`
+	List     []string               `json:"list"`
+	Time     time.Time              `json:"time"`
+	Inner    strictInner            `json:"inner"`
+	Inners   []strictInner          `json:"inners"`
+	InnerMap map[string]strictInner `json:"innerMap"`
+	Ignored  string                 `json:"-"`
+}
+
+var strictResponsesTests = []struct {
+	about       string
+	body        string
+	expectError string
+}{{
+	about: "valid response",
+	body:  `{"name":"x","E":"e","ptr":null,"list":null,"time":null,"count":null,"inner":{"n":1},"inners":[{"n":2}],"innerMap":{"a":{"n":3}}}`,
+}, {
+	about: "case-insensitive field names",
+	body:  `{"NAME":"x"}`,
+}, {
+	about:       "unknown field",
+	body:        `{"name":"x","extra":1}`,
+	expectError: `Get http://.*/x: json: unknown field "extra"`,
+}, {
+	about:       "unknown field in nested struct",
+	body:        `{"inner":{"n":1,"m":2}}`,
+	expectError: `Get http://.*/x: json: unknown field "m"`,
+}, {
+	about:       "ignored field",
+	body:        `{"Ignored":"x"}`,
+	expectError: `Get http://.*/x: json: unknown field "Ignored"`,
+}, {
+	about:       "null for required field",
+	body:        `{"name":null}`,
+	expectError: `Get http://.*/x: null value for required field name`,
+}, {
+	about:       "null for required embedded field",
+	body:        `{"E":null}`,
+	expectError: `Get http://.*/x: null value for required field E`,
+}, {
+	about:       "null for required struct field",
+	body:        `{"inner":null}`,
+	expectError: `Get http://.*/x: null value for required field inner`,
+}, {
+	about:       "null in slice element",
+	body:        `{"inners":[{"n":1},{"n":null}]}`,
+	expectError: `Get http://.*/x: null value for required field inners\[1\]\.n`,
+}, {
+	about:       "null in map element",
+	body:        `{"innerMap":{"a":{"n":null}}}`,
+	expectError: `Get http://.*/x: null value for required field innerMap\.a\.n`,
+}}
+
+func TestClientStrictResponses(t *testing.T) {
+	c := qt.New(t)
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:         srv.URL,
+		StrictResponses: true,
+	}
+	for _, test := range strictResponsesTests {
+		c.Run(test.about, func(c *qt.C) {
+			body = test.body
+			var resp strictResponse
+			err := client.Get(context.Background(), "/x", &resp)
+			if test.expectError == "" {
+				c.Assert(err, qt.IsNil)
+				return
+			}
+			c.Assert(err, qt.ErrorMatches, test.expectError)
+			_, ok := errgo.Cause(err).(*httprequest.DecodeResponseError)
+			c.Assert(ok, qt.IsTrue)
+		})
+	}
+}
+
+func TestClientNonStrictResponses(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":null,"extra":1}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+	}
+	var resp strictResponse
+	err := client.Get(context.Background(), "/x", &resp)
+	c.Assert(err, qt.IsNil)
+}
+
+func TestClientStrictResponsesErrorsNotChecked(t *testing.T) {
+	c := qt.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Message":"bad","Extra":1}`))
+	}))
+	defer srv.Close()
+
+	client := &httprequest.Client{
+		BaseURL:         srv.URL,
+		StrictResponses: true,
+	}
+	err := client.Get(context.Background(), "/x", nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/x: bad`)
+}
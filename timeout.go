@@ -0,0 +1,144 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+)
+
+var (
+	streamType     = reflect.TypeOf(Stream{})
+	attachmentType = reflect.TypeOf(Attachment{})
+	proxyType      = reflect.TypeOf(Proxy{})
+)
+
+// withTimeout returns a handler that calls h with a request context
+// that is cancelled after the given timeout. If h has not returned by
+// then, an error with code CodeTimeout is written instead of its
+// response, and any later writes by h are discarded. The request
+// is still counted by ActiveRequests until h returns.
+//
+// The response written by h is buffered until it returns,
+// so streamed responses are not sent incrementally (see
+// checkTimeoutResult). If h panics, the panic is raised again
+// with the stack trace of the goroutine running h.
+func (srv *Server) withTimeout(h httprouter.Handle, timeout time.Duration) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		tw := &timeoutWriter{
+			header: make(http.Header),
+		}
+		done := make(chan struct{})
+		panicc := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				tw.mu.Lock()
+				if tw.timedOut {
+					// The request was counted as still active
+					// when the timeout error was written.
+					srv.exit()
+				}
+				tw.mu.Unlock()
+			}()
+			defer func() {
+				if p := recover(); p != nil {
+					if p != http.ErrAbortHandler {
+						p = fmt.Sprintf("%v\n\n%s", p, debug.Stack())
+					}
+					panicc <- p
+				}
+			}()
+			h(tw, req.WithContext(ctx), p)
+			close(done)
+		}()
+		select {
+		case p := <-panicc:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			dst := w.Header()
+			for k, v := range tw.header {
+				dst[k] = v
+			}
+			if tw.code == 0 {
+				tw.code = http.StatusOK
+			}
+			w.WriteHeader(tw.code)
+			w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			// The handler is still running, so keep counting the
+			// request as active until it returns, after the caller
+			// has recorded the end of the request.
+			srv.hold()
+			srv.WriteError(req.Context(), w, Errorf(CodeTimeout, "handler did not complete within %v", timeout))
+		}
+	}
+}
+
+// checkTimeoutResult returns an error if results of type t,
+// the first result type of a handler function, are streamed
+// and so cannot be buffered by a handler with a timeout.
+func checkTimeoutResult(t reflect.Type) error {
+	switch {
+	case t == streamType, t == attachmentType, t == proxyType:
+	case t.Kind() == reflect.Chan && t.ChanDir() == reflect.RecvDir:
+		// Both SSE events and NDJSON values.
+	case t.Implements(readCloserType):
+	default:
+		return nil
+	}
+	return errgo.Newf("timeout cannot be used with streamed result type %s", t)
+}
+
+// timeoutWriter buffers the response written by a handler
+// running with a timeout.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	code     int
+	buf      bytes.Buffer
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.buf.Write(data)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 || code < 200 {
+		// Informational responses cannot be sent
+		// because the response is buffered.
+		return
+	}
+	tw.code = code
+}
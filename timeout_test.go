@@ -0,0 +1,153 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type timeoutHandlers struct {
+	lateErr chan error
+}
+
+func (h timeoutHandlers) Fast(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /fast" timeout:"1m"`
+}) (string, error) {
+	if _, ok := p.Context.Deadline(); !ok {
+		return "", httprequest.Errorf("", "no deadline")
+	}
+	p.Response.Header().Set("X-Fast", "yes")
+	return "ok", nil
+}
+
+func (h timeoutHandlers) Slow(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /slow" timeout:"10ms"`
+}) {
+	<-p.Context.Done()
+	// Wait for the timeout error to be written.
+	time.Sleep(10 * time.Millisecond)
+	_, err := p.Response.Write([]byte("late"))
+	h.lateErr <- err
+}
+
+func TestTimeout(t *testing.T) {
+	c := qt.New(t)
+
+	lateErr := make(chan error, 1)
+	var srv httprequest.Server
+	router := srv.NewRouter(srv.Handlers(func(p httprequest.Params) (timeoutHandlers, context.Context, error) {
+		return timeoutHandlers{lateErr}, p.Context, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/fast", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusOK, "ok")
+	c.Assert(rec.Header().Get("X-Fast"), qt.Equals, "yes")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/slow", nil))
+	qthttptest.AssertJSONResponse(c, rec, http.StatusServiceUnavailable, &httprequest.RemoteError{
+		Code:    httprequest.CodeTimeout,
+		Message: "handler did not complete within 10ms",
+	})
+	select {
+	case err := <-lateErr:
+		c.Assert(err, qt.Equals, http.ErrHandlerTimeout)
+	case <-time.After(5 * time.Second):
+		c.Fatalf("handler did not return")
+	}
+}
+
+func TestTimeoutPanic(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct {
+		httprequest.Route `httprequest:"GET /panic" timeout:"1m"`
+	}) {
+		panic("oops")
+	})
+	c.Assert(func() {
+		h.Handle(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil), nil)
+	}, qt.PanicMatches, `(?s)oops\n\ngoroutine .*timeout_test\.go.*`)
+}
+
+func TestTimeoutAbortHandler(t *testing.T) {
+	c := qt.New(t)
+
+	h := testServer.Handle(func(arg *struct {
+		httprequest.Route `httprequest:"GET /abort" timeout:"1m"`
+	}) {
+		panic(http.ErrAbortHandler)
+	})
+	c.Assert(func() {
+		h.Handle(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil), nil)
+	}, qt.PanicMatches, http.ErrAbortHandler.Error())
+}
+
+var timeoutStreamTests = []struct {
+	about  string
+	f      interface{}
+	expect string
+}{{
+	about: "stream",
+	f: func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /stream" timeout:"1s"`
+	}) (httprequest.Stream, error) {
+		return httprequest.Stream{}, nil
+	},
+	expect: `bad handler function: timeout cannot be used with streamed result type httprequest.Stream`,
+}, {
+	about: "proxy",
+	f: func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /proxy" timeout:"1s"`
+	}) (httprequest.Proxy, error) {
+		return httprequest.Proxy{}, nil
+	},
+	expect: `bad handler function: timeout cannot be used with streamed result type httprequest.Proxy`,
+}, {
+	about: "SSE",
+	f: func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /events" timeout:"1s"`
+	}) (<-chan httprequest.SSEEvent, error) {
+		return nil, nil
+	},
+	expect: `bad handler function: timeout cannot be used with streamed result type <-chan httprequest.SSEEvent`,
+}, {
+	about: "NDJSON",
+	f: func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /values" timeout:"1s"`
+	}) (<-chan int, error) {
+		return nil, nil
+	},
+	expect: `bad handler function: timeout cannot be used with streamed result type <-chan int`,
+}, {
+	about: "reader",
+	f: func(p httprequest.Params, arg *struct {
+		httprequest.Route `httprequest:"GET /reader" timeout:"1s"`
+	}) (io.ReadCloser, error) {
+		return nil, nil
+	},
+	expect: `bad handler function: timeout cannot be used with streamed result type io.ReadCloser`,
+}}
+
+func TestTimeoutStreamedResult(t *testing.T) {
+	c := qt.New(t)
+	for _, test := range timeoutStreamTests {
+		c.Run(test.about, func(c *qt.C) {
+			c.Assert(func() {
+				testServer.Handle(test.f)
+			}, qt.PanicMatches, test.expect)
+		})
+	}
+}
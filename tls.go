@@ -0,0 +1,93 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"time"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// TLSOptions holds the configuration for a Doer created by NewTLSDoer.
+type TLSOptions struct {
+	// Certificates holds the certificates presented to servers that
+	// request client certificates, as when using mutual TLS. See
+	// tls.LoadX509KeyPair.
+	Certificates []tls.Certificate
+
+	// RootCAs holds the certificate authorities used to verify
+	// server certificates. If it is nil, the host's root CAs are
+	// used. See NewCertPool.
+	RootCAs *x509.CertPool
+
+	// ServerName, if non-empty, holds the name used to verify
+	// server certificates in place of the host name in the
+	// request URL.
+	ServerName string
+
+	// MinVersion holds the minimum TLS version that is acceptable,
+	// such as tls.VersionTLS13. If it is zero, tls.VersionTLS12 is
+	// used.
+	MinVersion uint16
+
+	// Timeout, if non-zero, limits the time taken by each request,
+	// including reading the response body. Note that
+	// WithTimeout is usually a better way of limiting the time
+	// taken by a call.
+	Timeout time.Duration
+}
+
+// NewTLSDoer returns an *http.Client, for use as Client.Doer, that
+// makes TLS connections configured as specified by opts. Connections
+// are kept alive and reused, with the same timeouts for connecting,
+// TLS handshakes and idle connections as http.DefaultTransport, and
+// up to 10 idle connections are kept for each host.
+func NewTLSDoer(opts TLSOptions) (*http.Client, error) {
+	minVersion := opts.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	if minVersion < tls.VersionTLS12 {
+		return nil, errgo.Newf("minimum TLS version %#x is older than TLS 1.2", minVersion)
+	}
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig: &tls.Config{
+			Certificates: opts.Certificates,
+			RootCAs:      opts.RootCAs,
+			ServerName:   opts.ServerName,
+			MinVersion:   minVersion,
+		},
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}, nil
+}
+
+// NewCertPool returns a certificate pool holding all the certificates
+// in the given PEM-encoded data, for use as TLSOptions.RootCAs.
+func NewCertPool(pemData ...[]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for i, data := range pemData {
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errgo.Newf("no certificates found in PEM data %d", i)
+		}
+	}
+	return pool, nil
+}
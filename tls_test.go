@@ -0,0 +1,149 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// newClientCertificate returns a new self-signed client certificate
+// with the given common name.
+func newClientCertificate(c *qt.C, name string) (tls.Certificate, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, qt.IsNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	c.Assert(err, qt.IsNil)
+	cert, err := x509.ParseCertificate(der)
+	c.Assert(err, qt.IsNil)
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, cert
+}
+
+// mtlsServer returns a TLS server that requires client certificates
+// signed by clientCA and responds to /name with the common name of the
+// client certificate.
+func mtlsServer(clientCA *x509.Certificate) *httptest.Server {
+	var srv httprequest.Server
+	server := httptest.NewUnstartedServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *nameRequest) (string, error) {
+			return p.Request.TLS.PeerCertificates[0].Subject.CommonName, nil
+		}),
+	}))
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+	server.StartTLS()
+	return server
+}
+
+func TestNewTLSDoer(t *testing.T) {
+	c := qt.New(t)
+
+	clientCert, clientCA := newClientCertificate(c, "client")
+	srv := mtlsServer(clientCA)
+	defer srv.Close()
+	roots, err := httprequest.NewCertPool(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}))
+	c.Assert(err, qt.IsNil)
+	doer, err := httprequest.NewTLSDoer(httprequest.TLSOptions{
+		Certificates: []tls.Certificate{clientCert},
+		RootCAs:      roots,
+		MinVersion:   tls.VersionTLS13,
+	})
+	c.Assert(err, qt.IsNil)
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Doer:    doer,
+	}
+	var name string
+	err = client.Call(context.Background(), &nameRequest{}, &name)
+	c.Assert(err, qt.IsNil)
+	c.Assert(name, qt.Equals, "client")
+}
+
+func TestNewTLSDoerWithoutClientCertificate(t *testing.T) {
+	c := qt.New(t)
+
+	_, clientCA := newClientCertificate(c, "client")
+	srv := mtlsServer(clientCA)
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	doer, err := httprequest.NewTLSDoer(httprequest.TLSOptions{
+		RootCAs: roots,
+	})
+	c.Assert(err, qt.IsNil)
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Doer:    doer,
+	}
+	err = client.Call(context.Background(), &nameRequest{}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get "?https://.*/name"?: .*certificate.*`)
+}
+
+func TestNewTLSDoerUnknownAuthority(t *testing.T) {
+	c := qt.New(t)
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	doer, err := httprequest.NewTLSDoer(httprequest.TLSOptions{})
+	c.Assert(err, qt.IsNil)
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Doer:    doer,
+	}
+	err = client.Get(context.Background(), "/", nil)
+	c.Assert(err, qt.ErrorMatches, `Get "?https://.*"?: .*certificate signed by unknown authority.*`)
+}
+
+func TestNewTLSDoerBadMinVersion(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := httprequest.NewTLSDoer(httprequest.TLSOptions{
+		MinVersion: tls.VersionTLS11,
+	})
+	c.Assert(err, qt.ErrorMatches, `minimum TLS version 0x302 is older than TLS 1.2`)
+}
+
+func TestNewCertPoolNoCertificates(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := httprequest.NewCertPool([]byte("not a certificate"))
+	c.Assert(err, qt.ErrorMatches, `no certificates found in PEM data 0`)
+}
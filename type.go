@@ -6,8 +6,7 @@
 // It also provides a way to define methods as HTTP routes
 // using the same approach.
 //
-// It requires at least Go 1.7, and Go 1.9 is required if the importing
-// program also uses golang.org/x/net/context.
+// It requires at least Go 1.22.
 package httprequest
 
 import (
@@ -16,8 +15,10 @@ import (
 	"net/http"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"gopkg.in/errgo.v1"
@@ -48,6 +49,86 @@ type Params struct {
 	// Context holds a context for the request. In Go 1.7 and later,
 	// this should be used in preference to Request.Context.
 	Context context.Context
+	// Metadata holds descriptive information about the route.
+	// Like PathPattern, it is only set where the call was made
+	// by Server.Handle or Server.Handlers; it is not set by
+	// Server.HandleJSON or Server.HandleErrors.
+	Metadata RouteMetadata
+}
+
+// RouteMetadata holds descriptive information about a route, for use
+// by documentation generators, metrics, access logs and the like.
+// It is taken from tags on the Route field of a request type in
+// addition to the httprequest tag. For example:
+//
+//	type CreateUserRequest struct {
+//		httprequest.Route `httprequest:"POST /users" name:"CreateUser" summary:"Creates a user." tags:"users,admin"`
+//		...
+//	}
+type RouteMetadata struct {
+	// Name holds the operation name, from the "name" tag.
+	Name string
+
+	// Summary holds a short description of the
+	// route, from the "summary" tag.
+	Summary string
+
+	// Tags holds any tags associated with the route, from the
+	// comma-separated "tags" tag.
+	Tags []string
+
+	// CacheControl holds the Cache-Control header value set on
+	// successful responses from handlers that return a result, from
+	// the "cache" tag, for example `cache:"max-age=60, private"`.
+	// The directives are checked when the handler is created.
+	CacheControl string
+
+	// MaxBodySize holds the maximum size of request body accepted
+	// by the route, from the "maxbodysize" tag, overriding
+	// Server.MaxBodySize if it is non-zero. A negative value
+	// means that the size is not limited.
+	MaxBodySize int64
+
+	// Timeout holds the maximum time that the route's handler may
+	// take, from the "timeout" tag, for example `timeout:"5s"`.
+	// When it is non-zero, the handler's context is cancelled after
+	// the timeout and, if the handler has not returned by then, an
+	// error with code CodeTimeout is written instead of its response.
+	// The response is buffered until the handler returns, so a
+	// timeout cannot be used with handlers that return streamed
+	// results, such as Stream, Proxy or a channel, and should not
+	// be used by handlers that stream responses themselves.
+	Timeout time.Duration
+
+	// Auth holds the authorization requirements of the route, such
+	// as scopes, from the comma-separated "auth" tag, for example
+	// `auth:"read,write"`. They are checked by Server.Authorize.
+	Auth []string
+
+	// Idempotent holds whether retries of requests to the route
+	// with the same Idempotency-Key header should be answered with
+	// the original response, from the "idempotent" tag, for example
+	// `idempotent:"true"`. See Server.IdempotencyStore.
+	Idempotent bool
+
+	// MaxConcurrent holds the maximum number of requests to the
+	// route that may be served at once, from the "maxconcurrent"
+	// tag, for example `maxconcurrent:"4"`. Further requests wait
+	// for up to Server.ConcurrencyWait and are then rejected with a
+	// CodeServiceUnavailable error.
+	MaxConcurrent int
+
+	// ConcurrencyGroup holds the name of the limiter in
+	// Server.ConcurrencyGroups shared by the route, from the
+	// "concurrencygroup" tag, for example
+	// `concurrencygroup:"reports"`. It cannot be specified together
+	// with MaxConcurrent.
+	ConcurrencyGroup string
+
+	// Version holds the API version implemented by the route, from
+	// the "version" tag, for example `version:"2"`. It is used by
+	// Server.VersionedHandlers.
+	Version string
 }
 
 // resultMaker is provided to the unmarshal functions.
@@ -71,8 +152,21 @@ type marshaler func(reflect.Value, *Params) error
 type requestType struct {
 	method   string
 	path     string
+	metadata RouteMetadata
 	formBody bool
+	body     bool
 	fields   []field
+
+	// multipart holds whether the request has
+	// a multipart field.
+	multipart bool
+
+	// params describes the parameters of the request.
+	params []ParamInfo
+
+	// bodyContentType holds the content type required of
+	// the body field, if any, by a mergepatch or jsonpatch flag.
+	bodyContentType string
 }
 
 // field holds preprocessed information on an individual field
@@ -157,6 +251,10 @@ func parseRequestType(t reflect.Type) (*requestType, error) {
 			if err != nil {
 				return nil, errgo.Notef(err, "bad route tag %q", f.Tag)
 			}
+			pt.metadata, err = parseRouteMetadata(f.Tag)
+			if err != nil {
+				return nil, errgo.Notef(err, "bad route tag %q", f.Tag)
+			}
 			foundRoute = true
 			continue
 		}
@@ -172,10 +270,22 @@ func parseRequestType(t reflect.Type) (*requestType, error) {
 				return nil, errgo.New("more than one body field specified")
 			}
 			hasBody = true
+			pt.bodyContentType = tag.bodyContentType
+		case sourceMultipart:
+			if pt.multipart {
+				return nil, errgo.New("more than one multipart field specified")
+			}
+			if f.Type != multipartReaderType {
+				return nil, errgo.Newf("multipart field %s has type %s, need *multipart.Reader", f.Name, f.Type)
+			}
+			pt.multipart = true
 		}
 		if hasBody && pt.formBody {
 			return nil, errgo.New("cannot specify inbody field with a body field")
 		}
+		if pt.multipart && (hasBody || pt.formBody) {
+			return nil, errgo.New("cannot specify multipart field with a body or inbody field")
+		}
 		field := field{
 			index: f.Index,
 			name:  f.Name,
@@ -205,8 +315,16 @@ func parseRequestType(t reflect.Type) (*requestType, error) {
 		if f.Anonymous && tag.source != sourceNone {
 			taggedFieldIndex = f.Index
 		}
+		if tag.source != sourceNone {
+			pt.params = append(pt.params, ParamInfo{
+				Name: tag.name,
+				In:   sourceNames[tag.source],
+				Type: paramType(f.Type),
+			})
+		}
 		pt.fields = append(pt.fields, field)
 	}
+	pt.body = hasBody
 	return &pt, nil
 }
 
@@ -260,6 +378,117 @@ func parseRouteTag(tag reflect.StructTag) (method, path string, err error) {
 	return method, path, nil
 }
 
+// parseRouteMetadata parses the metadata tags
+// attached to a Route field.
+func parseRouteMetadata(tag reflect.StructTag) (RouteMetadata, error) {
+	m := RouteMetadata{
+		Name:    tag.Get("name"),
+		Summary: tag.Get("summary"),
+	}
+	m.Tags = splitTagList(tag.Get("tags"))
+	m.Auth = splitTagList(tag.Get("auth"))
+	var err error
+	m.CacheControl, err = parseCacheControl(tag.Get("cache"))
+	if err != nil {
+		return RouteMetadata{}, errgo.Notef(err, "bad cache tag")
+	}
+	if s := tag.Get("timeout"); s != "" {
+		m.Timeout, err = time.ParseDuration(s)
+		if err != nil || m.Timeout <= 0 {
+			return RouteMetadata{}, errgo.Newf("bad timeout tag %q", s)
+		}
+	}
+	if s := tag.Get("idempotent"); s != "" {
+		m.Idempotent, err = strconv.ParseBool(s)
+		if err != nil {
+			return RouteMetadata{}, errgo.Newf("bad idempotent tag %q", s)
+		}
+	}
+	if s := tag.Get("version"); s != "" {
+		if !isValidVersion(s) {
+			return RouteMetadata{}, errgo.Newf("bad version tag %q", s)
+		}
+		m.Version = s
+	}
+	if s := tag.Get("maxconcurrent"); s != "" {
+		m.MaxConcurrent, err = strconv.Atoi(s)
+		if err != nil || m.MaxConcurrent <= 0 {
+			return RouteMetadata{}, errgo.Newf("bad maxconcurrent tag %q", s)
+		}
+	}
+	m.ConcurrencyGroup = tag.Get("concurrencygroup")
+	if m.MaxConcurrent > 0 && m.ConcurrencyGroup != "" {
+		return RouteMetadata{}, errgo.New("cannot specify both maxconcurrent and concurrencygroup tags")
+	}
+	if s := tag.Get("maxbodysize"); s != "" {
+		m.MaxBodySize, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return RouteMetadata{}, errgo.Newf("bad maxbodysize tag %q", s)
+		}
+	}
+	return m, nil
+}
+
+// splitTagList splits a comma-separated list found in
+// a struct tag, ignoring empty elements.
+func splitTagList(s string) []string {
+	var elems []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// cacheDirectives holds the response Cache-Control directives allowed
+// in the cache tag, mapped to whether they take a number of seconds
+// as an argument.
+var cacheDirectives = map[string]bool{
+	"max-age":                true,
+	"s-maxage":               true,
+	"stale-while-revalidate": true,
+	"stale-if-error":         true,
+	"no-cache":               false,
+	"no-store":               false,
+	"no-transform":           false,
+	"must-revalidate":        false,
+	"proxy-revalidate":       false,
+	"must-understand":        false,
+	"private":                false,
+	"public":                 false,
+	"immutable":              false,
+}
+
+// parseCacheControl checks the Cache-Control directives in
+// the given comma-separated list and returns them in canonical form.
+func parseCacheControl(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	directives := strings.Split(s, ",")
+	for i, d := range directives {
+		d = strings.TrimSpace(d)
+		name, arg, hasArg := strings.Cut(d, "=")
+		needsArg, ok := cacheDirectives[name]
+		if !ok {
+			return "", errgo.Newf("unknown directive %q", d)
+		}
+		if hasArg != needsArg {
+			return "", errgo.Newf("invalid directive %q", d)
+		}
+		if hasArg {
+			n, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				return "", errgo.Newf("invalid directive %q", d)
+			}
+			d = name + "=" + strconv.FormatUint(n, 10)
+		}
+		directives[i] = d
+	}
+	return strings.Join(directives, ", "), nil
+}
+
 func makePointerResult(v reflect.Value) reflect.Value {
 	if v.IsNil() {
 		v.Set(reflect.New(v.Type().Elem()))
@@ -280,12 +509,17 @@ const (
 	sourceFormBody
 	sourceBody
 	sourceHeader
+	sourceMultipart
 )
 
 type tag struct {
 	name      string
 	source    tagSource
 	omitempty bool
+
+	// bodyContentType holds the content type required
+	// of a body field by the mergepatch or jsonpatch flags.
+	bodyContentType string
 }
 
 // parseTag parses the given struct tag attached to the given
@@ -315,8 +549,14 @@ func parseTag(rtag reflect.StructTag, fieldName string) (tag, error) {
 			t.source = sourceBody
 		case "header":
 			t.source = sourceHeader
+		case "multipart":
+			t.source = sourceMultipart
 		case "omitempty":
 			t.omitempty = true
+		case "mergepatch":
+			t.bodyContentType = MergePatchContentType
+		case "jsonpatch":
+			t.bodyContentType = JSONPatchContentType
 		default:
 			return tag{}, fmt.Errorf("unknown tag flag %q", f)
 		}
@@ -324,6 +564,9 @@ func parseTag(rtag reflect.StructTag, fieldName string) (tag, error) {
 	if t.omitempty && t.source != sourceForm && t.source != sourceHeader {
 		return tag{}, fmt.Errorf("can only use omitempty with form or header fields")
 	}
+	if t.bodyContentType != "" && t.source != sourceBody {
+		return tag{}, fmt.Errorf("can only use mergepatch or jsonpatch with body field")
+	}
 	if inBody {
 		if t.source != sourceForm {
 			return tag{}, fmt.Errorf("can only use inbody with form field")
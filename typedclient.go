@@ -0,0 +1,123 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"reflect"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+var callOptionsType = reflect.TypeOf([]CallOption(nil))
+
+// NewTypedClient returns a new value of type T, which must be a struct
+// type, with each of its exported fields of function type set to a
+// function that makes calls with c. It provides a typed client without
+// running httprequest-generate-client.
+//
+// Each such function must take a context.Context and a pointer to a
+// struct type with an httprequest.Route field, as passed to
+// Client.Call, optionally followed by a variadic ...CallOption
+// parameter, and return either an error or a response value and an
+// error. For example:
+//
+//	type UserClient struct {
+//		GetUser    func(ctx context.Context, p *params.GetUser) (*params.User, error)
+//		DeleteUser func(ctx context.Context, p *params.DeleteUser, opts ...httprequest.CallOption) error
+//	}
+//
+//	users, err := httprequest.NewTypedClient[UserClient](client)
+//	...
+//	user, err := users.GetUser(ctx, &params.GetUser{ID: id})
+//
+// Go does not allow an interface to be implemented by a type created
+// at run time, so T cannot be an interface type; a struct of functions
+// such as the above can be used to implement an interface with a few
+// lines of code if one is needed.
+func NewTypedClient[T any](c *Client) (*T, error) {
+	v := reflect.New(reflect.TypeOf((*T)(nil)).Elem())
+	if err := bindClientFuncs(c, v.Elem()); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return v.Interface().(*T), nil
+}
+
+// bindClientFuncs sets all the exported function fields of the struct
+// v to functions that make calls with c.
+func bindClientFuncs(c *Client, v reflect.Value) error {
+	t := v.Type()
+	if t.Kind() != reflect.Struct {
+		return errgo.Newf("type %s is not a struct", t)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Type.Kind() != reflect.Func {
+			continue
+		}
+		fn, err := clientFunc(c, f.Type)
+		if err != nil {
+			return errgo.Notef(err, "bad type for field %s", f.Name)
+		}
+		v.Field(i).Set(fn)
+	}
+	return nil
+}
+
+// clientFunc returns a function of type ft that makes calls with c.
+func clientFunc(c *Client, ft reflect.Type) (reflect.Value, error) {
+	switch {
+	case ft.NumIn() == 2 && !ft.IsVariadic():
+	case ft.NumIn() == 3 && ft.IsVariadic() && ft.In(2) == callOptionsType:
+	default:
+		return reflect.Value{}, errgo.Newf("%s: want func(context.Context, *T[, ...httprequest.CallOption])", ft)
+	}
+	if ft.In(0) != contextType {
+		return reflect.Value{}, errgo.Newf("%s: first argument is not context.Context", ft)
+	}
+	pt := ft.In(1)
+	if pt.Kind() != reflect.Ptr {
+		return reflect.Value{}, errgo.Newf("%s: second argument is not a pointer", ft)
+	}
+	rt, err := getRequestType(pt)
+	if err != nil {
+		return reflect.Value{}, errgo.Notef(err, "%s: bad parameter type", ft)
+	}
+	if rt.method == "" {
+		return reflect.Value{}, errgo.Newf("%s: type %s has no httprequest.Route field", ft, pt)
+	}
+	var respType reflect.Type
+	switch {
+	case ft.NumOut() == 1 && ft.Out(0) == errorType:
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+		respType = ft.Out(0)
+	default:
+		return reflect.Value{}, errgo.Newf("%s: result is not error or (T, error)", ft)
+	}
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		ctx, _ := args[0].Interface().(context.Context)
+		var opts []CallOption
+		if len(args) == 3 {
+			opts = args[2].Interface().([]CallOption)
+		}
+		var resp reflect.Value
+		var respPtr interface{}
+		if respType != nil {
+			resp = reflect.New(respType)
+			respPtr = resp.Interface()
+		}
+		err := c.Call(ctx, args[1].Interface(), respPtr, opts...)
+		errv := reflect.New(errorType).Elem()
+		if err != nil {
+			errv.Set(reflect.ValueOf(err))
+		}
+		if respType == nil {
+			return []reflect.Value{errv}
+		}
+		if err != nil {
+			return []reflect.Value{reflect.Zero(respType), errv}
+		}
+		return []reflect.Value{resp.Elem(), errv}
+	}), nil
+}
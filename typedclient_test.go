@@ -0,0 +1,99 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type typedItemClient struct {
+	GetItem   func(ctx context.Context, p *batchItemRequest) (int, error)
+	GetStatus func(ctx context.Context, p *statusRequest, opts ...httprequest.CallOption) error
+
+	// Fields that aren't exported functions are ignored.
+	Name    string
+	private func()
+}
+
+func TestNewTypedClient(t *testing.T) {
+	c := qt.New(t)
+
+	srv := batchServer(new(int32))
+	defer srv.Close()
+	client, err := httprequest.NewTypedClient[typedItemClient](&httprequest.Client{
+		BaseURL: srv.URL,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(client.private, qt.IsNil)
+
+	n, err := client.GetItem(context.Background(), &batchItemRequest{ID: 4})
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 40)
+
+	n, err = client.GetItem(context.Background(), &batchItemRequest{ID: -4})
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/item/-4: item -4 not found`)
+	c.Assert(errgo.Cause(err), qt.Satisfies, isRemoteError)
+	c.Assert(n, qt.Equals, 0)
+}
+
+func TestNewTypedClientCallOptions(t *testing.T) {
+	c := qt.New(t)
+
+	srv := statusServer()
+	defer srv.Close()
+	client, err := httprequest.NewTypedClient[typedItemClient](&httprequest.Client{
+		BaseURL: srv.URL,
+	})
+	c.Assert(err, qt.IsNil)
+
+	err = client.GetStatus(context.Background(), &statusRequest{Code: 201})
+	c.Assert(err, qt.IsNil)
+
+	err = client.GetStatus(context.Background(), &statusRequest{Code: 201}, httprequest.WithExpectedStatus(http.StatusOK))
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/status/201: unexpected HTTP response status: 201 Created`)
+}
+
+func TestNewTypedClientBadTypes(t *testing.T) {
+	c := qt.New(t)
+
+	_, err := httprequest.NewTypedClient[struct {
+		F func(p *batchItemRequest) error
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: func\(\*httprequest_test.batchItemRequest\) error: want func\(context.Context, \*T\[, ...httprequest.CallOption\]\)`)
+
+	_, err = httprequest.NewTypedClient[struct {
+		F func(ctx context.Context, p *batchItemRequest, opts ...string) error
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: .*: want func\(context.Context, \*T\[, ...httprequest.CallOption\]\)`)
+
+	_, err = httprequest.NewTypedClient[struct {
+		F func(ctx string, p *batchItemRequest) error
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: .*: first argument is not context.Context`)
+
+	_, err = httprequest.NewTypedClient[struct {
+		F func(ctx context.Context, p batchItemRequest) error
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: .*: second argument is not a pointer`)
+
+	_, err = httprequest.NewTypedClient[struct {
+		F func(ctx context.Context, p *struct{}) error
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: .*: type \*struct {} has no httprequest.Route field`)
+
+	_, err = httprequest.NewTypedClient[struct {
+		F func(ctx context.Context, p *batchItemRequest) int
+	}](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `bad type for field F: .*: result is not error or \(T, error\)`)
+
+	_, err = httprequest.NewTypedClient[int](&httprequest.Client{})
+	c.Assert(err, qt.ErrorMatches, `type int is not a struct`)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	errgo "gopkg.in/errgo.v1"
+)
+
+// unixSocketClients holds the *http.Client used for each Unix domain
+// socket, keyed by the socket's path, so that connections are reused.
+var unixSocketClients sync.Map
+
+// unixSocketDoer returns a Doer that sends requests to the Unix domain
+// socket named by req.URL, which has the "unix" scheme, and changes
+// req.URL to the HTTP URL of the resource served on the socket.
+//
+// The path of the socket is the part of the URL path up to and
+// including the first element with a ".sock" or ".socket" suffix,
+// and the rest of the path is the path of the resource; for example
+// the URL unix:///run/foo.sock/v1/items names the resource /v1/items
+// served on the socket /run/foo.sock.
+func unixSocketDoer(req *http.Request) (Doer, error) {
+	socket, path, ok := splitUnixSocketPath(req.URL.Path)
+	if !ok {
+		return nil, errgo.Newf("no socket file found in %q (want a path element ending in .sock or .socket)", req.URL)
+	}
+	u := *req.URL
+	u.Scheme = "http"
+	u.Host = "localhost"
+	u.Path = path
+	u.RawPath = ""
+	req.URL = &u
+	req.Host = u.Host
+	if client, ok := unixSocketClients.Load(socket); ok {
+		return client.(*http.Client), nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+	client, _ := unixSocketClients.LoadOrStore(socket, &http.Client{
+		Transport: transport,
+	})
+	return client.(*http.Client), nil
+}
+
+// splitUnixSocketPath splits the path of a unix URL into the path of
+// the socket and the path of the resource served on it.
+func splitUnixSocketPath(p string) (socket, path string, ok bool) {
+	elems := strings.SplitAfter(p, "/")
+	for i, elem := range elems {
+		elem = strings.TrimSuffix(elem, "/")
+		if !strings.HasSuffix(elem, ".sock") && !strings.HasSuffix(elem, ".socket") {
+			continue
+		}
+		socket = strings.TrimSuffix(strings.Join(elems[:i+1], ""), "/")
+		path = "/" + strings.Join(elems[i+1:], "")
+		return socket, path, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// unixSocketServer serves the handlers of statusServer on a Unix
+// domain socket with the given file name in a temporary directory,
+// returning the path of the socket.
+func unixSocketServer(c *qt.C, name string) string {
+	path := filepath.Join(c.TempDir(), name)
+	l, err := net.Listen("unix", path)
+	c.Assert(err, qt.IsNil)
+	var srv httprequest.Server
+	server := &http.Server{
+		Handler: srv.NewRouter([]httprequest.Handler{
+			srv.Handle(func(p httprequest.Params, arg *struct {
+				httprequest.Route `httprequest:"GET /v1/status/:code"`
+				Code              int `httprequest:"code,path"`
+			}) {
+				httprequest.WriteJSON(p.Response, arg.Code, arg.Code)
+			}),
+		}),
+	}
+	go server.Serve(l)
+	c.Cleanup(func() {
+		server.Close()
+	})
+	return path
+}
+
+func TestUnixSocketBaseURL(t *testing.T) {
+	c := qt.New(t)
+
+	path := unixSocketServer(c, "api.sock")
+	client := &httprequest.Client{
+		BaseURL: "unix://" + path + "/v1",
+	}
+	var code int
+	err := client.Call(context.Background(), &statusRequest{Code: 201}, &code)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 201)
+
+	err = client.Get(context.Background(), "/status/202", &code)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 202)
+
+	// An absolute unix URL can be used too.
+	client = &httprequest.Client{}
+	err = client.Get(context.Background(), "unix://"+path+"/v1/status/203", &code)
+	c.Assert(err, qt.IsNil)
+	c.Assert(code, qt.Equals, 203)
+}
+
+func TestUnixSocketBaseURLErrors(t *testing.T) {
+	c := qt.New(t)
+
+	client := &httprequest.Client{
+		BaseURL: "unix:///run/api/v1",
+	}
+	err := client.Get(context.Background(), "/status/200", nil)
+	c.Assert(err, qt.ErrorMatches, `no socket file found in "unix:///run/api/v1/status/200" \(want a path element ending in .sock or .socket\)`)
+
+	client = &httprequest.Client{
+		BaseURL: "unix:///run/api.socket/v1",
+		Doer:    http.DefaultClient,
+	}
+	err = client.Get(context.Background(), "/status/200", nil)
+	c.Assert(err, qt.ErrorMatches, `cannot use Unix domain socket URL "unix:///run/api.socket/v1/status/200" with a custom Doer`)
+
+	client = &httprequest.Client{
+		BaseURL: "unix://" + filepath.Join(c.TempDir(), "missing.sock"),
+	}
+	err = client.Get(context.Background(), "/status/200", nil)
+	c.Assert(err, qt.ErrorMatches, `Get "?http://localhost/status/200"?: dial unix .*missing.sock: connect: no such file or directory`)
+}
@@ -37,6 +37,27 @@ var (
 //	"body" - the field is filled in by parsing the request body
 //		as JSON.
 //
+//	"multipart" - the field, which must be of type
+//		*multipart.Reader, is set to a reader for the parts of
+//		a multipart/form-data request body. Parts are read only
+//		as the handler asks for them, so large uploads are
+//		streamed rather than buffered in memory or temporary
+//		files. A multipart field cannot be used together with
+//		body or inbody fields.
+//
+// A body field may also have one of the following flags:
+//
+//	"mergepatch" - the body is an RFC 7386 JSON merge patch
+//		document with content type application/merge-patch+json,
+//		and the field must be of type MergePatch.
+//
+//	"jsonpatch" - the body is an RFC 6902 JSON patch document with
+//		content type application/json-patch+json, and the field
+//		must be of type JSONPatch.
+//
+// The handler can then apply the patch to the current value of the
+// resource with the field's Apply method.
+//
 // For path and form parameters, the field will be filled out from
 // the field in p.PathVar or p.Form using one of the following
 // methods (in descending order of preference):
@@ -52,7 +73,8 @@ var (
 // -  otherwise fmt.Sscan will be used to set the value.
 //
 // When the unmarshaling fails, Unmarshal returns an error with an
-// ErrUnmarshal cause. If the type of x is inappropriate,
+// ErrUnmarshal cause that wraps a *FieldsError describing every
+// field that could not be unmarshaled. If the type of x is inappropriate,
 // it returns an error with an ErrBadUnmarshalType cause.
 func Unmarshal(p Params, x interface{}) error {
 	xv := reflect.ValueOf(x)
@@ -69,12 +91,16 @@ func Unmarshal(p Params, x interface{}) error {
 // unmarshal is the internal version of Unmarshal.
 func unmarshal(p Params, xv reflect.Value, pt *requestType) error {
 	xv = xv.Elem()
+	var ferr FieldsError
 	for _, f := range pt.fields {
 		fv := xv.FieldByIndex(f.index)
 		if err := f.unmarshal(fv, p, f.makeResult); err != nil {
-			return errgo.WithCausef(err, ErrUnmarshal, "cannot unmarshal into field %s", f.name)
+			ferr.add(f.name, err)
 		}
 	}
+	if len(ferr.Fields) > 0 {
+		return errgo.WithCausef(&ferr, ErrUnmarshal, "")
+	}
 	return nil
 }
 
@@ -85,7 +111,15 @@ func getUnmarshaler(tag tag, t reflect.Type) (unmarshaler, error) {
 	switch {
 	case tag.source == sourceNone:
 		return unmarshalNop, nil
+	case tag.source == sourceMultipart:
+		return unmarshalMultipart, nil
 	case tag.source == sourceBody:
+		switch {
+		case tag.bodyContentType == MergePatchContentType && t != mergePatchType:
+			return nil, errgo.Newf("mergepatch body field has type %s, need httprequest.MergePatch", t)
+		case tag.bodyContentType == JSONPatchContentType && t != jsonPatchType:
+			return nil, errgo.Newf("jsonpatch body field has type %s, need httprequest.JSONPatch", t)
+		}
 		return unmarshalBody, nil
 	case t == reflect.TypeOf([]string(nil)):
 		switch tag.source {
@@ -156,9 +190,10 @@ func unmarshalString(tag tag) unmarshaler {
 // into the given value.
 func unmarshalBody(v reflect.Value, p Params, makeResult resultMaker) error {
 	if !isJSONMediaType(p.Request.Header) {
-		fancyErr := newFancyDecodeError(p.Request.Header, p.Request.Body)
+		limit := maxErrorBodySizeFromContext(p.Request.Context())
+		fancyErr := newFancyDecodeError(p.Request.Header, p.Request.Body, limit)
 
-		return newDecodeRequestError(p.Request, fancyErr.body, fancyErr)
+		return newDecodeRequestError(p.Request, fancyErr.body, fancyErr, limit)
 	}
 	data, err := ioutil.ReadAll(p.Request.Body)
 	if err != nil {
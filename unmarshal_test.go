@@ -270,6 +270,23 @@ var unmarshalTests = []struct {
 		},
 	},
 	expectError: `cannot unmarshal into field A: cannot parse "not an int" into int: expected integer`,
+}, {
+	about: "several invalid fields",
+	val: struct {
+		A int `httprequest:",form"`
+		B int `httprequest:",form"`
+		C int `httprequest:",form"`
+	}{},
+	params: httprequest.Params{
+		Request: &http.Request{
+			Form: url.Values{
+				"A": {"not an int"},
+				"B": {"1"},
+				"C": {"nor this"},
+			},
+		},
+	},
+	expectError: `cannot unmarshal into field A: cannot parse "not an int" into int: expected integer; cannot unmarshal into field C: cannot parse "nor this" into int: expected integer`,
 }, {
 	about: "scan field not present",
 	val: struct {
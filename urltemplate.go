@@ -0,0 +1,85 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	errgo "gopkg.in/errgo.v1"
+)
+
+// WithURLVar returns a CallOption that supplies the value of a
+// variable in the URL template passed to Client.CallURL or in the path
+// of the route, for variables that are not set by fields of the call's
+// parameters. For example:
+//
+//	err := client.CallURL(ctx, "https://api.example.com/orgs/{org}/v1?api-version=3", &req, &resp,
+//		httprequest.WithURLVar("org", org),
+//	)
+//
+// A variable may appear in the URL either as {name}, where its escaped
+// value may appear in the path or the query, or as a :name path
+// segment, as in the path of a route. A value given with WithURLVar
+// takes precedence over one from the parameters.
+func WithURLVar(name, value string) CallOption {
+	return func(o *callOptions) {
+		o.urlVars = append(o.urlVars, httprouter.Param{
+			Key:   name,
+			Value: value,
+		})
+	}
+}
+
+// expandURLTemplate returns the URL template s with each {name}
+// variable replaced by its escaped value in vars.
+func expandURLTemplate(template string, vars httprouter.Params) (string, error) {
+	if !strings.Contains(template, "{") {
+		return template, nil
+	}
+	s := template
+	var buf strings.Builder
+	inQuery := false
+	for {
+		i := strings.IndexAny(s, "{?")
+		if i == -1 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		buf.WriteString(s[:i])
+		if s[i] == '?' {
+			inQuery = true
+			buf.WriteByte('?')
+			s = s[i+1:]
+			continue
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j == -1 {
+			return "", errgo.Newf("unterminated variable in URL template %q", template)
+		}
+		name := s[i+1 : i+j]
+		value, ok := urlVar(vars, name)
+		if !ok {
+			return "", errgo.Newf("no value for URL template variable %q", name)
+		}
+		if inQuery {
+			buf.WriteString(url.QueryEscape(value))
+		} else {
+			buf.WriteString(url.PathEscape(value))
+		}
+		s = s[i+j+1:]
+	}
+}
+
+// urlVar returns the value of the named variable in vars
+// and reports whether there is one.
+func urlVar(vars httprouter.Params, name string) (string, bool) {
+	for _, v := range vars {
+		if v.Key == name {
+			return v.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type urlTemplateRequest struct {
+	httprequest.Route `httprequest:"GET /items/:id"`
+	ID                string `httprequest:"id,path"`
+	Query             string `httprequest:"q,form,omitempty"`
+}
+
+var callURLTemplateTests = []struct {
+	about       string
+	url         string
+	opts        []httprequest.CallOption
+	expectURL   string
+	expectError string
+}{{
+	about:     "query retained",
+	url:       "http://example.com/api?key=a%2Fb&v=1",
+	expectURL: "http://example.com/api/items/42?key=a%2Fb&v=1&q=hello+world",
+}, {
+	about: "brace variables",
+	url:   "http://example.com/orgs/{org}/api?tenant={tenant}",
+	opts: []httprequest.CallOption{
+		httprequest.WithURLVar("org", "my org"),
+		httprequest.WithURLVar("tenant", "a&b"),
+	},
+	expectURL: "http://example.com/orgs/my%20org/api/items/42?tenant=a%26b&q=hello+world",
+}, {
+	about: "path segment variable",
+	url:   "http://example.com/orgs/:org",
+	opts: []httprequest.CallOption{
+		httprequest.WithURLVar("org", "canonical"),
+	},
+	expectURL: "http://example.com/orgs/canonical/items/42?q=hello+world",
+}, {
+	about: "option takes precedence",
+	url:   "http://example.com",
+	opts: []httprequest.CallOption{
+		httprequest.WithURLVar("id", "99"),
+	},
+	expectURL: "http://example.com/items/99?q=hello+world",
+}, {
+	about:       "missing brace variable",
+	url:         "http://example.com/orgs/{org}",
+	expectError: `no value for URL template variable "org"`,
+}, {
+	about:       "unterminated brace variable",
+	url:         "http://example.com/orgs/{org",
+	expectError: `unterminated variable in URL template "http://example.com/orgs/{org"`,
+}, {
+	about:       "missing path segment variable",
+	url:         "http://example.com/orgs/:org",
+	expectError: `missing value for path parameter "org"`,
+}}
+
+func TestCallURLTemplate(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range callURLTemplateTests {
+		c.Run(test.about, func(c *qt.C) {
+			var gotURL string
+			client := httprequest.Client{
+				Doer: doerFunc(func(req *http.Request) (*http.Response, error) {
+					gotURL = req.URL.String()
+					return &http.Response{
+						StatusCode: http.StatusNoContent,
+						Header:     make(http.Header),
+						Body:       http.NoBody,
+						Request:    req,
+					}, nil
+				}),
+			}
+			err := client.CallURL(context.Background(), test.url, &urlTemplateRequest{
+				ID:    "42",
+				Query: "hello world",
+			}, nil, test.opts...)
+			if test.expectError != "" {
+				c.Assert(err, qt.ErrorMatches, test.expectError)
+				return
+			}
+			c.Assert(err, qt.IsNil)
+			c.Assert(gotURL, qt.Equals, test.expectURL)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package vcr provides an httprequest.Doer that records HTTP
+// interactions to a file and replays them, so that tests of code that
+// calls external APIs can run deterministically without network access.
+//
+// A test typically records the interactions once by running with a
+// Recorder in ModeRecord against the real API, and then checks in the
+// resulting file and runs with ModeReplay from then on.
+package vcr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	errgo "gopkg.in/errgo.v1"
+
+	"gopkg.in/httprequest.v1"
+)
+
+// Mode specifies whether a Recorder records or replays interactions.
+type Mode int
+
+const (
+	// ModeReplay specifies that responses are replayed from
+	// the recorded interactions and no requests are sent.
+	ModeReplay Mode = iota
+
+	// ModeRecord specifies that requests are sent and the
+	// interactions are recorded.
+	ModeRecord
+)
+
+// Redacted holds the value that replaces the values of redacted
+// headers in recorded interactions.
+const Redacted = "REDACTED"
+
+// defaultRedactedHeaders holds the headers that
+// are always redacted.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+}
+
+// Recorder is an httprequest.Doer that records and replays HTTP
+// interactions. It is safe to call concurrently.
+type Recorder struct {
+	// Path holds the name of the file holding the recorded
+	// interactions.
+	Path string
+
+	// Mode specifies whether the Recorder records or replays.
+	Mode Mode
+
+	// Doer is used to send requests in ModeRecord. If it is nil,
+	// http.DefaultClient is used.
+	Doer httprequest.Doer
+
+	// Patterns holds path patterns, such as "/users/:id", in the form
+	// accepted by httprouter. A request whose path matches one of the
+	// patterns is matched with recorded requests whose paths match
+	// the same pattern; other requests are matched with recorded
+	// requests with the same path. A request also matches only
+	// recorded requests with the same method and body.
+	Patterns []string
+
+	// RedactHeaders holds the names of headers whose values are
+	// replaced with Redacted in recorded requests and responses, in
+	// addition to Authorization, Cookie, Proxy-Authorization and
+	// Set-Cookie.
+	RedactHeaders []string
+
+	// RedactBody, if not nil, is called to remove sensitive data from
+	// request and response bodies before they are recorded. It is
+	// also called on request bodies before they are matched with
+	// recorded requests.
+	RedactBody func(body []byte) []byte
+
+	mu           sync.Mutex
+	loaded       bool
+	interactions []*Interaction
+	used         []bool
+}
+
+// Interaction holds a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request holds a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response holds a recorded response.
+type Response struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body holds a recorded request or response body. It is recorded as a
+// JSON string if it holds valid UTF-8 text, and as an object holding
+// the base64-encoded data otherwise.
+type Body []byte
+
+// MarshalJSON implements json.Marshaler.
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(struct {
+		Base64 string `json:"base64"`
+	}{base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return errgo.Mask(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	*b = data
+	return nil
+}
+
+// cassette holds the contents of a Recorder's file.
+type cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Do implements httprequest.Doer by replaying a recorded response to
+// req, or by sending req and recording the interaction.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	if r.RedactBody != nil {
+		body = r.RedactBody(body)
+	}
+	if r.Mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+// replay returns the first unused recorded response to a request
+// matching req, which has the given body.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.load(); err != nil {
+		return nil, errgo.Mask(err)
+	}
+	for i, in := range r.interactions {
+		if r.used[i] || !r.matches(in, req, body) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errgo.Newf("no recorded response for %s %s", req.Method, req.URL)
+}
+
+// load loads the recorded interactions if they have not already been
+// loaded. It must be called with r.mu held.
+func (r *Recorder) load() error {
+	if r.loaded {
+		return nil
+	}
+	data, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		return errgo.Notef(err, "cannot read recorded interactions")
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return errgo.Notef(err, "cannot unmarshal recorded interactions from %q", r.Path)
+	}
+	r.interactions = c.Interactions
+	r.used = make([]bool, len(c.Interactions))
+	r.loaded = true
+	return nil
+}
+
+// matches reports whether the recorded interaction in
+// matches req, which has the given body.
+func (r *Recorder) matches(in *Interaction, req *http.Request, body []byte) bool {
+	if in.Request.Method != req.Method || !bytes.Equal(in.Request.Body, body) {
+		return false
+	}
+	u, err := req.URL.Parse(in.Request.URL)
+	if err != nil {
+		return false
+	}
+	if pattern := r.pattern(req.URL.Path); pattern != "" {
+		return r.pattern(u.Path) == pattern
+	}
+	return u.Path == req.URL.Path
+}
+
+// pattern returns the first of r.Patterns that matches
+// path, or "" if there is none.
+func (r *Recorder) pattern(path string) string {
+	for _, p := range r.Patterns {
+		if matchPattern(p, path) {
+			return p
+		}
+	}
+	return ""
+}
+
+// matchPattern reports whether path matches the httprouter path
+// pattern p.
+func matchPattern(p, path string) bool {
+	pelems := strings.Split(p, "/")
+	elems := strings.Split(path, "/")
+	for i, pe := range pelems {
+		if strings.HasPrefix(pe, "*") {
+			return i < len(elems)
+		}
+		if i >= len(elems) {
+			return false
+		}
+		if strings.HasPrefix(pe, ":") {
+			if elems[i] == "" {
+				return false
+			}
+			continue
+		}
+		if pe != elems[i] {
+			return false
+		}
+	}
+	return len(pelems) == len(elems)
+}
+
+// record sends req, which has the given redacted body, and records
+// the interaction.
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	doer := r.Doer
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot read response body")
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	if r.RedactBody != nil {
+		respBody = r.RedactBody(respBody)
+	}
+	in := &Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redactHeader(req.Header),
+			Body:   body,
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       respBody,
+		},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, in)
+	return resp, nil
+}
+
+// redactHeader returns a copy of h with the values
+// of the headers to be redacted replaced.
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, names := range [][]string{defaultRedactedHeaders, r.RedactHeaders} {
+		for _, name := range names {
+			name = http.CanonicalHeaderKey(name)
+			if vs := h[name]; vs != nil {
+				redacted := make([]string, len(vs))
+				for i := range redacted {
+					redacted[i] = Redacted
+				}
+				h[name] = redacted
+			}
+		}
+	}
+	return h
+}
+
+// Save writes the interactions recorded in ModeRecord to r.Path.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(cassette{
+		Interactions: r.interactions,
+	}, "", "\t")
+	if err != nil {
+		return errgo.Mask(err)
+	}
+	if err := ioutil.WriteFile(r.Path, append(data, '\n'), 0644); err != nil {
+		return errgo.Notef(err, "cannot write recorded interactions")
+	}
+	return nil
+}
+
+// readRequestBody returns the body of req, leaving
+// req.Body so that it can be read again.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, errgo.Notef(err, "cannot read request body")
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
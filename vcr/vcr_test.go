@@ -0,0 +1,185 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package vcr_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"gopkg.in/httprequest.v1"
+	"gopkg.in/httprequest.v1/vcr"
+)
+
+type getUserRequest struct {
+	httprequest.Route `httprequest:"GET /users/:id"`
+	ID                string `httprequest:"id,path"`
+}
+
+type createUserRequest struct {
+	httprequest.Route `httprequest:"POST /users"`
+	User              user `httprequest:",body"`
+}
+
+type user struct {
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty"`
+}
+
+// userServer returns a server that returns users named after their
+// IDs and echoes the names of created users.
+func userServer() *httptest.Server {
+	var srv httprequest.Server
+	return httptest.NewServer(srv.NewRouter([]httprequest.Handler{
+		srv.Handle(func(p httprequest.Params, req *getUserRequest) (user, error) {
+			p.Response.Header().Set("Set-Cookie", "session=secret")
+			return user{Name: "user-" + req.ID}, nil
+		}),
+		srv.Handle(func(p httprequest.Params, req *createUserRequest) (user, error) {
+			return user{Name: req.User.Name}, nil
+		}),
+	}))
+}
+
+func redactPassword(body []byte) []byte {
+	return bytes.Replace(body, []byte("hunter2"), []byte("REDACTED"), -1)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(c.TempDir(), "users.json")
+	srv := userServer()
+	recorder := &vcr.Recorder{
+		Path:          path,
+		Mode:          vcr.ModeRecord,
+		Patterns:      []string{"/users/:id"},
+		RedactHeaders: []string{"X-Api-Key"},
+		RedactBody:    redactPassword,
+	}
+	client := &httprequest.Client{
+		BaseURL: srv.URL,
+		Doer:    recorder,
+	}
+	ctx := context.Background()
+	var u user
+	err := client.Call(ctx, &getUserRequest{ID: "1"}, &u, httprequest.WithHeader("X-Api-Key", "key"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(u, qt.Equals, user{Name: "user-1"})
+	err = client.Call(ctx, &createUserRequest{User: user{Name: "bob", Password: "hunter2"}}, &u)
+	c.Assert(err, qt.IsNil)
+	c.Assert(u, qt.Equals, user{Name: "bob"})
+	err = recorder.Save()
+	c.Assert(err, qt.IsNil)
+	srv.Close()
+
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(data), qt.Not(qt.Contains), "hunter2")
+	c.Assert(string(data), qt.Not(qt.Contains), "secret")
+	c.Assert(string(data), qt.Not(qt.Contains), `"key"`)
+
+	// Replay the interactions with the server gone.
+	recorder = &vcr.Recorder{
+		Path:       path,
+		Patterns:   []string{"/users/:id"},
+		RedactBody: redactPassword,
+	}
+	client.Doer = recorder
+
+	// The request matches the recorded one by path pattern.
+	u = user{}
+	err = client.Call(ctx, &getUserRequest{ID: "2"}, &u)
+	c.Assert(err, qt.IsNil)
+	c.Assert(u, qt.Equals, user{Name: "user-1"})
+
+	// The request body is redacted before it is matched.
+	u = user{}
+	err = client.Call(ctx, &createUserRequest{User: user{Name: "bob", Password: "hunter2"}}, &u)
+	c.Assert(err, qt.IsNil)
+	c.Assert(u, qt.Equals, user{Name: "bob"})
+
+	// Each recorded response is replayed once.
+	err = client.Call(ctx, &getUserRequest{ID: "1"}, &u)
+	c.Assert(err, qt.ErrorMatches, `Get http://.*/users/1: no recorded response for GET http://.*/users/1`)
+
+	// A request with a different body doesn't match.
+	err = client.Call(ctx, &createUserRequest{User: user{Name: "alice"}}, &u)
+	c.Assert(err, qt.ErrorMatches, `Post http://.*/users: no recorded response for POST http://.*/users`)
+}
+
+func TestReplayExactPath(t *testing.T) {
+	c := qt.New(t)
+
+	path := filepath.Join(c.TempDir(), "users.json")
+	err := ioutil.WriteFile(path, []byte(`{
+	"interactions": [{
+		"request": {"method": "GET", "url": "http://example.com/users/1"},
+		"response": {
+			"status": 404,
+			"header": {"Content-Type": ["application/json"]},
+			"body": "{\"message\": \"not found\"}"
+		}
+	}, {
+		"request": {"method": "GET", "url": "http://example.com/users/2"},
+		"response": {
+			"status": 200,
+			"header": {"Content-Type": ["application/octet-stream"]},
+			"body": {"base64": "/wA="}
+		}
+	}]
+}`), 0644)
+	c.Assert(err, qt.IsNil)
+	client := &httprequest.Client{
+		BaseURL: "http://example.com",
+		Doer: &vcr.Recorder{
+			Path: path,
+		},
+	}
+	err = client.Call(context.Background(), &getUserRequest{ID: "1"}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/users/1: not found`)
+
+	var resp *http.Response
+	err = client.Call(context.Background(), &getUserRequest{ID: "2"}, &resp)
+	c.Assert(err, qt.IsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, qt.IsNil)
+	c.Assert(body, qt.DeepEquals, []byte{0xff, 0})
+
+	err = client.Call(context.Background(), &getUserRequest{ID: "3"}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/users/3: no recorded response for GET http://example.com/users/3`)
+}
+
+func TestReplayMissingFile(t *testing.T) {
+	c := qt.New(t)
+
+	client := &httprequest.Client{
+		BaseURL: "http://example.com",
+		Doer: &vcr.Recorder{
+			Path: filepath.Join(c.TempDir(), "missing.json"),
+		},
+	}
+	err := client.Call(context.Background(), &getUserRequest{ID: "1"}, nil)
+	c.Assert(err, qt.ErrorMatches, `Get http://example.com/users/1: cannot read recorded interactions: .*`)
+}
+
+func TestBodyRoundTrip(t *testing.T) {
+	c := qt.New(t)
+
+	for _, body := range []vcr.Body{vcr.Body("text"), vcr.Body{0xff, 0xfe}} {
+		data, err := body.MarshalJSON()
+		c.Assert(err, qt.IsNil)
+		var got vcr.Body
+		err = got.UnmarshalJSON(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.DeepEquals, body)
+	}
+}
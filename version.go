@@ -0,0 +1,171 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// VersionedHandlers is like HandlersFromAll except that methods whose
+// routes declare an API version with the version tag (see
+// RouteMetadata.Version) are served under a path prefixed with "/v"
+// and the version, so that several handler types can serve different
+// versions of the same API. For example, a method with the tag
+//
+//	httprequest:"GET /users/:id" version:"2"
+//
+// is served at /v2/users/:id, and PathPattern in its Params is
+// "/v2/users/:id".
+//
+// Each versioned route is also served at its unprefixed path, where
+// the version is chosen by the version parameter of the Accept header,
+// for example "Accept: application/json; version=2". Responses from
+// such routes vary by the Accept header. When no version is requested,
+// the earliest version is used so that existing clients are not
+// affected by the addition of new versions; when a version is
+// requested that the route does not have, the response is an error
+// with code CodeNotAcceptable.
+//
+// Methods whose routes do not declare a version are served at their
+// paths unchanged. As with HandlersFromAll, VersionedHandlers will
+// panic if any routes conflict, including the unprefixed paths of
+// versioned routes.
+func (srv *Server) VersionedHandlers(fs ...interface{}) []Handler {
+	var hs []Handler
+	routes := new(routeChecker)
+	for _, f := range fs {
+		hs = append(hs, srv.handlers("", f, routes, true)...)
+	}
+	for _, h := range srv.versionDispatchers(hs) {
+		if err := routes.add(h, "version dispatch"); err != nil {
+			panic(err)
+		}
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// versionDispatchers returns a handler for the unprefixed path of
+// each versioned route in hs that dispatches to the version chosen by
+// the request's Accept header.
+func (srv *Server) versionDispatchers(hs []Handler) []Handler {
+	type route struct {
+		method string
+		path   string
+	}
+	var routes []route
+	versions := make(map[route][]Handler)
+	for _, h := range hs {
+		if h.Metadata.Version == "" {
+			continue
+		}
+		r := route{
+			method: h.Method,
+			path:   strings.TrimPrefix(h.Path, versionPrefix(h.Metadata.Version)),
+		}
+		if versions[r] == nil {
+			routes = append(routes, r)
+		}
+		versions[r] = append(versions[r], h)
+	}
+	dispatchers := make([]Handler, 0, len(routes))
+	for _, r := range routes {
+		vhs := versions[r]
+		sort.SliceStable(vhs, func(i, j int) bool {
+			return versionLess(vhs[i].Metadata.Version, vhs[j].Metadata.Version)
+		})
+		dispatchers = append(dispatchers, Handler{
+			Method:   r.method,
+			Path:     r.path,
+			Handle:   srv.dispatchVersion(vhs),
+			Metadata: vhs[0].Metadata,
+			Params:   vhs[0].Params,
+		})
+	}
+	return dispatchers
+}
+
+// dispatchVersion returns a handler that calls the handler in hs, which
+// are ordered by version, for the version requested by the Accept
+// header, or the first if no version is requested.
+func (srv *Server) dispatchVersion(hs []Handler) httprouter.Handle {
+	available := make([]string, len(hs))
+	for i, h := range hs {
+		available[i] = h.Metadata.Version
+	}
+	return func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		w.Header().Add("Vary", "Accept")
+		version := acceptVersion(req.Header)
+		if version == "" {
+			hs[0].Handle(w, req, p)
+			return
+		}
+		for _, h := range hs {
+			if h.Metadata.Version == version {
+				h.Handle(w, req, p)
+				return
+			}
+		}
+		srv.WriteError(req.Context(), w, Errorf(CodeNotAcceptable, "API version %q not available (available versions: %s)", version, strings.Join(available, ", ")))
+	}
+}
+
+// acceptVersion returns the API version requested by the version
+// parameter of the Accept header in h, or "" if there is none.
+func acceptVersion(h http.Header) string {
+	for _, v := range h["Accept"] {
+		for _, mediaRange := range strings.Split(v, ",") {
+			_, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			if version := params["version"]; version != "" {
+				return version
+			}
+		}
+	}
+	return ""
+}
+
+// versionPrefix returns the path prefix under which
+// routes with the given version are served.
+func versionPrefix(version string) string {
+	return "/v" + version
+}
+
+// isValidVersion reports whether s can be used as an API version: it
+// must be a non-empty string of letters, digits, dots, hyphens and
+// underscores.
+func isValidVersion(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// versionLess reports whether version v1 is earlier than v2. Versions
+// that are both integers are compared numerically; others are compared
+// as strings.
+func versionLess(v1, v2 string) bool {
+	n1, err1 := strconv.Atoi(v1)
+	n2, err2 := strconv.Atoi(v2)
+	if err1 == nil && err2 == nil {
+		return n1 < n2
+	}
+	return v1 < v2
+}
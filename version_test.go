@@ -0,0 +1,176 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package httprequest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/juju/qthttptest"
+
+	"gopkg.in/httprequest.v1"
+)
+
+type userV1Handlers struct{}
+
+func (userV1Handlers) Get(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /users/:id" version:"1"`
+	ID                string `httprequest:"id,path"`
+}) (map[string]string, error) {
+	return map[string]string{"name": arg.ID, "pattern": p.PathPattern}, nil
+}
+
+func (userV1Handlers) Health(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /health"`
+}) (string, error) {
+	return "ok", nil
+}
+
+type userV2Handlers struct{}
+
+func (userV2Handlers) Get(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /users/:id" version:"2"`
+	ID                string `httprequest:"id,path"`
+}) (map[string]string, error) {
+	return map[string]string{"fullName": arg.ID, "pattern": p.PathPattern}, nil
+}
+
+type userV10Handlers struct{}
+
+func (userV10Handlers) Get(p httprequest.Params, arg *struct {
+	httprequest.Route `httprequest:"GET /users/:id" version:"10"`
+	ID                string `httprequest:"id,path"`
+}) (map[string]string, error) {
+	return map[string]string{"id": arg.ID, "pattern": p.PathPattern}, nil
+}
+
+var versionedHandlersTests = []struct {
+	about        string
+	path         string
+	accept       string
+	expectStatus int
+	expectBody   interface{}
+}{{
+	about:        "version prefix",
+	path:         "/v2/users/bob",
+	expectStatus: http.StatusOK,
+	expectBody:   map[string]string{"fullName": "bob", "pattern": "/v2/users/:id"},
+}, {
+	about:        "numeric version ordering",
+	path:         "/v10/users/bob",
+	expectStatus: http.StatusOK,
+	expectBody:   map[string]string{"id": "bob", "pattern": "/v10/users/:id"},
+}, {
+	about:        "accept version",
+	path:         "/users/bob",
+	accept:       "application/json; version=2",
+	expectStatus: http.StatusOK,
+	expectBody:   map[string]string{"fullName": "bob", "pattern": "/v2/users/:id"},
+}, {
+	about:        "no accept version uses earliest",
+	path:         "/users/bob",
+	accept:       "application/json",
+	expectStatus: http.StatusOK,
+	expectBody:   map[string]string{"name": "bob", "pattern": "/v1/users/:id"},
+}, {
+	about:        "unknown accept version",
+	path:         "/users/bob",
+	accept:       "application/json; version=3",
+	expectStatus: http.StatusNotAcceptable,
+	expectBody: &httprequest.RemoteError{
+		Code:    httprequest.CodeNotAcceptable,
+		Message: `API version "3" not available (available versions: 1, 2, 10)`,
+	},
+}, {
+	about:        "unversioned route",
+	path:         "/health",
+	expectStatus: http.StatusOK,
+	expectBody:   "ok",
+}}
+
+func TestVersionedHandlers(t *testing.T) {
+	c := qt.New(t)
+
+	var srv httprequest.Server
+	hs := srv.VersionedHandlers(
+		func(p httprequest.Params) (userV2Handlers, context.Context, error) {
+			return userV2Handlers{}, p.Context, nil
+		},
+		func(p httprequest.Params) (userV10Handlers, context.Context, error) {
+			return userV10Handlers{}, p.Context, nil
+		},
+		func(p httprequest.Params) (userV1Handlers, context.Context, error) {
+			return userV1Handlers{}, p.Context, nil
+		},
+	)
+	var routes []string
+	for _, h := range hs {
+		routes = append(routes, h.Method+" "+h.Path)
+	}
+	c.Assert(routes, qt.DeepEquals, []string{
+		"GET /v2/users/:id",
+		"GET /v10/users/:id",
+		"GET /v1/users/:id",
+		"GET /health",
+		"GET /users/:id",
+	})
+	router := srv.NewRouter(hs)
+	for _, test := range versionedHandlersTests {
+		c.Run(test.about, func(c *qt.C) {
+			req := httptest.NewRequest("GET", test.path, nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			qthttptest.AssertJSONResponse(c, rec, test.expectStatus, test.expectBody)
+		})
+	}
+}
+
+type unversionedUserHandlers struct{}
+
+func (unversionedUserHandlers) Get(*struct {
+	httprequest.Route `httprequest:"GET /users/:name"`
+}) {
+}
+
+func TestVersionedHandlersPanicsWithConflictingRoutes(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(func() {
+		testServer.VersionedHandlers(
+			func(p httprequest.Params) (userV1Handlers, context.Context, error) {
+				return userV1Handlers{}, p.Context, nil
+			},
+			func(p httprequest.Params) (unversionedUserHandlers, context.Context, error) {
+				return unversionedUserHandlers{}, p.Context, nil
+			},
+		)
+	}, qt.PanicMatches, `GET /users/:id defined by version dispatch conflicts with GET /users/:name defined by httprequest_test.unversionedUserHandlers.Get`)
+	c.Assert(func() {
+		testServer.VersionedHandlers(
+			func(p httprequest.Params) (userV1Handlers, context.Context, error) {
+				return userV1Handlers{}, p.Context, nil
+			},
+			func(p httprequest.Params) (userV1Handlers, context.Context, error) {
+				return userV1Handlers{}, p.Context, nil
+			},
+		)
+	}, qt.PanicMatches, `GET /v1/users/:id defined by httprequest_test.userV1Handlers.Get conflicts with .*`)
+}
+
+func TestHandlersIgnoreVersion(t *testing.T) {
+	c := qt.New(t)
+
+	hs := testServer.Handlers(func(p httprequest.Params) (userV2Handlers, context.Context, error) {
+		return userV2Handlers{}, p.Context, nil
+	})
+	c.Assert(hs, qt.HasLen, 1)
+	c.Assert(hs[0].Path, qt.Equals, "/users/:id")
+	c.Assert(hs[0].Metadata.Version, qt.Equals, "2")
+}